@@ -12,6 +12,9 @@ kubectl() { command kubectl --context "$CLUSTER_CONTEXT" --warnings-as-errors "$
 helm()    { command helm --kube-context "$CLUSTER_CONTEXT" "$@"; }
 helmer()  { "$BATS_TEST_DIRNAME/../scripts/helmer.sh" "$@"; }
 
+# Namespace of Kubewarden, KUBEWARDEN_NAMESPACE is also used by the Go suite in tests/airgap
+NAMESPACE=${NAMESPACE:-${KUBEWARDEN_NAMESPACE:-kubewarden}}
+
 # Export for retry function (subshell)
 export -f kubectl helm
 
@@ -26,8 +29,8 @@ setup_helper() {
         # shellcheck disable=SC2317
         echo "$BATS_TEST_FILENAME" > "${BATS_RUN_TMPDIR}/.skip"
         # Collect logs (here or teardown?)
-        # kubectl logs -n kubewarden -l app.kubernetes.io/component=controller
-        # kubectl logs -n kubewarden -l app.kubernetes.io/component=policy-server
+        # kubectl logs -n $NAMESPACE -l app.kubernetes.io/component=controller
+        # kubectl logs -n $NAMESPACE -l app.kubernetes.io/component=policy-server
     }
 
     # Wait for kubewarden pods unless --no-wait tag is set
@@ -123,7 +126,7 @@ function wait_policies {
 function create_policyserver {
     local name="${1:-pserver}"
     local image
-    image="ghcr.io/$(helm get values -a kubewarden-defaults -n kubewarden -o json | jq -er '.policyServer.image | .repository + ":"+ .tag')"
+    image="ghcr.io/$(helm get values -a kubewarden-defaults -n "$NAMESPACE" -o json | jq -er '.policyServer.image | .repository + ":"+ .tag')"
     kubectl apply -f - <<EOF
 apiVersion: policies.kubewarden.io/v1
 kind: PolicyServer
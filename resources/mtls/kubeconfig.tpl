@@ -1,8 +1,8 @@
 apiVersion: v1
 kind: Config
 users:
-# Target all services in kubewarden namespace
-- name: '*.kubewarden.svc'
+# Target all services in Kubewarden namespace, rendered by cluster_k3d.sh
+- name: '*.${NAMESPACE}.svc'
   user:
     client-certificate: /etc/mtls/domain.crt
     client-key: /etc/mtls/domain.key
@@ -8,6 +8,7 @@ CLUSTER_NAME=${CLUSTER_NAME:-k3s-default}
 MASTER_COUNT=${MASTER_COUNT:-1}
 WORKER_COUNT=${WORKER_COUNT:-0}
 MTLS=${MTLS:-}
+NAMESPACE=${NAMESPACE:-${KUBEWARDEN_NAMESPACE:-kubewarden}}
 
 # Directory of the current script
 BASEDIR=$(dirname "${BASH_SOURCE[0]}")
@@ -31,6 +32,8 @@ if [ "${1:-}" == 'create' ]; then
     if [ -n "${MTLS:-}" ]; then
         MTLS_DIR=$(realpath -s "$BASEDIR/../resources/mtls/")
         generate_certs "$MTLS_DIR" mtls.kubewarden.io
+        # API server sends the client certificate to the services of the Kubewarden namespace only
+        envsubst '$NAMESPACE' < "$MTLS_DIR/kubeconfig.tpl" > "$MTLS_DIR/kubeconfig"
     fi
 
     # /dev/mapper: https://k3d.io/v5.7.4/faq/faq/#issues-with-btrfs
@@ -31,7 +31,7 @@ trap 'echo "Error on ${BASH_SOURCE/$PWD/.}:${LINENO} $(sed -n "${LINENO} s/^\s*/
 # Find if Rancher is installed
 RANCHER=${RANCHER:-$(helm status -n cattle-system rancher &>/dev/null && echo 1 || echo "")}
 
-NAMESPACE=${NAMESPACE:-kubewarden}
+NAMESPACE=${NAMESPACE:-${KUBEWARDEN_NAMESPACE:-kubewarden}}
 # Kubewarden helm repository
 REPO_NAME=${REPO_NAME:-kubewarden}
 # Use charts from [./dirname|reponame]
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Fill the cache of the external artifacts, e.g. before copying it on an airgap test host:
+//
+//	go run ./cmd/cache [-dir <dir>] [-kubectl <version>] [-helm <version>] [-kwctl <version>] [-policies <policy@version,...>]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/download"
+)
+
+func main() {
+	dir := flag.String("dir", download.Dir(), "Directory of the cache, given to the tests with E2E_CACHE_DIR")
+	arch := flag.String("arch", runtime.GOARCH, "Architecture of the binaries, in GOARCH format")
+	kubectl := flag.String("kubectl", "v1.28.2", "Version of kubectl, not downloaded if empty")
+	helm := flag.String("helm", "", "Version of helm, not downloaded if empty")
+	kwctl := flag.String("kwctl", "", "Version of kwctl, not downloaded if empty")
+	policies := flag.String("policies", "", "Wasm modules of policies, in policy@version format, comma separated")
+	flag.Parse()
+
+	if err := os.Setenv("E2E_CACHE_DIR", *dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot use %s: %v\n", *dir, err)
+		os.Exit(1)
+	}
+
+	artifacts := []download.Artifact{download.K3sScript(os.Getenv("K3S_INSTALL_SHA256"))}
+	if *kubectl != "" {
+		artifacts = append(artifacts, download.Kubectl(*kubectl, *arch))
+	}
+	if *helm != "" {
+		artifacts = append(artifacts, download.Helm(*helm, *arch))
+	}
+	if *kwctl != "" {
+		artifacts = append(artifacts, download.Kwctl(*kwctl, *arch))
+	}
+	for _, p := range strings.Split(*policies, ",") {
+		if p == "" {
+			continue
+		}
+		policy, version, ok := strings.Cut(p, "@")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid policy %q, policy@version expected\n", p)
+			os.Exit(2)
+		}
+		artifacts = append(artifacts, download.Policy(policy, version))
+	}
+
+	failed := false
+	for _, a := range artifacts {
+		file, err := download.Get(context.Background(), a)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot get %s: %v\n", a.Name, err)
+			failed = true
+			continue
+		}
+		fmt.Println(file)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
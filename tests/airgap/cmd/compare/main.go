@@ -0,0 +1,125 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Compare two runs published by the suites (RESULTS_TARGET), e.g. the last release candidate and a new one:
+//
+//	go run ./cmd/compare [-source <s3://bucket/prefix|file.db>] [-json <file>] [-threshold <percent>] <base> <candidate>
+//
+// Each run is a run ID of the results storage or a local summary file, the exit code is 1 if the candidate regressed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/results"
+)
+
+/*
+Get the summaries of a run
+  - @param run Run ID, or a JSON summary file
+  - @param load Loads all the summaries of the results storage
+  - @returns Summaries of the run or an error
+*/
+func runSummaries(run string, load func() ([]results.Summary, error)) ([]results.Summary, error) {
+	if strings.HasSuffix(run, ".json") {
+		data, err := os.ReadFile(run)
+		if err != nil {
+			return nil, err
+		}
+		var s results.Summary
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", run, err)
+		}
+		return []results.Summary{s}, nil
+	}
+
+	all, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []results.Summary
+	for _, s := range all {
+		if s.RunID == run {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no results for run %s", run)
+	}
+
+	return out, nil
+}
+
+func main() {
+	source := flag.String("source", os.Getenv("RESULTS_TARGET"), "Results storage, S3 URL (s3://bucket/prefix) or SQLite file")
+	output := flag.String("json", "", "Also write the delta in this JSON file")
+	threshold := flag.Float64("threshold", 10, "Accepted increase of the performance numbers, in percent")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-source <storage>] [-json <file>] [-threshold <percent>] <base> <candidate>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// The storage is only loaded once, and only if a run ID is given
+	var all []results.Summary
+	load := func() ([]results.Summary, error) {
+		if all != nil {
+			return all, nil
+		}
+		if *source == "" {
+			return nil, fmt.Errorf("-source (or RESULTS_TARGET) has to be set to find run IDs")
+		}
+
+		var err error
+		all, err = results.Load(*source)
+		return all, err
+	}
+
+	var runs [2][]results.Summary
+	for i, run := range flag.Args() {
+		var err error
+		if runs[i], err = runSummaries(run, load); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot get the results of %s: %v\n", run, err)
+			os.Exit(1)
+		}
+	}
+
+	delta := results.Compare(runs[0], runs[1], *threshold)
+	fmt.Print(delta.Text())
+
+	if *output != "" {
+		data, err := json.MarshalIndent(delta, "", "  ")
+		if err == nil {
+			err = os.WriteFile(*output, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot write %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+	}
+
+	if delta.Regressed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,497 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Run the E2E tests without having to know the label filters or the Make targets:
+//
+//	go run ./cmd/e2e provision -infra <module>
+//	go run ./cmd/e2e install [-infra <module>] [-registry <prefix>] [-- <ginkgo flags>]
+//	go run ./cmd/e2e test -tier <tier> [-infra <module>] [-destroy] [-registry <prefix>] [-airgap-registry <registry>] [-- <ginkgo flags>]
+//	go run ./cmd/e2e cleanup [-janitor] [-infra <module>]
+//	go run ./cmd/e2e report [<report flags>]
+//
+// The former form, go run ./cmd/e2e [-list] [-infra <module>] [-destroy] <tier>, is the same as the test subcommand.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/infra"
+)
+
+// Tier of tests, run as a sequence of label filters
+type tier struct {
+	Description string
+	Steps       []string
+}
+
+// Labels of the cluster installation, ginkgo randomizes top-level containers so each one is a step
+var install = []string{"preflight", "install-k3s", "install-kubewarden", "install-backup-restore"}
+
+var tiers = map[string]tier{
+	"smoke": {
+		Description: "Quick check of the main Backup/Restore path",
+		Steps:       append(slices.Clone(install), "smoke"),
+	},
+	"full": {
+		Description: "All Backup/Restore tests that share the same cluster",
+		Steps:       append(slices.Clone(install), "full"),
+	},
+	"nightly": {
+		Description: "Full tier plus the tests that need their own storage or nodes",
+		// kube-bench needs a baseline without Kubewarden, Longhorn has to install the backup operator itself,
+		// the other tests move or destroy the stack, the ones with Rancher come last as it is not removed
+		Steps: []string{
+			"preflight", "install-k3s",
+			"nightly && kube-bench",
+			"install-kubewarden",
+			"nightly && test-longhorn-backup-restore",
+			"full",
+			"nightly && backup-matrix",
+			"nightly && backup-churn",
+			"nightly && policy-catalog",
+			"nightly && snapshot-recovery",
+			"nightly && test-namespace-backup-restore",
+			"nightly && test-disaster-recovery",
+			"nightly && elemental",
+			"nightly && downstream",
+			"nightly && fleet",
+			"nightly && test-rancher-backup-restore",
+		},
+	},
+	"perf": {
+		Description: "Performance tests",
+		Steps:       append(slices.Clone(install), "perf"),
+	},
+	"airgap": {
+		Description: "Build and deploy the airgap environment",
+		Steps:       []string{"airgap && prepare-archive", "airgap && airgap-rancher"},
+	},
+	"airgap-upgrade": {
+		Description: "Upgrade Kubewarden in the airgap environment, AIRGAP_UPGRADE=true has to be set",
+		// The previous version is installed by airgap-rancher
+		Steps: []string{"airgap && prepare-archive", "airgap && airgap-rancher", "airgap-upgrade"},
+	},
+	"selinux": {
+		Description: "Full tier on a node with SELinux enforcing, K3S_SELINUX=true has to be set",
+		// Denials are checked once everything has been executed
+		Steps: append(slices.Clone(install), "full", "selinux"),
+	},
+	"upgrade": {
+		Description: "Backup/Restore around a Kubewarden upgrade",
+		// Kubewarden is installed by the test itself
+		Steps: []string{"preflight", "install-k3s", "install-backup-restore", "upgrade"},
+	},
+}
+
+/*
+Get the ginkgo command of a step
+  - @param filter Label filter of the step
+  - @param extra Additional ginkgo flags
+  - @returns The command to execute
+*/
+func ginkgoCmd(filter string, extra []string) *exec.Cmd {
+	args := []string{"--label-filter", filter, "-r", "-v"}
+	if timeout := os.Getenv("GINKGO_TIMEOUT"); timeout != "" {
+		args = append(args, "--timeout", timeout+"s")
+	}
+	args = append(args, extra...)
+	args = append(args, "./e2e")
+
+	cmd := exec.Command("ginkgo", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
+
+func listTiers() {
+	names := make([]string, 0, len(tiers))
+	for name := range tiers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Printf("%-14s %s\n", name, tiers[name].Description)
+		for _, step := range tiers[name].Steps {
+			fmt.Printf("%8s - %s\n", "", step)
+		}
+	}
+}
+
+/*
+Create the test node with a Terraform/OpenTofu module
+  - @remarks The outputs are given to the SSH executor of the tests through K3S_NODE_* variables
+  - @param dir Directory of the module
+  - @returns Nothing or an error
+*/
+func provision(dir string) error {
+	fmt.Printf("### Creating the test node with %s\n", dir)
+
+	out, err := infra.Apply(dir)
+	if err != nil {
+		return err
+	}
+
+	// Inherited by all the ginkgo steps
+	for _, env := range out.Env() {
+		name, value, _ := strings.Cut(env, "=")
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("### Test node %s ready, ssh -i %s %s@%s\n", out.NodeIP, infra.KeyFile(dir), out.SSHUser, out.NodeIP)
+
+	return nil
+}
+
+/*
+Run the steps of a tier
+  - @param name Name of the tier
+  - @param t Tier to run
+  - @param extra Additional ginkgo flags
+  - @returns Exit code of the first failed step, 0 if all passed
+*/
+func runTier(name string, t tier, extra []string) int {
+	// Given in the notifications of each step
+	if err := os.Setenv("E2E_TIER", name); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot set the tier: %v\n", err)
+		return 1
+	}
+
+	// Stop at the first failed step, next ones depend on it
+	for i, step := range t.Steps {
+		fmt.Printf("### Step %d/%d of tier %s: %s\n", i+1, len(t.Steps), name, step)
+
+		if err := ginkgoCmd(step, extra).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Step %q failed: %v\n", step, err)
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				return exitErr.ExitCode()
+			}
+			return 1
+		}
+	}
+
+	fmt.Printf("### Tier %s passed: %s\n", name, strings.Join(t.Steps, ", "))
+	return 0
+}
+
+// Options shared by the subcommands, most of them default to their environment variable
+type options struct {
+	tier           string
+	infraDir       string
+	destroy        bool
+	registry       string
+	airgapRegistry string
+	yes            bool
+}
+
+// Variables a tier cannot run without, they change how K3s or the archive are built
+var required = map[string]string{
+	"airgap-upgrade": "AIRGAP_UPGRADE",
+	"selinux":        "K3S_SELINUX",
+}
+
+/*
+Check the combination of options before anything is created
+  - @returns Nothing or an error with the conflicting options
+*/
+func (o *options) validate() error {
+	var errs []error
+
+	if o.destroy && o.infraDir == "" {
+		errs = append(errs, errors.New("-destroy needs -infra, there is no test node to destroy"))
+	}
+
+	airgap := strings.HasPrefix(o.tier, "airgap")
+	if airgap && o.registry != "" {
+		errs = append(errs, fmt.Errorf("-registry %s cannot be used with tier %s, the airgap node only pulls from its own registry (see -airgap-registry)", o.registry, o.tier))
+	}
+	if !airgap && o.airgapRegistry != "" {
+		errs = append(errs, fmt.Errorf("-airgap-registry is only used by the airgap tiers, not by %s", o.tier))
+	}
+	if o.airgapRegistry != "" && !slices.Contains([]string{"registry", "harbor"}, o.airgapRegistry) {
+		errs = append(errs, fmt.Errorf("unknown airgap registry %q, registry or harbor is expected", o.airgapRegistry))
+	}
+
+	if env, ok := required[o.tier]; ok && os.Getenv(env) != "true" {
+		errs = append(errs, fmt.Errorf("tier %s needs %s=true", o.tier, env))
+	}
+
+	return errors.Join(errs...)
+}
+
+/*
+Give the options to the ginkgo steps
+  - @returns Nothing or an error
+*/
+func (o *options) setEnv() error {
+	for name, value := range map[string]string{
+		"KUBEWARDEN_REGISTRY": o.registry,
+		"AIRGAP_REGISTRY":     o.airgapRegistry,
+	} {
+		if value == "" {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+	// Checked by pkg/guard before uninstalling K3s and the other destructive steps
+	if o.yes {
+		return os.Setenv("E2E_YES", "true")
+	}
+	return nil
+}
+
+/*
+Add the flags of the options to a subcommand
+  - @param fs Flags of the subcommand
+  - @param o Options to set
+  - @param names Names of the flags to add
+  - @returns Nothing
+*/
+func addFlags(fs *flag.FlagSet, o *options, names ...string) {
+	for _, name := range names {
+		switch name {
+		case "tier":
+			fs.StringVar(&o.tier, "tier", "", "Tier to run, see the list subcommand")
+		case "infra":
+			fs.StringVar(&o.infraDir, "infra", os.Getenv("INFRA_DIR"), "Terraform/OpenTofu module creating the test node, e.g. infra/libvirt")
+		case "destroy":
+			fs.BoolVar(&o.destroy, "destroy", os.Getenv("INFRA_DESTROY") == "true", "Destroy the test node created with -infra at the end")
+		case "registry":
+			fs.StringVar(&o.registry, "registry", os.Getenv("KUBEWARDEN_REGISTRY"), "Registry prefix of the Kubewarden images, e.g. registry.suse.com")
+		case "yes":
+			fs.BoolVar(&o.yes, "yes", os.Getenv("E2E_YES") == "true", "Do the destructive steps without confirmation, e.g. uninstalling K3s")
+		case "airgap-registry":
+			fs.StringVar(&o.airgapRegistry, "airgap-registry", os.Getenv("AIRGAP_REGISTRY"), "Registry of the airgap node, registry or harbor")
+		}
+	}
+}
+
+/*
+Create the test node of -infra, if any
+  - @remarks Nothing is created in dry-run, the tests use the local host
+  - @param o Options of the subcommand
+  - @returns Nothing or an error
+*/
+func provisionIfNeeded(o *options) error {
+	if o.infraDir == "" || os.Getenv("E2E_DRY_RUN") == "true" {
+		return nil
+	}
+	return provision(o.infraDir)
+}
+
+/*
+Destroy the test node of -infra
+  - @param dir Directory of the module
+  - @returns Exit code, 1 if the node cannot be destroyed
+*/
+func destroyNode(dir string) int {
+	if os.Getenv("E2E_DRY_RUN") == "true" {
+		return 0
+	}
+
+	fmt.Printf("### Destroying the test node of %s\n", dir)
+	if err := infra.Destroy(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot destroy the test node: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+/*
+Parse the flags of a subcommand, the remaining arguments are given to ginkgo
+  - @param fs Flags of the subcommand
+  - @param o Options of the subcommand
+  - @param args Arguments of the subcommand
+  - @returns Additional ginkgo flags, the function exits on invalid flags
+*/
+func parse(fs *flag.FlagSet, o *options, args []string) []string {
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if err := o.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid options:\n%v\n", err)
+		os.Exit(2)
+	}
+	if err := o.setEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot set the options: %v\n", err)
+		os.Exit(1)
+	}
+	return fs.Args()
+}
+
+// Create the test node and print its environment, to be exported before the other subcommands
+func provisionCmd(args []string) int {
+	o := &options{}
+	fs := flag.NewFlagSet("provision", flag.ExitOnError)
+	addFlags(fs, o, "infra")
+	parse(fs, o, args)
+
+	if o.infraDir == "" {
+		fmt.Fprintln(os.Stderr, "-infra (or INFRA_DIR) has to be set")
+		return 2
+	}
+
+	out, err := infra.Apply(o.infraDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create the test node: %v\n", err)
+		return 1
+	}
+	for _, env := range out.Env() {
+		fmt.Printf("export %s\n", env)
+	}
+	return 0
+}
+
+// Install K3s, Kubewarden and the backup operator, without running any test
+func installCmd(args []string) int {
+	o := &options{tier: "install"}
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	addFlags(fs, o, "infra", "registry", "yes")
+	extra := parse(fs, o, args)
+
+	if err := provisionIfNeeded(o); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create the test node: %v\n", err)
+		return 1
+	}
+	return runTier("install", tier{Steps: install}, extra)
+}
+
+// Run a tier, the test node of -infra is created before and destroyed after with -destroy
+func testCmd(args []string) int {
+	o := &options{}
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	addFlags(fs, o, "tier", "infra", "destroy", "registry", "airgap-registry", "yes")
+	extra := parse(fs, o, args)
+
+	t, ok := tiers[o.tier]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown tier %q, use the list subcommand to get the available tiers\n", o.tier)
+		return 2
+	}
+
+	if err := provisionIfNeeded(o); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create the test node: %v\n", err)
+		return 1
+	}
+
+	code := runTier(o.tier, t, extra)
+
+	// Also done on failure, the node can be kept for debugging by not using -destroy
+	if o.destroy {
+		code = max(code, destroyNode(o.infraDir))
+	}
+	return code
+}
+
+// Delete what the tests installed, or only the leftovers of previous runs with -janitor
+func cleanupCmd(args []string) int {
+	o := &options{}
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	janitor := fs.Bool("janitor", false, "Only delete the leftovers of previous runs, the stack is kept")
+	addFlags(fs, o, "infra", "yes")
+	extra := parse(fs, o, args)
+
+	step := "cleanup"
+	if *janitor {
+		step = "janitor"
+	}
+	fmt.Printf("### Cleanup: %s\n", step)
+	if err := ginkgoCmd(step, extra).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Step %q failed: %v\n", step, err)
+		return 1
+	}
+
+	// With -infra, the whole test node goes away
+	if o.infraDir != "" {
+		return destroyNode(o.infraDir)
+	}
+	return 0
+}
+
+// Generate the trend charts of the published results, the flags are the ones of cmd/report
+func reportCmd(args []string) int {
+	cmd := exec.Command("go", append([]string{"run", "./cmd/report"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "Cannot generate the report: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// Subcommands, the former tier argument is still accepted as test -tier <tier>
+var commands = map[string]func(args []string) int{
+	"provision": provisionCmd,
+	"install":   installCmd,
+	"test":      testCmd,
+	"cleanup":   cleanupCmd,
+	"report":    reportCmd,
+	"list": func([]string) int {
+		listTiers()
+		return 0
+	},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <provision|install|test|cleanup|report|list> [flags] [-- <ginkgo flags>]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [-list] [-infra <module>] [-destroy] <tier> [-- <ginkgo flags>]\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Use <subcommand> -h for the flags of a subcommand")
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
+	// Former form, e.g. go run ./cmd/e2e -infra infra/libvirt full
+	list := flag.Bool("list", false, "List the tiers and their label filters")
+	o := &options{}
+	addFlags(flag.CommandLine, o, "infra", "destroy")
+	flag.Usage = func() {
+		usage()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		listTiers()
+		return
+	}
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	args := []string{"-tier", flag.Arg(0), "-infra", o.infraDir, fmt.Sprintf("-destroy=%t", o.destroy)}
+	os.Exit(testCmd(append(args, flag.Args()[1:]...)))
+}
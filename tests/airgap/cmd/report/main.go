@@ -0,0 +1,204 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Generate trend charts from the results published by the suites (RESULTS_TARGET):
+//
+//	go run ./cmd/report [-source <s3://bucket/prefix|file.db>] [-out <file.html>] [-last <n>]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/results"
+)
+
+// Size of the charts, in pixels
+const (
+	chartWidth  = 600
+	chartHeight = 160
+)
+
+// Chart is a trend across runs
+type Chart struct {
+	Title  string
+	Unit   string
+	Points []Point
+	Max    float64
+}
+
+// Point is the value of a run
+type Point struct {
+	Label string
+	Value float64
+	X, Y  float64
+}
+
+// Section gathers the charts of a suite, i.e. of a label filter
+type Section struct {
+	Suite  string
+	Runs   int
+	Charts []*Chart
+}
+
+var page = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>E2E trends</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+svg { border: 1px solid #ccc; margin: 0.5em 0 1.5em; }
+polyline { fill: none; stroke: #30ba78; stroke-width: 2; }
+circle { fill: #0c322c; }
+text { font-size: 11px; fill: #555; }
+</style>
+</head>
+<body>
+<h1>E2E trends</h1>
+{{- range .Sections}}
+<h2>{{.Suite}} ({{.Runs}} runs)</h2>
+{{- range $chart := .Charts}}
+<h3>{{.Title}}</h3>
+<svg width="{{$.Width}}" height="{{$.Height}}">
+<text x="4" y="12">max {{printf "%.3g" .Max}} {{.Unit}}</text>
+<polyline points="{{range .Points}}{{.X}},{{.Y}} {{end}}"/>
+{{- range .Points}}
+<circle cx="{{.X}}" cy="{{.Y}}" r="3"><title>{{.Label}}: {{printf "%.3g" .Value}} {{$chart.Unit}}</title></circle>
+{{- end}}
+</svg>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+/*
+Add a value of a run to a chart
+  - @param label Label of the run
+  - @param value Value of the run
+  - @returns Nothing
+*/
+func (c *Chart) add(label string, value float64) {
+	c.Points = append(c.Points, Point{Label: label, Value: value})
+	c.Max = max(c.Max, value)
+}
+
+/*
+Place the points of a chart
+  - @returns Nothing
+*/
+func (c *Chart) layout() {
+	for i := range c.Points {
+		p := &c.Points[i]
+		// Margins keep the points and the label visible
+		p.X = 10
+		if len(c.Points) > 1 {
+			p.X += float64(i) * (chartWidth - 20) / float64(len(c.Points)-1)
+		}
+		p.Y = chartHeight - 10
+		if c.Max > 0 {
+			p.Y -= p.Value / c.Max * (chartHeight - 30)
+		}
+	}
+}
+
+/*
+Build the charts of each suite
+  - @param summaries Summaries sorted by date
+  - @param last Number of runs kept per suite, all if 0
+  - @returns Sections sorted by suite
+*/
+func sections(summaries []results.Summary, last int) []Section {
+	bySuite := map[string][]results.Summary{}
+	for _, s := range summaries {
+		bySuite[s.Suite] = append(bySuite[s.Suite], s)
+	}
+
+	var out []Section
+	for suite, runs := range bySuite {
+		if last > 0 && len(runs) > last {
+			runs = runs[len(runs)-last:]
+		}
+
+		passRate := &Chart{Title: "Pass rate", Unit: "%"}
+		duration := &Chart{Title: "Duration", Unit: "s"}
+		metrics := map[string]*Chart{}
+		for _, run := range runs {
+			label := run.Date.Format("2006-01-02 15:04") + " " + run.RunID
+			passRate.add(label, run.PassRate())
+			duration.add(label, run.Duration)
+
+			for _, m := range run.Metrics {
+				if metrics[m.Metric] == nil {
+					metrics[m.Metric] = &Chart{Title: m.Metric, Unit: m.Unit}
+				}
+				metrics[m.Metric].add(label, m.Value)
+			}
+		}
+
+		section := Section{Suite: suite, Runs: len(runs), Charts: []*Chart{passRate, duration}}
+		for _, name := range slices.Sorted(maps.Keys(metrics)) {
+			section.Charts = append(section.Charts, metrics[name])
+		}
+		for _, c := range section.Charts {
+			c.layout()
+		}
+		out = append(out, section)
+	}
+	slices.SortFunc(out, func(a, b Section) int { return strings.Compare(a.Suite, b.Suite) })
+
+	return out
+}
+
+func main() {
+	source := flag.String("source", os.Getenv("RESULTS_TARGET"), "Results storage, S3 URL (s3://bucket/prefix) or SQLite file")
+	output := flag.String("out", "report.html", "Generated HTML file")
+	last := flag.Int("last", 30, "Number of runs per suite, all if 0")
+	flag.Parse()
+
+	if *source == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	summaries, err := results.Load(*source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot load the results of %s: %v\n", *source, err)
+		os.Exit(1)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	data := struct {
+		Sections      []Section
+		Width, Height int
+	}{sections(summaries, *last), chartWidth, chartHeight}
+	if err := page.Execute(f, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot generate %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d runs of %d suites written to %s\n", len(summaries), len(data.Sections), *output)
+}
@@ -15,10 +15,21 @@ limitations under the License.
 package e2e_test
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/download"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/harbor"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/imagelist"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
@@ -26,45 +37,149 @@ import (
 	"github.com/rancher-sandbox/ele-testhelpers/tools"
 )
 
-var _ = Describe("E2E - Build the airgap archive", Label("prepare-archive"), func() {
+// K3s registries configuration when Harbor is used, see the deploy script for registry:2
+const harborRegistriesYaml = `mirrors:
+  "%[1]s":
+    endpoint:
+      - "http://%[1]s"
+  "docker.io":
+    endpoint:
+      - "http://%[1]s"
+configs:
+  "%[1]s":
+    tls:
+      insecure_skip_verify: true
+  "%[2]s":
+    auth:
+      username: '%[3]s'
+      password: '%[4]s'
+    tls:
+      ca_file: /etc/rancher/k3s/harbor-ca.crt
+`
+
+/*
+Get the repository of an image in the airgap registry
+  - @param image Image or policy reference, with or without registry
+  - @returns Repository path, without registry and tag
+*/
+func repositoryPath(image string) string {
+	image = strings.TrimPrefix(image, "registry://")
+
+	// First element is a registry if it looks like a host
+	if host, rest, found := strings.Cut(image, "/"); found && strings.ContainsAny(host, ".:") {
+		image = rest
+	}
+
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+
+	return image
+}
+
+/*
+Get the version of a chart mirrored to upgrade from it
+  - @remarks The build script pulls the charts of the previous version in helm/previous
+  - @param chart Name of the chart
+  - @returns Chart version, the function will fail through Ginkgo in case of issue
+*/
+func previousAirgapChartVersion(chart string) string {
+	if dryrun.Enabled() {
+		return "<previous " + chart + " version>"
+	}
+
+	archives, err := filepath.Glob(os.Getenv("HOME") + "/airgap_rancher/helm/previous/" + chart + "-[0-9]*.tgz")
+	Expect(err).To(Not(HaveOccurred()))
+	Expect(archives).To(HaveLen(1), "mirrored chart of %s for the upgrade", chart)
+
+	return strings.TrimSuffix(strings.TrimPrefix(filepath.Base(archives[0]), chart+"-"), ".tgz")
+}
+
+var _ = Describe("E2E - Build the airgap archive", Label("prepare-archive", "airgap"), Ordered, Serial, func() {
 	It("Execute the script to build the archive", func() {
 
 		// Could be useful for manual debugging!
-		GinkgoWriter.Printf("Executed command: %s %s %s %s %s\n", airgapBuildScript, k3sVersion)
-		out, err := exec.Command(airgapBuildScript, k3sVersion).CombinedOutput()
-		Expect(err).To(Not(HaveOccurred()), string(out))
+		args := []string{k3sVersion}
+
+		// Both versions are mirrored for the airgap upgrade
+		if airgapUpgrade {
+			previousVersion := kubewardenPreviousVersion
+			if previousVersion == "" {
+				AddKubewardenRepo()
+				previousVersion = GetPreviousKubewardenVersion()
+			}
+			args = append(args, previousVersion)
+		}
+
+		GinkgoWriter.Printf("Executed command: %s %s\n", airgapBuildScript, strings.Join(args, " "))
+		_, err := runner.Run(airgapBuildScript, args...)
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	It("Mirror all the images referenced by the charts", func() {
+		helmDir := os.Getenv("HOME") + "/airgap_rancher/helm"
+
+		// Lists used by the build script to fill the hauler store
+		var mirrored []string
+		lists := []string{"kubewarden-controller/imagelist.txt", "kubewarden-defaults/imagelist.txt", "kubewarden-defaults/policylist.txt"}
+		for _, list := range lists {
+			if !dryrun.Enabled() {
+				refs, err := imagelist.Read(filepath.Join(helmDir, list))
+				Expect(err).To(Not(HaveOccurred()))
+				mirrored = append(mirrored, refs...)
+			}
+		}
+
+		// Optional images are enabled, so everything that could be deployed is rendered
+		charts := map[string][]string{
+			"kubewarden-controller": {"--set", "auditScanner.enabled=true"},
+			"kubewarden-defaults":   {"--set", "recommendedPolicies.enabled=true"},
+		}
+		for chart, flags := range charts {
+			archives, err := filepath.Glob(filepath.Join(helmDir, chart+"-*.tgz"))
+			Expect(err).To(Not(HaveOccurred()))
+			if dryrun.Enabled() {
+				archives = []string{filepath.Join(helmDir, chart+"-<version>.tgz")}
+			}
+			Expect(archives).To(HaveLen(1), "pulled chart of %s", chart)
+
+			manifests, err := kubectl.RunHelmBinaryWithOutput(append([]string{"template", chart, archives[0], "--namespace", kubewardenNS}, flags...)...)
+			Expect(err).To(Not(HaveOccurred()))
+
+			refs, err := imagelist.FromManifests(manifests)
+			Expect(err).To(Not(HaveOccurred()))
+			if dryrun.Enabled() {
+				continue
+			}
+			Expect(refs).To(Not(BeEmpty()), "no image found in %s", chart)
+
+			missing := imagelist.Missing(refs, mirrored)
+			Expect(missing).To(BeEmpty(), "images of %s not mirrored in the airgap archive", chart)
+		}
 	})
 })
 
-var _ = Describe("E2E - Deploy K3S/Rancher in airgap environment", Label("airgap-rancher"), func() {
+var _ = Describe("E2E - Deploy K3S/Rancher in airgap environment", Label("airgap-rancher", "airgap"), Ordered, Serial, func() {
 	It("Create the rancher-manager machine", func() {
 		By("Updating the default network configuration", func() {
 			// Don't check return code, as the default network could be already removed
 			for _, c := range []string{"net-destroy", "net-undefine"} {
-				_ = exec.Command("sudo", "virsh", c, "default").Run()
+				_, _ = runner.Sudo("virsh", c, "default")
 			}
 
 			// Wait a bit between virsh commands
 			time.Sleep(30 * time.Second)
-			err := exec.Command("sudo", "virsh", "net-create", netDefaultFileName).Run()
+			_, err := runner.Sudo("virsh", "net-create", netDefaultFileName)
 			Expect(err).To(Not(HaveOccurred()))
 		})
 
 		By("Creating the Rancher Manager VM", func() {
-			err := exec.Command("sudo", "virt-install",
-				"--name", "rancher-manager",
-				"--memory", "16384",
-				"--vcpus", "4",
-				"--disk", "path="+os.Getenv("HOME")+"/rancher-image.qcow2,bus=sata",
-				"--import",
-				"--os-variant", "opensuse-unknown",
-				"--network=default,mac=52:54:00:00:00:10",
-				"--noautoconsole").Run()
-			Expect(err).To(Not(HaveOccurred()))
+			CreateVM("rancher-manager", os.Getenv("HOME")+"/rancher-image.qcow2", "", "52:54:00:00:00:10")
 		})
 	})
 
-	It("Install K3S/Rancher in the rancher-manager machine", func() {
+	It("Install K3S/Rancher in the rancher-manager machine", SpecTimeout(timeouts.For(timeouts.Spec)), func(ctx SpecContext) {
 		airgapRepo := os.Getenv("HOME") + "/airgap_rancher"
 		archiveFile := "haul.tar.zst"
 		haulerBinary := "/usr/local/bin/hauler"
@@ -72,8 +187,16 @@ var _ = Describe("E2E - Deploy K3S/Rancher in airgap environment", Label("airgap
 		password := "root"
 		rancherManager := "rancher-manager.test"
 		repoServer := rancherManager + ":5000"
+		harborServer := rancherManager + ":30003"
 		userName := "root"
 
+		// Registry used by Kubewarden, and how policy servers access it
+		registry := repoServer
+		policySourceFlags := []string{
+			"--set", "policyServer.insecureSources[0]=" + rancherManager,
+			"--set", "policyServer.insecureSources[1]=" + repoServer,
+		}
+
 		// For ssh access
 		client := &tools.Client{
 			Host:     "192.168.122.102:22",
@@ -85,7 +208,7 @@ var _ = Describe("E2E - Deploy K3S/Rancher in airgap environment", Label("airgap
 		// Default timeout is too small, so New() cannot be used
 		k := &kubectl.Kubectl{
 			Namespace:    "",
-			PollTimeout:  tools.SetTimeout(300 * time.Second),
+			PollTimeout:  timeouts.For(timeouts.Rollout),
 			PollInterval: 500 * time.Millisecond,
 		}
 
@@ -135,9 +258,8 @@ var _ = Describe("E2E - Deploy K3S/Rancher in airgap environment", Label("airgap
 			err = client.GetFile(localKubeconfig, "/etc/rancher/k3s/k3s.yaml", 0644)
 			Expect(err).To(Not(HaveOccurred()))
 
-			// NOTE: not sure that this is need because we have the config file in ~/.kube/
-			err = os.Setenv("KUBECONFIG", localKubeconfig)
-			Expect(err).To(Not(HaveOccurred()))
+			// Default kubeconfig of kubectl, also used by the helpers of ele-testhelpers
+			localCluster.Kubeconfig = localKubeconfig
 
 			// Replace localhost with the IP of the VM
 			err = tools.Sed("127.0.0.1", "192.168.122.102", localKubeconfig)
@@ -146,79 +268,306 @@ var _ = Describe("E2E - Deploy K3S/Rancher in airgap environment", Label("airgap
 
 		By("Installing kubectl", func() {
 			// TODO: Variable for kubectl version
-			err := exec.Command("curl", "-sLO", "https://dl.k8s.io/release/v1.28.2/bin/linux/amd64/kubectl").Run()
-			Expect(err).To(Not(HaveOccurred()))
-			err = exec.Command("chmod", "+x", "kubectl").Run()
-			Expect(err).To(Not(HaveOccurred()))
-			err = exec.Command("sudo", "mv", "kubectl", "/usr/local/bin/").Run()
+			// kubectl runs on the test host, not on the airgap node
+			file := GetArtifact(download.Kubectl("v1.28.2", runtime.GOARCH))
+			ConfirmDestructive(&runner.Runner{}, "install kubectl in /usr/local/bin")
+			_, err := runner.Sudo("install", "-m", "0755", file, "/usr/local/bin/kubectl")
 			Expect(err).To(Not(HaveOccurred()))
 		})
 
+		if airgapRegistry == "harbor" {
+			var (
+				caFile string
+				hc     *harbor.Client
+			)
+
+			By("Deploying Harbor", func() {
+				password := randomHex(12)
+
+				// Harbor images are pulled from registry:2, through the docker.io mirror
+				RunHelmCmdWithRetry("upgrade", "--install", "harbor", "oci://"+repoServer+"/hauler/harbor",
+					"--namespace", "harbor",
+					"--create-namespace",
+					"--plain-http",
+					"--set", "expose.type=nodePort",
+					"--set", "expose.tls.enabled=true",
+					"--set", "expose.tls.certSource=auto",
+					"--set", "expose.tls.auto.commonName="+rancherManager,
+					"--set", "expose.nodePort.ports.https.nodePort=30003",
+					"--set", "externalURL=https://"+harborServer,
+					"--set", "harborAdminPassword="+password,
+					"--set", "persistence.enabled=false",
+					"--set", "trivy.enabled=false",
+					"--wait", "--wait-for-jobs",
+				)
+
+				// Self-signed CA generated by the chart
+				out, err := kubectl.RunWithoutErr("get", "secret", "harbor-nginx", "--namespace", "harbor",
+					"-o", "jsonpath={.data.ca\\.crt}")
+				Expect(err).To(Not(HaveOccurred()))
+				ca, err := base64.StdEncoding.DecodeString(out)
+				Expect(err).To(Not(HaveOccurred()))
+
+				caFile = filepath.Join(GetTempDir(), "harbor-ca.crt")
+				err = os.WriteFile(caFile, ca, 0644)
+				Expect(err).To(Not(HaveOccurred()))
+
+				hc, err = harbor.New("https://"+harborServer, "admin", password, ca)
+				Expect(err).To(Not(HaveOccurred()))
+			})
+
+			By("Replicating Kubewarden images and policies to Harbor", func() {
+				err := hc.CreateProject("kubewarden")
+				Expect(err).To(Not(HaveOccurred()))
+
+				err = hc.Replicate("kubewarden", "http://"+repoServer, "kubewarden/**", timeouts.For(timeouts.Install))
+				Expect(err).To(Not(HaveOccurred()))
+
+				repos, err := hc.Repositories("kubewarden")
+				Expect(err).To(Not(HaveOccurred()))
+
+				// Lists of the charts, extracted by the build script
+				for _, list := range []string{
+					airgapRepo + "/helm/kubewarden-controller/imagelist.txt",
+					airgapRepo + "/helm/kubewarden-defaults/imagelist.txt",
+					airgapRepo + "/helm/kubewarden-defaults/policylist.txt",
+				} {
+					data, err := os.ReadFile(list)
+					Expect(err).To(Not(HaveOccurred()))
+
+					for _, image := range strings.Fields(string(data)) {
+						Expect(repos).To(ContainElement(repositoryPath(image)), "%s not replicated", image)
+					}
+				}
+			})
+
+			By("Configuring K3s and Kubewarden to pull from Harbor with a robot account", func() {
+				robot, err := hc.CreateRobot("kubewarden", "kubewarden")
+				Expect(err).To(Not(HaveOccurred()))
+
+				ca, err := os.ReadFile(caFile)
+				Expect(err).To(Not(HaveOccurred()))
+
+				// Same mirrors as the deploy script, plus the Harbor credentials
+				registries := fmt.Sprintf(harborRegistriesYaml, repoServer, harborServer, robot.Name, robot.Secret)
+				for file, content := range map[string]string{
+					"/etc/rancher/k3s/harbor-ca.crt":   string(ca),
+					"/etc/rancher/k3s/registries.yaml": registries,
+				} {
+					_, err = client.RunSSH("echo " + base64.StdEncoding.EncodeToString([]byte(content)) + " | base64 -d | sudo tee " + file + " >/dev/null")
+					Expect(err).To(Not(HaveOccurred()))
+				}
+
+				_, err = client.RunSSH("sudo systemctl restart k3s")
+				Expect(err).To(Not(HaveOccurred()))
+				WaitForK3s(k)
+
+				// Policy servers pull the policies themselves
+				if _, err := kubectl.RunWithoutErr("get", "namespace", kubewardenNS); err != nil {
+					_, err = kubectl.RunWithoutErr("create", "namespace", kubewardenNS)
+					Expect(err).To(Not(HaveOccurred()))
+				}
+				_, _ = kubectl.RunWithoutErr("delete", "secret", "harbor-robot", "--namespace", kubewardenNS, "--ignore-not-found")
+				_, err = kubectl.RunWithoutErr("create", "secret", "docker-registry", "harbor-robot",
+					"--namespace", kubewardenNS,
+					"--docker-server", harborServer,
+					"--docker-username", robot.Name,
+					"--docker-password", robot.Secret)
+				Expect(err).To(Not(HaveOccurred()))
+			})
+
+			registry = harborServer
+			policySourceFlags = []string{
+				"--set", "policyServer.imagePullSecret=harbor-robot",
+				"--set", "policyServer.sourceAuthorities[0].uri=" + harborServer,
+				"--set-file", "policyServer.sourceAuthorities[0].certs[0]=" + caFile,
+			}
+		}
+
+		// Latest charts with the tested images, or the previous version to upgrade from
+		versionFlags := func(chart string, tags ...string) []string {
+			if airgapUpgrade {
+				return []string{"--version", previousAirgapChartVersion(chart)}
+			}
+
+			flags := []string{"--devel"}
+			for _, tag := range tags {
+				flags = append(flags, "--set", tag)
+			}
+			return flags
+		}
+
 		By("Installing Kubewarden crds", func() {
 			// Set flags for Kubewarden-crds installation
 			flags := []string{
 				"upgrade", "--install", "kubewarden-crds", "oci://" + repoServer + "/hauler/kubewarden-crds",
-				"--namespace", "kubewarden",
+				"--namespace", kubewardenNS,
 				"--create-namespace",
 				"--plain-http",
-				"--devel",
 			}
 
-			RunHelmCmdWithRetry(flags...)
+			RunHelmCmdWithRetry(append(flags, versionFlags("kubewarden-crds")...)...)
+
+			for _, crd := range kubewardenCRDs {
+				WaitForCRDEstablished(ctx, localCluster, crd)
+			}
 		})
 
 		By("Installing Kubewarden controller", func() {
 			// Set flags for Kubewarden controller installation
 			flags := []string{
 				"upgrade", "--install", "kubewarden-controller", "oci://" + repoServer + "/hauler/kubewarden-controller",
-				"--namespace", "kubewarden",
+				"--namespace", kubewardenNS,
 				"--plain-http",
-				"--set", "global.cattle.systemDefaultRegistry=" + repoServer,
-				"--set", "image.tag=" + kubewardenControllerVersion,
-				"--set", "auditScanner.image.tag=" + auditScannerVersion,
+				"--set", "global.cattle.systemDefaultRegistry=" + registry,
 				"--wait", "--wait-for-jobs",
-				"--devel",
 			}
+			flags = append(flags, versionFlags("kubewarden-controller",
+				"image.tag="+kubewardenControllerVersion,
+				"auditScanner.image.tag="+auditScannerVersion)...)
 
 			RunHelmCmdWithRetry(flags...)
 
 			// Wait for all pods to be started
 			checkList := [][]string{
-				{"kubewarden", "app.kubernetes.io/name=kubewarden-controller"},
+				{kubewardenNS, "app.kubernetes.io/name=kubewarden-controller"},
 			}
 			err := rancher.CheckPod(k, checkList)
 			Expect(err).To(Not(HaveOccurred()))
+
+			// Policy servers are validated by the webhook of the controller
+			WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "kubewarden-controller")
+			WaitForWebhookReady(ctx, kubewardenNS, "kubewarden-controller-webhook-service", 443)
 		})
 
 		By("Installing Kubewarden defaults", func() {
 			// Set flags for Kubewarden defaults installation
 			flags := []string{
 				"upgrade", "--install", "kubewarden-defaults", "oci://" + repoServer + "/hauler/kubewarden-defaults",
-				"--namespace", "kubewarden",
+				"--namespace", kubewardenNS,
 				"--plain-http",
-				"--set", "global.cattle.systemDefaultRegistry=" + repoServer,
-				"--set", "policyServer.insecureSources[0]=" + rancherManager,
-				"--set", "policyServer.insecureSources[1]=" + repoServer,
-				"--set", "policyServer.image.tag=" + policyServerVersion,
+				"--set", "global.cattle.systemDefaultRegistry=" + registry,
 				"--set", "recommendedPolicies.enabled=true",
-				"--set", "recommendedPolicies.defaultPoliciesRegistry=" + repoServer,
+				"--set", "recommendedPolicies.defaultPoliciesRegistry=" + registry,
 				"--wait", "--wait-for-jobs",
-				"--devel",
 			}
-			RunHelmCmdWithRetry(flags...)
+			flags = append(flags, versionFlags("kubewarden-defaults", "policyServer.image.tag="+policyServerVersion)...)
+			RunHelmCmdWithRetry(append(flags, policySourceFlags...)...)
 
 			// Wait for pod to be started
-			err := rancher.CheckPod(k, [][]string{{"kubewarden", "app.kubernetes.io/name=policy-server"}})
+			err := rancher.CheckPod(k, [][]string{{kubewardenNS, "app.kubernetes.io/name=policy-server"}})
 			Expect(err).To(Not(HaveOccurred()))
-
+			WaitForKubewardenReady(ctx, kubewardenNS)
 		})
 		// TODO: check all policies
 		By("Checking that one policy is in active state", func() {
-			Eventually(func() string {
-				out, _ := kubectl.RunWithoutErr("get", "cap", "do-not-run-as-root",
-					"-o", "jsonpath={.status.policyStatus}")
-				return out
-			}, tools.SetTimeout(5*time.Minute), 10*time.Second).Should(ContainSubstring("active"))
+			WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
 		})
 	})
 })
+
+// NOTE: AIRGAP_UPGRADE=true has to be set for the whole tier, airgap-rancher then installs the previous version
+var _ = Describe("E2E - Upgrade Kubewarden in airgap environment", Label("airgap-upgrade", "airgap"), Ordered, Serial, func() {
+	repoServer := "rancher-manager.test:5000"
+
+	// For ssh access
+	client := &tools.Client{
+		Host:     "192.168.122.102:22",
+		Username: "root",
+		Password: "root",
+	}
+
+	// Registry of the images, Harbor or registry:2, as configured by airgap-rancher
+	var registry string
+
+	BeforeAll(func() {
+		if !airgapUpgrade {
+			Skip("AIRGAP_UPGRADE is not enabled")
+		}
+
+		out, err := kubectl.RunHelmBinaryWithOutput("get", "values", "kubewarden-controller", "--namespace", kubewardenNS, "-o", "json")
+		Expect(err).To(Not(HaveOccurred()))
+		if dryrun.Enabled() {
+			registry = repoServer
+			return
+		}
+
+		var vals struct {
+			Global struct {
+				Cattle struct {
+					SystemDefaultRegistry string `json:"systemDefaultRegistry"`
+				} `json:"cattle"`
+			} `json:"global"`
+		}
+		err = json.Unmarshal([]byte(out), &vals)
+		Expect(err).To(Not(HaveOccurred()))
+		registry = vals.Global.Cattle.SystemDefaultRegistry
+		Expect(registry).To(Not(BeEmpty()), "no registry in the values of kubewarden-controller")
+	})
+
+	It("Upgrade Kubewarden from the airgap registry", SpecTimeout(timeouts.For(timeouts.Spec)), func(ctx SpecContext) {
+		var previousVersion string
+		if !dryrun.Enabled() {
+			previousVersion = GetInstalledKubewardenVersion(kubewardenNS)
+		}
+		GinkgoWriter.Printf("Upgrading Kubewarden %s\n", previousVersion)
+
+		// Values of the installation (registry, policy sources) are kept, with the defaults of the new charts
+		tags := map[string][]string{
+			"kubewarden-controller": {"--set", "image.tag=" + kubewardenControllerVersion, "--set", "auditScanner.image.tag=" + auditScannerVersion},
+			"kubewarden-defaults":   {"--set", "policyServer.image.tag=" + policyServerVersion},
+		}
+		for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+			flags := []string{
+				"upgrade", chart, "oci://" + repoServer + "/hauler/" + chart,
+				"--namespace", kubewardenNS,
+				"--plain-http",
+				"--devel",
+				"--reset-then-reuse-values",
+				"--wait", "--wait-for-jobs",
+			}
+			RunHelmCmdWithRetry(append(flags, tags[chart]...)...)
+
+			if chart == "kubewarden-crds" {
+				for _, crd := range kubewardenCRDs {
+					WaitForCRDEstablished(ctx, localCluster, crd)
+				}
+			}
+		}
+
+		WaitForKubewardenReady(ctx, kubewardenNS)
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+
+		if !dryrun.Enabled() {
+			Expect(GetInstalledKubewardenVersion(kubewardenNS)).To(Not(Equal(previousVersion)), "Kubewarden not upgraded")
+		}
+	})
+
+	It("Pull everything from the airgap registry", func() {
+		images, err := kubectl.RunWithoutErr("get", "pods", "--namespace", kubewardenNS,
+			"-o", "jsonpath={.items[*].spec.containers[*].image}")
+		Expect(err).To(Not(HaveOccurred()))
+		for _, image := range strings.Fields(images) {
+			Expect(image).To(HavePrefix(registry+"/"), "image %s not pulled from the airgap registry", image)
+		}
+
+		modules, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicies", "-o", "jsonpath={.items[*].spec.module}")
+		Expect(err).To(Not(HaveOccurred()))
+		for _, module := range strings.Fields(modules) {
+			Expect(module).To(HavePrefix("registry://"+registry+"/"), "policy %s not pulled from the airgap registry", module)
+		}
+
+		// A pull from the internet would have failed on the isolated node
+		failures, err := kubectl.RunWithoutErr("get", "events", "--namespace", kubewardenNS, "--field-selector", "reason=Failed",
+			"-o", "jsonpath={range .items[*]}{.message}{\"\\n\"}{end}")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(failures).To(Not(ContainSubstring("pull")), "failed pulls during the upgrade")
+
+		// The node must still be isolated, otherwise nothing has been proven
+		if !dryrun.Enabled() {
+			CheckSSH(client)
+			_, err = client.RunSSH("curl -sfm 10 -o /dev/null https://charts.kubewarden.io/index.yaml")
+			Expect(err).To(HaveOccurred(), "the airgap node can reach the internet")
+		}
+	})
+})
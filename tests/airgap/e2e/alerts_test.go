@@ -0,0 +1,155 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/metrics"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/portforward"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: telemetry needs the OpenTelemetry operator, the test is skipped without it
+var _ = Describe("E2E - Fire the alerts of the Kubewarden controller", Label("alerts", "full"), Ordered, Serial, func() {
+	// Namespace of Prometheus, only installed if the Prometheus operator is not there
+	const prometheusNS = "prometheus"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	rulesName := UniqueName("kubewarden-alerts")
+	serverName := UniqueName("broken-server")
+	policyName := UniqueName("broken-policy")
+	const alert = "KubewardenReconcileErrors"
+
+	// Rule groups of the charts and of alert-rules.yaml
+	var groups []string
+	var prometheus *portforward.Forward
+
+	BeforeAll(func(ctx SpecContext) {
+		if _, err := kubectl.RunWithoutErr("get", "crd", "opentelemetrycollectors.opentelemetry.io"); err != nil {
+			Skip("OpenTelemetry operator is not installed")
+		}
+
+		if _, err := kubectl.RunWithoutErr("get", "crd", "prometheusrules.monitoring.coreos.com"); err != nil {
+			InstallPrometheus(k, prometheusNS)
+			DeferCleanup(RemovePrometheus, prometheusNS)
+		}
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "prometheusrule,servicemonitor", rulesName, "--namespace", prometheusNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			// Other tests expect the default values
+			InstallKubewarden(k, kubewardenNS, "")
+		})
+
+		var err error
+		prometheus, err = portforward.Start(ctx, prometheusNS, "svc/prometheus-operated", 9090)
+		Expect(err).To(Not(HaveOccurred()))
+		DeferCleanup(func() {
+			prometheus.Stop()
+		})
+	})
+
+	It("Enable the telemetry and the alert rules", func() {
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().Telemetry(true))
+
+		// Sidecars are only injected in new pods
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment", "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = kubectl.RunWithoutErr("rollout", "status", "deployment", "--namespace", kubewardenNS,
+			fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(alertRulesYaml, map[string]string{
+			"%NAME%":          rulesName,
+			"%ALERT%":         alert,
+			"%PROMETHEUS_NS%": prometheusNS,
+			"%KUBEWARDEN_NS%": kubewardenNS,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	It("Load the shipped alert rules in Prometheus", func(ctx SpecContext) {
+		groups = []string{rulesName}
+
+		// Kubewarden charts do not always ship rules, the ones found are loaded as well
+		out, err := kubectl.RunWithoutErr("get", "prometheusrules", "--namespace", kubewardenNS,
+			"-o", "jsonpath={.items[*].spec.groups[*].name}")
+		Expect(err).To(Not(HaveOccurred()))
+		shipped := strings.Fields(out)
+		AddReportEntry("shipped-rule-groups", shipped)
+		groups = append(groups, shipped...)
+
+		WaitFor(ctx, wait.Check(func() error {
+			loaded, err := metrics.RuleGroups(prometheus.URL("http"))
+			if err != nil {
+				return err
+			}
+			for _, g := range groups {
+				if !slices.Contains(loaded, g) {
+					return fmt.Errorf("rule group %s is not loaded", g)
+				}
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "rule groups to be loaded by Prometheus"})
+	})
+
+	It("Fire the alert of a reconcile error", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// The module cannot be pulled, the policy server and its policy cannot be reconciled
+		file := CopyYaml(brokenPolicyYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%SERVER_NAME%":         serverName,
+			"%POLICY_NAME%":         policyName,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitFor(ctx, wait.Check(func() error {
+			alerts, err := metrics.Alerts(prometheus.URL("http"))
+			if err != nil {
+				return err
+			}
+			for _, a := range alerts {
+				if a.Name() == alert && a.State == "firing" {
+					AddReportEntry("fired-alert", a.Labels)
+					return nil
+				}
+			}
+			return fmt.Errorf("alert %s is not firing", alert)
+		}), wait.Options{Class: timeouts.Rollout, Description: "alert " + alert + " to fire"})
+	})
+})
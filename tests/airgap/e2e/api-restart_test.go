@@ -0,0 +1,144 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: K3s is restarted, the test is skipped if K3s was not installed by the tests
+// API_RESTART_POLICIES sets the number of policies (default 50)
+var _ = Describe("E2E - Converge the policies after a restart of the API server", Label("api-restart", "full"), Ordered, Serial, func() {
+	// The first batch is applied before the restart, the others while K3s restarts
+	const batchSize = 10
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	policyCount := 50
+	if n, err := strconv.Atoi(os.Getenv("API_RESTART_POLICIES")); err == nil {
+		policyCount = n
+	}
+
+	scaleID := UniqueName("api-restart")
+	serverName := scaleID + "-server"
+	selector := "e2e-scale=" + scaleID
+	var controllerRestarts string
+
+	getControllerRestarts := func() string {
+		out, err := kubectl.RunWithoutErr("get", "pods", "--namespace", kubewardenNS, "-l", "app.kubernetes.io/name=kubewarden-controller",
+			"-o", "jsonpath={.items[*].status.containerStatuses[*].restartCount}")
+		Expect(err).To(Not(HaveOccurred()))
+		return out
+	}
+
+	BeforeAll(func() {
+		if _, err := k3sNode.Run("test", "-f", k3sMarkerFile); err != nil {
+			Skip("K3s was not installed by the tests")
+		}
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicies", "-l", selector, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Restart K3s while the policies are created", func(ctx SpecContext) {
+		controllerRestarts = getControllerRestarts()
+
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// The policy server is in the first batch, so the restart happens while its policies are reconciled
+		var batches [][]string
+		data, err := os.ReadFile(CopyYaml(policyCatalogServerYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"catalog-server":        serverName,
+		}))
+		Expect(err).To(Not(HaveOccurred()))
+		batch := []string{string(data)}
+		for i := range policyCount {
+			if len(batch) == batchSize {
+				batches = append(batches, batch)
+				batch = nil
+			}
+			data, err := os.ReadFile(CopyYaml(scalePolicyYaml, map[string]string{
+				"%NAME%":          fmt.Sprintf("%s-policy-%d", scaleID, i),
+				"%POLICY_SERVER%": serverName,
+				"%SCALE_ID%":      scaleID,
+			}))
+			Expect(err).To(Not(HaveOccurred()))
+			batch = append(batch, string(data))
+		}
+		batches = append(batches, batch)
+
+		restarted := make(chan error, 1)
+		for i, batch := range batches {
+			file := filepath.Join(GetTempDir(), fmt.Sprintf("%s-%d.yaml", scaleID, i))
+			err := os.WriteFile(file, []byte(strings.Join(batch, "\n---\n")), 0644)
+			Expect(err).To(Not(HaveOccurred()))
+
+			// The API server is down during the restart, the apply is retried until it is back
+			WaitFor(ctx, wait.Check(func() error {
+				return kubectl.Apply("", file)
+			}), wait.Options{Class: timeouts.Rollout, Description: fmt.Sprintf("batch %d of the policies to be applied", i)})
+
+			if i == 0 {
+				go func() {
+					_, err := k3sNode.WithSudo().Run("systemctl", "restart", "k3s")
+					restarted <- err
+				}()
+			}
+		}
+
+		err = <-restarted
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForK3s(k)
+	})
+
+	It("Converge all the policies without intervention", func(ctx SpecContext) {
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "kubewarden-controller")
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-"+serverName)
+
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "clusteradmissionpolicies", "-l", selector,
+				"-o", `jsonpath={range .items[*]}{.status.policyStatus}{"\n"}{end}`)
+			return strconv.Itoa(strings.Count(out, "active"))
+		}, Equal(strconv.Itoa(policyCount))), wait.Options{Class: timeouts.Install, Description: fmt.Sprintf("%d policies active", policyCount)})
+
+		// The controller can exit when it loses its leader lease, it only has to come back by itself
+		AddReportEntry("controller restarts", fmt.Sprintf("%q before, %q after the restart of K3s", controllerRestarts, getControllerRestarts()))
+		err := CheckPoliciesActive()
+		Expect(err).To(Not(HaveOccurred()))
+	})
+})
@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// Event of the API server audit log, only what is checked
+type auditEvent struct {
+	AuditID string `json:"auditID"`
+	Verb    string `json:"verb"`
+	User    struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectRef struct {
+		Resource  string `json:"resource"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"objectRef"`
+	RequestObject struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	} `json:"requestObject"`
+	ResponseStatus struct {
+		Code    int    `json:"code"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"responseStatus"`
+	StageTimestamp string `json:"stageTimestamp"`
+}
+
+// NOTE: the audit log is enabled when K3s is installed by the tests, unless K3S_AUDIT_LOG=false
+var _ = Describe("E2E - Correlate denied requests with the API server audit log", Label("audit-log", "full"), Flaky(), func() {
+	// Name of the webhook of a ClusterAdmissionPolicy, as registered by the controller
+	const webhook = "clusterwide-do-not-run-as-root.kubewarden.admission"
+
+	BeforeEach(func() {
+		if _, err := k3sNode.WithSudo().Run("test", "-f", k3sAuditLog); err != nil {
+			Skip("audit log of the API server is not enabled")
+		}
+	})
+
+	It("Find the audit event of a pod denied by a policy", func(ctx SpecContext) {
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+
+		pod := UniqueName("audit-root-pod")
+		out, err := kubectl.Run("run", pod, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+
+		if dryrun.Enabled() {
+			dryrun.Record("search the creation of pod %s in %s", pod, k3sAuditLog)
+			return
+		}
+
+		// Events are written when the response is sent, the log can be late
+		var found *auditEvent
+		WaitFor(ctx, wait.Check(func() error {
+			out, err := k3sNode.WithSudo().Run("grep", "-F", pod, k3sAuditLog)
+			if err != nil {
+				return fmt.Errorf("pod %s is not in the audit log: %w", pod, err)
+			}
+
+			for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+				var e auditEvent
+				if json.Unmarshal([]byte(line), &e) != nil {
+					continue
+				}
+				if e.Verb == "create" && e.ObjectRef.Resource == "pods" &&
+					(e.ObjectRef.Name == pod || e.RequestObject.Metadata.Name == pod) {
+					found = &e
+					return nil
+				}
+			}
+			return fmt.Errorf("no creation of pod %s in the audit log", pod)
+		}), wait.Options{Class: timeouts.Rollout, Description: "audit event of pod " + pod})
+
+		AddReportEntry("audit event", fmt.Sprintf("%s %s by %s: %d %s %s", found.StageTimestamp, found.AuditID,
+			found.User.Username, found.ResponseStatus.Code, found.ResponseStatus.Reason, found.ResponseStatus.Message))
+
+		// What an end user needs to know which policy denied the request, and why
+		Expect(found.ResponseStatus.Code).To(BeNumerically(">=", 400))
+		Expect(found.ResponseStatus.Message).To(ContainSubstring(fmt.Sprintf(`admission webhook "%s" denied the request`, webhook)))
+	})
+})
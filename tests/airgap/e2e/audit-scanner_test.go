@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: Kubewarden is reinstalled with the default values at the end
+var _ = Describe("E2E - Schedule, disable and enable the audit scanner", Label("audit-scanner", "full"), Ordered, Serial, func() {
+	// Short enough to see several scans in a spec
+	const schedule = "*/1 * * * *"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	ns := UniqueName("audit-scanner")
+	podName := UniqueName("audited-pod")
+
+	// Versions of the reports of the namespace, changed by each scan
+	reportVersions := func() string {
+		out, err := kubectl.RunWithoutErr("get", "policyreports", "--namespace", ns,
+			"-o", "jsonpath={range .items[*]}{.metadata.name}={.metadata.resourceVersion} {end}")
+		Expect(err).To(Not(HaveOccurred()))
+		return strings.TrimSpace(out)
+	}
+
+	// Names of the resources in the reports, per-resource and per-namespace formats
+	reportedResources := func() string {
+		out, _ := kubectl.RunWithoutErr("get", "policyreports", "--namespace", ns,
+			"-o", "jsonpath={.items[*].scope.name} {.items[*].results[*].resources[*].name}")
+		return out
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		// Audited by the recommended policies, reports are only created for existing resources
+		_, err = kubectl.RunWithoutErr("run", podName, "--namespace", ns, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsNonRoot": true, "runAsUser": 1000}}}`)
+		Expect(err).To(Not(HaveOccurred()))
+
+		DeferCleanup(func() {
+			// Other tests expect the default values
+			InstallKubewarden(k, kubewardenNS, "")
+			_, err := kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Scan the cluster on the configured schedule", func(ctx SpecContext) {
+		start := time.Now()
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().AuditScanner(true, schedule))
+
+		out, err := kubectl.RunWithoutErr("get", "cronjob", auditScannerCronJob, "--namespace", kubewardenNS,
+			"-o", "jsonpath={.spec.schedule}")
+		Expect(err).To(Not(HaveOccurred()))
+		if !dryrun.Enabled() {
+			Expect(out).To(Equal(schedule))
+		}
+
+		WaitForAuditScan(ctx, start)
+		WaitFor(ctx, wait.Match(reportedResources, ContainSubstring(podName)),
+			wait.Options{Class: timeouts.Rollout, Description: "report of pod " + podName})
+
+		// Reports are rewritten by the next scan
+		before := reportVersions()
+		WaitForAuditScan(ctx, time.Now())
+		WaitFor(ctx, wait.Match(reportVersions, Not(Equal(before))),
+			wait.Options{Class: timeouts.Rollout, Description: "reports of " + ns + " to be updated"})
+	})
+
+	It("Stop updating the reports when disabled", func(ctx SpecContext) {
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().AuditScanner(false, schedule))
+		WaitForDeletion(ctx, "cronjob", auditScannerCronJob, kubewardenNS)
+
+		// A job started before the upgrade can still be running
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "jobs", "--namespace", kubewardenNS, "-o", "name")
+			return out
+		}, Not(ContainSubstring(auditScannerCronJob))), wait.Options{Class: timeouts.Rollout, Description: "jobs of the audit scanner to be deleted"})
+
+		// Reports are kept, but not updated anymore
+		before := reportVersions()
+		if dryrun.Enabled() {
+			dryrun.Record("check that the reports of %s are not updated for 3 scan intervals", ns)
+			return
+		}
+		Expect(before).To(Not(BeEmpty()), "reports deleted with the audit scanner")
+		Consistently(reportVersions).WithTimeout(3*time.Minute).WithPolling(15*time.Second).
+			Should(Equal(before), "reports of %s updated while the audit scanner is disabled", ns)
+	})
+
+	It("Resume updating the reports when enabled again", func(ctx SpecContext) {
+		before := reportVersions()
+		start := time.Now()
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().AuditScanner(true, schedule))
+
+		WaitForAuditScan(ctx, start)
+		WaitFor(ctx, wait.Match(reportVersions, Not(Equal(before))),
+			wait.Options{Class: timeouts.Rollout, Description: "reports of " + ns + " to be updated"})
+	})
+
+	It("Garbage collect the reports of deleted resources", func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("delete", "pod", podName, "--namespace", ns, "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Stale reports are removed by the next scan, or by their owner reference
+		start := time.Now()
+		WaitForAuditScan(ctx, start)
+		WaitFor(ctx, wait.Match(reportedResources, Not(ContainSubstring(podName))),
+			wait.Options{Class: timeouts.Rollout, Description: "stale report of pod " + podName + " to be deleted"})
+	})
+})
@@ -0,0 +1,188 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// Admission requests sent while the policy server scales
+type admissionLoad struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	failures  []string
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NOTE: the HPA needs the metrics server of K3s, AUTOSCALING_LATENCY_BUDGET sets the accepted p95 latency
+var _ = Describe("E2E - Autoscale the policy server", Label("autoscaling", "perf"), Flaky(), Ordered, Serial, func() {
+	serverName := UniqueName("autoscaled-server")
+	policyName := UniqueName("autoscaled-policy")
+	deployment := "policy-server-" + serverName
+	ns := UniqueName("autoscaling")
+
+	budget := 2 * time.Second
+	if b, err := time.ParseDuration(os.Getenv("AUTOSCALING_LATENCY_BUDGET")); err == nil {
+		budget = b
+	}
+
+	// Pods are created with a server dry-run, they are evaluated by the policy but never stored
+	startLoad := func(workers int) *admissionLoad {
+		load := &admissionLoad{stop: make(chan struct{})}
+		for range workers {
+			load.wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer load.wg.Done()
+
+				for {
+					select {
+					case <-load.stop:
+						return
+					default:
+					}
+
+					start := time.Now()
+					out, err := kubectl.Run("run", UniqueName("load-pod"), "--namespace", ns, "--image=rancher/pause:3.2",
+						"--dry-run=server")
+					elapsed := time.Since(start)
+
+					load.mu.Lock()
+					load.latencies = append(load.latencies, elapsed)
+					if err != nil {
+						load.failures = append(load.failures, out)
+					}
+					load.mu.Unlock()
+				}
+			}()
+		}
+		return load
+	}
+
+	// Waits for the in-flight requests, then reports the p95 latency
+	stopLoad := func(name string, load *admissionLoad) time.Duration {
+		close(load.stop)
+		load.wg.Wait()
+
+		if len(load.latencies) == 0 {
+			return 0
+		}
+		slices.Sort(load.latencies)
+		p95 := load.latencies[len(load.latencies)*95/100]
+
+		AddReportEntry(name, fmt.Sprintf("%d requests, %d failures, p95 %s", len(load.latencies), len(load.failures), p95))
+		RecordMetric("autoscaling "+name+" admission p95 latency", p95.Seconds(), "s")
+		return p95
+	}
+
+	replicas := func() int {
+		out, _ := kubectl.RunWithoutErr("get", "deployment", deployment, "--namespace", kubewardenNS,
+			"-o", "jsonpath={.status.readyReplicas}")
+		n, _ := strconv.Atoi(out)
+		return n
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "hpa", serverName, "--namespace", kubewardenNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Deploy a policy server scaled by an HPA", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(autoscalingYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%KUBEWARDEN_NS%":       kubewardenNS,
+			"%NAMESPACE%":           ns,
+			"%SERVER_NAME%":         serverName,
+			"%POLICY_NAME%":         policyName,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+	})
+
+	It("Scale out under load with a bounded latency", func(ctx SpecContext) {
+		if dryrun.Enabled() {
+			dryrun.Record("send pods to %s until %s scales out, p95 latency under %s", policyName, deployment, budget)
+			return
+		}
+
+		load := startLoad(10)
+		WaitFor(ctx, wait.Check(func() error {
+			if n := replicas(); n < 2 {
+				return fmt.Errorf("%d ready replicas", n)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: deployment + " to scale out"})
+		p95 := stopLoad("scale out", load)
+
+		Expect(load.failures).To(BeEmpty(), "requests failed during the scale out")
+		Expect(p95).To(BeNumerically("<", budget), "p95 latency during the scale out is over budget")
+	})
+
+	It("Scale in without dropping in-flight requests", func(ctx SpecContext) {
+		if dryrun.Enabled() {
+			dryrun.Record("send pods to %s while %s scales in, no request dropped", policyName, deployment)
+			return
+		}
+
+		// Waiting for the utilization to be low takes long, the HPA is forced to one replica instead
+		load := startLoad(2)
+		_, err := kubectl.RunWithoutErr("patch", "hpa", serverName, "--namespace", kubewardenNS, "--type", "merge",
+			"-p", `{"spec": {"maxReplicas": 1}}`)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitFor(ctx, wait.Check(func() error {
+			out, _ := kubectl.RunWithoutErr("get", "deployment", deployment, "--namespace", kubewardenNS,
+				"-o", "jsonpath={.status.replicas}")
+			if out != "1" {
+				return fmt.Errorf("%s replicas", out)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: deployment + " to scale in"})
+		p95 := stopLoad("scale in", load)
+
+		Expect(load.failures).To(BeEmpty(), "requests dropped during the scale in")
+		Expect(p95).To(BeNumerically("<", budget), "p95 latency during the scale in is over budget")
+	})
+})
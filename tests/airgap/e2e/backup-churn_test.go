@@ -0,0 +1,258 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// Policies and policy servers created and deleted while scheduled backups run
+type policyChurn struct {
+	mu         sync.Mutex
+	servers    []string
+	iterations int
+	failures   []string
+	stop       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NOTE: BACKUP_CHURN_BACKUPS sets the number of scheduled backups taken during the churn
+var _ = Describe("E2E - Backup/Restore during continuous policy churn", Label("backup-churn", "nightly"), Ordered, Serial, func() {
+	backupCount := 3
+	if n, err := strconv.Atoi(os.Getenv("BACKUP_CHURN_BACKUPS")); err == nil {
+		backupCount = n
+	}
+
+	churnID := UniqueName("backup-churn")
+	backupName := UniqueName("kubewarden-churn-backup")
+	restoreName := UniqueName("kubewarden-churn-restore")
+	selector := "e2e-scale=" + churnID
+
+	var (
+		churn   *policyChurn
+		latest  string
+		backups []string
+	)
+
+	applyFile := func(asset string, values map[string]string) error {
+		out, err := kubectl.Run("apply", "-f", CopyYaml(asset, values))
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return nil
+	}
+
+	// Each iteration adds a policy server with a bound policy and deletes the ones of the previous iteration,
+	// nothing waits for the controller so the backups are taken in the middle of the reconciliations
+	startChurn := func(image string) *policyChurn {
+		churn := &policyChurn{stop: make(chan struct{})}
+		churn.wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer churn.wg.Done()
+
+			for i := 0; ; i++ {
+				server := fmt.Sprintf("%s-server-%d", churnID, i)
+				var errs []error
+				errs = append(errs, applyFile(policyCatalogServerYaml, map[string]string{
+					"%POLICY_SERVER_IMAGE%": image,
+					"catalog-server":        server,
+				}))
+				for kind, ps := range map[string]string{"bound": server, "default": "default"} {
+					errs = append(errs, applyFile(scalePolicyYaml, map[string]string{
+						"%NAME%":          fmt.Sprintf("%s-%s-%d", churnID, kind, i),
+						"%POLICY_SERVER%": ps,
+						"%SCALE_ID%":      churnID,
+					}))
+				}
+				if i > 0 {
+					// Bound policies are deleted with their policy server
+					if out, err := kubectl.Run("delete", "policyserver", fmt.Sprintf("%s-server-%d", churnID, i-1), "--wait=false"); err != nil {
+						errs = append(errs, fmt.Errorf("%w: %s", err, out))
+					}
+					if out, err := kubectl.Run("delete", "clusteradmissionpolicy", fmt.Sprintf("%s-default-%d", churnID, i-1), "--wait=false"); err != nil {
+						errs = append(errs, fmt.Errorf("%w: %s", err, out))
+					}
+				}
+
+				churn.mu.Lock()
+				churn.servers = append(churn.servers, server)
+				churn.iterations++
+				for _, err := range errs {
+					if err != nil {
+						churn.failures = append(churn.failures, err.Error())
+					}
+				}
+				churn.mu.Unlock()
+
+				select {
+				case <-churn.stop:
+					return
+				case <-time.After(10 * time.Second):
+				}
+			}
+		}()
+		return churn
+	}
+
+	stopChurn := func() {
+		if churn == nil {
+			return
+		}
+		select {
+		case <-churn.stop:
+		default:
+			close(churn.stop)
+		}
+		churn.wg.Wait()
+	}
+
+	// Resources of the test, as name=reference lines
+	list := func(kind, jsonpath string, extra ...string) map[string]string {
+		out, err := kubectl.RunWithoutErr(append([]string{"get", kind, "-o", "jsonpath={range .items[*]}" + jsonpath + `{"\n"}{end}`}, extra...)...)
+		Expect(err).To(Not(HaveOccurred()))
+
+		items := map[string]string{}
+		for _, line := range strings.Fields(out) {
+			name, ref, _ := strings.Cut(line, "=")
+			if strings.Contains(name, churnID) {
+				items[name] = ref
+			}
+		}
+		return items
+	}
+
+	deleteChurn := func() {
+		_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicies", "-l", selector, "--ignore-not-found", "--wait=false")
+		Expect(err).To(Not(HaveOccurred()))
+		if churn != nil && len(churn.servers) > 0 {
+			_, err = kubectl.RunWithoutErr(append([]string{"delete", "policyservers", "--ignore-not-found", "--wait"}, churn.servers...)...)
+			Expect(err).To(Not(HaveOccurred()))
+		}
+	}
+
+	BeforeAll(func() {
+		if deployed, _ := GetReleases(localCluster, "cattle-resources-system"); len(deployed) == 0 {
+			Skip("rancher-backup operator is not installed")
+		}
+
+		DeferCleanup(func() {
+			stopChurn()
+			// The schedule stops with the Backup resource
+			_, err := kubectl.RunWithoutErr("delete", "backup", backupName, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "restore", restoreName, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			deleteChurn()
+		})
+	})
+
+	It("Take scheduled backups while policies are created and deleted", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		churn = startChurn(image)
+
+		file := CopyYaml(scheduledBackupYaml, map[string]string{
+			"%NAME%":      backupName,
+			"%SCHEDULE%":  "@every 1m",
+			"%RETENTION%": strconv.Itoa(backupCount + 1),
+		})
+		err = kubectl.Apply(clusterNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Each scheduled backup updates the file name of the Backup resource
+		WaitFor(ctx, wait.Check(func() error {
+			out, _ := kubectl.RunWithoutErr("get", "backup", backupName, "-o", "jsonpath={.status.filename}")
+			if out != "" && !slices.Contains(backups, out) {
+				backups = append(backups, out)
+			}
+			if len(backups) < backupCount {
+				return fmt.Errorf("%d of %d scheduled backups taken", len(backups), backupCount)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Backup, Description: strconv.Itoa(backupCount) + " scheduled backups of " + backupName})
+
+		stopChurn()
+		// Backups taken once the churn resources are deleted would hide a torn restore
+		_, err = kubectl.RunWithoutErr("delete", "backup", backupName)
+		Expect(err).To(Not(HaveOccurred()))
+
+		Expect(churn.failures).To(BeEmpty(), "churn failures:\n%s", strings.Join(churn.failures, "\n"))
+		AddReportEntry("backup churn", fmt.Sprintf("%d iterations, backups %s", churn.iterations, strings.Join(backups, ", ")))
+		if len(backups) > 0 {
+			latest = backups[len(backups)-1]
+		}
+	})
+
+	It("Restore the latest scheduled backup", func(ctx SpecContext) {
+		deleteChurn()
+		WaitFor(ctx, wait.Check(func() error {
+			if left := list("clusteradmissionpolicies", "{.metadata.name}={.spec.policyServer}", "-l", selector); len(left) > 0 {
+				return fmt.Errorf("%d churn policies left", len(left))
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "churn policies to be deleted"})
+
+		Expect(latest).To(Not(BeEmpty()), "no scheduled backup of %s", backupName)
+		ApplyRestore(restoreName, latest, false)
+		WaitForReady(ctx, "restore", restoreName)
+	})
+
+	It("Restore a consistent set of policies, policy servers and webhooks", func(ctx SpecContext) {
+		servers := list("policyservers", "{.metadata.name}={.metadata.uid}")
+		policies := list("clusteradmissionpolicies", "{.metadata.name}={.spec.policyServer}", "-l", selector)
+		AddReportEntry("restored churn resources", fmt.Sprintf("%d policy servers, %d policies", len(servers), len(policies)))
+
+		// No policy references a policy server deleted before the backup
+		for policy, server := range policies {
+			if server == "default" {
+				continue
+			}
+			Expect(servers).To(HaveKey(server), "policy %s references the deleted policy server %s", policy, server)
+		}
+
+		// Reconciliation is done, the webhooks and deployments have to match the restored resources
+		WaitFor(ctx, wait.Check(func() error {
+			for webhook := range list("validatingwebhookconfigurations,mutatingwebhookconfigurations", "{.metadata.name}=") {
+				if _, found := policies[strings.TrimPrefix(webhook, "clusterwide-")]; !found {
+					return fmt.Errorf("webhook %s has no policy", webhook)
+				}
+			}
+			for deployment := range list("deployments", "{.metadata.name}=", "--namespace", kubewardenNS) {
+				if _, found := servers[strings.TrimPrefix(deployment, "policy-server-")]; !found {
+					return fmt.Errorf("deployment %s has no policy server", deployment)
+				}
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "no orphan webhook or policy server deployment"})
+
+		for policy := range policies {
+			WaitForPolicyActive(ctx, localCluster, policy)
+		}
+	})
+})
@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: the specs are built when the tree is constructed, so BACKUP_MATRIX_VERSIONS is read directly
+var _ = Describe("E2E - Restore with another version of the backup operator", Label("backup-matrix", "nightly"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Operator releases, from the oldest to the newest
+	var versions []string
+	for _, v := range strings.Split(os.Getenv("BACKUP_MATRIX_VERSIONS"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+
+	if len(versions) < 2 {
+		It("Restore with another version of the backup operator", func() {
+			Skip("BACKUP_MATRIX_VERSIONS needs at least two operator versions")
+		})
+		return
+	}
+
+	// Check the chart version of the installed operator
+	checkOperatorVersion := func(version string) {
+		releases, err := GetReleases(localCluster, "cattle-resources-system")
+		Expect(err).To(Not(HaveOccurred()))
+
+		var chart string
+		for _, r := range releases {
+			if r.Name == "rancher-backup" {
+				chart = r.Chart
+			}
+		}
+		Expect(chart).To(Equal("rancher-backup-"+strings.Trim(version, "v")), "backup operator version")
+	}
+
+	AfterAll(func() {
+		// Next specs expect the version asked for the run
+		InstallBackupOperator(k, backupRestoreVersion)
+	})
+
+	// Restore with a newer and an older version for each pair
+	var entries []TableEntry
+	for i := range versions {
+		for _, newer := range versions[i+1:] {
+			entries = append(entries,
+				Entry("from "+versions[i]+" to the newer "+newer, versions[i], newer),
+				Entry("from "+newer+" to the older "+versions[i], newer, versions[i]),
+			)
+		}
+	}
+
+	DescribeTable("Back up with one version and restore with another",
+		func(ctx SpecContext, from, to string) {
+			policyName := UniqueName("backup-matrix-policy")
+			backupName := UniqueName("kubewarden-matrix-backup")
+			restoreName := UniqueName("kubewarden-matrix-restore")
+
+			DeferCleanup(func() {
+				_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+				Expect(err).To(Not(HaveOccurred()))
+			})
+
+			By("Installing the backup operator "+from, func() {
+				InstallBackupOperator(k, from)
+				checkOperatorVersion(from)
+			})
+
+			By("Adding a policy", func() {
+				file := CopyYaml(policyCatalogPolicyYaml, map[string]string{
+					"%NAME%":          policyName,
+					"%POLICY_SERVER%": "default",
+					"%MODULE%":        "ghcr.io/kubewarden/policies/pod-privileged:v0.2.1",
+					"%SETTINGS%":      "{}",
+				})
+				err := kubectl.Apply("", file)
+				Expect(err).To(Not(HaveOccurred()))
+				WaitForPolicyActive(ctx, localCluster, policyName)
+			})
+
+			var backupFile string
+			By("Adding a backup resource", func() {
+				ApplyBackup(backupName)
+				WaitForReady(ctx, "backup", backupName)
+				backupFile = GetBackupFile(backupName)
+			})
+
+			By("Deleting the policy", func() {
+				_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--wait")
+				Expect(err).To(Not(HaveOccurred()))
+			})
+
+			By("Installing the backup operator "+to, func() {
+				InstallBackupOperator(k, to)
+				checkOperatorVersion(to)
+			})
+
+			By("Restoring the backup of "+from, func() {
+				ApplyRestore(restoreName, backupFile, false)
+				WaitForReady(ctx, "restore", restoreName)
+			})
+
+			By("Checking that the policy is back", func() {
+				WaitForPolicyActive(ctx, localCluster, policyName)
+			})
+		},
+		entries,
+	)
+})
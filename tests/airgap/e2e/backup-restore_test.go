@@ -15,15 +15,17 @@ limitations under the License.
 package e2e_test
 
 import (
-	"os"
-	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/backup"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/events"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
-	"github.com/rancher-sandbox/ele-testhelpers/tools"
 )
 
 const (
@@ -31,25 +33,31 @@ const (
 	restoreResourceName = "kubewarden-restore"
 )
 
-var _ = Describe("E2E - Install K3S", Label("install-k3s"), func() {
+var _ = Describe("E2E - Install K3S", Label("install-k3s"), Serial, func() {
 	// Create kubectl context
 	// Default timeout is too small, so New() cannot be used
 	k := &kubectl.Kubectl{
 		Namespace:    "",
-		PollTimeout:  tools.SetTimeout(300 * time.Second),
+		PollTimeout:  timeouts.For(timeouts.Rollout),
 		PollInterval: 500 * time.Millisecond,
 	}
 
-	// Define local Kubeconfig file
-	localKubeconfig := os.Getenv("HOME") + "/.kube/config"
+	// Other steps expect the installation to be done before them
+	BeforeEach(func() {
+		RequireInstallStep("install-k3s")
+	})
 
 	It("Install K3S", func() {
 
 		By("Installing K3S", func() {
-			InstallK3s()
+			if ReuseK3s(k3sNode) {
+				GinkgoWriter.Printf("Reusing K3s already installed\n")
+				return
+			}
+			InstallK3s(k3sNode)
 		})
 		By("Starting K3s", func() {
-			StartK3s()
+			StartK3s(k3sNode)
 		})
 
 		By("Waiting for K3s to be started", func() {
@@ -58,157 +66,151 @@ var _ = Describe("E2E - Install K3S", Label("install-k3s"), func() {
 
 		By("Configuring Kubeconfig file", func() {
 			// Copy K3s file in ~/.kube/config
-			// NOTE: don't check for error, as it will happen anyway
-			file, _ := exec.Command("bash", "-c", "ls /etc/rancher/k3s/k3s.yaml").Output()
-			Expect(file).To(Not(BeEmpty()))
-			err := tools.CopyFile(strings.Trim(string(file), "\n"), localKubeconfig)
-			Expect(err).To(Not(HaveOccurred()))
-
-			err = os.Setenv("KUBECONFIG", localKubeconfig)
-			Expect(err).To(Not(HaveOccurred()))
+			ConfigureKubeconfig(k3sNode)
 		})
 	})
 })
 
-var _ = Describe("E2E - Install Kubewarden", Label("install-kubewarden"), func() {
+var _ = Describe("E2E - Install Kubewarden", Label("install-kubewarden"), Serial, func() {
 	// Create kubectl context
 	// Default timeout is too small, so New() cannot be used
 	k := &kubectl.Kubectl{
 		Namespace:    "",
-		PollTimeout:  tools.SetTimeout(300 * time.Second),
+		PollTimeout:  timeouts.For(timeouts.Rollout),
 		PollInterval: 500 * time.Millisecond,
 	}
 
+	// Other steps expect the installation to be done before them
+	BeforeEach(func() {
+		RequireInstallStep("install-kubewarden")
+	})
+
 	It("Install Kubewarden stack", func() {
 
 		By("Installing Kubewarden stack", func() {
-			InstallKubewarden(k)
+			if ReuseKubewarden(kubewardenNS, "") {
+				GinkgoWriter.Printf("Reusing Kubewarden already installed in %s\n", kubewardenNS)
+				return
+			}
+			InstallKubewarden(k, kubewardenNS, "")
 		})
 	})
 })
 
-var _ = Describe("E2E - Install Backup/Restore Operator", Label("install-backup-restore"), func() {
+var _ = Describe("E2E - Install Backup/Restore Operator", Label("install-backup-restore"), Serial, func() {
 	// Create kubectl context
 	// Default timeout is too small, so New() cannot be used
 	k := &kubectl.Kubectl{
 		Namespace:    "",
-		PollTimeout:  tools.SetTimeout(300 * time.Second),
+		PollTimeout:  timeouts.For(timeouts.Rollout),
 		PollInterval: 500 * time.Millisecond,
 	}
 
+	// Other steps expect the installation to be done before them
+	BeforeEach(func() {
+		RequireInstallStep("install-backup-restore")
+	})
+
 	It("Install Backup/Restore Operator", func() {
 
 		By("Installing rancher-backup-operator", func() {
-			InstallBackupOperator(k)
+			if ReuseBackupOperator() {
+				GinkgoWriter.Printf("Reusing rancher-backup-operator already installed\n")
+				return
+			}
+			InstallBackupOperator(k, backupRestoreVersion)
 		})
 	})
 })
 
-var _ = Describe("E2E - Test full Backup/Restore", Label("test-full-backup-restore"), func() {
+var _ = Describe("E2E - Test full Backup/Restore", Label("test-full-backup-restore", "full"), Serial, func() {
 	// Create kubectl context
 	// Default timeout is too small, so New() cannot be used
 	k := &kubectl.Kubectl{
 		Namespace:    "",
-		PollTimeout:  tools.SetTimeout(300 * time.Second),
+		PollTimeout:  timeouts.For(timeouts.Rollout),
 		PollInterval: 500 * time.Millisecond,
 	}
 
-	var backupFile string
+	var artifact *backup.Artifact
+
+	backupName := UniqueName(backupResourceName)
+	restoreName := UniqueName(restoreResourceName)
 
-	It("Do a full backup/restore test", func() {
+	It("Do a full backup/restore test", func(ctx SpecContext) {
 		// TODO: use another case id for full backup/restore test
 		// Report to Qase
 		// testCaseID = 65
 
 		By("Adding a backup resource", func() {
-			err := kubectl.Apply("kubewarden", backupYaml)
-			Expect(err).To(Not(HaveOccurred()))
+			ApplyBackup(backupName)
 		})
 
 		By("Checking that the backup has been done", func() {
-			out, err := kubectl.RunWithoutErr("get", "backup", backupResourceName,
-				"-o", "jsonpath={.metadata.name}")
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(out).To(ContainSubstring(backupResourceName))
-
-			// Wait for backup to be done
-			CheckBackupRestore("Done with backup")
+			WaitForReady(ctx, "backup", backupName)
 		})
 
 		By("Copying the backup file", func() {
-			// Get local storage path
-			localPath := GetBackupDir()
-
-			// Get the backup file from the previous backup
-			file, err := kubectl.RunWithoutErr("get", "backup", backupResourceName, "-o", "jsonpath={.status.filename}")
-			Expect(err).To(Not(HaveOccurred()))
-
-			// Share the filename across other functions
-			backupFile = file
+			// Share the artifact across other functions
+			artifact = backup.New(GetBackupFile(backupName), backup.Target{Type: backup.LocalPath, Location: GetBackupDir()})
 
 			// Copy backup file
-			err = exec.Command("sudo", "cp", localPath+"/"+backupFile, ".").Run()
+			err := artifact.Store(".")
 			Expect(err).To(Not(HaveOccurred()))
+
+			// Record the checksum, useful to compare with the file kept in CI artifacts
+			GinkgoWriter.Printf("Backup file %s: %d bytes, sha256 %s\n", artifact.FileName, artifact.Size, artifact.Sum)
+			AddReportEntry("backup-sha256", artifact.Sum)
 		})
 
 		By("Uninstalling K3s", func() {
-			out, err := exec.Command("k3s-uninstall.sh").CombinedOutput()
-			Expect(err).To(Not(HaveOccurred()), out)
+			UninstallK3s(k3sNode)
 		})
 
 		By("Installing K3s", func() {
-			InstallK3s()
+			InstallK3s(k3sNode)
 		})
 
-		// Use the new Kube config
-		err := os.Setenv("KUBECONFIG", "/etc/rancher/k3s/k3s.yaml")
-		Expect(err).To(Not(HaveOccurred()))
-
 		By("Starting K3s", func() {
-			StartK3s()
+			StartK3s(k3sNode)
 		})
 
+		// Use the new Kube config
+		ConfigureKubeconfig(k3sNode)
+
 		By("Waiting for K3s to be started", func() {
 			WaitForK3s(k)
 		})
 
 		By("Installing rancher-backup-operator", func() {
-			InstallBackupOperator(k)
+			InstallBackupOperator(k, backupRestoreVersion)
 		})
 
-		By("Copying backup file to restore", func() {
-			// Get new local storage path
-			localPath := GetBackupDir()
-
-			// Copy backup file
-			err := exec.Command("sudo", "cp", backupFile, localPath).Run()
+		By("Verifying the backup file integrity", func() {
+			// The K3s reinstall should not have touched the local copy
+			err := artifact.Verify()
 			Expect(err).To(Not(HaveOccurred()))
 		})
 
-		By("Adding a restore resource", func() {
-			// Set the backup file in the restore resource
-			err := tools.Sed("%BACKUP_FILE%", backupFile, restoreYaml)
+		By("Copying backup file to restore", func() {
+			// Copy backup file into the new local storage path
+			err := artifact.Inject(backup.Target{Type: backup.LocalPath, Location: GetBackupDir()})
 			Expect(err).To(Not(HaveOccurred()))
+		})
 
+		By("Adding a restore resource", func() {
 			// "prune" option should be set to true here
-			err = tools.Sed("%PRUNE%", "false", restoreYaml)
-			Expect(err).To(Not(HaveOccurred()))
-
-			// And apply
-			err = kubectl.Apply(clusterNS, restoreYaml)
-			Expect(err).To(Not(HaveOccurred()))
+			ApplyRestore(restoreName, artifact.FileName, false)
 		})
 
 		By("Checking that the restore has been done", func() {
-			// Wait until resources are available again
-			Eventually(func() string {
-				out, _ := kubectl.RunWithoutErr("get", "restore", restoreResourceName,
-					"-o", "jsonpath={.metadata.name}")
-				return out
-			}, tools.SetTimeout(5*time.Minute), 10*time.Second).Should(ContainSubstring(restoreResourceName))
+			WaitForReady(ctx, "restore", restoreName)
+		})
 
-			// Wait for restore to be done
-			CheckBackupRestore("Done restoring")
+		// The next specs of the tier run on the same cluster, as after the installation steps
+		By("Re-installing Kubewarden on top of restored resources", func() {
+			InstallKubewarden(k, kubewardenNS, "")
+			WaitForReconciled(ctx)
 		})
 		/*
 			By("Installing CertManager", func() {
@@ -231,26 +233,22 @@ var _ = Describe("E2E - Test full Backup/Restore", Label("test-full-backup-resto
 	})
 })
 
-var _ = Describe("E2E - Test simple Backup/Restore", Label("test-simple-backup-restore"), func() {
-	It("Do a backup", func() {
+var _ = Describe("E2E - Test simple Backup/Restore", Label("test-simple-backup-restore", "smoke", "full"), Ordered, Serial, func() {
+	backupName := UniqueName(backupResourceName)
+	restoreName := UniqueName(restoreResourceName)
+
+	It("Do a backup", func(ctx SpecContext) {
 
 		By("Adding a backup resource", func() {
-			err := kubectl.Apply(clusterNS, backupYaml)
-			Expect(err).To(Not(HaveOccurred()))
+			ApplyBackup(backupName)
 		})
 
 		By("Checking that the backup has been done", func() {
-			out, err := kubectl.RunWithoutErr("get", "backup", backupResourceName,
-				"-o", "jsonpath={.metadata.name}")
-			Expect(err).To(Not(HaveOccurred()))
-			Expect(out).To(ContainSubstring(backupResourceName))
-
-			// Wait for backup to be done
-			CheckBackupRestore("Done with backup")
+			WaitForReady(ctx, "backup", backupName)
 		})
 	})
 
-	It("Do a restore", func() {
+	It("Do a restore", func(ctx SpecContext) {
 
 		By("Deleting some Elemental resources", func() {
 			for _, obj := range []string{"MachineRegistration", "MachineInventorySelectorTemplate"} {
@@ -270,35 +268,197 @@ var _ = Describe("E2E - Test simple Backup/Restore", Label("test-simple-backup-r
 
 		By("Adding a restore resource", func() {
 			// Get the backup file from the previous backup
-			backupFile, err := kubectl.RunWithoutErr("get", "backup", backupResourceName, "-o", "jsonpath={.status.filename}")
+			ApplyRestore(restoreName, GetBackupFile(backupName), true)
+		})
+
+		By("Checking that the restore has been done", func() {
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking Kubewarden resources after restore", func() {
+		})
+	})
+})
+
+var _ = Describe("E2E - Test Backup/Restore with pending policies", Label("test-pending-backup-restore", "full"), Serial, func() {
+	const (
+		policyStatusJSONPath = "jsonpath={.status.policyStatus}"
+		// Module of the restored policies, once they can be activated
+		pendingModule = "ghcr.io/kubewarden/tests/pod-privileged:v0.2.5"
+		// TEST-NET-1 address, never routed: the policies stay pending until the module is changed
+		unreachableModule = "192.0.2.1/kubewarden/tests/pod-privileged:v0.2.5"
+	)
+
+	pendingBackupName := UniqueName("kubewarden-pending-backup")
+	pendingPolicyName := UniqueName("pending-privileged-pods")
+	pendingRestoreName := UniqueName("kubewarden-pending-restore")
+	pendingServerName := UniqueName("pending-server")
+
+	It("Do a backup/restore while policies are not yet active", func(ctx SpecContext) {
+		By("Adding policies bound to a new PolicyServer", func() {
+			image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+			Expect(err).To(Not(HaveOccurred()))
+
+			policies := CopyYaml(pendingPoliciesYaml, map[string]string{
+				"%POLICY_SERVER_IMAGE%":   image,
+				"%MODULE%":                unreachableModule,
+				"pending-privileged-pods": pendingPolicyName,
+				"pending-server":          pendingServerName,
+			})
+			err = kubectl.Apply(clusterNS, policies)
 			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Adding a backup resource before the policies are active", func() {
+			ApplyBackup(pendingBackupName)
 
-			// Set the backup file in the restore resource
-			err = tools.Sed("%BACKUP_FILE%", backupFile, restoreYaml)
+			// The module cannot be downloaded, so the policy is still pending
+			out, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", pendingPolicyName, "-o", policyStatusJSONPath)
 			Expect(err).To(Not(HaveOccurred()))
+			GinkgoWriter.Printf("Policy status at backup time: %s\n", out)
+			Expect(out).To(Not(Equal("active")))
+		})
 
-			// "prune" option should be set to true here
-			err = tools.Sed("%PRUNE%", "true", restoreYaml)
+		By("Checking that the backup has been done", func() {
+			WaitForReady(ctx, "backup", pendingBackupName)
+		})
+
+		By("Deleting the pending resources", func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", pendingPolicyName)
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", pendingServerName)
 			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(pendingRestoreName, GetBackupFile(pendingBackupName), false)
 
-			// And apply
-			err = kubectl.Apply(clusterNS, restoreYaml)
+			// Resources cannot be reconciled yet, the restored policy is pending
+			err := WaitForDone(ctx, "restore", pendingRestoreName)
 			Expect(err).To(Not(HaveOccurred()))
 		})
 
-		By("Checking that the restore has been done", func() {
-			// Wait until resources are available again
-			Eventually(func() string {
-				out, _ := kubectl.RunWithoutErr("get", "restore", restoreResourceName,
-					"-o", "jsonpath={.metadata.name}")
+		By("Checking that restored policies converge to active state", func() {
+			_, err := kubectl.RunWithoutErr("patch", "clusteradmissionpolicy", pendingPolicyName, "--type", "merge",
+				"-p", `{"spec": {"module": "registry://`+pendingModule+`"}}`)
+			Expect(err).To(Not(HaveOccurred()))
+
+			WaitFor(ctx, wait.Match(func() string {
+				out, _ := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", pendingPolicyName, "-o", policyStatusJSONPath)
 				return out
-			}, tools.SetTimeout(5*time.Minute), 10*time.Second).Should(ContainSubstring(restoreResourceName))
+			}, Equal("active")), wait.Options{Class: timeouts.Restore, Description: "restored policy to be active"})
+			WaitForReconciled(ctx)
+		})
 
-			// Wait for restore to be done
-			CheckBackupRestore("Done restoring")
+		By("Checking that the restored PolicyServer has been reconciled", func() {
+			// Deployment created again by the controller, not restored from the backup
+			WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-"+pendingServerName)
+			WaitForEventOfCurrent(ctx, "Deployment", "policy-server-"+pendingServerName, kubewardenNS, events.HaveEvent("ScalingReplicaSet"))
 		})
+	})
+})
 
-		By("Checking Kubewarden resources after restore", func() {
+var _ = Describe("E2E - Test Restore of a missing backup file", Label("test-missing-backup-restore", "full"), Serial, func() {
+	missingRestoreName := UniqueName("kubewarden-missing-restore")
+
+	It("Report the failure of a restore without backup file", func(ctx SpecContext) {
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "restore", missingRestoreName, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
 		})
+
+		start := time.Now()
+		ApplyRestore(missingRestoreName, "missing-"+randomHex(8)+".tar.gz", false)
+
+		if dryrun.Enabled() {
+			dryrun.Record("check that restore %s fails within %s", missingRestoreName, backupFailureGrace)
+			return
+		}
+
+		// The condition message is reported, not a timeout of the restore class
+		err := WaitForDone(ctx, "restore", missingRestoreName)
+		Expect(err).To(MatchError(ContainSubstring("restore " + missingRestoreName + " failed: ")))
+		Expect(err.Error()).To(Not(ContainSubstring("not met after")))
+		GinkgoWriter.Printf("Restore failure: %s\n", err)
+
+		elapsed := time.Since(start)
+		Expect(elapsed).To(BeNumerically("<", backupFailureGrace+2*time.Minute), "failure of %s reported after %s", missingRestoreName, elapsed)
 	})
 })
+
+var _ = Describe("E2E - Test in-place Restore over a drifted installation", Label("test-in-place-backup-restore", "full"), Serial, func() {
+	const timeoutJSONPath = "jsonpath={.spec.timeoutSeconds}"
+
+	addPolicy := func(ctx SpecContext, name string) {
+		file := CopyYaml(policyCatalogPolicyYaml, map[string]string{
+			"%NAME%":          name,
+			"%POLICY_SERVER%": "default",
+			"%MODULE%":        "ghcr.io/kubewarden/policies/pod-privileged:v0.2.1",
+			"%SETTINGS%":      "{}",
+		})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, name)
+	}
+
+	policyExists := func(name string) bool {
+		_, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", name)
+		return err == nil
+	}
+
+	DescribeTable("Restore over policies changed after the backup",
+		func(ctx SpecContext, prune bool) {
+			backupName := UniqueName("kubewarden-in-place-backup")
+			restoreName := UniqueName("kubewarden-in-place-restore")
+			modifiedPolicy := UniqueName("in-place-modified")
+			deletedPolicy := UniqueName("in-place-deleted")
+			addedPolicy := UniqueName("in-place-added")
+
+			DeferCleanup(func() {
+				_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", modifiedPolicy, deletedPolicy, addedPolicy,
+					"--ignore-not-found", "--wait")
+				Expect(err).To(Not(HaveOccurred()))
+			})
+
+			var timeout string
+			By("Adding policies and a backup resource", func() {
+				addPolicy(ctx, modifiedPolicy)
+				addPolicy(ctx, deletedPolicy)
+
+				var err error
+				timeout, err = kubectl.RunWithoutErr("get", "clusteradmissionpolicy", modifiedPolicy, "-o", timeoutJSONPath)
+				Expect(err).To(Not(HaveOccurred()))
+
+				ApplyBackup(backupName)
+				WaitForReady(ctx, "backup", backupName)
+			})
+
+			By("Drifting from the backup", func() {
+				_, err := kubectl.RunWithoutErr("patch", "clusteradmissionpolicy", modifiedPolicy, "--type", "merge",
+					"-p", `{"spec": {"timeoutSeconds": 3}}`)
+				Expect(err).To(Not(HaveOccurred()))
+				_, err = kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", deletedPolicy, "--wait")
+				Expect(err).To(Not(HaveOccurred()))
+				addPolicy(ctx, addedPolicy)
+			})
+
+			By("Restoring the backup in place", func() {
+				ApplyRestore(restoreName, GetBackupFile(backupName), prune)
+				WaitForReady(ctx, "restore", restoreName)
+			})
+
+			By("Checking the reconciliation semantics", func() {
+				// Resources of the backup are restored as they were, whatever the prune option
+				out, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", modifiedPolicy, "-o", timeoutJSONPath)
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(out).To(Equal(timeout), "modification of %s not undone", modifiedPolicy)
+				WaitForPolicyActive(ctx, localCluster, deletedPolicy)
+
+				// Resources created after the backup are only deleted with prune
+				Expect(policyExists(addedPolicy)).To(Equal(!prune), "policy %s created after the backup, prune %t", addedPolicy, prune)
+			})
+		},
+		Entry("without prune", false),
+		Entry("with prune", true),
+	)
+})
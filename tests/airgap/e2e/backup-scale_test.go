@@ -0,0 +1,179 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: BACKUP_SCALE_POLICIES and BACKUP_SCALE_SERVERS set the number of resources, BACKUP_SCALE_BUDGET the time budget of the backup and of the restore
+var _ = Describe("E2E - Backup/Restore hundreds of policies", Label("backup-scale", "perf"), Ordered, Serial, func() {
+	policyCount := 300
+	if n, err := strconv.Atoi(os.Getenv("BACKUP_SCALE_POLICIES")); err == nil {
+		policyCount = n
+	}
+	serverCount := 3
+	if n, err := strconv.Atoi(os.Getenv("BACKUP_SCALE_SERVERS")); err == nil {
+		serverCount = n
+	}
+	budget := 10 * time.Minute
+	if b, err := time.ParseDuration(os.Getenv("BACKUP_SCALE_BUDGET")); err == nil {
+		budget = b
+	}
+
+	scaleID := UniqueName("backup-scale")
+	backupName := UniqueName("kubewarden-scale-backup")
+	restoreName := UniqueName("kubewarden-scale-restore")
+	selector := "e2e-scale=" + scaleID
+
+	var servers []string
+	for i := range serverCount {
+		servers = append(servers, fmt.Sprintf("%s-server-%d", scaleID, i))
+	}
+
+	// Pods of the operator and of the controller, they must not be OOM killed at scale
+	operators := map[string]string{
+		"cattle-resources-system": "app.kubernetes.io/name=rancher-backup",
+		kubewardenNS:              "app.kubernetes.io/name=kubewarden-controller",
+	}
+	restarts := map[string]string{}
+
+	getRestarts := func(ns, label string) string {
+		out, err := kubectl.RunWithoutErr("get", "pods", "--namespace", ns, "-l", label,
+			"-o", "jsonpath={.items[*].status.containerStatuses[*].restartCount}")
+		Expect(err).To(Not(HaveOccurred()))
+		return out
+	}
+
+	// Number of policies of the test in the given status, all of them if empty
+	countPolicies := func(status string) int {
+		out, _ := kubectl.RunWithoutErr("get", "clusteradmissionpolicies", "-l", selector,
+			"-o", `jsonpath={range .items[*]}{.status.policyStatus}{"\n"}{end}`)
+		n := 0
+		for _, s := range strings.Fields(out) {
+			if status == "" || s == status {
+				n++
+			}
+		}
+		return n
+	}
+
+	waitForPolicies := func(ctx SpecContext, status string, count int) {
+		WaitFor(ctx, wait.Match(func() string {
+			return strconv.Itoa(countPolicies(status))
+		}, Equal(strconv.Itoa(count))), wait.Options{Class: timeouts.Install, Description: fmt.Sprintf("%d policies %s", count, cmp.Or(status, "deployed"))})
+	}
+
+	// Time taken by an operation, reported and checked against the budget
+	checkBudget := func(operation string, start time.Time) {
+		elapsed := time.Since(start)
+		RecordMetric(fmt.Sprintf("backup-scale %s time of %d policies", operation, policyCount), elapsed.Seconds(), "s")
+		Expect(elapsed).To(BeNumerically("<", budget), "%s of %d policies took %s", operation, policyCount, elapsed)
+	}
+
+	BeforeAll(func() {
+		for ns, label := range operators {
+			restarts[ns] = getRestarts(ns, label)
+		}
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicies", "-l", selector, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr(append([]string{"delete", "policyserver", "--ignore-not-found", "--wait"}, servers...)...)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Deploy hundreds of policies on several policy servers", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Everything is applied at once, one file per resource would be too slow
+		var docs []string
+		add := func(src string, values map[string]string) {
+			data, err := os.ReadFile(CopyYaml(src, values))
+			Expect(err).To(Not(HaveOccurred()))
+			docs = append(docs, string(data))
+		}
+		for _, server := range servers {
+			add(policyCatalogServerYaml, map[string]string{
+				"%POLICY_SERVER_IMAGE%": image,
+				"catalog-server":        server,
+			})
+		}
+		for i := range policyCount {
+			add(scalePolicyYaml, map[string]string{
+				"%NAME%":          fmt.Sprintf("%s-policy-%d", scaleID, i),
+				"%POLICY_SERVER%": servers[i%serverCount],
+				"%SCALE_ID%":      scaleID,
+			})
+		}
+
+		file := filepath.Join(GetTempDir(), scaleID+".yaml")
+		err = os.WriteFile(file, []byte(strings.Join(docs, "\n---\n")), 0644)
+		Expect(err).To(Not(HaveOccurred()))
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		waitForPolicies(ctx, "active", policyCount)
+	})
+
+	It("Back up all the policies", func(ctx SpecContext) {
+		start := time.Now()
+		ApplyBackup(backupName)
+		WaitForReady(ctx, "backup", backupName)
+		checkBudget("backup", start)
+	})
+
+	It("Wipe the policies and the policy servers", func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicies", "-l", selector, "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = kubectl.RunWithoutErr(append([]string{"delete", "policyserver", "--wait"}, servers...)...)
+		Expect(err).To(Not(HaveOccurred()))
+
+		waitForPolicies(ctx, "", 0)
+	})
+
+	It("Restore all the policies and the policy servers", func(ctx SpecContext) {
+		start := time.Now()
+		ApplyRestore(restoreName, GetBackupFile(backupName), false)
+		WaitForReady(ctx, "restore", restoreName)
+		checkBudget("restore", start)
+
+		// Completeness, nothing can be missing
+		for _, server := range servers {
+			WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-"+server)
+		}
+		waitForPolicies(ctx, "active", policyCount)
+	})
+
+	It("Keep the backup operator and the controller running", func() {
+		for ns, label := range operators {
+			Expect(getRestarts(ns, label)).To(Equal(restarts[ns]), "pods %s of %s restarted", label, ns)
+		}
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Rotate the serving certificate of a policy server", Label("cert-rotation", "full"), Ordered, Serial, func() {
+	serverName := UniqueName("rotation-server")
+	policyName := UniqueName("rotation-policy")
+	// Deployment, service and certificate secret have the same name
+	deployment := "policy-server-" + serverName
+
+	getCertificate := func() string {
+		out, _ := kubectl.RunWithoutErr("get", "secret", deployment, "--namespace", kubewardenNS,
+			"-o", `jsonpath={.data.tls\.crt}`)
+		return out
+	}
+
+	BeforeAll(func(ctx SpecContext) {
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		for _, file := range []string{
+			CopyYaml(policyCatalogServerYaml, map[string]string{
+				"%POLICY_SERVER_IMAGE%": image,
+				"catalog-server":        serverName,
+			}),
+			CopyYaml(scalePolicyYaml, map[string]string{
+				"%NAME%":          policyName,
+				"%POLICY_SERVER%": serverName,
+				"%SCALE_ID%":      policyName,
+			}),
+		} {
+			err = kubectl.Apply("", file)
+			Expect(err).To(Not(HaveOccurred()))
+		}
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		WaitForCABundles(ctx, kubewardenNS)
+	})
+
+	It("Serve a new certificate signed by the same CA", func(ctx SpecContext) {
+		previous := getCertificate()
+		Expect(previous).To(Not(BeEmpty()), "no serving certificate for %s", serverName)
+
+		// The controller creates the secret again with a new certificate
+		_, err := kubectl.RunWithoutErr("delete", "secret", deployment, "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitFor(ctx, wait.Match(getCertificate, And(Not(BeEmpty()), Not(Equal(previous)))),
+			wait.Options{Class: timeouts.Rollout, Description: "new serving certificate of " + serverName})
+
+		// Certificates are only loaded when the policy server starts
+		_, err = kubectl.RunWithoutErr("rollout", "restart", "deployment/"+deployment, "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, deployment)
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		WaitForCABundles(ctx, kubewardenNS)
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/chaos"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: Chaos Mesh is removed at the end, with the experiments of the run
+var _ = Describe("E2E - Keep Kubewarden working under injected faults", Label("chaos", "full"), Ordered, Serial, func() {
+	// Recommended policy of kubewarden-defaults, in protect mode
+	const policyName = "do-not-run-as-root"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Pods of the default policy server, the namespace is only known once the suite is started
+	policyServer := func() chaos.Selector {
+		return chaos.Selector{Namespace: kubewardenNS, Labels: map[string]string{"kubewarden/policy-server": "default"}}
+	}
+
+	// The webhook can fail while the faults are injected, only a denial by the policy is expected at the end
+	waitForDenial := func(ctx SpecContext) {
+		WaitFor(ctx, wait.Check(func() error {
+			out, err := kubectl.Run("run", UniqueName("chaos-root-pod"), "--image=rancher/pause:3.2", "--dry-run=server",
+				"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+			if err == nil {
+				return fmt.Errorf("pod running as root accepted")
+			}
+			if !strings.Contains(out, "denied the request") {
+				return fmt.Errorf("pod not denied by %s: %s", policyName, out)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "pods running as root to be denied"})
+	}
+
+	BeforeAll(func() {
+		InstallChaosMesh(k)
+		DeferCleanup(RemoveChaosMesh)
+	})
+
+	It("Recover from the kill of a policy server pod", func(ctx SpecContext) {
+		InjectChaos(ctx, chaos.PodKill(UniqueName("kill-policy-server"), policyServer()))
+
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-default")
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		waitForDenial(ctx)
+	})
+
+	It("Load the policies from a slow registry", func(ctx SpecContext) {
+		// Registry of the module, e.g. ghcr.io or the local registry in airgap
+		module, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", policyName, "-o", "jsonpath={.spec.module}")
+		Expect(err).To(Not(HaveOccurred()))
+		registry, _, _ := strings.Cut(strings.TrimPrefix(module, "registry://"), "/")
+		registry, _, _ = strings.Cut(registry, ":")
+		registry = cmp.Or(registry, "ghcr.io")
+		InjectChaos(ctx, chaos.NetworkDelay(UniqueName("slow-registry"), policyServer(), []string{registry}, 2*time.Second, 30*time.Minute))
+
+		// Modules are only pulled when the policy server starts
+		start := time.Now()
+		_, err = kubectl.RunWithoutErr("rollout", "restart", "deployment/policy-server-default", "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-default")
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		AddReportEntry("policy server restart with a slow registry", time.Since(start).Round(time.Second).String())
+
+		waitForDenial(ctx)
+	})
+
+	It("Back up Kubewarden on a slow backup volume", func(ctx SpecContext) {
+		if deployed, _ := GetReleases(localCluster, "cattle-resources-system"); len(deployed) == 0 {
+			Skip("rancher-backup operator is not installed")
+		}
+		volumePath, err := kubectl.RunWithoutErr("get", "pod", "-l", "app.kubernetes.io/name=rancher-backup",
+			"--namespace", "cattle-resources-system",
+			"-o", `jsonpath={.items[0].spec.containers[0].volumeMounts[?(@.name=="pv-storage")].mountPath}`)
+		Expect(err).To(Not(HaveOccurred()))
+		if volumePath == "" {
+			Skip("backups are not stored on a volume")
+		}
+
+		InjectChaos(ctx, chaos.IOLatency(UniqueName("slow-backup-volume"), chaos.Selector{
+			Namespace: "cattle-resources-system",
+			Labels:    map[string]string{"app.kubernetes.io/name": "rancher-backup"},
+		}, volumePath, 100*time.Millisecond, 30*time.Minute))
+
+		backupName := UniqueName("chaos-backup")
+		ApplyBackup(backupName)
+		WaitForReady(ctx, "backup", backupName)
+	})
+})
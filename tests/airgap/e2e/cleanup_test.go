@@ -0,0 +1,175 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: everything is best effort, as a broken run could have left anything behind
+var _ = Describe("E2E - Cleanup everything created by the tests", Label("cleanup"), Ordered, Serial, func() {
+	var (
+		clusterReachable bool
+		runNamespaces    []string
+	)
+
+	// Log failures instead of stopping, so next steps are still done
+	bestEffort := func(what string, err error) {
+		if err != nil {
+			GinkgoWriter.Printf("Cleanup of %s failed (ignored): %v\n", what, err)
+		}
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("get", "nodes")
+		clusterReachable = err == nil
+		if !clusterReachable {
+			GinkgoWriter.Printf("Cluster is not reachable, only local resources are cleaned\n")
+			return
+		}
+
+		// Namespaces created by the tests have the run label
+		out, err := kubectl.RunWithoutErr("get", "namespaces", "-l", runLabel, "-o", "jsonpath={.items[*].metadata.name}")
+		bestEffort("namespace list", err)
+		runNamespaces = strings.Fields(out)
+	})
+
+	It("Delete Backup/Restore resources", func() {
+		if !clusterReachable {
+			Skip("cluster is not reachable")
+		}
+
+		_, err := kubectl.RunWithoutErr("delete", "backups.resources.cattle.io,restores.resources.cattle.io",
+			"--all", "--ignore-not-found", "--timeout=2m")
+		bestEffort("Backup/Restore resources", err)
+	})
+
+	It("Delete Kubewarden resources", func() {
+		if !clusterReachable {
+			Skip("cluster is not reachable")
+		}
+
+		// Policies and policy servers have finalizers, the controller has to be running
+		_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicies,admissionpolicies,policyservers",
+			"--all", "--all-namespaces", "--ignore-not-found", "--timeout=5m")
+		bestEffort("Kubewarden resources", err)
+	})
+
+	It("Uninstall Helm charts", func() {
+		if !clusterReachable {
+			Skip("cluster is not reachable")
+		}
+
+		// Longhorn refuses to be uninstalled without this flag
+		_, _ = kubectl.RunWithoutErr("patch", "settings.longhorn.io", "deleting-confirmation-flag",
+			"--namespace", "longhorn-system", "--type", "merge", "-p", `{"value": "true"}`)
+
+		// Releases in uninstall order, dependent charts first
+		var releases [][2]string
+		// Kubewarden could also be installed in the namespaces used by the namespace restore test
+		for _, ns := range append([]string{kubewardenNS}, runNamespaces...) {
+			for _, chart := range []string{"kubewarden-defaults", "kubewarden-controller", "kubewarden-crds"} {
+				releases = append(releases, [2]string{ns, chart})
+			}
+		}
+		releases = append(releases,
+			[2]string{"cattle-system", "rancher"},
+			[2]string{"cert-manager", "cert-manager"},
+			[2]string{"cattle-resources-system", "rancher-backup"},
+			[2]string{"cattle-resources-system", "rancher-backup-crd"},
+			[2]string{"longhorn-system", "longhorn"},
+			[2]string{"minio", "minio"},
+		)
+
+		for _, r := range releases {
+			ns, name := r[0], r[1]
+			deployed, err := GetReleases(localCluster, ns)
+			if err != nil || !slices.ContainsFunc(deployed, func(d helmRelease) bool { return d.Name == name }) {
+				continue
+			}
+
+			err = kubectl.RunHelmBinaryWithCustomErr("uninstall", name, "--namespace", ns, "--wait")
+			bestEffort("release "+ns+"/"+name, err)
+		}
+	})
+
+	It("Delete test namespaces", func() {
+		if !clusterReachable {
+			Skip("cluster is not reachable")
+		}
+
+		// Labelled resources of all runs, including the namespaces
+		bestEffort("resources of previous runs", CleanupRun(""))
+
+		namespaces := []string{kubewardenNS, clusterNS, "cattle-resources-system", "longhorn-system", "minio"}
+		slices.Sort(namespaces)
+		for _, ns := range slices.Compact(namespaces) {
+			_, err := kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--timeout=5m")
+			bestEffort("namespace "+ns, err)
+		}
+
+		_, err := kubectl.RunWithoutErr("delete", "secret", "backup-s3-credentials", "minio-s3-credentials", "--namespace", "default", "--ignore-not-found")
+		bestEffort("S3 credentials", err)
+	})
+
+	It("Delete local files", func() {
+		// The dry-run plan is in the temporary files
+		if dryrun.Enabled() {
+			dryrun.Record("remove %s and kubewarden-*.tar.gz", filepath.Join(os.TempDir(), "kubewarden-e2e"))
+			return
+		}
+
+		bestEffort("temporary files", os.RemoveAll(filepath.Join(os.TempDir(), "kubewarden-e2e")))
+
+		// Local copies of the backup files
+		files, _ := filepath.Glob("kubewarden-*.tar.gz")
+		for _, f := range files {
+			bestEffort("backup file "+f, os.Remove(f))
+		}
+	})
+
+	It("Delete test VMs", func() {
+		if _, err := exec.LookPath("virsh"); err != nil {
+			Skip("libvirt is not installed")
+		}
+
+		out, err := runner.Sudo("virsh", "list", "--all", "--name")
+		bestEffort("VM list", err)
+
+		// Only the disaster recovery node, rancher-manager is the airgap environment itself
+		for _, vm := range strings.Fields(out) {
+			if vm == "dr-node" {
+				DeleteVM(vm)
+			}
+		}
+	})
+
+	It("Uninstall K3s if installed by the tests", func() {
+		if _, err := k3sNode.Run("test", "-f", k3sMarkerFile); err != nil {
+			Skip("K3s was not installed by the tests")
+		}
+
+		UninstallK3s(k3sNode)
+	})
+})
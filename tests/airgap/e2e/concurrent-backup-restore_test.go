@@ -0,0 +1,163 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: an operation applied while another one is running has to wait for it (serialised),
+// or be rejected with an error condition, the first operation is done in both cases
+var _ = Describe("E2E - Test concurrent Backup/Restore", Label("test-concurrent-backup-restore", "full"), Ordered, Serial, func() {
+	var baseBackupFile string
+
+	baseBackupName := UniqueName("kubewarden-concurrent-base")
+	backupNames := []string{UniqueName("kubewarden-concurrent-backup"), UniqueName("kubewarden-concurrent-backup")}
+	restoreNames := []string{
+		UniqueName("kubewarden-concurrent-restore"),
+		UniqueName("kubewarden-concurrent-restore"),
+		UniqueName("kubewarden-concurrent-restore"),
+	}
+
+	// Apply a Restore resource of the base backup without waiting for it
+	applyRestore := func(name string) {
+		ApplyRestore(name, baseBackupFile, false)
+	}
+
+	// Kind and name of a Backup or Restore resource
+	type operation struct {
+		kind, name string
+	}
+
+	// The operator has picked the resource up but not finished it
+	waitForInProgress := func(ctx context.Context, op operation) {
+		WaitFor(ctx, wait.Check(func() error {
+			out, _ := kubectl.RunWithoutErr("get", op.kind, op.name, "-o", "jsonpath={.status.conditions[*].type}")
+			if out == "" {
+				return fmt.Errorf("%s %s not started", op.kind, op.name)
+			}
+			if GetConditionStatus(op.kind, op.name, "Ready") == "True" {
+				return wait.Permanent(fmt.Errorf("%s %s is done before the next operation is applied", op.kind, op.name))
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Backup, Interval: 100 * time.Millisecond, MaxInterval: time.Second,
+			Description: op.kind + " " + op.name + " to be in progress"})
+	}
+
+	// Time the Ready condition was set
+	readyTime := func(op operation) time.Time {
+		out, err := kubectl.RunWithoutErr("get", op.kind, op.name,
+			"-o", "jsonpath={.status.conditions[?(@.type==\"Ready\")].lastTransitionTime}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		t, err := time.Parse(time.RFC3339, out)
+		Expect(err).To(Not(HaveOccurred()), "no Ready time for %s %s", op.kind, op.name)
+		return t
+	}
+
+	// The first operation is done, the second one is serialised after it or rejected
+	checkOutcome := func(ctx context.Context, first, second operation) (rejected bool) {
+		WaitForReady(ctx, first.kind, first.name)
+
+		if err := WaitForDone(ctx, second.kind, second.name); err != nil {
+			GinkgoWriter.Printf("%s %s rejected while %s %s was running: %v\n", second.kind, second.name, first.kind, first.name, err)
+			return true
+		}
+
+		if dryrun.Enabled() {
+			dryrun.Record("check that %s %s is done after %s %s", second.kind, second.name, first.kind, first.name)
+			return false
+		}
+		Expect(readyTime(second)).To(Not(BeTemporally("<", readyTime(first))),
+			"%s %s is done before %s %s, operations are not serialised", second.kind, second.name, first.kind, first.name)
+		return false
+	}
+
+	// Nothing should be left in a broken state
+	checkState := func(ctx context.Context) {
+		WaitFor(ctx, wait.Check(CheckWebhookServices), wait.Options{Class: timeouts.Rollout, Description: "webhook services"})
+		WaitFor(ctx, wait.Check(CheckPoliciesActive), wait.Options{Class: timeouts.Rollout, Description: "active policies"})
+	}
+
+	BeforeAll(func(ctx SpecContext) {
+		By("Adding a base backup to restore from", func() {
+			ApplyBackup(baseBackupName)
+			WaitForReady(ctx, "backup", baseBackupName)
+
+			baseBackupFile = GetBackupFile(baseBackupName)
+		})
+	})
+
+	It("Restore while a backup is running", func(ctx SpecContext) {
+		backup := operation{"backup", backupNames[0]}
+		restore := operation{"restore", restoreNames[0]}
+
+		By("Adding a restore while the backup is in progress", func() {
+			ApplyBackup(backup.name)
+			waitForInProgress(ctx, backup)
+			applyRestore(restore.name)
+		})
+
+		By("Checking that the restore is serialised or rejected", func() {
+			checkOutcome(ctx, backup, restore)
+			GetBackupFile(backup.name)
+		})
+
+		By("Checking Kubewarden state", func() {
+			checkState(ctx)
+		})
+	})
+
+	It("Backup while a restore is running", func(ctx SpecContext) {
+		restore := operation{"restore", restoreNames[1]}
+		backup := operation{"backup", backupNames[1]}
+		var rejected bool
+
+		By("Adding a backup while the restore is in progress", func() {
+			applyRestore(restore.name)
+			waitForInProgress(ctx, restore)
+			ApplyBackup(backup.name)
+		})
+
+		By("Checking that the backup is serialised or rejected", func() {
+			rejected = checkOutcome(ctx, restore, backup)
+		})
+
+		By("Checking Kubewarden state", func() {
+			checkState(ctx)
+		})
+
+		By("Checking that the backup taken during restore can be restored", func() {
+			if rejected {
+				GinkgoWriter.Printf("No backup taken during the restore\n")
+				return
+			}
+
+			baseBackupFile = GetBackupFile(backup.name)
+			applyRestore(restoreNames[2])
+			WaitForReady(ctx, "restore", restoreNames[2])
+			checkState(ctx)
+		})
+	})
+})
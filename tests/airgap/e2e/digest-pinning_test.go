@@ -0,0 +1,160 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: skopeo is needed on the test host to push the policies in the scratch registry
+var _ = Describe("E2E - Pin policy modules by digest", Label("digest-pinning", "full"), Ordered, Serial, func() {
+	const (
+		registryNS = "scratch-registry"
+		// Denies privileged pods, replaced by a module accepting them
+		denyingModule   = "ghcr.io/kubewarden/policies/pod-privileged:v0.2.1"
+		acceptingModule = "ghcr.io/kubewarden/policies/safe-labels:v0.1.14"
+	)
+
+	var registry string
+
+	serverName := UniqueName("mutable-server")
+	pinnedName := UniqueName("pinned-policy")
+	tagName := UniqueName("tag-policy")
+	pinnedNS := UniqueName("pinned-ns")
+	tagNS := UniqueName("tag-ns")
+
+	// Overwrite the tag of the mutable policy
+	push := func(module string) {
+		_, err := runner.Run("skopeo", "copy", "--dest-tls-verify=false",
+			"docker://"+module, "docker://"+registry+"/e2e/mutable-policy:v1")
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	// A privileged pod is denied or not by the policy of the namespace, recommended policies could deny it too
+	checkPrivilegedPods := func(ns, policy string, denied bool) {
+		pod := UniqueName("privileged-pod")
+		out, err := kubectl.Run("run", pod, "--namespace", ns, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`)
+		if err == nil {
+			_, _ = kubectl.RunWithoutErr("delete", "pod", pod, "--namespace", ns, "--wait=false")
+		}
+
+		webhook := fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, policy)
+		if denied {
+			Expect(out).To(ContainSubstring(webhook), "privileged pod not denied by %s", policy)
+		} else {
+			Expect(out).To(Not(ContainSubstring(webhook)), "privileged pod denied by %s", policy)
+		}
+	}
+
+	restartPolicyServer := func(ctx SpecContext) {
+		deployment := "policy-server-" + serverName
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment/"+deployment, "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, deployment)
+	}
+
+	BeforeAll(func() {
+		if _, err := exec.LookPath("skopeo"); err != nil {
+			Skip("skopeo is not installed")
+		}
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", pinnedName, tagName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", registryNS, pinnedNS, tagNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Push the policy in a scratch registry", func(ctx SpecContext) {
+		file := CopyYaml(scratchRegistryYaml, nil)
+		err := kubectl.Apply(registryNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeploymentReady(ctx, localCluster, registryNS, "scratch-registry")
+
+		// Node port, reachable from the test host and from the policy servers
+		registry = GetNodeIP() + ":30501"
+		push(denyingModule)
+	})
+
+	It("Reference the policy by digest and by tag", func(ctx SpecContext) {
+		// Digest of the manifest as stored in the registry
+		manifestFile := filepath.Join(GetTempDir(), "mutable-policy-manifest.json")
+		out, err := runner.Run("skopeo", "inspect", "--raw", "--tls-verify=false", "docker://"+registry+"/e2e/mutable-policy:v1")
+		Expect(err).To(Not(HaveOccurred()))
+		err = os.WriteFile(manifestFile, []byte(out), 0644)
+		Expect(err).To(Not(HaveOccurred()))
+		digest, err := runner.Run("skopeo", "manifest-digest", manifestFile)
+		Expect(err).To(Not(HaveOccurred()))
+
+		for _, ns := range []string{pinnedNS, tagNS} {
+			_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+			Expect(err).To(Not(HaveOccurred()))
+			LabelRun("namespace", ns)
+		}
+
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(mutablePoliciesYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%REGISTRY%":            registry,
+			"%DIGEST%":              strings.TrimSpace(digest),
+			"%SERVER_NAME%":         serverName,
+			"%PINNED_NAME%":         pinnedName,
+			"%PINNED_NS%":           pinnedNS,
+			"%TAG_NAME%":            tagName,
+			"%TAG_NS%":              tagNS,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForPolicyActive(ctx, localCluster, pinnedName)
+		WaitForPolicyActive(ctx, localCluster, tagName)
+
+		checkPrivilegedPods(pinnedNS, pinnedName, true)
+		checkPrivilegedPods(tagNS, tagName, true)
+	})
+
+	It("Keep running policies when the tag is overwritten", func() {
+		push(acceptingModule)
+
+		// Modules are only pulled when the policy server starts
+		checkPrivilegedPods(pinnedNS, pinnedName, true)
+		checkPrivilegedPods(tagNS, tagName, true)
+	})
+
+	It("Pick up the new content of the tag only after a rollout", func(ctx SpecContext) {
+		restartPolicyServer(ctx)
+		WaitForPolicyActive(ctx, localCluster, pinnedName)
+		WaitForPolicyActive(ctx, localCluster, tagName)
+
+		checkPrivilegedPods(pinnedNS, pinnedName, true)
+		checkPrivilegedPods(tagNS, tagName, false)
+	})
+})
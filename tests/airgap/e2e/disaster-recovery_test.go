@@ -0,0 +1,163 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/backup"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+	"github.com/rancher-sandbox/ele-testhelpers/tools"
+)
+
+// NOTE: BACKUP_S3_* and AWS_* variables (or BACKUP_MINIO) have to be set, S3 storage should be reachable from the VM
+var _ = Describe("E2E - Test Disaster Recovery with S3 storage", Label("test-disaster-recovery", "nightly"), Serial, func() {
+	const (
+		// Reserved in assets/net-default-airgap.xml, the VM gets 192.168.122.105
+		drNodeMAC  = "52:54:00:00:00:13"
+		drNodeName = "dr-node"
+	)
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// For ssh access, same credentials as the rancher-manager image
+	client := &tools.Client{
+		Host:     "192.168.122.105:22",
+		Username: userName,
+		Password: "root",
+	}
+
+	node := runner.Remote(client)
+
+	// Each node is a fresh machine created from the same image
+	drDisk := os.Getenv("HOME") + "/" + drNodeName + ".qcow2"
+	drImage := os.Getenv("HOME") + "/rancher-image.qcow2"
+
+	// Provision a new machine with K3s, Kubewarden and the backup operator
+	provisionNode := func() {
+		CreateVM(drNodeName, drDisk, drImage, drNodeMAC)
+		CheckSSH(client)
+		MarkDisposable(node)
+		InstallK3s(node)
+		ConfigureKubeconfig(node)
+		WaitForK3s(k)
+		InstallBackupOperator(k, backupRestoreVersion)
+	}
+
+	var artifact *backup.Artifact
+
+	backupName := UniqueName(backupResourceName)
+	restoreName := UniqueName(restoreResourceName)
+	// Charts re-create the recommended policies, only this one has to come from the backup
+	policyName := UniqueName("dr-policy")
+
+	BeforeEach(func() {
+		// MinIO stays in the test cluster, reachable from the VM through its node port
+		if backupS3Bucket == "" && backupMinIO {
+			UseS3Store(DeployMinIO(k))
+		}
+
+		if backupS3Bucket == "" {
+			Skip("BACKUP_S3_BUCKET is not defined")
+		}
+	})
+
+	It("Restore Kubewarden from S3 after losing the whole cluster", func(ctx SpecContext) {
+		// The kubeconfig of the node is used until the end, then the VM is deleted even if the test fails
+		DeferCleanup(SaveKubeconfig())
+		DeferCleanup(func() {
+			out, err := runner.Sudo("virsh", "list", "--all", "--name")
+			Expect(err).To(Not(HaveOccurred()))
+			if slices.Contains(strings.Fields(out), drNodeName) {
+				DeleteVM(drNodeName)
+			}
+
+			// Left if the VM could not be defined
+			err = os.Remove(drDisk)
+			if !os.IsNotExist(err) {
+				Expect(err).To(Not(HaveOccurred()))
+			}
+		})
+
+		By("Provisioning the cluster node", func() {
+			provisionNode()
+			InstallKubewarden(k, kubewardenNS, "")
+			ApplyBackupOnlyPolicy(policyName)
+		})
+
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+
+			// Wait for backup to be done
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Checking that the backup file is stored in S3", func() {
+			artifact = backup.New(GetBackupFile(backupName), backup.Target{
+				Type:     backup.S3,
+				Location: "s3://" + strings.TrimSuffix(backupS3Bucket+"/"+backupS3Folder, "/"),
+				Endpoint: backupS3Endpoint,
+			})
+
+			// Keep a local copy to verify the file is not altered until restore
+			err := artifact.Store(GinkgoT().TempDir())
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Destroying the whole cluster node", func() {
+			DeleteVM(drNodeName)
+		})
+
+		By("Provisioning a new cluster node", func() {
+			provisionNode()
+		})
+
+		By("Adding a restore resource", func() {
+			// The file has to be untouched in S3
+			err := artifact.VerifyRemote()
+			Expect(err).To(Not(HaveOccurred()))
+
+			ApplyRestore(restoreName, artifact.FileName, false)
+
+			// Wait for restore to be done
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that policies are restored", func() {
+			CheckBackupOnlyPolicyRestored(policyName)
+		})
+
+		By("Re-installing Kubewarden on top of restored resources", func() {
+			InstallKubewarden(k, kubewardenNS, "")
+		})
+
+		By("Checking that policies are enforced", func() {
+			CheckBackupOnlyPolicyEnforced(ctx, policyName)
+		})
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: CoreDNS is scaled to 0 during the test, its replicas are restored at the end
+var _ = Describe("E2E - Follow the failure policies when DNS is down", Label("dns-failure", "full"), Ordered, Serial, func() {
+	serverName := UniqueName("dns-server")
+	failPolicy := UniqueName("dns-fail-policy")
+	ignorePolicy := UniqueName("dns-ignore-policy")
+	failNS := UniqueName("dns-fail")
+	ignoreNS := UniqueName("dns-ignore")
+	deployment := "policy-server-" + serverName
+
+	// Replicas of CoreDNS before the test
+	var dnsReplicas string
+
+	scaleDNS := func(ctx SpecContext, replicas string) {
+		_, err := kubectl.RunWithoutErr("scale", "deployment/coredns", "--namespace", "kube-system", "--replicas", replicas)
+		Expect(err).To(Not(HaveOccurred()))
+		if replicas != "0" {
+			WaitForDeploymentReady(ctx, localCluster, "kube-system", "coredns")
+		}
+	}
+
+	createPod := func(ns string) (string, error) {
+		return kubectl.Run("run", UniqueName("dns-privileged-pod"), "--namespace", ns, "--image=rancher/pause:3.2", "--dry-run=server",
+			"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`)
+	}
+
+	// Privileged pods are denied by the policy of the namespace
+	checkDenied := func(ns, policy string) {
+		out, err := createPod(ns)
+		Expect(err).To(HaveOccurred(), "privileged pod accepted in %s", ns)
+		Expect(out).To(ContainSubstring(fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, policy)))
+	}
+
+	BeforeAll(func() {
+		for _, ns := range []string{failNS, ignoreNS} {
+			_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+			Expect(err).To(Not(HaveOccurred()))
+			LabelRun("namespace", ns)
+		}
+
+		out, err := kubectl.RunWithoutErr("get", "deployment/coredns", "--namespace", "kube-system", "-o", "jsonpath={.spec.replicas}")
+		Expect(err).To(Not(HaveOccurred()))
+		dnsReplicas = cmp.Or(out, "1")
+
+		DeferCleanup(func(ctx SpecContext) {
+			// DNS first, everything else needs it
+			scaleDNS(ctx, dnsReplicas)
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", failPolicy, ignorePolicy, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", failNS, ignoreNS, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Deploy policies with both failure policies", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(dnsPoliciesYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%SERVER_NAME%":         serverName,
+			"%FAIL_POLICY%":         failPolicy,
+			"%FAIL_NAMESPACE%":      failNS,
+			"%IGNORE_POLICY%":       ignorePolicy,
+			"%IGNORE_NAMESPACE%":    ignoreNS,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForPolicyActive(ctx, localCluster, failPolicy)
+		WaitForPolicyActive(ctx, localCluster, ignorePolicy)
+		checkDenied(failNS, failPolicy)
+		checkDenied(ignoreNS, ignorePolicy)
+	})
+
+	It("Keep enforcing with running policy servers", func(ctx SpecContext) {
+		scaleDNS(ctx, "0")
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "pods", "--namespace", "kube-system", "-l", "k8s-app=kube-dns", "-o", "name")
+			return out
+		}, BeEmpty()), wait.Options{Class: timeouts.Rollout, Description: "CoreDNS pods to be deleted"})
+
+		// Webhooks are called through their service, the API server does not need DNS
+		checkDenied(failNS, failPolicy)
+		checkDenied(ignoreNS, ignorePolicy)
+	})
+
+	It("Follow the failure policies of a policy server that cannot pull its module", func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("delete", "pods", "--namespace", kubewardenNS, "-l", "kubewarden/policy-server="+serverName, "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// The new pod cannot resolve the registry, so the webhooks have no endpoint
+		WaitFor(ctx, wait.Check(func() error {
+			if out, err := createPod(ignoreNS); err != nil {
+				return fmt.Errorf("privileged pod not accepted with failurePolicy Ignore: %s", out)
+			}
+			out, err := createPod(failNS)
+			if err == nil {
+				return fmt.Errorf("privileged pod accepted with failurePolicy Fail")
+			}
+			if strings.Contains(out, "denied the request") {
+				return fmt.Errorf("webhook of %s still served", failPolicy)
+			}
+			if !strings.Contains(out, "failed calling webhook") {
+				return fmt.Errorf("unexpected error: %s", out)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "failure policies to be applied"})
+	})
+
+	It("Enforce the policies again once DNS is restored", func(ctx SpecContext) {
+		scaleDNS(ctx, dnsReplicas)
+
+		// Skips the back-off of the policy server crashing without DNS
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment/"+deployment, "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, deployment)
+
+		WaitForPolicyActive(ctx, localCluster, failPolicy)
+		WaitForPolicyActive(ctx, localCluster, ignorePolicy)
+		checkDenied(failNS, failPolicy)
+		checkDenied(ignoreNS, ignorePolicy)
+	})
+})
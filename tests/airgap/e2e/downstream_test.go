@@ -0,0 +1,142 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/rancherapi"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+	"github.com/rancher-sandbox/ele-testhelpers/tools"
+)
+
+// NOTE: a VM is created from rancher-image.qcow2 if DOWNSTREAM_NODE_HOST is not set, Rancher must be reachable from it
+var _ = Describe("E2E - Install Kubewarden on a downstream cluster through Rancher", Label("downstream", "nightly"), Ordered, Serial, func() {
+	const (
+		downstreamMAC    = "52:54:00:00:00:11"
+		downstreamName   = "downstream"
+		downstreamPolicy = "do-not-run-as-root"
+	)
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Same credentials as the rancher-manager image, unless another node is given
+	client := &tools.Client{
+		Host:     "192.168.122.103:22",
+		Username: userName,
+		Password: "root",
+	}
+	createVM := true
+	if host := os.Getenv("DOWNSTREAM_NODE_HOST"); host != "" {
+		client = &tools.Client{
+			Host:     host + ":22",
+			Username: cmp.Or(os.Getenv("DOWNSTREAM_NODE_USER"), "root"),
+			Password: os.Getenv("DOWNSTREAM_NODE_PASSWORD"),
+		}
+		createVM = false
+	}
+	node := runner.Remote(client)
+
+	clusterName := UniqueName("e2e-downstream")
+	repoName := UniqueName("kubewarden-charts")
+
+	var (
+		api        *rancherapi.Client
+		clusterID  string
+		downstream *cluster.Cluster
+	)
+
+	BeforeAll(func() {
+		DeferCleanup(func() {
+			// Rancher removes its agent from the downstream cluster
+			_, err := kubectl.RunWithoutErr("delete", "clusters.provisioning.cattle.io", clusterName,
+				"--namespace", "fleet-default", "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+
+			if createVM {
+				DeleteVM(downstreamName)
+				return
+			}
+			if _, err := node.Run("test", "-f", k3sMarkerFile); err == nil {
+				UninstallK3s(node.WithSudo())
+			}
+		})
+	})
+
+	It("Provision the downstream node", func() {
+		if createVM {
+			CreateVM(downstreamName, os.Getenv("HOME")+"/"+downstreamName+".qcow2", os.Getenv("HOME")+"/rancher-image.qcow2", downstreamMAC)
+		}
+		CheckSSH(client)
+		if createVM {
+			MarkDisposable(node)
+		}
+		InstallK3s(node)
+	})
+
+	It("Import the downstream cluster into Rancher", func(ctx SpecContext) {
+		if _, err := kubectl.RunWithoutErr("get", "deployment", "rancher", "--namespace", "cattle-system"); err != nil {
+			InstallRancher(k)
+		}
+
+		clusterID = ImportCluster(ctx, node, clusterName)
+	})
+
+	It("Get the downstream kubeconfig through the Rancher API", func() {
+		if !dryrun.Enabled() {
+			api = rancherapi.New("https://"+GetRancherHostname(), CreateRancherToken())
+		}
+		downstream = cluster.New(clusterName, GetClusterKubeconfig(api, clusterID))
+
+		out, err := downstream.KubectlWithoutErr("get", "nodes", "-o", "jsonpath={.items[*].status.addresses[?(@.type==\"InternalIP\")].address}")
+		Expect(err).To(Not(HaveOccurred()))
+		if !dryrun.Enabled() {
+			Expect(out).To(ContainSubstring(node.Host()))
+		}
+	})
+
+	It("Install Kubewarden on the downstream cluster through Rancher", func(ctx SpecContext) {
+		InstallKubewardenThroughRancher(ctx, api, downstream, clusterID, repoName, KubewardenValues())
+	})
+
+	It("Enforce the policies on the downstream cluster only", func(ctx SpecContext) {
+		WaitForPolicyActive(ctx, downstream, downstreamPolicy)
+
+		out, err := downstream.Kubectl("run", UniqueName("downstream-root-pod"), "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring(fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, downstreamPolicy)))
+
+		// Nothing is installed by Rancher in its own cluster
+		out, err = localCluster.KubectlWithoutErr("get", "clusterrepos.catalog.cattle.io", repoName, "--ignore-not-found", "-o", "name")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(out).To(BeEmpty(), "%s created in the Rancher cluster", repoName)
+	})
+})
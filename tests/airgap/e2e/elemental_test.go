@@ -0,0 +1,118 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: Rancher Manager is installed if needed, the Elemental operator is removed at the end
+var _ = Describe("E2E - Gate Elemental resources with Kubewarden policies", Label("elemental", "nightly"), Ordered, Serial, func() {
+	// Namespace of the Elemental resources in Rancher
+	const elementalNS = "fleet-default"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	policyName := UniqueName("elemental-owner")
+	registrationName := UniqueName("e2e-registration")
+	backupName := UniqueName("kubewarden-elemental-backup")
+	restoreName := UniqueName("kubewarden-elemental-restore")
+
+	createRegistration := func(name, owner string) (string, error) {
+		file := CopyYaml(machineRegistrationYaml, map[string]string{
+			"%NAME%":      name,
+			"%NAMESPACE%": elementalNS,
+			"%OWNER%":     owner,
+		})
+		return kubectl.Run("apply", "-f", file)
+	}
+
+	checkGate := func() {
+		out, err := createRegistration(UniqueName("e2e-unowned-registration"), "")
+		Expect(err).To(HaveOccurred(), "MachineRegistration without owner allowed")
+		Expect(out).To(ContainSubstring(fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, policyName)))
+		Expect(out).To(ContainSubstring("MachineRegistrations need an e2e-owner machine inventory label"))
+	}
+
+	BeforeAll(func() {
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "machineregistration", registrationName, "--namespace", elementalNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			RemoveElementalOperator()
+		})
+	})
+
+	It("Install the Elemental operator next to Kubewarden", func() {
+		if _, err := kubectl.RunWithoutErr("get", "deployment", "rancher", "--namespace", "cattle-system"); err != nil {
+			InstallRancher(k)
+		}
+		InstallElementalOperator(k)
+	})
+
+	It("Gate MachineRegistrations with a policy", func(ctx SpecContext) {
+		file := CopyYaml(elementalPolicyYaml, map[string]string{"%NAME%": policyName})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, policyName)
+
+		checkGate()
+
+		out, err := createRegistration(registrationName, "e2e-team")
+		Expect(err).To(Not(HaveOccurred()), out)
+	})
+
+	It("Keep the policy and the Elemental resources after a backup/restore", func(ctx SpecContext) {
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Deleting the policy and the MachineRegistration", func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "machineregistration", registrationName, "--namespace", elementalNS, "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(restoreName, GetBackupFile(backupName), false)
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that both are back", func() {
+			out, err := kubectl.RunWithoutErr("get", "machineregistration", registrationName, "--namespace", elementalNS,
+				"-o", "jsonpath={.spec.machineInventoryLabels.e2e-owner}")
+			Expect(err).To(Not(HaveOccurred()), "MachineRegistration %s not restored", registrationName)
+			Expect(out).To(Equal("e2e-team"))
+
+			WaitForPolicyActive(ctx, localCluster, policyName)
+			checkGate()
+		})
+	})
+})
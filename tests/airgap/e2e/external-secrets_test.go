@@ -0,0 +1,187 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: skopeo is needed on the test host to push the policy in the authenticated registry
+var _ = Describe("E2E - Use registry credentials materialized by external-secrets", Label("external-secrets", "full"), Ordered, Serial, func() {
+	const (
+		registryNS = "auth-registry"
+		vaultKey   = "e2e-registry"
+		module     = "ghcr.io/kubewarden/policies/pod-privileged:v0.2.1"
+	)
+
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	var registry string
+
+	// Both users are accepted by the registry, the credentials rotate from the first to the second one
+	users := [][2]string{
+		{"e2e-first", randomHex(12)},
+		{"e2e-second", randomHex(12)},
+	}
+
+	serverName := UniqueName("private-server")
+	policyName := UniqueName("private-policy")
+	secretName := UniqueName("registry-credentials")
+	deployment := "policy-server-" + serverName
+	ns := UniqueName("external-secrets")
+
+	// Content of a kubernetes.io/dockerconfigjson Secret for the registry
+	dockerConfig := func(user, password string) string {
+		auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+		data, err := json.Marshal(map[string]any{
+			"auths": map[string]any{
+				registry: map[string]string{"username": user, "password": password, "auth": auth},
+			},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+		return string(data)
+	}
+
+	// Credentials are only written in Vault, external-secrets syncs them in the Secret
+	putCredentials := func(ctx SpecContext, user, password string) {
+		config := dockerConfig(user, password)
+		_, err := kubectl.RunWithoutErr("exec", "vault-0", "--namespace", "vault", "--",
+			"env", "VAULT_TOKEN=root", "vault", "kv", "put", "secret/"+vaultKey, "dockerconfigjson="+config)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitFor(ctx, wait.Check(func() error {
+			out, _ := kubectl.RunWithoutErr("get", "secret", secretName, "--namespace", kubewardenNS,
+				"-o", `jsonpath={.data.\.dockerconfigjson}`)
+			data, _ := base64.StdEncoding.DecodeString(out)
+			if string(data) != config {
+				return fmt.Errorf("secret %s does not have the credentials of %s", secretName, user)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "credentials of " + user + " in " + secretName})
+	}
+
+	// The policy server must not crash loop, whatever the state of the Secret
+	checkRestarts := func() {
+		out, err := kubectl.RunWithoutErr("get", "pods", "--namespace", kubewardenNS,
+			"-l", "kubewarden/policy-server="+serverName,
+			"-o", "jsonpath={.items[*].status.containerStatuses[*].restartCount}")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(out).To(Not(MatchRegexp(`[1-9]`)), "containers of %s restarted", deployment)
+	}
+
+	BeforeAll(func() {
+		if _, err := exec.LookPath("skopeo"); err != nil {
+			Skip("skopeo is not installed")
+		}
+
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "externalsecret,secretstore", secretName, "--namespace", kubewardenNS,
+				"--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "secret", secretName, secretName+"-vault-token",
+				"--namespace", kubewardenNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", registryNS, ns, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			RemoveExternalSecrets()
+		})
+	})
+
+	It("Install external-secrets and Vault", func() {
+		InstallExternalSecrets(k)
+	})
+
+	It("Push the policy in an authenticated registry", func(ctx SpecContext) {
+		file := CopyYaml(authRegistryYaml, map[string]string{
+			"%FIRST_USER%":      users[0][0],
+			"%FIRST_PASSWORD%":  users[0][1],
+			"%SECOND_USER%":     users[1][0],
+			"%SECOND_PASSWORD%": users[1][1],
+		})
+		err := kubectl.Apply(registryNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeploymentReady(ctx, localCluster, registryNS, "auth-registry")
+
+		registry = GetNodeIP() + ":30502"
+		_, err = runner.Run("skopeo", "copy", "--dest-tls-verify=false", "--dest-creds", users[0][0]+":"+users[0][1],
+			"docker://"+module, "docker://"+registry+"/e2e/private-policy:v1")
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	It("Load the policy with the credentials of Vault", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(externalSecretsYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%KUBEWARDEN_NS%":       kubewardenNS,
+			"%NAMESPACE%":           ns,
+			"%REGISTRY%":            registry,
+			"%SECRET%":              secretName,
+			"%SERVER_NAME%":         serverName,
+			"%POLICY_NAME%":         policyName,
+			"%VAULT_KEY%":           vaultKey,
+		})
+
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		putCredentials(ctx, users[0][0], users[0][1])
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		checkRestarts()
+	})
+
+	It("Use the rotated credentials without restart loops", func(ctx SpecContext) {
+		putCredentials(ctx, users[1][0], users[1][1])
+		checkRestarts()
+
+		// Modules are only pulled when the policy server starts
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment/"+deployment, "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, deployment)
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		checkRestarts()
+
+		out, err := kubectl.Run("run", UniqueName("private-policy-pod"), "--namespace", ns, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring(fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, policyName)))
+	})
+})
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/terminating"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Check that deletions are not stuck by finalizers", Label("finalizers", "full"), Ordered, Serial, func() {
+	// Namespaced policy of finalizer-resources.yaml
+	const namespacedPolicy = "namespaced-privileged-pods"
+
+	serverName := UniqueName("finalizer-server")
+	downPolicy := UniqueName("finalizer-down-policy")
+	boundPolicy := UniqueName("finalizer-bound-policy")
+	deployment := "policy-server-" + serverName
+	ns := UniqueName("finalizers")
+
+	scalePolicyServer := func(ctx SpecContext, replicas int) {
+		_, err := kubectl.RunWithoutErr("patch", "policyserver", serverName, "--type", "merge",
+			"-p", fmt.Sprintf(`{"spec": {"replicas": %d}}`, replicas))
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, deployment)
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		// Only needed if a spec fails, everything is deleted by the specs
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", downPolicy, boundPolicy, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Deploy a policy server with bound policies", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(finalizerResourcesYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%SERVER_NAME%":         serverName,
+			"%DOWN_POLICY%":         downPolicy,
+			"%BOUND_POLICY%":        boundPolicy,
+			"%NAMESPACE%":           ns,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForPolicyActive(ctx, localCluster, downPolicy)
+		WaitForPolicyActive(ctx, localCluster, boundPolicy)
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "admissionpolicy", namespacedPolicy, "--namespace", ns,
+				"-o", "jsonpath={.status.policyStatus}")
+			return out
+		}, Equal("active")), wait.Options{Class: timeouts.Rollout, Description: "policy " + namespacedPolicy + " to be active"})
+	})
+
+	It("Delete the namespace of a namespaced policy", func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("delete", "namespace", ns, "--wait=false")
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeletion(ctx, "admissionpolicy", namespacedPolicy, ns)
+		WaitForDeletion(ctx, "namespace", ns, "")
+	})
+
+	It("Delete a policy while its policy server is down", func(ctx SpecContext) {
+		scalePolicyServer(ctx, 0)
+		// The policy server has to come back for the next specs
+		DeferCleanup(func(ctx SpecContext) {
+			scalePolicyServer(ctx, 1)
+		})
+
+		_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", downPolicy, "--wait=false")
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeletion(ctx, "clusteradmissionpolicy", downPolicy, "")
+	})
+
+	It("Delete a policy server with a bound policy", func(ctx SpecContext) {
+		WaitForPolicyActive(ctx, localCluster, boundPolicy)
+
+		_, err := kubectl.RunWithoutErr("delete", "policyserver", serverName, "--wait=false")
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Bound policies are deleted with their policy server
+		WaitForDeletion(ctx, "clusteradmissionpolicy", boundPolicy, "")
+		WaitForDeletion(ctx, "policyserver", serverName, "")
+		WaitForDeletion(ctx, "deployment", deployment, kubewardenNS)
+	})
+
+	It("Leave no resource of the run stuck terminating", func() {
+		stuck, err := terminating.Find([]string{"policyservers", "clusteradmissionpolicies", "admissionpolicies", "namespaces"}, "", 0)
+		Expect(err).To(Not(HaveOccurred()))
+
+		var ours []terminating.Resource
+		for _, r := range stuck {
+			if strings.Contains(r.Name, GetRunID()) {
+				ours = append(ours, r)
+			}
+		}
+		Expect(ours).To(BeEmpty(), "resources stuck terminating:\n%s", terminating.Format(ours))
+	})
+})
@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"crypto/tls"
+	"os"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/fips"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/portforward"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+	"github.com/rancher-sandbox/ele-testhelpers/rancher"
+)
+
+// NOTE: K3s node has to run in FIPS mode, FIPS_IMAGES can be set to install the FIPS builds
+var _ = Describe("E2E - Check Kubewarden on a FIPS host", Label("fips", "full"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// TLS endpoints of the Kubewarden components
+	endpoints := map[string]int{
+		"svc/policy-server-default":                 8443,
+		"svc/kubewarden-controller-webhook-service": 443,
+	}
+
+	// Set once the FIPS builds are installed
+	var fipsBuilds bool
+
+	BeforeAll(func() {
+		if !fips.Enabled(k3sNode) {
+			Skip("FIPS mode is not enabled on the K3s node")
+		}
+	})
+
+	AfterAll(func() {
+		// Other tests expect the default values
+		if fipsBuilds {
+			InstallKubewarden(k, kubewardenNS, "")
+		}
+	})
+
+	It("Start the Kubewarden components", func() {
+		if prefix := os.Getenv("FIPS_IMAGES"); prefix != "" {
+			By("Installing the FIPS builds of Kubewarden", func() {
+				InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().FIPS(prefix))
+				fipsBuilds = true
+			})
+		}
+
+		By("Checking that all the components are running", func() {
+			err := rancher.CheckPod(k, [][]string{
+				{kubewardenNS, "app.kubernetes.io/name=kubewarden-controller"},
+				{kubewardenNS, "app.kubernetes.io/name=policy-server"},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Serve TLS with approved cipher suites only", func(ctx SpecContext) {
+		for resource, port := range endpoints {
+			f, err := portforward.Start(ctx, kubewardenNS, resource, port)
+			Expect(err).To(Not(HaveOccurred()))
+			DeferCleanup(f.Stop)
+
+			By("Checking the cipher suite negotiated by "+resource, func() {
+				for _, maxVersion := range []uint16{tls.VersionTLS12, tls.VersionTLS13} {
+					state, err := fips.Handshake(f.Addr(), nil, maxVersion)
+					Expect(err).To(Not(HaveOccurred()))
+					Expect(fips.Approved(state.CipherSuite)).To(BeTrue(),
+						"%s negotiated %s with %s", resource, tls.CipherSuiteName(state.CipherSuite), tls.VersionName(state.Version))
+				}
+			})
+
+			By("Checking that "+resource+" refuses non-approved cipher suites", func() {
+				_, err := fips.Handshake(f.Addr(), fips.RejectedCipherSuites, tls.VersionTLS12)
+				Expect(err).To(HaveOccurred(), "%s accepted a non-FIPS cipher suite", resource)
+			})
+		}
+	})
+
+	It("Enforce policies", func(ctx SpecContext) {
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+
+		out, err := kubectl.Run("run", UniqueName("fips-root-pod"), "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+	})
+})
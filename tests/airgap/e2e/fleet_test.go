@@ -0,0 +1,214 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/rancherapi"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+	"github.com/rancher-sandbox/ele-testhelpers/tools"
+)
+
+// Downstream cluster of the Fleet tests
+type fleetCluster struct {
+	// Environment of the cluster, used by the targets of the bundle
+	Env string
+	// VM of the cluster, empty if the node is given with FLEET_NODE_HOSTS
+	VM string
+	// MAC address of the VM, reserved in net-default-airgap.xml
+	MAC string
+	// SSH access to the node
+	Client *tools.Client
+	// Name of the provisioning cluster
+	Name string
+	// ID of the management cluster
+	ID string
+	// Cluster reached with the kubeconfig generated through the Rancher API
+	Cluster *cluster.Cluster
+}
+
+// NOTE: VMs are created from rancher-image.qcow2 if FLEET_NODE_HOSTS is not set, Rancher must be reachable from them
+var _ = Describe("E2E - Distribute policies to several clusters with Fleet", Label("fleet", "nightly"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Same credentials as the rancher-manager image, with the addresses of net-default-airgap.xml
+	clusters := []*fleetCluster{
+		{Env: "prod", VM: "fleet-prod", MAC: "52:54:00:00:00:11", Client: &tools.Client{Host: "192.168.122.103:22", Username: userName, Password: "root"}},
+		{Env: "dev", VM: "fleet-dev", MAC: "52:54:00:00:00:12", Client: &tools.Client{Host: "192.168.122.104:22", Username: userName, Password: "root"}},
+	}
+	if hosts := os.Getenv("FLEET_NODE_HOSTS"); hosts != "" {
+		for i, host := range strings.SplitN(hosts, ",", len(clusters)) {
+			clusters[i].VM = ""
+			clusters[i].Client = &tools.Client{
+				Host:     host + ":22",
+				Username: cmp.Or(os.Getenv("DOWNSTREAM_NODE_USER"), "root"),
+				Password: os.Getenv("DOWNSTREAM_NODE_PASSWORD"),
+			}
+		}
+	}
+	for _, c := range clusters {
+		c.Name = UniqueName("e2e-fleet-" + c.Env)
+	}
+
+	fleetID := UniqueName("fleet")
+	bundleName := UniqueName("e2e-policies")
+	policyName := UniqueName("fleet-privileged")
+	repoName := UniqueName("kubewarden-charts")
+
+	// Privileged pods are only denied by the policy of the bundle
+	vals := KubewardenValues().RecommendedPolicies(false, "monitor")
+
+	createPrivilegedPod := func(c *cluster.Cluster, name string) (string, error) {
+		return c.Kubectl("run", name, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`)
+	}
+
+	BeforeAll(func() {
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "bundles.fleet.cattle.io", bundleName,
+				"--namespace", "fleet-default", "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, c := range clusters {
+				// Rancher removes its agent from the downstream cluster
+				_, err := kubectl.RunWithoutErr("delete", "clusters.provisioning.cattle.io", c.Name,
+					"--namespace", "fleet-default", "--ignore-not-found", "--wait")
+				Expect(err).To(Not(HaveOccurred()))
+
+				node := runner.Remote(c.Client)
+				if c.VM != "" {
+					DeleteVM(c.VM)
+				} else if _, err := node.Run("test", "-f", k3sMarkerFile); err == nil {
+					UninstallK3s(node.WithSudo())
+				}
+			}
+		})
+	})
+
+	It("Import two clusters into Rancher with Kubewarden", func(ctx SpecContext) {
+		if _, err := kubectl.RunWithoutErr("get", "deployment", "rancher", "--namespace", "cattle-system"); err != nil {
+			InstallRancher(k)
+		}
+
+		var api *rancherapi.Client
+		if !dryrun.Enabled() {
+			api = rancherapi.New("https://"+GetRancherHostname(), CreateRancherToken())
+		}
+
+		for _, c := range clusters {
+			node := runner.Remote(c.Client)
+
+			By("Provisioning the "+c.Env+" cluster", func() {
+				if c.VM != "" {
+					CreateVM(c.VM, os.Getenv("HOME")+"/"+c.VM+".qcow2", os.Getenv("HOME")+"/rancher-image.qcow2", c.MAC)
+				}
+				CheckSSH(c.Client)
+				if c.VM != "" {
+					MarkDisposable(node)
+				}
+				InstallK3s(node)
+			})
+
+			By("Importing the "+c.Env+" cluster", func() {
+				c.ID = ImportCluster(ctx, node, c.Name)
+
+				// Labels of the provisioning cluster are synced to the Fleet cluster
+				_, err := kubectl.RunWithoutErr("label", "clusters.provisioning.cattle.io", c.Name, "--namespace", "fleet-default",
+					"--overwrite", "e2e-fleet="+fleetID, "e2e-fleet-env="+c.Env)
+				Expect(err).To(Not(HaveOccurred()))
+
+				c.Cluster = cluster.New(c.Name, GetClusterKubeconfig(api, c.ID))
+			})
+
+			By("Installing Kubewarden on the "+c.Env+" cluster", func() {
+				InstallKubewardenThroughRancher(ctx, api, c.Cluster, c.ID, repoName, vals)
+			})
+		}
+	})
+
+	It("Deploy the policy bundle on both clusters", func(ctx SpecContext) {
+		file := CopyYaml(fleetPolicyBundleYaml, map[string]string{
+			"%NAME%":     bundleName,
+			"%POLICY%":   policyName,
+			"%FLEET_ID%": fleetID,
+		})
+		_, err := kubectl.RunWithoutErr("apply", "-f", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "bundles.fleet.cattle.io", bundleName, "--namespace", "fleet-default",
+				"-o", "jsonpath={.status.summary.desiredReady}/{.status.summary.ready}")
+			return out
+		}, Equal(fmt.Sprintf("%d/%d", len(clusters), len(clusters)))), wait.Options{Class: timeouts.Rollout, Description: "bundle " + bundleName + " on all the clusters"})
+	})
+
+	It("Keep the same policy on both clusters", func(ctx SpecContext) {
+		var specs []string
+		for _, c := range clusters {
+			WaitForPolicyActive(ctx, c.Cluster, policyName)
+
+			// The mode is the only difference between the environments
+			spec, err := c.Cluster.KubectlWithoutErr("get", "clusteradmissionpolicy", policyName,
+				"-o", "jsonpath={.spec.module} {.spec.rules} {.spec.policyServer}")
+			Expect(err).To(Not(HaveOccurred()))
+			specs = append(specs, spec)
+		}
+
+		Expect(specs).To(HaveEach(specs[0]), "policy %s differs between the clusters", policyName)
+	})
+
+	It("Apply the customization of each environment", func() {
+		expected := map[string]string{"prod": "protect", "dev": "monitor"}
+		for _, c := range clusters {
+			mode, err := c.Cluster.KubectlWithoutErr("get", "clusteradmissionpolicy", policyName, "-o", "jsonpath={.spec.mode}")
+			Expect(err).To(Not(HaveOccurred()))
+			if dryrun.Enabled() {
+				continue
+			}
+			Expect(mode).To(Equal(expected[c.Env]), "mode of %s on the %s cluster", policyName, c.Env)
+
+			podName := UniqueName("fleet-privileged-pod")
+			out, err := createPrivilegedPod(c.Cluster, podName)
+			if c.Env == "prod" {
+				Expect(err).To(HaveOccurred(), "privileged pod allowed on the %s cluster", c.Env)
+				Expect(out).To(ContainSubstring(fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, policyName)))
+				continue
+			}
+
+			// Only reported in monitor mode
+			Expect(err).To(Not(HaveOccurred()), out)
+			_, err = c.Cluster.KubectlWithoutErr("delete", "pod", podName, "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+		}
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/golden"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// NOTE: set UPDATE_GOLDEN=true to write the reconciled resources in the golden files, missing ones fail the test otherwise
+var _ = Describe("E2E - Compare the reconciled resources with their golden files", Label("golden", "full"), func() {
+	// GOLDEN_DIR allows to compare with the golden files of another version
+	dir := cmp.Or(os.Getenv("GOLDEN_DIR"), reconciledGoldenDir)
+
+	// Resources created by the controller and the charts, without those of the tests
+	resources := []struct {
+		kind, ns string
+	}{
+		{"deployments", kubewardenNS},
+		{"services", kubewardenNS},
+		{"validatingwebhookconfigurations", ""},
+		{"mutatingwebhookconfigurations", ""},
+	}
+
+	It("Check that reconciled resources did not change", func() {
+		if dryrun.Enabled() {
+			dryrun.Record("compare the resources of %s with the golden files of %s", kubewardenNS, dir)
+			return
+		}
+
+		update := os.Getenv("UPDATE_GOLDEN") == "true"
+
+		var changed []string
+		for _, r := range resources {
+			objects, err := golden.Get(r.kind, r.ns, "")
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, o := range objects {
+				name := o.Name()
+				// Cluster-wide webhooks of other products, and policies of the tests
+				if r.ns == "" && !strings.Contains(name, "kubewarden") && !strings.HasPrefix(name, "clusterwide-") &&
+					!strings.HasPrefix(name, "namespaced-") || strings.Contains(name, GetRunID()) {
+					continue
+				}
+
+				data, err := o.Normalize()
+				Expect(err).To(Not(HaveOccurred()))
+
+				file := filepath.Join(dir, r.kind, name+".yaml")
+				diff, err := golden.Compare(file, data, update)
+				Expect(err).To(Not(HaveOccurred()), "new resources are written with UPDATE_GOLDEN=true")
+
+				if diff != "" {
+					AddReportEntry("golden diff of "+r.kind+"/"+name, diff)
+					changed = append(changed, file)
+				}
+			}
+		}
+
+		if update {
+			AddReportEntry("golden files updated", dir)
+			return
+		}
+		Expect(changed).To(BeEmpty(), "reconciled resources differ from the golden files, see the report entries")
+	})
+})
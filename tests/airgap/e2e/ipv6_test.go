@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: K3s has to be installed with K3S_IP_FAMILY=dual or K3S_IP_FAMILY=ipv6
+var _ = Describe("E2E - Check Kubewarden on a dual-stack or IPv6-only cluster", Label("ipv6", "full"), Ordered, Serial, func() {
+	clientNS := UniqueName("ipv6-client")
+	backupName := UniqueName("kubewarden-ipv6-backup")
+
+	// Keep the IPv6 addresses of a list
+	ipv6Only := func(list string) []string {
+		var ips []string
+		for _, ip := range strings.Fields(list) {
+			if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+				ips = append(ips, ip)
+			}
+		}
+		return ips
+	}
+
+	// IPv6 addresses and port of the pods behind a service, also with single-stack IPv4 services of a dual-stack cluster
+	ipv6Endpoints := func(service string) []string {
+		out, err := kubectl.RunWithoutErr("get", "endpointslices", "--namespace", kubewardenNS,
+			"-l", "kubernetes.io/service-name="+service,
+			"-o", `jsonpath={.items[0].ports[0].port}|{range .items[*].endpoints[*]}{.targetRef.name} {end}`)
+		Expect(err).To(Not(HaveOccurred()))
+		port, pods, _ := strings.Cut(out, "|")
+
+		var endpoints []string
+		for _, pod := range strings.Fields(pods) {
+			ips, err := kubectl.RunWithoutErr("get", "pod", pod, "--namespace", kubewardenNS, "-o", "jsonpath={.status.podIPs[*].ip}")
+			Expect(err).To(Not(HaveOccurred()))
+			for _, ip := range ipv6Only(ips) {
+				endpoints = append(endpoints, net.JoinHostPort(ip, port))
+			}
+		}
+		Expect(endpoints).To(Not(BeEmpty()), "no IPv6 endpoint for service %s", service)
+		return endpoints
+	}
+
+	// HTTP status code of a request sent over IPv6 from a new pod, 000 if the connection failed
+	httpCodeFrom := func(url string) string {
+		cmd := "curl -6 -sk -o /dev/null -m 5 -w '%{http_code}' " + url + " || true"
+		out, err := kubectl.RunWithoutErr("run", UniqueName("ipv6-curl"), "--namespace", clientNS,
+			"--image=curlimages/curl", "--restart=Never", "--rm", "-i", "--quiet",
+			"--command", "--", "sh", "-c", cmd)
+		Expect(err).To(Not(HaveOccurred()))
+
+		return strings.TrimSpace(out)
+	}
+
+	BeforeAll(func() {
+		if k3sIPFamily == "" {
+			Skip("K3S_IP_FAMILY is not set")
+		}
+
+		_, err := kubectl.RunWithoutErr("create", "namespace", clientNS)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", clientNS)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "namespace", clientNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Give IPv6 addresses to the Kubewarden services", func() {
+		for _, service := range []string{"kubewarden-controller-webhook-service", "policy-server-default"} {
+			out, err := kubectl.RunWithoutErr("get", "service", service, "--namespace", kubewardenNS,
+				"-o", "jsonpath={.spec.clusterIPs[*]}")
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Services of a dual-stack cluster are single-stack IPv4 unless asked otherwise
+			if k3sIPFamily == "ipv6" {
+				Expect(ipv6Only(out)).To(Equal(strings.Fields(out)), "cluster IPs of %s", service)
+			}
+			ipv6Endpoints(service)
+		}
+	})
+
+	It("Serve the webhooks over IPv6", func(ctx SpecContext) {
+		for _, endpoint := range ipv6Endpoints("policy-server-default") {
+			Expect(httpCodeFrom("https://"+endpoint+"/readiness")).To(Equal("200"), "readiness of %s", endpoint)
+		}
+
+		if k3sIPFamily == "ipv6" {
+			url := fmt.Sprintf("https://policy-server-default.%s.svc:8443/readiness", kubewardenNS)
+			Expect(httpCodeFrom(url)).To(Equal("200"), "readiness of %s", url)
+		}
+
+		// The API server calls the webhook of the policy
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+		out, err := kubectl.Run("run", UniqueName("ipv6-root-pod"), "--namespace", clientNS, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+	})
+
+	It("Scrape the metrics over IPv6", func() {
+		out, err := kubectl.RunWithoutErr("get", "services", "--namespace", kubewardenNS,
+			"-o", "jsonpath={.items[*].metadata.name}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		var services []string
+		for _, svc := range strings.Fields(out) {
+			if strings.Contains(svc, "metrics") {
+				services = append(services, svc)
+			}
+		}
+		if len(services) == 0 {
+			Skip("no metrics service in " + kubewardenNS)
+		}
+
+		// API errors (401, 403...) still mean that the port is reachable
+		for _, svc := range services {
+			for _, endpoint := range ipv6Endpoints(svc) {
+				Expect(httpCodeFrom("https://"+endpoint+"/metrics")).To(Not(Equal("000")), "scraping %s of %s", endpoint, svc)
+			}
+		}
+	})
+
+	It("Back up to the backup target over IPv6", func(ctx SpecContext) {
+		if _, err := kubectl.RunWithoutErr("get", "deployment", "rancher-backup", "--namespace", "cattle-resources-system"); err != nil {
+			Skip("backup operator is not installed")
+		}
+
+		ApplyBackup(backupName)
+		WaitForReady(ctx, "backup", backupName)
+	})
+})
@@ -0,0 +1,34 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// NOTE: unlike the cleanup suite, the installed stack is kept
+var _ = Describe("E2E - Delete resources left by previous runs", Label("janitor"), Serial, func() {
+	It("Delete all resources with the run label", func() {
+		// Only one run if defined, all runs otherwise
+		id := os.Getenv("JANITOR_RUN_ID")
+		GinkgoWriter.Printf("Cleaning resources of run %q\n", id)
+
+		err := CleanupRun(id)
+		Expect(err).To(Not(HaveOccurred()))
+	})
+})
@@ -0,0 +1,82 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/kubebench"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: kube-bench has to be installed on the K3s node, the baseline is taken before
+// installing Kubewarden unless KUBE_BENCH_BASELINE (a kube-bench JSON output) is set
+var _ = Describe("E2E - CIS scan of the cluster with Kubewarden", Label("kube-bench", "nightly"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	benchmark := cmp.Or(os.Getenv("KUBE_BENCH_BENCHMARK"), "k3s-cis-1.8")
+
+	var baseline *kubebench.Report
+
+	BeforeAll(func() {
+		if _, err := k3sNode.Run("which", "kube-bench"); err != nil {
+			Skip("kube-bench is not installed on the K3s node")
+		}
+	})
+
+	It("Get the findings without Kubewarden", func() {
+		var err error
+		if file := os.Getenv("KUBE_BENCH_BASELINE"); file != "" {
+			baseline, err = kubebench.Load(file)
+			Expect(err).To(Not(HaveOccurred()))
+		} else {
+			Expect(IsKubewardenInstalled(kubewardenNS, "")).To(BeFalse(),
+				"Kubewarden is already installed, KUBE_BENCH_BASELINE has to be set")
+
+			baseline, err = kubebench.Run(k3sNode, benchmark)
+			Expect(err).To(Not(HaveOccurred()))
+		}
+
+		AddReportEntry("kube-bench baseline failures", strings.Join(baseline.Failures(), ", "))
+	})
+
+	It("Install Kubewarden", func() {
+		if IsKubewardenInstalled(kubewardenNS, "") {
+			Skip("Kubewarden is already installed")
+		}
+
+		InstallKubewarden(k, kubewardenNS, "")
+	})
+
+	It("Check that Kubewarden does not add critical findings", func() {
+		report, err := kubebench.Run(k3sNode, benchmark)
+		Expect(err).To(Not(HaveOccurred()))
+
+		AddReportEntry("kube-bench failures", strings.Join(report.Failures(), ", "))
+		Expect(report.NewFailures(baseline)).To(BeEmpty(), "new failed checks with Kubewarden installed")
+	})
+})
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: should be executed after install-kubewarden with KUBEWARDEN_NAMESPACE set
+var _ = Describe("E2E - Check Kubewarden namespace", Label("check-kubewarden-namespace", "smoke", "full"), func() {
+	It("Check that the whole stack runs in the configured namespace", func(ctx SpecContext) {
+		By("Checking the Helm releases namespace", func() {
+			out, err := kubectl.RunHelmBinaryWithOutput("list", "--namespace", kubewardenNS, "--deployed", "--short")
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+				Expect(out).To(ContainSubstring(chart))
+			}
+		})
+
+		By("Checking that webhooks reference services of the configured namespace", func() {
+			Expect(CheckWebhookServices()).To(Succeed())
+
+			for _, svc := range GetWebhookServices() {
+				Expect(strings.HasPrefix(svc, kubewardenNS+"/")).To(BeTrue(),
+					"webhook references service %s outside of namespace %s", svc, kubewardenNS)
+			}
+		})
+
+		// Only relevant when a custom namespace is used
+		if kubewardenNS != "kubewarden" {
+			By("Checking that nothing has been created in the default namespace", func() {
+				out, err := kubectl.RunWithoutErr("get", "deployments,services,secrets,configmaps",
+					"--namespace", "kubewarden",
+					"--selector", "app.kubernetes.io/part-of=kubewarden",
+					"-o", "jsonpath={.items[*].metadata.name}")
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(out).To(BeEmpty())
+			})
+		}
+
+		By("Checking that recommended policies are active", func() {
+			WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+		})
+	})
+})
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: should be executed after install-kubewarden with KUBEWARDEN_REGISTRY set
+var _ = Describe("E2E - Check Kubewarden images registry", Label("check-kubewarden-registry", "smoke", "full"), func() {
+	BeforeEach(func() {
+		if kubewardenRegistry == "" {
+			Skip("KUBEWARDEN_REGISTRY is not defined")
+		}
+	})
+
+	It("Check that the whole stack uses images of the configured registry", func(ctx SpecContext) {
+		prefix := strings.TrimSuffix(kubewardenRegistry, "/") + "/"
+
+		By("Checking the images of the running pods", func() {
+			// Policy servers could still be rolled out
+			WaitFor(ctx, wait.Check(func() error {
+				return CheckImagesRegistry(kubewardenNS, kubewardenRegistry)
+			}), wait.Options{Class: timeouts.Rollout, Description: "pods using images of " + kubewardenRegistry})
+		})
+
+		By("Checking the images of the policy servers", func() {
+			out, err := kubectl.RunWithoutErr("get", "policyservers",
+				"-o", "jsonpath={range .items[*]}{.metadata.name}={.spec.image}{\"\\n\"}{end}")
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, server := range strings.Fields(out) {
+				_, image, _ := strings.Cut(server, "=")
+				Expect(image).To(HavePrefix(prefix), "policy server %s", server)
+			}
+		})
+
+		By("Checking the modules of the recommended policies", func() {
+			// Only the policies of the chart, the tests deploy their own ones
+			manifest, err := localCluster.Helm("get", "manifest", "kubewarden-defaults", "--namespace", kubewardenNS)
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, line := range strings.Split(manifest, "\n") {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module: "); ok {
+					Expect(strings.Trim(module, `"'`)).To(HavePrefix("registry://"+prefix), "policy module")
+				}
+			}
+		})
+
+		By("Checking the images of the audit scanner", func() {
+			out, err := kubectl.RunWithoutErr("get", "cronjobs", "--namespace", kubewardenNS,
+				"-o", "jsonpath={.items[*].spec.jobTemplate.spec.template.spec.containers[*].image}")
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, image := range strings.Fields(out) {
+				Expect(image).To(HavePrefix(prefix))
+			}
+		})
+	})
+})
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/logs"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: LARGE_POLICY_MODULE and LARGE_POLICY_SETTINGS (JSON) can set another module, LARGE_POLICY_BUDGET the time-to-active budget
+var _ = Describe("E2E - Pull a large policy module on each start", Label("large-policy", "perf"), Ordered, Serial, func() {
+	// One of the largest modules of the catalog, it embeds the Sigstore verification
+	module := cmp.Or(os.Getenv("LARGE_POLICY_MODULE"), "ghcr.io/kubewarden/policies/verify-image-signatures:v0.3.0")
+	settings := cmp.Or(os.Getenv("LARGE_POLICY_SETTINGS"),
+		`{"signatures": [{"image": "ghcr.io/kubewarden/*", "githubActions": {"owner": "kubewarden"}}]}`)
+
+	serverName := UniqueName("large-policy-server")
+	policyName := UniqueName("large-policy")
+	deployment := "deployment/policy-server-" + serverName
+
+	budget := 3 * time.Minute
+	if b, err := time.ParseDuration(os.Getenv("LARGE_POLICY_BUDGET")); err == nil {
+		budget = b
+	}
+
+	// Time taken by the policy to be active, the policy server rolls out in the meantime
+	timeToActive := func(ctx SpecContext, load string, start time.Time) time.Duration {
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		elapsed := time.Since(start)
+
+		RecordMetric("large-policy "+load+" time-to-active", elapsed.Seconds(), "s")
+		return elapsed
+	}
+
+	// Without a persistent store, each start of the policy server downloads the module again
+	pullTime := func(load string, since time.Time) {
+		if dryrun.Enabled() {
+			dryrun.Record("measure the pull of %s by %s", module, deployment)
+			return
+		}
+
+		entries, err := logs.Get(kubewardenNS, deployment, since)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// From the first download entry of the module to the next step of the start
+		var start, end time.Time
+		for _, e := range entries {
+			ts, err := time.Parse(time.RFC3339Nano, e.Field("timestamp"))
+			if err != nil {
+				continue
+			}
+			downloading := strings.Contains(strings.ToLower(e.Field("message")), "download") && strings.Contains(fmt.Sprint(e), module)
+			if start.IsZero() {
+				if downloading {
+					start = ts
+				}
+			} else if !downloading {
+				end = ts
+				break
+			}
+		}
+		Expect(start.IsZero()).To(BeFalse(), "no download of %s by %s", module, deployment)
+		Expect(end.IsZero()).To(BeFalse(), "download of %s by %s is not done", module, deployment)
+
+		RecordMetric("large-policy "+load+" pull time", end.Sub(start).Seconds(), "s")
+	}
+
+	// Both specs use the same policy server
+	AfterAll(func() {
+		_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	It("Load the large module", func(ctx SpecContext) {
+		start := time.Now()
+
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(largePolicyYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%MODULE%":              module,
+			"%SETTINGS%":            settings,
+			"%SERVER_NAME%":         serverName,
+			"%POLICY_NAME%":         policyName,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		firstLoad := timeToActive(ctx, "first load", start)
+		Expect(firstLoad).To(BeNumerically("<", budget), "time-to-active of %s is over budget", module)
+
+		pullTime("first load", start)
+	})
+
+	It("Restart the policy server and pull the module again", func(ctx SpecContext) {
+		since := time.Now()
+
+		_, err := kubectl.RunWithoutErr("rollout", "restart", deployment, "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-"+serverName)
+
+		restart := timeToActive(ctx, "restart", since)
+		Expect(restart).To(BeNumerically("<", budget), "time-to-active of %s after a restart is over budget", module)
+
+		pullTime("restart", since)
+	})
+})
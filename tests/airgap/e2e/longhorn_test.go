@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: Longhorn requires open-iscsi on the host and the backup operator
+// should not be already installed, as the storage class of its PVC cannot be changed
+var _ = Describe("E2E - Test Backup/Restore on Longhorn storage", Label("test-longhorn-backup-restore", "nightly"), Serial, func() {
+	const longhornPolicyName = "do-not-run-as-root"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	backupName := UniqueName(backupResourceName)
+	restoreName := UniqueName(restoreResourceName)
+	longhornSnapshotName := UniqueName("kubewarden-backup-snapshot")
+
+	It("Do a backup/restore with a Longhorn backup volume", func(ctx SpecContext) {
+		By("Installing Longhorn", func() {
+			InstallLonghorn(k)
+		})
+
+		By("Installing rancher-backup-operator on Longhorn storage", func() {
+			// Next tests use the configured storage class
+			previousClass := backupStorageClass
+			DeferCleanup(func() {
+				backupStorageClass = previousClass
+			})
+
+			backupStorageClass = "longhorn"
+			InstallBackupOperator(k, backupRestoreVersion)
+		})
+
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+
+			// Wait for backup to be done
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Taking a snapshot of the backup volume", func() {
+			snapshot := CopyYaml(longhornSnapshotYaml, map[string]string{
+				"%VOLUME_NAME%":              GetBackupVolume(),
+				"kubewarden-backup-snapshot": longhornSnapshotName,
+			})
+			err := kubectl.Apply("longhorn-system", snapshot)
+			Expect(err).To(Not(HaveOccurred()))
+
+			WaitFor(ctx, wait.Match(func() string {
+				out, _ := kubectl.RunWithoutErr("get", "snapshots.longhorn.io", longhornSnapshotName,
+					"--namespace", "longhorn-system",
+					"-o", "jsonpath={.status.readyToUse}")
+				return out
+			}, Equal("true")), wait.Options{Class: timeouts.Backup, Description: "Longhorn snapshot"})
+		})
+
+		By("Deleting a Kubewarden policy", func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", longhornPolicyName)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Restoring the backup volume from the snapshot", func() {
+			RevertLonghornVolume(ctx, "cattle-resources-system", "rancher-backup", GetBackupVolume(), longhornSnapshotName)
+		})
+
+		By("Adding a restore resource", func() {
+			// The backup file comes from the reverted volume
+			ApplyRestore(restoreName, GetBackupFile(backupName), false)
+
+			// Wait for restore to be done
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that the deleted policy is active again", func() {
+			WaitForPolicyActive(ctx, localCluster, longhornPolicyName)
+		})
+
+		By("Checking that the backup volume snapshot is still available", func() {
+			out, err := kubectl.RunWithoutErr("get", "snapshots.longhorn.io", longhornSnapshotName,
+				"--namespace", "longhorn-system",
+				"-o", "jsonpath={.status.readyToUse}")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(out).To(Equal("true"))
+		})
+	})
+})
@@ -0,0 +1,161 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/metrics"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/portforward"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: telemetry needs the OpenTelemetry operator, the test is skipped without it
+var _ = Describe("E2E - Check the metrics of the Kubewarden components", Label("metrics", "full"), Flaky(), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Metrics endpoints, the policy server ones are exposed by its collector sidecar
+	type endpoint struct {
+		resource string
+		port     int
+	}
+	controller := endpoint{"deployment/kubewarden-controller", 8088}
+	policyServer := endpoint{"deployment/policy-server-default", 8080}
+
+	// Sum of the series of a metric, scraped through a port-forward stopped at the end of the spec
+	scrape := func(ctx context.Context, e endpoint, name string, labels ...string) float64 {
+		if dryrun.Enabled() {
+			dryrun.Record("scrape %s from %s:%d", name, e.resource, e.port)
+			return 0
+		}
+
+		f, err := portforward.Start(ctx, kubewardenNS, e.resource, e.port)
+		Expect(err).To(Not(HaveOccurred()))
+		defer f.Stop()
+
+		samples, err := metrics.Scrape(f.URL("http") + "/metrics")
+		Expect(err).To(Not(HaveOccurred()))
+
+		sum, found := samples.Sum(name, labels...)
+		Expect(found).To(BeTrue(), "no %s series exposed by %s", name, e.resource)
+		return sum
+	}
+
+	// Metrics are exported periodically, the increment is not immediate
+	waitForIncrement := func(ctx context.Context, e endpoint, before float64, name string, labels ...string) {
+		WaitFor(ctx, wait.Check(func() error {
+			if after := scrape(ctx, e, name, labels...); after <= before {
+				return fmt.Errorf("%s is still %v", name, after)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: name + " to increment"})
+	}
+
+	// Set once the telemetry values are installed
+	var telemetry bool
+
+	BeforeAll(func() {
+		if _, err := kubectl.RunWithoutErr("get", "crd", "opentelemetrycollectors.opentelemetry.io"); err != nil {
+			Skip("OpenTelemetry operator is not installed")
+		}
+	})
+
+	AfterAll(func() {
+		// Other tests expect the default values
+		if telemetry {
+			InstallKubewarden(k, kubewardenNS, "")
+		}
+	})
+
+	It("Enable the telemetry", func(ctx SpecContext) {
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().Telemetry(true))
+
+		// Reverted once all the specs are done
+		telemetry = true
+
+		// Sidecars are only injected in new pods
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment", "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = kubectl.RunWithoutErr("rollout", "status", "deployment", "--namespace", kubewardenNS,
+			fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+	})
+
+	It("Count the reconciles of the controller", func(ctx SpecContext) {
+		const name, label = "controller_runtime_reconcile_total", `controller="policyserver"`
+		before := scrape(ctx, controller, name, label)
+
+		// Any change of the policy server is reconciled
+		_, err := kubectl.RunWithoutErr("annotate", "policyserver", "default", "--overwrite", "e2e-metrics="+UniqueName("reconcile"))
+		Expect(err).To(Not(HaveOccurred()))
+		DeferCleanup(kubectl.RunWithoutErr, "annotate", "policyserver", "default", "e2e-metrics-")
+
+		waitForIncrement(ctx, controller, before, name, label)
+	})
+
+	It("Count the evaluations of the policy server", func(ctx SpecContext) {
+		const name = "kubewarden_policy_evaluations_total"
+		before := scrape(ctx, policyServer, name)
+
+		out, err := kubectl.Run("run", UniqueName("metrics-root-pod"), "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+
+		waitForIncrement(ctx, policyServer, before, name, `policy_name="do-not-run-as-root"`)
+	})
+
+	It("Count the evaluations of an audit run", func(ctx SpecContext) {
+		const name = "kubewarden_policy_evaluations_total"
+
+		out, err := kubectl.RunWithoutErr("get", "cronjobs", "--namespace", kubewardenNS, "-o", "name")
+		Expect(err).To(Not(HaveOccurred()))
+		if out == "" {
+			Skip("audit scanner is not installed")
+		}
+
+		before := scrape(ctx, policyServer, name)
+
+		// Audit requests are evaluated by the policy server like admission requests
+		for _, cronjob := range strings.Fields(out) {
+			job := UniqueName("metrics-audit")
+			_, err := kubectl.RunWithoutErr("create", "job", job, "--namespace", kubewardenNS, "--from", cronjob)
+			Expect(err).To(Not(HaveOccurred()))
+			DeferCleanup(kubectl.RunWithoutErr, "delete", "job", job, "--namespace", kubewardenNS, "--ignore-not-found")
+
+			_, err = kubectl.RunWithoutErr("wait", "job/"+job, "--namespace", kubewardenNS, "--for=condition=Complete",
+				fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+			Expect(err).To(Not(HaveOccurred()))
+		}
+
+		waitForIncrement(ctx, policyServer, before, name)
+	})
+})
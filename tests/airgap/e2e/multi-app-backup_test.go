@@ -0,0 +1,157 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: cert-manager is installed if needed, and kept at the end
+var _ = Describe("E2E - Backup/Restore Kubewarden and cert-manager together", Label("multi-app-backup", "full"), Ordered, Serial, func() {
+	appID := UniqueName("multi-app")
+	ns := appID
+	policyName := appID + "-policy"
+	backupName := UniqueName("kubewarden-multi-app-backup")
+	restoreName := UniqueName("kubewarden-multi-app-restore")
+
+	// Certificates referenced by the Kubewarden webhooks, as namespace/name, when the controller uses cert-manager
+	var injected map[string]string
+
+	waitForCertificate := func(ctx SpecContext, name string) {
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "certificate", name, "--namespace", ns,
+				"-o", `jsonpath={.status.conditions[?(@.type=="Ready")].status}`)
+			return out
+		}, Equal("True")), wait.Options{Class: timeouts.Rollout, Description: "certificate " + name + " to be ready"})
+	}
+
+	getSecret := func(ns, name, key string) string {
+		out, err := kubectl.RunWithoutErr("get", "secret", name, "--namespace", ns,
+			"-o", "jsonpath={.data."+strings.ReplaceAll(key, ".", `\.`)+"}")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(out).To(Not(BeEmpty()), "no %s in secret %s/%s", key, ns, name)
+		return out
+	}
+
+	getInjected := func() map[string]string {
+		out, err := kubectl.RunWithoutErr("get", "validatingwebhookconfigurations,mutatingwebhookconfigurations",
+			"-o", `jsonpath={range .items[*]}{.kind}/{.metadata.name}={.metadata.annotations.cert-manager\.io/inject-ca-from}{"\n"}{end}`)
+		Expect(err).To(Not(HaveOccurred()))
+
+		refs := map[string]string{}
+		for _, line := range strings.Fields(out) {
+			webhook, certificate, _ := strings.Cut(line, "=")
+			if strings.Contains(webhook, "kubewarden") && certificate != "" {
+				refs[webhook] = certificate
+			}
+		}
+		return refs
+	}
+
+	BeforeAll(func() {
+		if deployed, _ := GetReleases(localCluster, "cattle-resources-system"); len(deployed) == 0 {
+			Skip("rancher-backup operator is not installed")
+		}
+		if deployed, _ := GetReleases(localCluster, "cert-manager"); len(deployed) == 0 {
+			InstallCertManager()
+		}
+
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Issue certificates next to the Kubewarden policies", func(ctx SpecContext) {
+		file := CopyYaml(multiAppCertsYaml, map[string]string{
+			"%PREFIX%":    appID,
+			"%NAMESPACE%": ns,
+		})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		waitForCertificate(ctx, appID+"-ca")
+		waitForCertificate(ctx, appID+"-leaf")
+
+		file = CopyYaml(scalePolicyYaml, map[string]string{
+			"%NAME%":          policyName,
+			"%POLICY_SERVER%": "default",
+			"%SCALE_ID%":      appID,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, policyName)
+
+		injected = getInjected()
+		AddReportEntry("webhooks using cert-manager certificates", injected)
+	})
+
+	It("Back up both applications in one backup", func(ctx SpecContext) {
+		file := CopyYaml(multiAppBackupYaml, map[string]string{
+			"%NAME%":      backupName,
+			"%NAMESPACE%": ns,
+		})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForReady(ctx, "backup", backupName)
+	})
+
+	It("Delete the resources of both applications", func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeletion(ctx, "namespace", ns, "")
+	})
+
+	It("Restore both applications from the backup", func(ctx SpecContext) {
+		ApplyRestore(restoreName, GetBackupFile(backupName), false)
+		WaitForReady(ctx, "restore", restoreName)
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		waitForCertificate(ctx, appID+"-ca")
+		waitForCertificate(ctx, appID+"-leaf")
+	})
+
+	It("Keep the references between the restored resources", func() {
+		// The leaf certificate is still issued by the restored CA, nothing has been issued again
+		ca := getSecret(ns, appID+"-ca", "tls.crt")
+		Expect(getSecret(ns, appID+"-leaf", "ca.crt")).To(Equal(ca), "certificate %s not issued by the restored CA", appID+"-leaf")
+
+		// The webhooks of Kubewarden still trust the certificates they are injected from
+		Expect(getInjected()).To(Equal(injected))
+		for webhook, certificate := range injected {
+			certNS, certName, _ := strings.Cut(certificate, "/")
+			secret, err := kubectl.RunWithoutErr("get", "certificate", certName, "--namespace", certNS, "-o", "jsonpath={.spec.secretName}")
+			Expect(err).To(Not(HaveOccurred()))
+
+			bundle, err := kubectl.RunWithoutErr("get", webhook, "-o", "jsonpath={.webhooks[0].clientConfig.caBundle}")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(bundle).To(Equal(getSecret(certNS, secret, "ca.crt")), "caBundle of %s does not match certificate %s", webhook, certificate)
+		}
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Isolate tenants with their own policy servers", Label("multi-tenancy", "full"), Ordered, Serial, func() {
+	// Policies of tenants.yaml, one per tenant
+	const (
+		policyA = "tenant-a-privileged-pods"
+		policyB = "tenant-b-forbidden-label"
+	)
+
+	serverA := UniqueName("tenant-a-server")
+	serverB := UniqueName("tenant-b-server")
+	nsA := UniqueName("tenant-a")
+	nsB := UniqueName("tenant-b")
+
+	// Namespaced policies are not handled by WaitForPolicyActive
+	waitForPolicyActive := func(ctx context.Context, ns, policy string) {
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "admissionpolicy", policy, "--namespace", ns,
+				"-o", "jsonpath={.status.policyStatus}")
+			return out
+		}, Equal("active")), wait.Options{Class: timeouts.Rollout, Description: "policy " + policy + " to be active"})
+	}
+
+	// Recommended policies could deny the pods too, only the webhook of the tenant policy matters
+	checkPod := func(ns, policyNS, policy string, denied bool, args ...string) {
+		pod := UniqueName("tenant-pod")
+		out, err := kubectl.Run(append([]string{"run", pod, "--namespace", ns, "--image=rancher/pause:3.2"}, args...)...)
+		if err == nil {
+			_, _ = kubectl.RunWithoutErr("delete", "pod", pod, "--namespace", ns, "--wait=false")
+		}
+
+		webhook := fmt.Sprintf(`admission webhook "namespaced-%s-%s.kubewarden.admission" denied the request`, policyNS, policy)
+		if denied {
+			Expect(out).To(ContainSubstring(webhook), "pod in %s not denied by %s", ns, policy)
+		} else {
+			Expect(out).To(Not(ContainSubstring(webhook)), "pod in %s denied by %s of %s", ns, policy, policyNS)
+		}
+	}
+
+	privileged := []string{"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`}
+	forbidden := []string{"--labels", "forbidden=true"}
+
+	BeforeAll(func() {
+		for _, ns := range []string{nsA, nsB} {
+			_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+			Expect(err).To(Not(HaveOccurred()))
+			LabelRun("namespace", ns)
+		}
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "namespace", nsA, nsB, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "policyserver", serverA, serverB, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Deploy a policy server and policies for each tenant", func(ctx SpecContext) {
+		image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(tenantsYaml, map[string]string{
+			"%POLICY_SERVER_IMAGE%": image,
+			"%SERVER_A%":            serverA,
+			"%SERVER_B%":            serverB,
+			"%NS_A%":                nsA,
+			"%NS_B%":                nsB,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		waitForPolicyActive(ctx, nsA, policyA)
+		waitForPolicyActive(ctx, nsB, policyB)
+	})
+
+	It("Apply the policies of a tenant only to its namespace", func() {
+		By("Checking the namespace of tenant A", func() {
+			checkPod(nsA, nsA, policyA, true, privileged...)
+			checkPod(nsA, nsB, policyB, false, forbidden...)
+		})
+
+		By("Checking the namespace of tenant B", func() {
+			checkPod(nsB, nsB, policyB, true, forbidden...)
+			checkPod(nsB, nsA, policyA, false, privileged...)
+		})
+	})
+
+	It("Keep the limits of each policy server", func() {
+		limits := map[string]string{
+			serverA: "500m 256Mi",
+			serverB: "250m 128Mi",
+		}
+
+		for server, want := range limits {
+			out, err := kubectl.RunWithoutErr("get", "deployment", "policy-server-"+server, "--namespace", kubewardenNS,
+				"-o", "jsonpath={.spec.template.spec.containers[0].resources.limits.cpu} {.spec.template.spec.containers[0].resources.limits.memory}")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(out).To(Equal(want), "limits of policy server %s", server)
+		}
+	})
+})
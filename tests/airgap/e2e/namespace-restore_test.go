@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Test Backup/Restore into a different namespace", Label("test-namespace-backup-restore", "nightly"), Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	backupName := UniqueName(backupResourceName)
+	restoreName := UniqueName(restoreResourceName)
+	restoredNS := UniqueName("kubewarden-restored")
+	// The new installation re-creates the recommended policies, only this one has to come from the backup
+	policyName := UniqueName("namespace-policy")
+
+	It("Restore Kubewarden resources after moving the stack to another namespace", func(ctx SpecContext) {
+		// Kubewarden is moved away from its configured namespace
+		originalNS := kubewardenNS
+
+		// The next specs expect Kubewarden in its configured namespace
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+
+			deployed, err := GetReleases(localCluster, restoredNS)
+			Expect(err).To(Not(HaveOccurred()))
+			for _, chart := range []string{"kubewarden-defaults", "kubewarden-controller", "kubewarden-crds"} {
+				if slices.ContainsFunc(deployed, func(r helmRelease) bool { return r.Name == chart }) {
+					RunHelmCmdWithRetry("uninstall", chart, "--namespace", restoredNS, "--wait")
+				}
+			}
+
+			_, err = kubectl.RunWithoutErr("delete", "namespace", restoredNS, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+
+			InstallKubewarden(k, originalNS, "")
+		})
+
+		By("Adding a backup resource", func() {
+			ApplyBackupOnlyPolicy(policyName)
+			ApplyBackup(backupName)
+
+			// Wait for backup to be done
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Uninstalling Kubewarden from the original namespace", func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, chart := range []string{"kubewarden-defaults", "kubewarden-controller", "kubewarden-crds"} {
+				RunHelmCmdWithRetry("uninstall", chart, "--namespace", originalNS, "--wait")
+			}
+
+			err = kubectl.DeleteNamespace(originalNS)
+			Expect(err).To(Not(HaveOccurred()))
+
+			err = k.WaitForNamespaceDelete(originalNS)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Installing Kubewarden into a different namespace", func() {
+			InstallKubewarden(k, restoredNS, "")
+			LabelRun("namespace", restoredNS)
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(restoreName, GetBackupFile(backupName), false)
+
+			// Wait for restore to be done
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that webhooks reference services of the new namespace", func() {
+			// Fail with the list of dangling services instead of a simple timeout
+			WaitFor(ctx, wait.Check(CheckWebhookServices), wait.Options{Class: timeouts.Rollout, Description: "webhook services"})
+
+			for _, svc := range GetWebhookServices() {
+				Expect(strings.HasPrefix(svc, restoredNS+"/")).To(BeTrue(),
+					"webhook still references service %s from the original namespace", svc)
+			}
+		})
+
+		By("Checking that webhooks have the CA of the new namespace", func() {
+			WaitForCABundles(ctx, restoredNS)
+		})
+
+		By("Checking that restored policies are enforced", func() {
+			CheckBackupOnlyPolicyEnforced(ctx, policyName)
+		})
+	})
+})
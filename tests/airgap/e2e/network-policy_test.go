@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: the network policy controller of K3s has to be enabled (default)
+var _ = Describe("E2E - Check Kubewarden behind network policies", Label("network-policy", "full"), Ordered, Serial, func() {
+	clientNS := UniqueName("netpol-client")
+	policyServerURL := fmt.Sprintf("https://policy-server-default.%s.svc:8443/readiness", kubewardenNS)
+
+	// HTTP status code of a request sent from a new pod, 000 if the connection failed,
+	// API errors (401, 403...) still mean that the port is reachable
+	httpCodeFrom := func(labels, url string) string {
+		cmd := "curl -sk -o /dev/null -m 5 -w '%{http_code}' " + url + " || true"
+		out, err := kubectl.RunWithoutErr("run", UniqueName("netpol-curl"), "--namespace", clientNS,
+			"--image=curlimages/curl", "--restart=Never", "--rm", "-i", "--quiet", "--labels", labels,
+			"--command", "--", "sh", "-c", cmd)
+		Expect(err).To(Not(HaveOccurred()))
+
+		return strings.TrimSpace(out)
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", clientNS)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", clientNS)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "namespace", clientNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		// Reached before applying the network policies, otherwise the next checks would not prove anything
+		Expect(httpCodeFrom("app=netpol-client", policyServerURL)).To(Not(Equal("000")))
+
+		file := CopyYaml(networkPoliciesYaml, nil)
+		err = kubectl.Apply(kubewardenNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Kept for all the specs, other tests share the Kubewarden namespace
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "networkpolicies", "--namespace", kubewardenNS,
+				"-l", runLabel+"="+GetRunID(), "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Isolate the policy server with the network policies", func(ctx SpecContext) {
+		// Rules are enforced asynchronously by the network policy controller
+		WaitFor(ctx, wait.Match(func() string {
+			return httpCodeFrom("app=netpol-client", policyServerURL)
+		}, Equal("000")), wait.Options{Class: timeouts.Rollout, Description: "policy server to be unreachable"})
+	})
+
+	It("Enforce policies", func(ctx SpecContext) {
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+
+		out, err := kubectl.Run("run", UniqueName("netpol-root-pod"), "--namespace", clientNS, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+
+		Expect(CheckPoliciesActive()).To(Succeed())
+	})
+
+	It("Scrape the metrics", func() {
+		out, err := kubectl.RunWithoutErr("get", "services", "--namespace", kubewardenNS,
+			"-o", "jsonpath={range .items[*]}{.metadata.name}:{.spec.ports[0].port}{\"\\n\"}{end}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		var endpoints []string
+		for _, svc := range strings.Fields(out) {
+			if strings.Contains(svc, "metrics") {
+				name, port, _ := strings.Cut(svc, ":")
+				endpoints = append(endpoints, fmt.Sprintf("https://%s.%s.svc:%s/metrics", name, kubewardenNS, port))
+			}
+		}
+		if len(endpoints) == 0 {
+			Skip("no metrics service in " + kubewardenNS)
+		}
+
+		for _, url := range endpoints {
+			Expect(httpCodeFrom("kubewarden-metrics-scraper=true", url)).To(Not(Equal("000")), "scraping %s", url)
+		}
+	})
+
+	It("Block unrelated pods", func() {
+		Expect(httpCodeFrom("app=netpol-client", policyServerURL)).To(Equal("000"))
+	})
+})
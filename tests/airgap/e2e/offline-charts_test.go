@@ -0,0 +1,153 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/chartrepo"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: the charts are downloaded once, then installed with the upstream repositories unreachable
+var _ = Describe("E2E - Install the charts from an offline Helm repository", Label("offline-charts", "full"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Listening on all addresses, so the repository is also reachable from the airgap network
+	addr := os.Getenv("HELM_OFFLINE_ADDR")
+	if addr == "" {
+		addr = "0.0.0.0:8879"
+	}
+
+	var server *chartrepo.Server
+
+	// Charts of all the install helpers of the stack
+	charts := func() []chartrepo.Chart {
+		f := kubewardenFlavors[kubewardenFlavor]
+		list := []chartrepo.Chart{
+			{Repo: f.RepoURL, Name: "kubewarden-crds"},
+			{Repo: f.RepoURL, Name: "kubewarden-controller"},
+			{Repo: f.RepoURL, Name: "kubewarden-defaults"},
+			{Repo: "https://charts.jetstack.io", Name: "cert-manager"},
+		}
+
+		for _, chart := range []string{"rancher-backup-crd", "rancher-backup"} {
+			if backupRestoreVersion == "" {
+				list = append(list, chartrepo.Chart{Repo: "https://charts.rancher.io", Name: chart})
+				continue
+			}
+			list = append(list, chartrepo.Chart{
+				Repo: "https://github.com/rancher/backup-restore-operator/releases/download/" + backupRestoreVersion + "/" +
+					chart + "-" + strings.Trim(backupRestoreVersion, "v") + ".tgz",
+			})
+		}
+
+		return list
+	}
+
+	BeforeAll(func() {
+		DeferCleanup(func() {
+			offlineChartRepo = os.Getenv("HELM_OFFLINE_REPO")
+			if server != nil {
+				Expect(server.Close()).To(Succeed())
+			}
+		})
+	})
+
+	It("Serve the charts from an offline repository", func() {
+		dir := filepath.Join(GetTempDir(), "offline-charts")
+		err := chartrepo.Package(dir, charts())
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Reachable by helm on the test host, and by the airgap VMs through the default libvirt network
+		host := "127.0.0.1"
+		if h := os.Getenv("HELM_OFFLINE_HOST"); h != "" {
+			host = h
+		}
+		server, err = chartrepo.Serve(dir, addr, host)
+		Expect(err).To(Not(HaveOccurred()))
+		GinkgoWriter.Printf("Offline chart repository: %s\n", server.URL())
+	})
+
+	It("Install the stack without reaching the upstream repositories", func() {
+		// Anything not on the repository, the node or the API server goes to a proxy that does not exist
+		noProxy := []string{"127.0.0.1", "localhost", GetNodeIP()}
+		if host := k3sNode.Host(); host != "" {
+			noProxy = append(noProxy, host)
+		}
+		for name, value := range map[string]string{
+			"HTTP_PROXY":  "http://127.0.0.1:9",
+			"HTTPS_PROXY": "http://127.0.0.1:9",
+			"NO_PROXY":    strings.Join(noProxy, ","),
+		} {
+			previous, set := os.LookupEnv(name)
+			Expect(os.Setenv(name, value)).To(Succeed())
+			DeferCleanup(func() {
+				if set {
+					Expect(os.Setenv(name, previous)).To(Succeed())
+					return
+				}
+				Expect(os.Unsetenv(name)).To(Succeed())
+			})
+		}
+
+		offlineChartRepo = server.URL()
+		InstallCertManager()
+		InstallKubewarden(k, kubewardenNS, "")
+		InstallBackupOperator(k, backupRestoreVersion)
+	})
+
+	It("Fetch all the charts from the offline repository", func() {
+		out, err := kubectl.RunHelmBinaryWithOutput("repo", "list", "-o", "json")
+		Expect(err).To(Not(HaveOccurred()))
+
+		if dryrun.Enabled() {
+			return
+		}
+
+		var repos []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		}
+		err = json.Unmarshal([]byte(out), &repos)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Repositories of the install helpers all point to the offline one
+		for _, repo := range repos {
+			if slices.Contains([]string{"jetstack", "rancher-chart", kubewardenFlavors[kubewardenFlavor].RepoName}, repo.Name) {
+				Expect(repo.URL).To(Equal(server.URL()), "repository %s", repo.Name)
+			}
+		}
+
+		for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults", "cert-manager", "rancher-backup-crd", "rancher-backup"} {
+			Expect(server.Served(chart)).To(BeTrue(), "chart %s not fetched from %s, requests: %v", chart, server.URL(), server.Requests())
+		}
+	})
+})
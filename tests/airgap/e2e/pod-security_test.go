@@ -0,0 +1,116 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/events"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Install Kubewarden in a restricted namespace", Label("pod-security", "full"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	psaLabels := []string{
+		"pod-security.kubernetes.io/enforce=restricted",
+		"pod-security.kubernetes.io/enforce-version=latest",
+	}
+
+	// Pod creations refused by Pod Security Admission, reported by the ReplicaSets and Jobs
+	getViolations := func() string {
+		list, _ := events.For("", "", kubewardenNS)
+		return events.Format(slices.DeleteFunc(list, func(e events.Event) bool { return e.Reason != "FailedCreate" }))
+	}
+
+	// The labels are kept until the end of the container, so Kubewarden is installed under them
+	BeforeAll(func() {
+		if _, err := kubectl.RunWithoutErr("get", "namespace", kubewardenNS); err != nil {
+			_, err := kubectl.RunWithoutErr("create", "namespace", kubewardenNS)
+			Expect(err).To(Not(HaveOccurred()))
+		}
+
+		// The server warns about the already running pods that would be refused
+		out, err := kubectl.RunWithoutErr(append([]string{"label", "--dry-run=server", "--overwrite", "namespace", kubewardenNS}, psaLabels...)...)
+		Expect(err).To(Not(HaveOccurred()))
+		if strings.Contains(out, "Warning") {
+			AddReportEntry("pod-security warnings", out)
+		}
+
+		_, err = kubectl.RunWithoutErr(append([]string{"label", "--overwrite", "namespace", kubewardenNS}, psaLabels...)...)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Other tests install Kubewarden in the namespace without these labels
+		DeferCleanup(func() {
+			args := []string{"label", "namespace", kubewardenNS}
+			for _, l := range psaLabels {
+				key, _, _ := strings.Cut(l, "=")
+				args = append(args, key+"-")
+			}
+			_, err := kubectl.RunWithoutErr(args...)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Install Kubewarden", func() {
+		InstallKubewarden(k, kubewardenNS, "")
+	})
+
+	It("Start all the components", func() {
+		// Pods created before the labels are only checked when they are recreated
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment", "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+
+		_, err = kubectl.RunWithoutErr("rollout", "status", "deployment", "--namespace", kubewardenNS,
+			fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+		Expect(err).To(Not(HaveOccurred()), "refused pods:\n%s", getViolations())
+	})
+
+	It("Run the audit scanner", func() {
+		out, err := kubectl.RunWithoutErr("get", "cronjobs", "--namespace", kubewardenNS, "-o", "name")
+		Expect(err).To(Not(HaveOccurred()))
+		if out == "" {
+			Skip("audit scanner is not installed")
+		}
+
+		for _, cronjob := range strings.Fields(out) {
+			job := UniqueName("audit-scanner")
+			_, err := kubectl.RunWithoutErr("create", "job", job, "--namespace", kubewardenNS, "--from", cronjob)
+			Expect(err).To(Not(HaveOccurred()))
+			DeferCleanup(kubectl.RunWithoutErr, "delete", "job", job, "--namespace", kubewardenNS, "--ignore-not-found")
+
+			_, err = kubectl.RunWithoutErr("wait", "job/"+job, "--namespace", kubewardenNS, "--for=condition=Complete",
+				fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+			Expect(err).To(Not(HaveOccurred()), "refused pods:\n%s", getViolations())
+		}
+	})
+
+	It("Check that recommended policies are active", func(ctx SpecContext) {
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+		Expect(CheckPoliciesActive()).To(Succeed())
+	})
+})
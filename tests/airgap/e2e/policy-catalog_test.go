@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// Policy of the pinned catalog, settings are only needed when the defaults are not valid
+type catalogPolicy struct {
+	Name     string         `json:"name"`
+	Module   string         `json:"module"`
+	Settings map[string]any `json:"settings,omitempty"`
+}
+
+// NOTE: failures are early warnings of a catalog breakage, the catalog is refreshed with scripts/update-policy-catalog
+var _ = Describe("E2E - Load all the policies of the Kubewarden catalog", Label("policy-catalog", "nightly"), Serial, func() {
+	It("Deploy each policy of the catalog in monitor mode", func(ctx SpecContext) {
+		var catalog struct {
+			Policies []catalogPolicy `json:"policies"`
+		}
+
+		data, err := os.ReadFile(policyCatalogJson)
+		Expect(err).To(Not(HaveOccurred()))
+		err = json.Unmarshal(data, &catalog)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(catalog.Policies).To(Not(BeEmpty()))
+
+		serverName := UniqueName("catalog-server")
+
+		By("Creating a scratch policy server", func() {
+			// Same image as the default policy server
+			image, err := kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+			Expect(err).To(Not(HaveOccurred()))
+
+			file := CopyYaml(policyCatalogServerYaml, map[string]string{
+				"%POLICY_SERVER_IMAGE%": image,
+				"catalog-server":        serverName,
+			})
+			err = kubectl.Apply("", file)
+			Expect(err).To(Not(HaveOccurred()))
+
+			DeferCleanup(func() {
+				_, err := kubectl.RunWithoutErr("delete", "policyserver", serverName, "--ignore-not-found", "--wait")
+				Expect(err).To(Not(HaveOccurred()))
+			})
+
+			// Deployment created by the controller for the policy server
+			WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-"+serverName)
+		})
+
+		// Policy name => reason of the failure
+		failed := map[string]string{}
+
+		for _, p := range catalog.Policies {
+			By("Loading "+p.Module, func() {
+				name := UniqueName("catalog-" + p.Name)
+
+				// Settings are inlined as JSON, which is valid YAML
+				settings, err := json.Marshal(p.Settings)
+				Expect(err).To(Not(HaveOccurred()))
+				if p.Settings == nil {
+					settings = []byte("{}")
+				}
+
+				file := CopyYaml(policyCatalogPolicyYaml, map[string]string{
+					"%NAME%":          name,
+					"%MODULE%":        p.Module,
+					"%POLICY_SERVER%": serverName,
+					"%SETTINGS%":      string(settings),
+				})
+				err = kubectl.Apply("", file)
+				Expect(err).To(Not(HaveOccurred()))
+
+				// One policy at a time, so a broken one does not prevent the others to be loaded
+				defer func() {
+					_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", name, "--ignore-not-found", "--wait")
+					Expect(err).To(Not(HaveOccurred()))
+				}()
+
+				if dryrun.Enabled() {
+					dryrun.Record("wait up to %s for policy %s to be active", timeouts.For(timeouts.Rollout), name)
+					return
+				}
+
+				// Not WaitForPolicyActive, the other policies still have to be checked
+				err = wait.For(ctx, wait.Match(func() string {
+					out, _ := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", name,
+						"-o", "jsonpath={.status.policyStatus}")
+					return out
+				}, Equal("active")), wait.Options{Class: timeouts.Rollout, Description: "policy " + name + " to be active"})
+				if err != nil {
+					// The policy server reports why the module cannot be loaded
+					out, _ := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", name,
+						"-o", "jsonpath={.status.conditions[*].message}")
+					failed[p.Name] = strings.TrimSpace(p.Module + " " + out)
+				}
+			})
+		}
+
+		var report strings.Builder
+		for _, p := range catalog.Policies {
+			status := "loaded"
+			if reason, ok := failed[p.Name]; ok {
+				status = "FAILED: " + reason
+			}
+			fmt.Fprintf(&report, "%s: %s\n", p.Name, status)
+		}
+		AddReportEntry("policy catalog", report.String())
+
+		Expect(failed).To(BeEmpty(), "%d of %d catalog policies cannot be loaded", len(failed), len(catalog.Policies))
+	})
+})
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"context"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Test Backup/Restore of policy groups", Label("test-policy-group-backup-restore", "full"), Serial, func() {
+	backupName := UniqueName("kubewarden-group-backup")
+	groupName := UniqueName("group-privileged-pods")
+	restoreName := UniqueName("kubewarden-group-restore")
+
+	// Policy groups have the same status as policies
+	waitForGroupActive := func(ctx context.Context) {
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "clusteradmissionpolicygroup", groupName,
+				"-o", "jsonpath={.status.policyStatus}")
+			return out
+		}, Equal("active")), wait.Options{Class: timeouts.Rollout, Description: "policy group " + groupName + " to be active"})
+	}
+
+	BeforeEach(func() {
+		RequireKubewardenFeature("policy-groups")
+	})
+
+	It("Restore a deleted policy group", func(ctx SpecContext) {
+		By("Adding a policy group", func() {
+			group := CopyYaml(policyGroupYaml, map[string]string{"group-privileged-pods": groupName})
+			err := kubectl.Apply(clusterNS, group)
+			Expect(err).To(Not(HaveOccurred()))
+
+			waitForGroupActive(ctx)
+		})
+
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+
+			// Wait for backup to be done
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Deleting the policy group", func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicygroup", groupName)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(restoreName, GetBackupFile(backupName), false)
+
+			// Wait for restore to be done
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that the policy group is active again", func() {
+			waitForGroupActive(ctx)
+		})
+	})
+})
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/policyreport"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: Kubewarden is reinstalled with the default values at the end
+var _ = Describe("E2E - Update the PolicyReports after a remediation", Label("policy-report", "full"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	ns := UniqueName("remediation")
+	deployment := UniqueName("remediated-app")
+	pinnedPolicy := UniqueName("pinned-images")
+	privilegedPolicy := UniqueName("no-privileged")
+
+	// Not pulled, there is no replica
+	const image = "rancher/pause:3.2"
+
+	var pinnedImage string
+
+	applyDeployment := func(image string, privileged bool) {
+		file := CopyYaml(remediationDeployYaml, map[string]string{
+			"%NAME%":       deployment,
+			"%NAMESPACE%":  ns,
+			"%IMAGE%":      image,
+			"%PRIVILEGED%": strconv.FormatBool(privileged),
+		})
+		// Accepted in monitor mode, even with violations
+		out, err := kubectl.Run("apply", "-f", file)
+		Expect(err).To(Not(HaveOccurred()), out)
+	}
+
+	// Results of both policies for the Deployment, after the next scan
+	waitForResults := func(ctx SpecContext, pinned, privileged string) {
+		WaitForAuditScan(ctx, time.Now())
+		WaitFor(ctx, wait.Check(func() error {
+			results, err := policyreport.Results(ns)
+			if err != nil {
+				return err
+			}
+			got := [2]string{
+				policyreport.Find(results, "Deployment", deployment, pinnedPolicy),
+				policyreport.Find(results, "Deployment", deployment, privilegedPolicy),
+			}
+			if got != [2]string{pinned, privileged} {
+				return fmt.Errorf("results of %s are %q and %q, %q and %q expected", deployment, got[0], got[1], pinned, privileged)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "results of " + deployment + " in the PolicyReport"})
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", pinnedPolicy, privilegedPolicy, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+			// Other tests expect the default values
+			InstallKubewarden(k, kubewardenNS, "")
+		})
+	})
+
+	It("Report the violations of a Deployment", func(ctx SpecContext) {
+		// Scans every minute, and the recommended policies do not reject the privileged Deployment
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().
+			RecommendedPolicies(true, "monitor").
+			AuditScanner(true, "*/1 * * * *"))
+
+		file := CopyYaml(remediationPoliciesYaml, map[string]string{
+			"%PINNED_POLICY%":     pinnedPolicy,
+			"%PRIVILEGED_POLICY%": privilegedPolicy,
+			"%NAMESPACE%":         ns,
+		})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, pinnedPolicy)
+		WaitForPolicyActive(ctx, localCluster, privilegedPolicy)
+
+		applyDeployment(image, true)
+		waitForResults(ctx, "fail", "fail")
+	})
+
+	It("Pass the image policy once the image is pinned", func(ctx SpecContext) {
+		// Digest of an image already running, a valid reference is enough
+		imageID, err := kubectl.RunWithoutErr("get", "pods", "--namespace", kubewardenNS, "-l", "app.kubernetes.io/name=kubewarden-controller",
+			"-o", "jsonpath={.items[0].status.containerStatuses[0].imageID}")
+		Expect(err).To(Not(HaveOccurred()))
+		_, digest, _ := strings.Cut(imageID, "@")
+
+		pinnedImage = image + "@" + cmp.Or(digest, "sha256:"+strings.Repeat("0", 64))
+
+		applyDeployment(pinnedImage, true)
+		waitForResults(ctx, "pass", "fail")
+	})
+
+	It("Pass both policies once privileged is removed", func(ctx SpecContext) {
+		applyDeployment(pinnedImage, false)
+		waitForResults(ctx, "pass", "pass")
+	})
+})
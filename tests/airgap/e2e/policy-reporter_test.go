@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/policyreport"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/policyreporter"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/portforward"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: policy-reporter is removed and Kubewarden is reinstalled with the default values at the end
+var _ = Describe("E2E - Aggregate the PolicyReports of Kubewarden with policy-reporter", Label("policy-reporter", "full"), Ordered, Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	ns := UniqueName("policy-reporter")
+	pinnedPolicy := UniqueName("pinned-images")
+	privilegedPolicy := UniqueName("no-privileged")
+
+	// Never pulled, there is no replica
+	const image = "rancher/pause:3.2"
+	pinnedImage := image + "@sha256:" + strings.Repeat("0", 64)
+
+	// Deployments with the policies they violate
+	deployments := []struct {
+		name       string
+		image      string
+		privileged bool
+	}{
+		{UniqueName("compliant-app"), pinnedImage, false},
+		{UniqueName("unpinned-app"), image, false},
+		{UniqueName("violating-app"), image, true},
+	}
+
+	// Severities are set by the annotations of remediation-policies.yaml
+	expected := map[policyreport.Key]int{
+		{Policy: pinnedPolicy, Severity: "medium", Result: "pass"}:   1,
+		{Policy: pinnedPolicy, Severity: "medium", Result: "fail"}:   2,
+		{Policy: privilegedPolicy, Severity: "high", Result: "pass"}: 2,
+		{Policy: privilegedPolicy, Severity: "high", Result: "fail"}: 1,
+	}
+
+	// Counts of the tested policies only, the audit scanner prefixes the cluster-wide ones
+	count := func(results []policyreport.Result) map[policyreport.Key]int {
+		var ours []policyreport.Result
+		for _, r := range results {
+			for _, policy := range []string{pinnedPolicy, privilegedPolicy} {
+				if strings.HasSuffix(r.Policy, policy) {
+					r.Policy = policy
+					ours = append(ours, r)
+				}
+			}
+		}
+		return policyreport.Count(ours)
+	}
+
+	checkCounts := func(source string, results []policyreport.Result) error {
+		if got := count(results); !maps.Equal(got, expected) {
+			return fmt.Errorf("results counted by %s are %v, %v expected", source, got, expected)
+		}
+		return nil
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", pinnedPolicy, privilegedPolicy, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+			RemovePolicyReporter()
+			// Other tests expect the default values
+			InstallKubewarden(k, kubewardenNS, "")
+		})
+	})
+
+	It("Install policy-reporter next to Kubewarden", func(ctx SpecContext) {
+		InstallPolicyReporter(k)
+
+		// Scans every minute, and the recommended policies do not reject the privileged Deployment
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().
+			RecommendedPolicies(true, "monitor").
+			AuditScanner(true, "*/1 * * * *"))
+
+		file := CopyYaml(remediationPoliciesYaml, map[string]string{
+			"%PINNED_POLICY%":     pinnedPolicy,
+			"%PRIVILEGED_POLICY%": privilegedPolicy,
+			"%NAMESPACE%":         ns,
+		})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, pinnedPolicy)
+		WaitForPolicyActive(ctx, localCluster, privilegedPolicy)
+	})
+
+	It("Report the results of the audited Deployments", func(ctx SpecContext) {
+		for _, d := range deployments {
+			file := CopyYaml(remediationDeployYaml, map[string]string{
+				"%NAME%":       d.name,
+				"%NAMESPACE%":  ns,
+				"%IMAGE%":      d.image,
+				"%PRIVILEGED%": strconv.FormatBool(d.privileged),
+			})
+			// Accepted in monitor mode, even with violations
+			out, err := kubectl.Run("apply", "-f", file)
+			Expect(err).To(Not(HaveOccurred()), out)
+		}
+
+		WaitForAuditScan(ctx, time.Now())
+		WaitFor(ctx, wait.Check(func() error {
+			results, err := policyreport.Results(ns)
+			if err != nil {
+				return err
+			}
+			return checkCounts("the PolicyReports", results)
+		}), wait.Options{Class: timeouts.Rollout, Description: "results of the PolicyReports of " + ns})
+	})
+
+	It("Aggregate the same counts in the API of policy-reporter", func(ctx SpecContext) {
+		f, err := portforward.Start(ctx, "policy-reporter", "svc/policy-reporter", 8080)
+		Expect(err).To(Not(HaveOccurred()))
+		DeferCleanup(f.Stop)
+
+		// policy-reporter watches the reports, they are not aggregated immediately
+		WaitFor(ctx, wait.Check(func() error {
+			results, err := policyreporter.Results(f.URL("http"), ns)
+			if err != nil {
+				return err
+			}
+			return checkCounts("policy-reporter", results)
+		}), wait.Options{Class: timeouts.Rollout, Description: "results of policy-reporter for " + ns})
+	})
+})
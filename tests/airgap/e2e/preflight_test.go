@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/download"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("E2E - Preflight checks of the test host", Label("preflight"), func() {
+	airgap := os.Getenv("PREFLIGHT_AIRGAP") == "true"
+
+	// Fail once with all the problems found, so everything can be fixed in one go
+	failOnProblems := func(problems []string) {
+		if len(problems) > 0 {
+			Fail("Preflight checks failed:\n  - " + strings.Join(problems, "\n  - "))
+		}
+	}
+
+	It("Check that sudo can be used without password", func() {
+		_, err := runner.Run("sudo", "-n", "true")
+		Expect(err).To(Not(HaveOccurred()),
+			"sudo is needed to install K3s and read backup files, configure NOPASSWD for the current user")
+	})
+
+	It("Check that required binaries are available", func() {
+		binaries := []string{"curl", "helm", "kubectl"}
+		if backupS3Bucket != "" || backupMinIO {
+			binaries = append(binaries, "aws")
+		}
+		if airgap {
+			binaries = append(binaries, "hauler", "qemu-img", "skopeo", "virsh", "virt-install", "yq")
+		}
+
+		var problems []string
+		for _, bin := range binaries {
+			if _, err := exec.LookPath(bin); err != nil {
+				problems = append(problems, fmt.Sprintf("%s not found in PATH, install it or add its directory to PATH", bin))
+			}
+		}
+
+		failOnProblems(problems)
+	})
+
+	It("Check that there is enough free disk space", func() {
+		minGB := uint64(20)
+		if v := os.Getenv("PREFLIGHT_MIN_DISK_GB"); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			Expect(err).To(Not(HaveOccurred()), "PREFLIGHT_MIN_DISK_GB must be a number of GB")
+			minGB = n
+		}
+
+		// K3s images and backup files are stored in /var/lib, airgap archives in HOME
+		var problems []string
+		for _, dir := range []string{os.Getenv("HOME"), "/var/lib"} {
+			var fs syscall.Statfs_t
+			if err := syscall.Statfs(dir, &fs); err != nil {
+				problems = append(problems, fmt.Sprintf("cannot get free space of %s: %v", dir, err))
+				continue
+			}
+
+			freeGB := fs.Bavail * uint64(fs.Bsize) / (1 << 30)
+			GinkgoWriter.Printf("Free space in %s: %dGB\n", dir, freeGB)
+			if freeGB < minGB {
+				problems = append(problems, fmt.Sprintf("only %dGB free in %s, at least %dGB are needed (see PREFLIGHT_MIN_DISK_GB)", freeGB, dir, minGB))
+			}
+		}
+
+		failOnProblems(problems)
+	})
+
+	It("Check that artifacts can be downloaded", func() {
+		var problems []string
+
+		if airgap {
+			// Everything should already be on the host
+			for _, file := range []string{os.Getenv("HOME") + "/rancher-image.qcow2", netDefaultFileName, airgapBuildScript} {
+				if _, err := os.Stat(file); err != nil {
+					problems = append(problems, fmt.Sprintf("%s is missing, it is needed to create the airgap environment", file))
+				}
+			}
+		} else {
+			urls := []string{"https://get.k3s.io", "https://charts.kubewarden.io/index.yaml", "https://charts.rancher.io/index.yaml", "https://ghcr.io/v2/"}
+			if download.Offline() {
+				// The install script of K3s can only come from the pre-seeded cache
+				urls = urls[1:]
+				if _, err := os.Stat(filepath.Join(download.Dir(), download.K3sScript("").Name)); err != nil {
+					problems = append(problems, fmt.Sprintf("install script of K3s not in %s, fill the cache with make cache", download.Dir()))
+				}
+			}
+
+			client := &http.Client{Timeout: 15 * time.Second}
+			for _, url := range urls {
+				// Any HTTP answer is fine, even 401 from registries
+				resp, err := client.Head(url)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s is not reachable (%v), check the proxy/firewall or use a mirror", url, err))
+					continue
+				}
+				resp.Body.Close()
+			}
+		}
+
+		failOnProblems(problems)
+	})
+})
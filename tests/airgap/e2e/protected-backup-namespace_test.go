@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/events"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: BACKUP_IMAGES_SIGNER can be set to the GitHub owner signing the operator images
+var _ = Describe("E2E - Test Backup/Restore in a namespace protected by Kubewarden", Label("test-protected-backup-namespace", "full"), Ordered, Serial, func() {
+	const backupNS = "cattle-resources-system"
+
+	backupName := UniqueName("kubewarden-protected-backup")
+	restoreName := UniqueName("kubewarden-protected-restore")
+	policies := []string{"backup-deny-privileged-pods", "backup-signed-images"}
+
+	// Namespaced policies are not handled by WaitForPolicyActive
+	waitForPoliciesActive := func(ctx context.Context) {
+		for _, policy := range policies {
+			WaitFor(ctx, wait.Match(func() string {
+				out, _ := kubectl.RunWithoutErr("get", "admissionpolicy", policy, "--namespace", backupNS,
+					"-o", "jsonpath={.status.policyStatus}")
+				return out
+			}, Equal("active")), wait.Options{Class: timeouts.Rollout, Description: "policy " + policy + " to be active"})
+		}
+	}
+
+	BeforeAll(func() {
+		// Policies are kept for all the specs, they would block the next tests using this namespace
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "admissionpolicies", "--namespace", backupNS,
+				"-l", runLabel+"="+GetRunID(), "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Protect the backup namespace with Kubewarden policies", func(ctx SpecContext) {
+		file := CopyYaml(backupNSPoliciesYaml, map[string]string{
+			"%SIGNER%": cmp.Or(os.Getenv("BACKUP_IMAGES_SIGNER"), "rancher"),
+		})
+		err := kubectl.Apply(backupNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		waitForPoliciesActive(ctx)
+	})
+
+	It("Deny privileged pods in the backup namespace", func(ctx SpecContext) {
+		out, err := kubectl.Run("run", UniqueName("backup-privileged-pod"), "--namespace", backupNS,
+			"--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+
+		By("Checking that pods of controllers are denied too", func() {
+			deployment := UniqueName("backup-privileged-deployment")
+			_, err := kubectl.RunWithoutErr("create", "deployment", deployment, "--namespace", backupNS, "--image=rancher/pause:3.2")
+			Expect(err).To(Not(HaveOccurred()))
+			DeferCleanup(kubectl.RunWithoutErr, "delete", "deployment", deployment, "--namespace", backupNS, "--ignore-not-found")
+
+			_, err = kubectl.RunWithoutErr("patch", "deployment", deployment, "--namespace", backupNS, "--type", "json",
+				"-p", `[{"op": "add", "path": "/spec/template/spec/containers/0/securityContext", "value": {"privileged": true}}]`)
+			Expect(err).To(Not(HaveOccurred()))
+
+			// The denial is only visible in the ReplicaSet events
+			WaitForEvent(ctx, "ReplicaSet", "", backupNS, events.HaveEventWithMessage("FailedCreate", "backup-deny-privileged-pods"))
+		})
+	})
+
+	It("Restart the backup operator under the policies", func() {
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment", "--namespace", backupNS,
+			"-l", "app.kubernetes.io/name=rancher-backup")
+		Expect(err).To(Not(HaveOccurred()))
+
+		_, err = kubectl.RunWithoutErr("rollout", "status", "deployment", "--namespace", backupNS,
+			"-l", "app.kubernetes.io/name=rancher-backup", fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Refused pods are reported in the ReplicaSet events, only the test deployment is denied
+		list, err := events.For("ReplicaSet", "", backupNS)
+		Expect(err).To(Not(HaveOccurred()))
+		list = slices.DeleteFunc(list, func(e events.Event) bool {
+			return strings.Contains(e.InvolvedObject.Name, "backup-privileged-deployment")
+		})
+		Expect(list).To(Not(events.HaveEvent("FailedCreate")))
+
+		// Images have been pinned by the signature policy
+		images := GetPodImages(backupNS)
+		Expect(images).To(Not(BeEmpty()))
+		for _, image := range images {
+			Expect(image).To(ContainSubstring("@sha256:"))
+		}
+	})
+
+	It("Backup and restore Kubewarden", func(ctx SpecContext) {
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(restoreName, GetBackupFile(backupName), false)
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that all the policies are still active", func() {
+			waitForPoliciesActive(ctx)
+			Expect(CheckPoliciesActive()).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: K3s configuration is changed during the test, and restored at the end
+var _ = Describe("E2E - Pull Kubewarden through a pull-through cache", Label("pull-through-cache", "full"), Ordered, Serial, func() {
+	const (
+		cacheNS          = "registry-cache"
+		registriesFile   = "/etc/rancher/k3s/registries.yaml"
+		noDefaultDropIn  = "/etc/rancher/k3s/config.yaml.d/99-e2e-registry-cache.yaml"
+		registriesBackup = registriesFile + ".e2e-backup"
+	)
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Kubewarden images and policies are all in ghcr.io
+	upstream := cmp.Or(os.Getenv("PULL_CACHE_UPSTREAM"), "ghcr.io")
+
+	var cache string
+
+	// Pods created after this point pull their images again
+	restartKubewarden := func() {
+		_, err := kubectl.RunWithoutErr("rollout", "restart", "deployment", "--namespace", kubewardenNS)
+		Expect(err).To(Not(HaveOccurred()))
+
+		_, err = kubectl.RunWithoutErr("rollout", "status", "deployment", "--namespace", kubewardenNS,
+			fmt.Sprintf("--timeout=%s", timeouts.For(timeouts.Rollout)))
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	// The cache is used by all the specs, cleanups are run in reverse order once they are done
+	BeforeAll(func() {
+		// Other tests expect the default values
+		DeferCleanup(func() {
+			InstallKubewarden(k, kubewardenNS, "")
+		})
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "namespace", cacheNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		// Upstream registry is used again before the cache is removed
+		DeferCleanup(func() {
+			_, err := k3sNode.WithSudo().Shell(fmt.Sprintf(`
+				rm -f %[1]s %[3]s
+				[ -f %[2]s ] && mv %[2]s %[1]s || true`, registriesFile, registriesBackup, noDefaultDropIn))
+			Expect(err).To(Not(HaveOccurred()))
+
+			RestartK3s(k3sNode, k)
+		})
+	})
+
+	It("Deploy the pull-through cache", func(ctx SpecContext) {
+		file := CopyYaml(pullCacheYaml, map[string]string{"%UPSTREAM%": "https://" + upstream})
+		err := kubectl.Apply(cacheNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitForDeploymentReady(ctx, localCluster, cacheNS, "registry-cache")
+
+		// Node port, reachable from containerd and from the policy servers
+		cache = GetNodeIP() + ":30500"
+	})
+
+	It("Configure K3s to pull only through the cache", func() {
+		// Without the default endpoint, containerd cannot fall back to the upstream registry
+		_, err := k3sNode.WithSudo().Shell(fmt.Sprintf(`
+			[ -f %[1]s ] && cp -a %[1]s %[2]s
+			mkdir -p $(dirname %[3]s)
+			echo 'disable-default-registry-endpoint: true' > %[3]s
+			cat > %[1]s <<EOF
+mirrors:
+  "%[4]s":
+    endpoint:
+      - "http://%[5]s"
+EOF`, registriesFile, registriesBackup, noDefaultDropIn, upstream, cache))
+		Expect(err).To(Not(HaveOccurred()))
+
+		RestartK3s(k3sNode, k)
+	})
+
+	It("Warm up the cache", func(ctx SpecContext) {
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().PullAlways().PoliciesRegistry(cache, true))
+
+		restartKubewarden()
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+
+		By("Checking that images and policies went through the cache", func() {
+			out, err := kubectl.RunWithoutErr("logs", "deployment/registry-cache", "--namespace", cacheNS)
+			Expect(err).To(Not(HaveOccurred()))
+
+			Expect(out).To(ContainSubstring("/v2/kubewarden/kubewarden-controller/manifests/"))
+			Expect(out).To(ContainSubstring("/v2/kubewarden/policy-server/manifests/"))
+			Expect(out).To(ContainSubstring("/v2/kubewarden/policies/"))
+		})
+	})
+
+	It("Pull everything from the cache without upstream access", func(ctx SpecContext) {
+		file := CopyYaml(pullCacheOfflineYaml, nil)
+		err := kubectl.Apply(cacheNS, file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// Images and policies are pulled again, the cache is the only source
+		restartKubewarden()
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+		Expect(CheckPoliciesActive()).To(Succeed())
+	})
+})
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/backup"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/rancherapi"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: BACKUP_S3_* and AWS_* variables have to be set, the backup has to survive the cluster wipe
+var _ = Describe("E2E - Test Backup/Restore of Rancher and Kubewarden together", Label("test-rancher-backup-restore", "nightly"), Serial, func() {
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	backupName := UniqueName(backupResourceName)
+	restoreName := UniqueName(restoreResourceName)
+	// Rancher resource created before the backup, to check that Rancher data is restored
+	roleName := UniqueName("e2e-global-role")
+	// Charts re-create the recommended policies, only this one has to come from the backup
+	policyName := UniqueName("rancher-policy")
+
+	// Endpoints used by the Kubewarden UI extension, without a browser
+	checkUIExtensionAPI := func(ctx SpecContext) {
+		if dryrun.Enabled() {
+			dryrun.Record("check the Rancher API endpoints of the Kubewarden UI extension")
+			return
+		}
+
+		api := rancherapi.New("https://"+GetRancherHostname(), CreateRancherToken())
+
+		// Rancher lists the Helm releases as apps, with their status
+		WaitFor(ctx, wait.Check(func() error {
+			var app struct {
+				Status struct {
+					Summary struct {
+						State string `json:"state"`
+					} `json:"summary"`
+				} `json:"status"`
+			}
+			for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+				if err := api.Get("/v1/catalog.cattle.io.apps/"+kubewardenNS+"/"+chart, &app); err != nil {
+					return err
+				}
+				if app.Status.Summary.State != "deployed" {
+					return fmt.Errorf("app %s is %s", chart, app.Status.Summary.State)
+				}
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "Kubewarden apps in the Rancher API"})
+
+		// Policy server and policies pages
+		servers, err := api.IDs("policies.kubewarden.io.policyservers")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(servers).To(ContainElement("default"))
+		policies, err := api.IDs("policies.kubewarden.io.clusteradmissionpolicies")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(policies).To(ContainElement("do-not-run-as-root"))
+
+		// Summaries of the policy reports, proxied to the cluster
+		err = api.Get("/k8s/clusters/local/apis/wgpolicyk8s.io/v1alpha2/clusterpolicyreports", nil)
+		Expect(err).To(Not(HaveOccurred()))
+		err = api.Get("/k8s/clusters/local/apis/wgpolicyk8s.io/v1alpha2/policyreports", nil)
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	BeforeEach(func() {
+		if backupS3Bucket == "" {
+			Skip("BACKUP_S3_BUCKET is not defined")
+		}
+	})
+
+	It("Restore Rancher and Kubewarden after wiping the cluster", func(ctx SpecContext) {
+		var artifact *backup.Artifact
+
+		By("Installing Rancher Manager next to Kubewarden", func() {
+			InstallKubewarden(k, kubewardenNS, "")
+			InstallRancher(k)
+			ApplyBackupOnlyPolicy(policyName)
+		})
+
+		By("Checking the API of the Kubewarden UI extension", func() {
+			checkUIExtensionAPI(ctx)
+		})
+
+		By("Adding a Rancher global role", func() {
+			file := CopyYaml(rancherGlobalRoleYaml, map[string]string{"name: e2e-global-role": "name: " + roleName})
+			_, err := kubectl.RunWithoutErr("apply", "-f", file)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+
+			// Wait for backup to be done
+			WaitForReady(ctx, "backup", backupName)
+
+			artifact = backup.New(GetBackupFile(backupName), backup.Target{
+				Type:     backup.S3,
+				Location: "s3://" + strings.TrimSuffix(backupS3Bucket+"/"+backupS3Folder, "/"),
+				Endpoint: backupS3Endpoint,
+			})
+			err := artifact.Store(GinkgoT().TempDir())
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Wiping the cluster", func() {
+			UninstallK3s(k3sNode)
+		})
+
+		By("Provisioning a new cluster with the backup operator", func() {
+			InstallK3s(k3sNode)
+			ConfigureKubeconfig(k3sNode)
+			WaitForK3s(k)
+			InstallBackupOperator(k, backupRestoreVersion)
+		})
+
+		By("Adding a restore resource", func() {
+			err := artifact.VerifyRemote()
+			Expect(err).To(Not(HaveOccurred()))
+
+			ApplyRestore(restoreName, artifact.FileName, false)
+
+			// Wait for restore to be done
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that policies are restored", func() {
+			CheckBackupOnlyPolicyRestored(policyName)
+		})
+
+		// As in the Rancher migration procedure, charts are installed on top of the restored resources
+		By("Re-installing Rancher and Kubewarden", func() {
+			InstallRancher(k)
+			InstallKubewarden(k, kubewardenNS, "")
+		})
+
+		By("Checking that Rancher data is restored", func() {
+			_, err := kubectl.RunWithoutErr("get", "globalroles.management.cattle.io", roleName)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Checking that policies are enforced", func() {
+			CheckBackupOnlyPolicyEnforced(ctx, policyName)
+		})
+
+		By("Checking the API of the Kubewarden UI extension after the restore", func() {
+			checkUIExtensionAPI(ctx)
+		})
+	})
+})
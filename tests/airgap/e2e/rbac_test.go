@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/rbac"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: set UPDATE_GOLDEN=true to write the current permissions in the golden file
+var _ = Describe("E2E - Audit the permissions of the Kubewarden service accounts", Label("rbac", "full"), Ordered, func() {
+	// Cluster-wide permissions are the only ones in a namespace not managed by Kubewarden
+	scopes := map[string]string{
+		"namespace": kubewardenNS,
+		"cluster":   "default",
+	}
+
+	// Service account used by each component, taken from what is running
+	serviceAccounts := map[string]string{}
+
+	BeforeAll(func() {
+		for component, query := range map[string][]string{
+			"controller":    {"pods", "-l", "app.kubernetes.io/name=kubewarden-controller", "-o", "jsonpath={.items[0].spec.serviceAccountName}"},
+			"policy-server": {"pods", "-l", "app.kubernetes.io/name=policy-server", "-o", "jsonpath={.items[0].spec.serviceAccountName}"},
+			"audit-scanner": {"cronjobs", "-o", "jsonpath={.items[0].spec.jobTemplate.spec.template.spec.serviceAccountName}"},
+		} {
+			out, err := kubectl.RunWithoutErr(append([]string{"get", "--namespace", kubewardenNS}, query...)...)
+			if err != nil || out == "" {
+				GinkgoWriter.Printf("No service account found for %s\n", component)
+				continue
+			}
+			serviceAccounts[component] = strings.TrimSpace(out)
+		}
+		Expect(serviceAccounts).To(HaveKey("controller"))
+	})
+
+	It("Check that service accounts have no unexpected permission", func() {
+		actual := rbac.Golden{}
+		for component, sa := range serviceAccounts {
+			actual[component] = map[string][]string{}
+			for scope, ns := range scopes {
+				perms, err := rbac.Permissions(kubewardenNS, sa, ns)
+				Expect(err).To(Not(HaveOccurred()))
+				actual[component][scope] = perms
+			}
+		}
+
+		if os.Getenv("UPDATE_GOLDEN") == "true" {
+			Expect(actual.Write(rbacGoldenYaml)).To(Succeed())
+			AddReportEntry("golden file updated", rbacGoldenYaml)
+			return
+		}
+
+		golden, err := rbac.Load(rbacGoldenYaml)
+		Expect(err).To(Not(HaveOccurred()))
+
+		var unexpected []string
+		for component, sa := range serviceAccounts {
+			for scope := range scopes {
+				perms := actual[component][scope]
+				for _, p := range golden.Unexpected(component, scope, perms) {
+					unexpected = append(unexpected, component+" ("+sa+", "+scope+"): "+p)
+				}
+
+				if unused := golden.Unused(component, scope, perms); len(unused) > 0 {
+					AddReportEntry("unused golden permissions of "+component+" ("+scope+")", strings.Join(unused, "\n"))
+				}
+			}
+		}
+		Expect(unexpected).To(BeEmpty(), "permissions not in %s", rbacGoldenYaml)
+	})
+})
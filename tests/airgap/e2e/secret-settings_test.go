@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: the Secret is in the Kubewarden namespace, so it is in the backups of the Kubewarden resources
+var _ = Describe("E2E - Test policies using values of a Secret", Label("secret-settings", "full"), Ordered, Serial, func() {
+	policyName := UniqueName("secret-settings")
+	secretName := UniqueName("policy-token")
+	backupName := UniqueName("kubewarden-secret-backup")
+	restoreName := UniqueName("kubewarden-secret-restore")
+	ns := UniqueName("secret-settings")
+
+	// Tokens are compared with the Secret data, so in base64
+	setToken := func(token string) {
+		if _, err := kubectl.RunWithoutErr("get", "secret", secretName, "--namespace", kubewardenNS); err == nil {
+			_, err := kubectl.RunWithoutErr("patch", "secret", secretName, "--namespace", kubewardenNS,
+				"-p", fmt.Sprintf(`{"stringData": {"token": %q}}`, token))
+			Expect(err).To(Not(HaveOccurred()))
+			return
+		}
+
+		_, err := kubectl.RunWithoutErr("create", "secret", "generic", secretName, "--namespace", kubewardenNS,
+			"--from-literal", "token="+token)
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = kubectl.RunWithoutErr("label", "secret", secretName, "--namespace", kubewardenNS, runLabel+"="+GetRunID())
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	createPod := func(token string) (string, error) {
+		return kubectl.Run("run", UniqueName("token-pod"), "--namespace", ns, "--image=rancher/pause:3.2",
+			"--annotations", "e2e-token="+base64.StdEncoding.EncodeToString([]byte(token)))
+	}
+
+	// The Secret is watched by the policy server, changes are not immediate
+	waitForToken := func(ctx SpecContext, token string) {
+		WaitFor(ctx, wait.Check(func() error {
+			if out, err := createPod(token); err != nil {
+				return fmt.Errorf("pod with the current token denied: %s", out)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "token of " + secretName + " to be used"})
+	}
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "secret", secretName, "--namespace", kubewardenNS, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Validate pods with the token of a Secret", func(ctx SpecContext) {
+		setToken("first-token")
+
+		file := CopyYaml(secretSettingsPolicyYaml, map[string]string{
+			"%NAME%":      policyName,
+			"%NAMESPACE%": ns,
+			"%SECRET%":    secretName,
+			"%SECRET_NS%": kubewardenNS,
+		})
+		err := kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, policyName)
+
+		waitForToken(ctx, "first-token")
+
+		out, err := createPod("wrong-token")
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("does not match the token of the Secret"))
+	})
+
+	It("Rotate the token of the Secret", func(ctx SpecContext) {
+		setToken("rotated-token")
+		waitForToken(ctx, "rotated-token")
+
+		out, err := createPod("first-token")
+		Expect(err).To(HaveOccurred(), "pod with the old token allowed")
+		Expect(out).To(ContainSubstring("does not match the token of the Secret"))
+	})
+
+	It("Keep the Secret and the policy after a backup/restore", func(ctx SpecContext) {
+		By("Adding a backup resource", func() {
+			ApplyBackup(backupName)
+			WaitForReady(ctx, "backup", backupName)
+		})
+
+		By("Deleting the policy and the Secret", func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "secret", secretName, "--namespace", kubewardenNS)
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(restoreName, GetBackupFile(backupName), false)
+			WaitForReady(ctx, "restore", restoreName)
+		})
+
+		By("Checking that the rotated token is still used", func() {
+			_, err := kubectl.RunWithoutErr("get", "secret", secretName, "--namespace", kubewardenNS)
+			Expect(err).To(Not(HaveOccurred()), "secret %s not restored", secretName)
+
+			WaitForPolicyActive(ctx, localCluster, policyName)
+			waitForToken(ctx, "rotated-token")
+		})
+	})
+})
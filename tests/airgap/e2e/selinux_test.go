@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/selinux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// NOTE: run after the other suites, the denials of the whole boot of the node are checked
+var _ = Describe("E2E - Check Kubewarden on a node with SELinux enforcing", Label("selinux"), func() {
+	// Processes of Kubewarden and of the backup operator, the binary of the controller is /manager
+	comms := []string{"policy-server", "audit-scanner", "manager", "backup-restore-operator"}
+
+	BeforeEach(func() {
+		if !k3sSELinux {
+			Skip("K3S_SELINUX is not enabled")
+		}
+		if dryrun.Enabled() {
+			dryrun.Record("check the SELinux denials of %s", strings.Join(comms, ", "))
+			Skip("nothing to check in dry-run")
+		}
+	})
+
+	It("Run K3s with SELinux enforcing", func() {
+		out, err := k3sNode.Run("getenforce")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(strings.TrimSpace(out)).To(Equal("Enforcing"))
+
+		// Containers are confined only if K3s is started with its SELinux support
+		out, err = k3sNode.Shell("ps -eZ | grep k3s-server")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(out).To(ContainSubstring("container_runtime_t"), "K3s is not running with the k3s-selinux policy")
+	})
+
+	It("Generate no AVC denial for Kubewarden components", func() {
+		denials, err := selinux.Search(k3sNode, "this-boot")
+		Expect(err).To(Not(HaveOccurred()))
+		if len(denials) > 0 {
+			AddReportEntry("SELinux denials", selinux.Format(denials))
+		}
+
+		ours := selinux.Filter(denials, comms)
+		Expect(ours).To(BeEmpty(), "AVC denials of Kubewarden components:\n%s", selinux.Format(ours))
+	})
+})
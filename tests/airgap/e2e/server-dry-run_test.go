@@ -0,0 +1,129 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Check server-side dry-run and diff with policies", Label("server-dry-run", "full"), Ordered, func() {
+	validatingName := UniqueName("dry-run-validating")
+	mutatingName := UniqueName("dry-run-mutating")
+	ns := UniqueName("server-dry-run")
+
+	// Pod manifest, as a user would keep it in a file
+	podFile := func(name string, privileged bool) string {
+		container := map[string]any{"name": "pause", "image": "rancher/pause:3.2"}
+		if privileged {
+			container["securityContext"] = map[string]any{"privileged": true}
+		}
+		manifest, err := json.Marshal(map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]any{"name": name, "namespace": ns},
+			"spec":       map[string]any{"containers": []any{container}},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := filepath.Join(GetTempDir(), name+".json")
+		err = os.WriteFile(file, manifest, 0644)
+		Expect(err).To(Not(HaveOccurred()))
+		return file
+	}
+
+	// Dry-run requests must never be persisted
+	checkNotCreated := func(name string) {
+		_, err := kubectl.RunWithoutErr("get", "pod", name, "--namespace", ns)
+		Expect(err).To(HaveOccurred(), "pod %s created by a dry-run request", name)
+	}
+
+	BeforeAll(func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", validatingName, mutatingName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		file := CopyYaml(dryRunPoliciesYaml, map[string]string{
+			"%VALIDATING%": validatingName,
+			"%MUTATING%":   mutatingName,
+			"%NAMESPACE%":  ns,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, validatingName)
+		WaitForPolicyActive(ctx, localCluster, mutatingName)
+	})
+
+	It("Declare the webhooks without side effects", func() {
+		// The API server skips webhooks with side effects for dry-run requests
+		for kind, name := range map[string]string{
+			"validatingwebhookconfiguration": validatingName,
+			"mutatingwebhookconfiguration":   mutatingName,
+		} {
+			out, err := kubectl.RunWithoutErr("get", kind, "clusterwide-"+name, "-o", "jsonpath={.webhooks[*].sideEffects}")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(out).To(Equal("None"), "side effects of %s %s", kind, name)
+		}
+	})
+
+	It("Evaluate dry-run requests with the validating policy", func() {
+		name := UniqueName("dry-run-privileged")
+
+		out, err := kubectl.Run("create", "--filename", podFile(name, true), "--dry-run=server")
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring(fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, validatingName)))
+		checkNotCreated(name)
+	})
+
+	It("Return the mutation of dry-run requests", func() {
+		name := UniqueName("dry-run-mutated")
+
+		out, err := kubectl.RunWithoutErr("create", "--filename", podFile(name, false), "--dry-run=server",
+			"-o", "jsonpath={.spec.containers[0].securityContext.runAsUser}")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(out).To(Equal("1000"), "dry-run pod not mutated by %s", mutatingName)
+		checkNotCreated(name)
+	})
+
+	It("Do not show the mutation in kubectl diff", func() {
+		name := UniqueName("diff-pod")
+		file := podFile(name, false)
+
+		_, err := kubectl.RunWithoutErr("apply", "--filename", file)
+		Expect(err).To(Not(HaveOccurred()))
+
+		out, err := kubectl.RunWithoutErr("get", "pod", name, "--namespace", ns,
+			"-o", "jsonpath={.spec.containers[0].securityContext.runAsUser}")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(out).To(Equal("1000"))
+
+		// Exit code is 1 when there are differences, the mutated fields are not in the manifest
+		out, err = kubectl.Run("diff", "--filename", file)
+		Expect(err).To(Not(HaveOccurred()), "kubectl diff shows differences after a mutation:\n%s", out)
+	})
+})
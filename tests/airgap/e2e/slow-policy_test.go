@@ -0,0 +1,87 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Check the timeouts of slow policies", Label("slow-policy", "full"), Ordered, Serial, func() {
+	// Outcomes documented for the webhook timeout and failure policy
+	const (
+		allowed        = "allowed"
+		webhookTimeout = "rejected by the API server"
+		policyTimeout  = "rejected by the policy server"
+	)
+
+	ns := UniqueName("slow-policies")
+
+	BeforeAll(func() {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(kubectl.RunWithoutErr, "delete", "namespace", ns, "--ignore-not-found")
+	})
+
+	DescribeTable("Create a pod evaluated by a slow policy",
+		func(ctx SpecContext, sleep time.Duration, timeoutSeconds int, failurePolicy, outcome string) {
+			name := UniqueName("slow-policy")
+
+			file := CopyYaml(slowPolicyYaml, map[string]string{
+				"%NAME%":           name,
+				"%NAMESPACE%":      ns,
+				"%SLEEP%":          strconv.FormatInt(sleep.Milliseconds(), 10),
+				"%TIMEOUT%":        strconv.Itoa(timeoutSeconds),
+				"%FAILURE_POLICY%": failurePolicy,
+			})
+			err := kubectl.Apply("", file)
+			Expect(err).To(Not(HaveOccurred()))
+			DeferCleanup(kubectl.RunWithoutErr, "delete", "clusteradmissionpolicy", name, "--ignore-not-found", "--wait")
+
+			WaitForPolicyActive(ctx, localCluster, name)
+
+			// kubectl must get an answer, whatever the outcome
+			start := time.Now()
+			out, err := kubectl.Run("run", UniqueName("slow-pod"), "--namespace", ns, "--image=rancher/pause:3.2",
+				"--request-timeout=60s")
+			elapsed := time.Since(start)
+			GinkgoWriter.Printf("Answer after %s: %s\n", elapsed, out)
+
+			switch outcome {
+			case allowed:
+				Expect(err).To(Not(HaveOccurred()))
+			case webhookTimeout:
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring("failed calling webhook"))
+			case policyTimeout:
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring("denied the request"))
+			}
+			Expect(elapsed).To(BeNumerically("<", time.Duration(timeoutSeconds)*time.Second+10*time.Second))
+		},
+		Entry("faster than the webhook timeout", 500*time.Millisecond, 10, "Fail", allowed),
+		// The policy server interrupts evaluations after 2 seconds by default
+		Entry("slower than the webhook timeout, failing", 1500*time.Millisecond, 1, "Fail", webhookTimeout),
+		Entry("slower than the webhook timeout, ignored", 1500*time.Millisecond, 1, "Ignore", allowed),
+		Entry("slower than the policy timeout", 5*time.Second, 10, "Fail", policyTimeout),
+		Entry("slower than the policy timeout, ignored", 5*time.Second, 10, "Ignore", policyTimeout),
+	)
+})
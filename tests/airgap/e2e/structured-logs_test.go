@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/logs"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+var _ = Describe("E2E - Check the structured logs of the policy server", Label("structured-logs", "full"), Ordered, Serial, func() {
+	const policyServer = "deployment/policy-server-default"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	AfterAll(func() {
+		// Other tests expect the default values
+		InstallKubewarden(k, kubewardenNS, "")
+	})
+
+	It("Configure the policy server to log in JSON", func(ctx SpecContext) {
+		InstallKubewardenWithValues(k, kubewardenNS, "", KubewardenValues().
+			PolicyServerEnv("KUBEWARDEN_LOG_FMT", "json").
+			PolicyServerEnv("KUBEWARDEN_LOG_LEVEL", "info"))
+
+		WaitForDeploymentReady(ctx, localCluster, kubewardenNS, "policy-server-default")
+
+		WaitForPolicyActive(ctx, localCluster, "do-not-run-as-root")
+	})
+
+	It("Log the evaluation of a rejected request", func(ctx SpecContext) {
+		since := time.Now()
+		pod := UniqueName("logs-root-pod")
+
+		out, err := kubectl.Run("run", pod, "--image=rancher/pause:3.2",
+			"--overrides", `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+		Expect(err).To(HaveOccurred())
+		Expect(out).To(ContainSubstring("denied the request"))
+
+		if dryrun.Enabled() {
+			dryrun.Record("check the JSON logs of %s for the evaluation of pod %s", policyServer, pod)
+			return
+		}
+
+		// Fields of the validation span of the policy server
+		fields := map[string]string{
+			"policy_id": "clusterwide-do-not-run-as-root",
+			"kind":      "Pod",
+			"name":      pod,
+			"allowed":   "false",
+		}
+
+		var found []logs.Entry
+		WaitFor(ctx, wait.Check(func() error {
+			entries, err := logs.Get(kubewardenNS, policyServer, since)
+			if err != nil {
+				return err
+			}
+
+			if found = logs.Find(entries, fields); len(found) == 0 {
+				return fmt.Errorf("no evaluation of pod %s in %d log entries", pod, len(entries))
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "evaluation of pod " + pod + " to be logged"})
+
+		// Rejections can be correlated with the API server, e.g. with the audit log
+		Expect(found[0].Field("request_uid")).To(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`))
+	})
+})
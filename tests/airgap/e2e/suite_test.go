@@ -15,200 +15,2649 @@ limitations under the License.
 package e2e_test
 
 import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cabundle"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/chaos"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/download"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/events"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/guard"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/manifest"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/notify"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/portforward"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/rancherapi"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/reconcile"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/results"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/terminating"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/values"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/version"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
 	. "github.com/onsi/ginkgo/v2"
+	ginkgotypes "github.com/onsi/ginkgo/v2/types"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
 	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
 	"github.com/rancher-sandbox/ele-testhelpers/rancher"
 	"github.com/rancher-sandbox/ele-testhelpers/tools"
+	"golang.org/x/mod/semver"
 )
 
+// What to do with already installed components
 const (
-	airgapBuildScript   = "../scripts/build-airgap"
-	backupYaml          = "../assets/backup.yaml"
-	ciTokenYaml         = "../assets/local-kubeconfig-token-skel.yaml"
-	installConfigYaml   = "../../install-config.yaml"
-	localKubeconfigYaml = "../assets/local-kubeconfig-skel.yaml"
-	restoreYaml         = "../assets/restore.yaml"
-	upgradeSkelYaml     = "../assets/upgrade_skel.yaml"
-	userName            = "root"
-	userPassword        = "r0s@pwd1"
-	vmNameRoot          = "node"
+	installUpgrade = "upgrade"
+	installSkip    = "skip"
 )
 
+const (
+	airgapBuildScript        = "../scripts/build-airgap"
+	alertRulesYaml           = "../assets/alert-rules.yaml"
+	auditScannerCronJob      = "audit-scanner"
+	authRegistryYaml         = "../assets/auth-registry.yaml"
+	autoscalingYaml          = "../assets/autoscaling.yaml"
+	backupNSPoliciesYaml     = "../assets/backup-namespace-policies.yaml"
+	backupOnlyPolicyYaml     = "../assets/backup-only-policy.yaml"
+	backupYaml               = "../assets/backup.yaml"
+	brokenPolicyYaml         = "../assets/broken-policy.yaml"
+	ciTokenYaml              = "../assets/local-kubeconfig-token-skel.yaml"
+	dnsPoliciesYaml          = "../assets/dns-policies.yaml"
+	downstreamClusterYaml    = "../assets/downstream-cluster.yaml"
+	downstreamRepoYaml       = "../assets/downstream-chart-repo.yaml"
+	dryRunPoliciesYaml       = "../assets/dry-run-policies.yaml"
+	elementalPolicyYaml      = "../assets/elemental-policy.yaml"
+	externalSecretsYaml      = "../assets/external-secrets.yaml"
+	finalizerResourcesYaml   = "../assets/finalizer-resources.yaml"
+	fleetPolicyBundleYaml    = "../assets/fleet-policy-bundle.yaml"
+	installConfigYaml        = "../../install-config.yaml"
+	k3sAuditPolicyYaml       = "../assets/k3s-audit-policy.yaml"
+	largePolicyYaml          = "../assets/large-policy.yaml"
+	localKubeconfigYaml      = "../assets/local-kubeconfig-skel.yaml"
+	longhornSnapshotYaml     = "../assets/longhorn-snapshot.yaml"
+	machineRegistrationYaml  = "../assets/machine-registration.yaml"
+	multiAppBackupYaml       = "../assets/multi-app-backup.yaml"
+	multiAppCertsYaml        = "../assets/multi-app-certs.yaml"
+	mutablePoliciesYaml      = "../assets/mutable-policies.yaml"
+	networkPoliciesYaml      = "../assets/network-policies.yaml"
+	pendingPoliciesYaml      = "../assets/pending-policies.yaml"
+	policyCatalogJson        = "../assets/policy-catalog.json"
+	policyCatalogPolicyYaml  = "../assets/catalog-policy.yaml"
+	policyCatalogServerYaml  = "../assets/catalog-policy-server.yaml"
+	policyGroupYaml          = "../assets/policy-group.yaml"
+	pullCacheYaml            = "../assets/pull-through-cache.yaml"
+	pullCacheOfflineYaml     = "../assets/pull-through-cache-offline.yaml"
+	rancherGlobalRoleYaml    = "../assets/rancher-global-role.yaml"
+	rancherTokenYaml         = "../assets/rancher-token.yaml"
+	rbacGoldenYaml           = "../assets/golden/rbac.yaml"
+	reconciledGoldenDir      = "../assets/golden/reconciled"
+	remediationDeployYaml    = "../assets/remediation-deployment.yaml"
+	remediationPoliciesYaml  = "../assets/remediation-policies.yaml"
+	restoreYaml              = "../assets/restore.yaml"
+	scalePolicyYaml          = "../assets/scale-policy.yaml"
+	scheduledBackupYaml      = "../assets/scheduled-backup.yaml"
+	scratchRegistryYaml      = "../assets/scratch-registry.yaml"
+	secretSettingsPolicyYaml = "../assets/secret-settings-policy.yaml"
+	slowPolicyYaml           = "../assets/slow-policy.yaml"
+	tenantsYaml              = "../assets/tenants.yaml"
+	timeSkewPolicyYaml       = "../assets/time-skew-policy.yaml"
+	upgradePoliciesYaml      = "../assets/upgrade-policies.yaml"
+	upgradeSkelYaml          = "../assets/upgrade_skel.yaml"
+	userInfoPolicyYaml       = "../assets/user-info-policy.yaml"
+	k3sMarkerFile            = "/etc/rancher/k3s/.installed-by-e2e"
+	k3sAuditLog              = "/var/lib/rancher/k3s/server/logs/audit.log"
+	k3sDBDir                 = "/var/lib/rancher/k3s/server/db"
+	userName                 = "root"
+	userPassword             = "r0s@pwd1"
+	runLabel                 = "e2e-run"
+	flakyLabel               = "flaky"
+	vmNameRoot               = "node"
+)
+
+// Chart found in Helm repositories
+type helmChart struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	AppVersion string `json:"app_version"`
+}
+
+// Origin of the Kubewarden charts and images
+type flavor struct {
+	RepoName string
+	RepoURL  string
+	// Registry of the images, the one set in the charts if empty
+	Registry string
+}
+
+// S3 storage deployed in the cluster with MinIO
+type minioStore struct {
+	// Endpoint reachable from the pods and the test host, through a node port
+	Endpoint  string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// Secret with the endpoint and credentials, in the default namespace
+	Secret string
+}
+
+// Release deployed with Helm
+type helmRelease struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Revision   string `json:"revision"`
+	Chart      string `json:"chart"`
+	AppVersion string `json:"app_version"`
+	Status     string `json:"status"`
+}
+
 var (
+	airgapRegistry              string
+	airgapUpgrade               bool
 	auditScannerVersion         string
 	backupRestoreVersion        string
+	backupS3Bucket              string
+	backupS3Endpoint            string
+	backupS3Folder              string
+	backupMinIO                 bool
+	backupStorageClass          string
 	clusterNS                   string
 	kubewardenControllerVersion string
+	kubewardenFlavor            string
+	kubewardenNS                string
+	kubewardenPreviousVersion   string
+	kubewardenRegistry          string
 	policyServerVersion         string
+	installMode                 string
+	k3sAuditLogEnabled          bool
+	k3sSELinux                  bool
+	k3sIPFamily                 string
+	k3sExternalIP               string
+	offlineChartRepo            string
+	k3sNode                     *runner.Runner
 	k3sVersion                  string
+	localCluster                *cluster.Cluster
+	longhornVersion             string
 	netDefaultFileName          string
+	policyReporterVersion       string
+	rancherChannel              string
 	rancherHostname             string
+	rancherVersion              string
+
+	// Upstream charts use ghcr.io images, Prime ones are the SUSE builds
+	kubewardenFlavors = map[string]flavor{
+		"upstream": {RepoName: "kubewarden", RepoURL: "https://charts.kubewarden.io"},
+		"prime":    {RepoName: "rancher-chart", RepoURL: "https://charts.rancher.io", Registry: "registry.rancher.com"},
+	}
+
+	// CRDs of all the tested Kubewarden versions, policy groups are not in the older ones
+	kubewardenCRDs = []string{
+		"policyservers.policies.kubewarden.io",
+		"clusteradmissionpolicies.policies.kubewarden.io",
+		"admissionpolicies.policies.kubewarden.io",
+	}
+
+	// Minimal Kubewarden version of the tested features
+	kubewardenFeatures = map[string]string{
+		"policy-groups": "v1.17.0",
+	}
+
+	// Interval of the progress logs of the long waits
+	progressInterval = time.Minute
+
+	// Used to get unique resource names
+	nameCounter atomic.Int64
+	runID       string
+	runIDOnce   sync.Once
 )
 
-func CheckBackupRestore(v string) {
-	Eventually(func() string {
-		out, _ := kubectl.RunWithoutErr("logs", "-l app.kubernetes.io/name=rancher-backup",
-			"--tail=-1", "--since=5m",
-			"--namespace", "cattle-resources-system")
-		return out
-	}, tools.SetTimeout(5*time.Minute), 10*time.Second).Should(ContainSubstring(v))
+/*
+Wait for a condition
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param cond Condition to wait for
+  - @param opts Options of the wait
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitFor(ctx context.Context, cond wait.Condition, opts wait.Options) {
+	if dryrun.Enabled() {
+		timeout := opts.Timeout
+		if timeout == 0 {
+			timeout = timeouts.For(opts.Class)
+		}
+		dryrun.Record("wait up to %s for %s", timeout, opts.Description)
+		return
+	}
+
+	// Last status of the wait, shown in the progress reports of Ginkgo and logged regularly
+	var status atomic.Value
+	status.Store("waiting for " + opts.Description)
+	detach := AttachProgressReporter(func() string { return status.Load().(string) })
+	defer detach()
+
+	lastLog := time.Now()
+	opts.OnRetry = func(s string) {
+		status.Store(s)
+		if time.Since(lastLog) >= progressInterval {
+			GinkgoWriter.Printf("%s: %s\n", time.Now().Format(time.TimeOnly), s)
+			lastLog = time.Now()
+		}
+	}
+
+	err := wait.For(ctx, cond, opts)
+	Expect(err).To(Not(HaveOccurred()), "waiting for %s", opts.Description)
+}
+
+/*
+Get the ID of the current run, set on all created resources
+  - @remarks E2E_RUN_ID can be set to use a known ID, default is based on the random seed shared by all parallel processes
+  - @returns The run ID
+*/
+func GetRunID() string {
+	runIDOnce.Do(func() {
+		runID = os.Getenv("E2E_RUN_ID")
+		if runID == "" {
+			runID = strconv.FormatInt(GinkgoRandomSeed(), 36)
+		}
+	})
+
+	return runID
+}
+
+/*
+Get a resource name unique to the run, the spec and the parallel process
+  - @param prefix Prefix of the name
+  - @returns The unique name
+*/
+func UniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%s-p%d-%d", prefix, GetRunID(), GinkgoParallelProcess(), nameCounter.Add(1))
+}
+
+/*
+Allow a known-flaky container to be retried
+  - @remarks Retries are opt-in with E2E_FLAKE_ATTEMPTS, the specs passing on retry are listed at the end of the suite
+  - @remarks Evaluated when the tree is built, before BeforeSuite, so the variable is read here
+  - @returns Decorators of the container: the flaky label and the number of attempts
+*/
+func Flaky() []any {
+	attempts, err := strconv.Atoi(os.Getenv("E2E_FLAKE_ATTEMPTS"))
+	if err != nil || attempts < 1 {
+		attempts = 0
+	}
+
+	return []any{Label(flakyLabel), FlakeAttempts(attempts)}
+}
+
+/*
+Report the specs that only passed on retry
+  - @remarks They are written in a JSON file, set with FLAKE_REPORT, default is flaky-specs.json
+  - @param report Report of the suite
+  - @returns Nothing, an error is only displayed as the suite is already done
+*/
+func ReportFlakySpecs(report Report) {
+	type flakySpec struct {
+		Name     string   `json:"name"`
+		Labels   []string `json:"labels"`
+		Attempts int      `json:"attempts"`
+		Location string   `json:"location"`
+	}
+
+	var flakes []flakySpec
+	for _, spec := range report.SpecReports {
+		if spec.State.Is(ginkgotypes.SpecStatePassed) && spec.NumAttempts > 1 {
+			flakes = append(flakes, flakySpec{
+				Name:     spec.FullText(),
+				Labels:   spec.Labels(),
+				Attempts: spec.NumAttempts,
+				Location: spec.LeafNodeLocation.String(),
+			})
+		}
+	}
+	if len(flakes) == 0 {
+		return
+	}
+
+	fmt.Printf("\n### Specs that only passed on retry (quarantine candidates)\n")
+	for _, f := range flakes {
+		fmt.Printf("  - %s (%d attempts) at %s\n", f.Name, f.Attempts, f.Location)
+	}
+
+	data, err := json.MarshalIndent(flakes, "", "  ")
+	if err == nil {
+		err = os.WriteFile(cmp.Or(os.Getenv("FLAKE_REPORT"), "flaky-specs.json"), data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot write the report of flaky specs: %s\n", err)
+	}
+}
+
+/*
+Set the run label on an existing resource
+  - @remarks Needed for resources not created from a YAML template, like namespaces created by Helm
+  - @param kind Kind of the resource
+  - @param name Name of the resource
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func LabelRun(kind, name string) {
+	_, err := kubectl.RunWithoutErr("label", "--overwrite", kind, name, runLabel+"="+GetRunID())
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Delete all resources with the run label
+  - @remarks Resources are deleted even if only some kinds are available in the cluster
+  - @param id ID of the run to clean, all runs if empty
+  - @returns Nothing or the errors of all failed deletions
+*/
+func CleanupRun(id string) error {
+	selector := runLabel
+	if id != "" {
+		selector += "=" + id
+	}
+
+	// Dependent resources first, namespaces at the end
+	var errs []error
+	for _, kind := range []string{
+		// Faults are recovered first
+		"podchaos.chaos-mesh.org",
+		"networkchaos.chaos-mesh.org",
+		"iochaos.chaos-mesh.org",
+		"restores.resources.cattle.io",
+		"backups.resources.cattle.io",
+		"resourcesets.resources.cattle.io",
+		"clusteradmissionpolicygroups.policies.kubewarden.io",
+		"admissionpolicygroups.policies.kubewarden.io",
+		"clusteradmissionpolicies.policies.kubewarden.io",
+		"admissionpolicies.policies.kubewarden.io",
+		"policyservers.policies.kubewarden.io",
+		"snapshots.longhorn.io",
+		"globalroles.management.cattle.io",
+		"networkpolicies",
+		"namespaces",
+	} {
+		if strings.Contains(kind, ".") {
+			if _, err := kubectl.RunWithoutErr("get", "crd", kind); err != nil {
+				continue
+			}
+		}
+
+		out, err := kubectl.RunWithoutErr("delete", kind, "-l", selector,
+			"--all-namespaces", "--ignore-not-found", "--timeout=5m")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deleting %s: %w", kind, err))
+			continue
+		}
+		GinkgoWriter.Print(out)
+	}
+
+	return errors.Join(errs...)
+}
+
+/*
+Add a Backup resource
+  - @param name Name of the Backup resource
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func ApplyBackup(name string) {
+	file := CopyYaml(backupYaml, map[string]string{"name: kubewarden-backup": "name: " + name})
+	err := kubectl.Apply(clusterNS, file)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Add a Restore resource
+  - @param name Name of the Restore resource
+  - @param backupFile Backup file to restore
+  - @param prune Delete the resources not in the backup file
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func ApplyRestore(name, backupFile string, prune bool) {
+	file := CopyYaml(restoreYaml, map[string]string{
+		"name: kubewarden-restore": "name: " + name,
+		"%BACKUP_FILE%":            backupFile,
+		"%PRUNE%":                  strconv.FormatBool(prune),
+	})
+	err := kubectl.Apply(clusterNS, file)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Get the file of a Backup resource
+  - @param name Name of the Backup resource
+  - @returns Backup file name, the function will fail through Ginkgo in case of issue
+*/
+func GetBackupFile(name string) string {
+	out, err := kubectl.RunWithoutErr("get", "backup", name, "-o", "jsonpath={.status.filename}")
+	Expect(err).To(Not(HaveOccurred()))
+	Expect(out).To(Not(BeEmpty()), "backup %s has no file", name)
+
+	return out
+}
+
+/*
+Add a policy that can only come back from a backup
+  - @remarks Kubewarden charts never create it, unlike the recommended policies re-created by a reinstall
+  - @param name Name of the ClusterAdmissionPolicy
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func ApplyBackupOnlyPolicy(name string) {
+	file := CopyYaml(backupOnlyPolicyYaml, map[string]string{"%NAME%": name})
+	err := kubectl.Apply(clusterNS, file)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Check that a policy added by ApplyBackupOnlyPolicy is restored
+  - @remarks Only the resource is checked, Kubewarden may not be installed yet to activate it
+  - @param name Name of the ClusterAdmissionPolicy
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func CheckBackupOnlyPolicyRestored(name string) {
+	_, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", name)
+	Expect(err).To(Not(HaveOccurred()), "policy %s is not restored", name)
+}
+
+/*
+Check that a policy added by ApplyBackupOnlyPolicy is enforced
+  - @remarks Only the privileged pods with the label of the policy are denied
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param name Name of the ClusterAdmissionPolicy
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func CheckBackupOnlyPolicyEnforced(ctx context.Context, name string) {
+	WaitForPolicyActive(ctx, localCluster, name)
+
+	out, err := kubectl.Run("run", UniqueName("backup-only-pod"), "--image=rancher/pause:3.2", "--labels", "e2e-backup-only="+name,
+		"--overrides", `{"spec": {"containers": [{"name": "pause", "image": "rancher/pause:3.2", "securityContext": {"privileged": true}}]}}`)
+	Expect(err).To(HaveOccurred())
+	Expect(out).To(ContainSubstring("denied the request"))
+}
+
+/*
+Wait for a Backup or Restore resource to be done
+  - @remarks Unlike the operator logs, the resource status is not shared with other specs
+  - @remarks After a restore, Kubewarden resources also have to be reconciled if the controller is running
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param kind Kind of the resource, backup or restore
+  - @param name Name of the resource
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForReady(ctx context.Context, kind, name string) {
+	err := WaitForDone(ctx, kind, name)
+	Expect(err).To(Not(HaveOccurred()))
+
+	// Restored resources have no status until the controller reconciles them, e.g. after a disaster recovery
+	if kind == "restore" {
+		if _, err := kubectl.RunWithoutErr("get", "deployment", "kubewarden-controller", "--namespace", kubewardenNS); err == nil {
+			WaitForReconciled(ctx)
+		}
+	}
+}
+
+// Time a Backup or Restore has to keep the same error to be failed
+const backupFailureGrace = time.Minute
+
+/*
+Wait for a Backup or Restore resource to be done or to fail
+  - @remarks The operator retries on errors, a failure is only reported once its condition has not changed for backupFailureGrace
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param kind Kind of the resource, backup or restore
+  - @param name Name of the resource
+  - @returns Nothing, or an error with the message of the failed condition
+*/
+func WaitForDone(ctx context.Context, kind, name string) error {
+	class := timeouts.Backup
+	if kind == "restore" {
+		class = timeouts.Restore
+	}
+	opts := wait.Options{Class: class, Description: kind + " " + name + " to be ready"}
+
+	// Only the wait is planned
+	if dryrun.Enabled() {
+		WaitFor(ctx, nil, opts)
+		return nil
+	}
+
+	var failure string
+	var failedSince time.Time
+	return wait.For(ctx, func(context.Context) (string, error) {
+		if status, _, _ := GetCondition(kind, name, "Ready"); status == "True" {
+			return status, nil
+		}
+
+		// Errors are set on the Ready or Reconciling conditions, depending on the operator version
+		message := ""
+		for _, condition := range []string{"Ready", "Reconciling"} {
+			if status, reason, msg := GetCondition(kind, name, condition); status == "False" && reason == "Error" {
+				message = condition + ": " + msg
+				break
+			}
+		}
+
+		if message == "" {
+			failure = ""
+			return "", fmt.Errorf("%s %s not ready", kind, name)
+		}
+		if message != failure {
+			failure, failedSince = message, time.Now()
+		}
+		if time.Since(failedSince) >= backupFailureGrace {
+			return message, wait.Permanent(fmt.Errorf("%s %s failed: %s", kind, name, message))
+		}
+		return message, fmt.Errorf("%s %s failing: %s", kind, name, message)
+	}, opts)
+}
+
+/*
+Wait for all the Kubewarden resources to be reconciled
+  - @remarks Policies have to be active, policy servers ready and webhooks served with a certificate of their caBundle
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForReconciled(ctx context.Context) {
+	WaitFor(ctx, wait.Check(func() error {
+		issues, err := reconcile.Check()
+		if err != nil {
+			return err
+		}
+		if len(issues) > 0 {
+			return fmt.Errorf("%d resources not reconciled:\n%s", len(issues), reconcile.Format(issues))
+		}
+		return nil
+	}), wait.Options{Class: timeouts.Restore, Description: "Kubewarden resources to be reconciled"})
+
+	// The CA of the controller can be restored from the backup
+	WaitForCABundles(ctx, kubewardenNS)
+}
+
+/*
+Wait for a Kubewarden policy to be active
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param c Cluster of the policy
+  - @param policy Name of the ClusterAdmissionPolicy
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForPolicyActive(ctx context.Context, c *cluster.Cluster, policy string) {
+	WaitFor(ctx, wait.Match(func() string {
+		out, _ := c.KubectlWithoutErr("get", "clusteradmissionpolicy", policy,
+			"-o", "jsonpath={.status.policyStatus}")
+		return out
+	}, Equal("active")), wait.Options{Class: timeouts.Rollout, Description: "policy " + policy + " to be active"})
+}
+
+/*
+Wait for a scan of the audit scanner
+  - @remarks Scans are done by the jobs of its CronJob, the reports are updated when the job succeeds
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param since Time after which the scan has to be completed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForAuditScan(ctx context.Context, since time.Time) {
+	WaitFor(ctx, wait.Check(func() error {
+		out, _ := kubectl.RunWithoutErr("get", "cronjob", auditScannerCronJob, "--namespace", kubewardenNS,
+			"-o", "jsonpath={.status.lastSuccessfulTime}")
+		if t, err := time.Parse(time.RFC3339, out); err != nil || !t.After(since) {
+			return fmt.Errorf("no scan completed since %s", since.Format(time.TimeOnly))
+		}
+		return nil
+	}), wait.Options{Class: timeouts.Rollout, Description: "scan of the audit scanner"})
+}
+
+/*
+Wait for a deployment to be rolled out
+  - @remarks All the replicas of the last generation have to be ready, zero replicas is a valid rollout
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param c Cluster of the deployment
+  - @param ns Namespace of the deployment
+  - @param name Name of the deployment
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForDeploymentReady(ctx context.Context, c *cluster.Cluster, ns, name string) {
+	WaitFor(ctx, wait.Check(func() error {
+		out, err := c.KubectlWithoutErr("get", "deployment", name, "--namespace", ns, "-o",
+			"jsonpath={.metadata.generation} {.status.observedGeneration} {.spec.replicas} {.status.replicas} {.status.updatedReplicas} {.status.readyReplicas}")
+		if err != nil {
+			return err
+		}
+
+		// Missing counters are zero
+		var generation, observed, replicas, current, updated, ready int
+		_, _ = fmt.Sscan(out, &generation, &observed, &replicas, &current, &updated, &ready)
+		if observed < generation {
+			return fmt.Errorf("generation %d of deployment %s not observed yet", generation, name)
+		}
+		// Old replicas are still counted until they are terminated
+		if current != replicas || updated != replicas || ready != replicas {
+			return fmt.Errorf("deployment %s: %d/%d updated, %d/%d ready, %d in total", name, updated, replicas, ready, replicas, current)
+		}
+		return nil
+	}), wait.Options{Class: timeouts.Rollout, Description: "deployment " + name + " to be ready"})
+}
+
+/*
+Wait for a CRD to be established
+  - @remarks Resources of a CRD cannot be created before, even if the CRD exists
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param c Cluster of the CRD
+  - @param crd Name of the CRD, e.g. policyservers.policies.kubewarden.io
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForCRDEstablished(ctx context.Context, c *cluster.Cluster, crd string) {
+	WaitFor(ctx, wait.Match(func() string {
+		out, _ := c.KubectlWithoutErr("get", "crd", crd,
+			"-o", `jsonpath={.status.conditions[?(@.type=="Established")].status}`)
+		return out
+	}, Equal("True")), wait.Options{Class: timeouts.Install, Description: "CRD " + crd + " to be established"})
+}
+
+/*
+Wait for a webhook service to serve TLS
+  - @remarks The service is probed through a port-forward, the certificate is not verified
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param ns Namespace of the service
+  - @param service Name of the service
+  - @param port Port of the service
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForWebhookReady(ctx context.Context, ns, service string, port int) {
+	// Pods behind the service can change during a rollout, the port-forward is started for each probe
+	WaitFor(ctx, wait.Check(func() error {
+		f, err := portforward.Start(ctx, ns, "svc/"+service, port)
+		if err != nil {
+			return err
+		}
+		defer f.Stop()
+
+		conn, err := tls.Dial("tcp", f.Addr(), &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return fmt.Errorf("no TLS handshake with %s: %w", service, err)
+		}
+		return conn.Close()
+	}), wait.Options{Class: timeouts.Rollout, Description: "webhook " + service + " to serve TLS"})
+}
+
+/*
+Wait for events of a resource
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param kind Kind of the resource, empty for all kinds
+  - @param name Name of the resource, empty for all resources of the kind
+  - @param ns Namespace of the events
+  - @param matcher Matcher of the events, e.g. events.HaveEvent("FailedCreate")
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForEvent(ctx context.Context, kind, name, ns string, matcher types.GomegaMatcher) {
+	WaitFor(ctx, wait.Check(func() error {
+		list, err := events.For(kind, name, ns)
+		if err != nil {
+			return err
+		}
+
+		if ok, err := matcher.Match(list); err != nil || !ok {
+			return cmp.Or(err, fmt.Errorf("%s", matcher.FailureMessage(list)))
+		}
+
+		return nil
+	}), wait.Options{Class: timeouts.Rollout, Description: "events of " + kind + " " + name + " in " + ns})
+}
+
+/*
+Wait for events of the current instance of a resource
+  - @remarks Events of a previous resource with the same name are ignored, e.g. of a Deployment deleted and created again
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param kind Kind of the resource
+  - @param name Name of the resource
+  - @param ns Namespace of the resource and its events
+  - @param matcher Matcher of the events, e.g. events.HaveEvent("ScalingReplicaSet")
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForEventOfCurrent(ctx context.Context, kind, name, ns string, matcher types.GomegaMatcher) {
+	uid, err := kubectl.RunWithoutErr("get", kind, name, "--namespace", ns, "-o", "jsonpath={.metadata.uid}")
+	Expect(err).To(Not(HaveOccurred()))
+
+	WaitFor(ctx, wait.Check(func() error {
+		list, err := events.For(kind, name, ns)
+		if err != nil {
+			return err
+		}
+		list = events.Involving(list, uid)
+
+		if ok, err := matcher.Match(list); err != nil || !ok {
+			return cmp.Or(err, fmt.Errorf("%s", matcher.FailureMessage(list)))
+		}
+
+		return nil
+	}), wait.Options{Class: timeouts.Rollout, Description: "events of " + kind + " " + name + " (" + uid + ") in " + ns})
+}
+
+/*
+Wait for a resource to be deleted
+  - @remarks The finalizers left on the resource are reported while waiting
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param kind Kind of the resource
+  - @param name Name of the resource
+  - @param ns Namespace of the resource, empty for cluster-wide resources
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForDeletion(ctx context.Context, kind, name, ns string) {
+	args := []string{"get", kind, name, "--ignore-not-found", "-o", "jsonpath={.metadata.uid} {.metadata.finalizers}"}
+	if ns != "" {
+		args = append(args, "--namespace", ns)
+	}
+
+	WaitFor(ctx, wait.Check(func() error {
+		out, err := kubectl.RunWithoutErr(args...)
+		if err != nil {
+			return err
+		}
+
+		// Nothing is returned once the resource is gone
+		if out == "" {
+			return nil
+		}
+		_, finalizers, _ := strings.Cut(out, " ")
+		return fmt.Errorf("%s %s still exists, finalizers: %s", kind, name, cmp.Or(finalizers, "none"))
+	}), wait.Options{Class: timeouts.Rollout, Description: kind + " " + name + " to be deleted"})
+}
+
+/*
+Report the Kubewarden resources stuck terminating
+  - @remarks Used when a spec fails, a stuck finalizer often explains the next failures
+  - @param older Minimum time since the deletion request
+  - @returns Nothing, a report entry is added if resources are stuck
+*/
+func ReportTerminating(older time.Duration) {
+	kinds := []string{"policyservers", "clusteradmissionpolicies", "admissionpolicies",
+		"clusteradmissionpolicygroups", "admissionpolicygroups", "namespaces"}
+
+	stuck, err := terminating.Find(kinds, "", older)
+	if err != nil {
+		GinkgoWriter.Printf("Cannot find resources stuck terminating: %s\n", err)
+		return
+	}
+	if len(stuck) > 0 {
+		AddReportEntry("stuck terminating", terminating.Format(stuck), ReportEntryVisibilityFailureOrVerbose)
+	}
+}
+
+/*
+Get the status of a resource condition
+  - @param kind Kind of the resource
+  - @param name Name of the resource
+  - @param condition Type of the condition
+  - @returns Status of the condition, empty if not set
+*/
+func GetConditionStatus(kind, name, condition string) string {
+	out, _ := kubectl.RunWithoutErr("get", kind, name,
+		"-o", "jsonpath={.status.conditions[?(@.type==\""+condition+"\")].status}")
+	return out
+}
+
+/*
+Get a resource condition
+  - @param kind Kind of the resource
+  - @param name Name of the resource
+  - @param condition Type of the condition
+  - @returns Status, reason and message of the condition, empty if not set
+*/
+func GetCondition(kind, name, condition string) (string, string, string) {
+	selector := "{.status.conditions[?(@.type==\"" + condition + "\")]"
+	out, _ := kubectl.RunWithoutErr("get", kind, name,
+		"-o", "jsonpath="+selector+".status}|"+selector+".reason}|"+selector+".message}")
+
+	// The message is the last field, it can contain the separator
+	fields := strings.SplitN(out, "|", 3)
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	return fields[0], fields[1], fields[2]
+}
+
+/*
+Check that all Kubewarden policies are active
+  - @returns Nothing or an error listing the policies not active
+*/
+func CheckPoliciesActive() error {
+	out, err := kubectl.RunWithoutErr("get", "admissionpolicies,clusteradmissionpolicies", "--all-namespaces",
+		"-o", "jsonpath={range .items[*]}{.kind}/{.metadata.name}={.status.policyStatus}{\"\\n\"}{end}")
+	if err != nil {
+		return err
+	}
+
+	var inactive []string
+	for _, policy := range strings.Fields(out) {
+		if !strings.HasSuffix(policy, "=active") {
+			inactive = append(inactive, policy)
+		}
+	}
+
+	if len(inactive) > 0 {
+		return fmt.Errorf("policies not active: %s", strings.Join(inactive, ", "))
+	}
+
+	return nil
+}
+
+/*
+Get the directory where temporary files are created
+  - @remarks Everything in it is removed by the cleanup suite
+  - @returns Path of the directory, the function will fail through Ginkgo in case of issue
+*/
+func GetTempDir() string {
+	// Each parallel process has its own directory
+	dir := filepath.Join(os.TempDir(), "kubewarden-e2e", strconv.Itoa(GinkgoParallelProcess()))
+
+	err := os.MkdirAll(dir, 0755)
+	Expect(err).To(Not(HaveOccurred()))
+
+	return dir
+}
+
+/*
+Copy a YAML template and set its values, the template itself is not modified
+  - @param src Template file to copy
+  - @param values Map of placeholders (or simple regex) and the values to set
+  - @returns The path of the generated file, the function will fail through Ginkgo in case of issue
+*/
+func CopyYaml(src string, values map[string]string) string {
+	f, err := os.CreateTemp(GetTempDir(), filepath.Base(src)+"-")
+	Expect(err).To(Not(HaveOccurred()))
+	dst := f.Name()
+	f.Close()
+
+	err = tools.CopyFile(src, dst)
+	Expect(err).To(Not(HaveOccurred()))
+
+	// All templates have the run label
+	values = maps.Clone(values)
+	if values == nil {
+		values = map[string]string{}
+	}
+	values["%E2E_RUN%"] = GetRunID()
+
+	for k, v := range values {
+		err := tools.Sed(k, v, dst)
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	return dst
+}
+
+/*
+Get configured backup directory
+  - @returns Configured backup directory
+*/
+func GetBackupDir() string {
+	claimName, err := kubectl.RunWithoutErr("get", "pod", "-l", "app.kubernetes.io/name=rancher-backup",
+		"--namespace", "cattle-resources-system",
+		"-o", "jsonpath={.items[*].spec.volumes[?(@.name==\"pv-storage\")].persistentVolumeClaim.claimName}")
+	Expect(err).To(Not(HaveOccurred()))
+
+	out, err := kubectl.RunWithoutErr("get", "pv",
+		"--namespace", "cattle-resources-system",
+		"-o", "jsonpath={.items[?(@.spec.claimRef.name==\""+claimName+"\")].spec.local.path}")
+	Expect(err).To(Not(HaveOccurred()))
+
+	return out
+}
+
+/*
+Get the volume used by the backup operator
+  - @returns Name of the PersistentVolume bound to the backup operator claim
+*/
+func GetBackupVolume() string {
+	claimName, err := kubectl.RunWithoutErr("get", "pod", "-l", "app.kubernetes.io/name=rancher-backup",
+		"--namespace", "cattle-resources-system",
+		"-o", "jsonpath={.items[*].spec.volumes[?(@.name==\"pv-storage\")].persistentVolumeClaim.claimName}")
+	Expect(err).To(Not(HaveOccurred()))
+
+	out, err := kubectl.RunWithoutErr("get", "pvc", claimName,
+		"--namespace", "cattle-resources-system",
+		"-o", "jsonpath={.spec.volumeName}")
+	Expect(err).To(Not(HaveOccurred()))
+	Expect(out).To(Not(BeEmpty()))
+
+	return out
+}
+
+/*
+Check if the rancher-backup operator can be reused by the initial installation
+  - @remarks Only the version asked for the run can be reused, broken releases are fixed first
+  - @returns True if reuse is asked and the charts are deployed
+*/
+func ReuseBackupOperator() bool {
+	deployed := true
+	for _, chart := range []string{"rancher-backup-crd", "rancher-backup"} {
+		deployed = PrepareRelease("cattle-resources-system", chart) && deployed
+	}
+
+	return ReuseInstalled() && deployed
+}
+
+/*
+Install rancher-backup operator
+  - @remarks The chart is upgraded or downgraded in place if another version is installed
+  - @param k kubectl structure
+  - @param version Operator release to install, the chart of the Rancher repository is used if empty
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallBackupOperator(k *kubectl.Kubectl, version string) {
+	for _, chart := range []string{"rancher-backup-crd", "rancher-backup"} {
+		PrepareRelease("cattle-resources-system", chart)
+	}
+
+	// S3 storage in the cluster, when no external one is defined
+	if backupS3Bucket == "" && backupMinIO {
+		UseS3Store(DeployMinIO(k))
+	}
+
+	// Default chart
+	chartRepo := "rancher-chart"
+
+	// Set specific operator version if defined, the offline repository has the archives of the releases
+	release := version != "" && offlineChartRepo == ""
+	if release {
+		chartRepo = "https://github.com/rancher/backup-restore-operator/releases/download/" + version
+	} else {
+		AddHelmRepo(chartRepo, "https://charts.rancher.io")
+	}
+
+	for _, chart := range []string{"rancher-backup-crd", "rancher-backup"} {
+		// Set the filename in chart if a custom version is defined
+		chartName := chart
+		if release {
+			chartName = chart + "-" + strings.Trim(version, "v") + ".tgz"
+		}
+
+		// Global installation flags
+		flags := []string{
+			"upgrade", "--install", chart, chartRepo + "/" + chartName,
+			"--namespace", "cattle-resources-system",
+			"--create-namespace",
+			"--wait", "--wait-for-jobs",
+		}
+		if version != "" && !release {
+			flags = append(flags, "--version", strings.Trim(version, "v"))
+		}
+
+		// Add specific options for the rancher-backup chart
+		if chart == "rancher-backup" {
+			if backupS3Bucket != "" {
+				flags = append(flags, BackupS3Flags()...)
+			} else {
+				flags = append(flags,
+					"--set", "persistence.enabled=true",
+					"--set", "persistence.storageClass="+backupStorageClass,
+				)
+			}
+		}
+
+		RunHelmCmdWithRetry(flags...)
+
+		WaitFor(context.Background(), wait.Check(func() error {
+			return rancher.CheckPod(k, [][]string{{"cattle-resources-system", "app.kubernetes.io/name=rancher-backup"}})
+		}), wait.Options{Class: timeouts.Rollout, Description: chart + " pods"})
+	}
+}
+
+/*
+Configure S3 storage for the rancher-backup chart
+  - @remarks Credentials are taken from the AWS_* variables, also used by the aws CLI
+  - @returns Helm flags to use S3 as default backup storage
+*/
+func BackupS3Flags() []string {
+	const secretName = "backup-s3-credentials"
+
+	// Credentials have to be stored in a secret, in any namespace
+	err := kubectl.DeleteSecret("default", secretName)
+	Expect(err).To(Not(HaveOccurred()))
+	err = kubectl.CreateSecretFromLiteral("default", secretName, map[string]string{
+		"accessKey": os.Getenv("AWS_ACCESS_KEY_ID"),
+		"secretKey": os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	})
+	Expect(err).To(Not(HaveOccurred()))
+
+	region := os.Getenv("AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return []string{
+		"--set", "persistence.enabled=false",
+		"--set", "s3.enabled=true",
+		"--set", "s3.bucketName=" + backupS3Bucket,
+		"--set", "s3.folder=" + backupS3Folder,
+		"--set", "s3.endpoint=" + strings.TrimPrefix(strings.TrimPrefix(backupS3Endpoint, "https://"), "http://"),
+		"--set", "s3.region=" + region,
+		"--set", "s3.credentialSecretName=" + secretName,
+		"--set", "s3.credentialSecretNamespace=default",
+		"--set", "s3.insecureTLSSkipVerify=true",
+	}
+}
+
+/*
+Deploy MinIO with a bucket and an access key for the tests
+  - @remarks Credentials are generated, and stored with the endpoint in the minio-s3-credentials secret
+  - @param k kubectl structure
+  - @returns The S3 storage, the function will fail through Ginkgo in case of issue
+*/
+func DeployMinIO(k *kubectl.Kubectl) *minioStore {
+	const (
+		minioNS       = "minio"
+		minioNodePort = "32000"
+		secretName    = "minio-s3-credentials"
+	)
+
+	store := &minioStore{
+		Bucket:    "kubewarden-backups",
+		AccessKey: "e2e-" + GetRunID(),
+		SecretKey: randomHex(20),
+		Secret:    "default/" + secretName,
+	}
+
+	// Kept from a previous deployment, the user is updated by the chart otherwise
+	if out, err := kubectl.RunWithoutErr("get", "secret", secretName, "--namespace", "default",
+		"-o", "jsonpath={.data.secretKey}"); err == nil && out != "" {
+		data, err := base64.StdEncoding.DecodeString(out)
+		Expect(err).To(Not(HaveOccurred()))
+		store.SecretKey = string(data)
+	}
+
+	AddHelmRepo("minio", "https://charts.min.io")
+
+	// Buckets and users are created by a post-install job of the chart
+	RunHelmCmdWithRetry("upgrade", "--install", "minio", "minio/minio",
+		"--namespace", minioNS,
+		"--create-namespace",
+		"--set", "mode=standalone",
+		"--set", "replicas=1",
+		"--set", "persistence.size=5Gi",
+		"--set", "resources.requests.memory=512Mi",
+		"--set", "rootUser=e2e-admin",
+		"--set", "rootPassword="+store.SecretKey,
+		"--set", "service.type=NodePort",
+		"--set", "service.nodePort="+minioNodePort,
+		"--set", "buckets[0].name="+store.Bucket,
+		"--set", "buckets[0].policy=none",
+		"--set", "buckets[0].purge=false",
+		"--set", "users[0].accessKey="+store.AccessKey,
+		"--set", "users[0].secretKey="+store.SecretKey,
+		"--set", "users[0].policy=readwrite",
+		"--wait", "--wait-for-jobs",
+	)
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{{minioNS, "app=minio"}})
+	}), wait.Options{Class: timeouts.Install, Description: "MinIO pods"})
+
+	store.Endpoint = "http://" + GetNodeIP() + ":" + minioNodePort
+
+	err := kubectl.DeleteSecret("default", secretName)
+	Expect(err).To(Not(HaveOccurred()))
+	err = kubectl.CreateSecretFromLiteral("default", secretName, map[string]string{
+		"endpoint":  store.Endpoint,
+		"bucket":    store.Bucket,
+		"accessKey": store.AccessKey,
+		"secretKey": store.SecretKey,
+	})
+	Expect(err).To(Not(HaveOccurred()))
+
+	return store
+}
+
+/*
+Remove MinIO and its data
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RemoveMinIO() {
+	if deployed, _ := GetReleases(localCluster, "minio"); len(deployed) > 0 {
+		err := kubectl.RunHelmBinaryWithCustomErr("uninstall", "minio", "--namespace", "minio", "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	_, err := kubectl.RunWithoutErr("delete", "namespace", "minio", "--ignore-not-found")
+	Expect(err).To(Not(HaveOccurred()))
+
+	err = kubectl.DeleteSecret("default", "minio-s3-credentials")
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Use an S3 storage for the backups
+  - @remarks The AWS_* variables are set, for the backup operator and the aws CLI
+  - @param store S3 storage
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func UseS3Store(store *minioStore) {
+	backupS3Bucket = store.Bucket
+	backupS3Endpoint = store.Endpoint
+
+	for key, value := range map[string]string{
+		"AWS_ACCESS_KEY_ID":     store.AccessKey,
+		"AWS_SECRET_ACCESS_KEY": store.SecretKey,
+	} {
+		Expect(os.Setenv(key, value)).To(Succeed())
+	}
+}
+
+/*
+Get a random hexadecimal string
+  - @remarks This function is only used internally, not exported
+  - @param n Number of random bytes
+  - @returns The random string
+*/
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	Expect(err).To(Not(HaveOccurred()))
+
+	return hex.EncodeToString(b)
+}
+
+/*
+Install Longhorn storage
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallLonghorn(k *kubectl.Kubectl) {
+	AddHelmRepo("longhorn", "https://charts.longhorn.io")
+
+	flags := []string{
+		"upgrade", "--install", "longhorn", "longhorn/longhorn",
+		"--namespace", "longhorn-system",
+		"--create-namespace",
+		// Single node cluster, so only one replica can be scheduled
+		"--set", "persistence.defaultClassReplicaCount=1",
+		"--set", "defaultSettings.defaultReplicaCount=1",
+		"--wait", "--wait-for-jobs",
+	}
+
+	// Set specific Longhorn version if defined
+	if longhornVersion != "" {
+		flags = append(flags, "--version", longhornVersion)
+	}
+
+	RunHelmCmdWithRetry(flags...)
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{"longhorn-system", "app=longhorn-manager"},
+			{"longhorn-system", "app=longhorn-csi-plugin"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "Longhorn pods"})
+}
+
+/*
+Revert a Longhorn volume to one of its snapshots
+  - @remarks Longhorn only reverts a volume attached in maintenance mode, so its workload is scaled down meanwhile
+  - @remarks The Longhorn API has the same format as the Rancher one, so the rancherapi client is used
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param ns Namespace of the workload using the volume
+  - @param deployment Deployment using the volume, with a single replica
+  - @param volume Name of the Longhorn volume, the PersistentVolume name for the CSI volumes
+  - @param snapshot Name of the Longhorn snapshot
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RevertLonghornVolume(ctx context.Context, ns, deployment, volume, snapshot string) {
+	waitForState := func(state string) {
+		WaitFor(ctx, wait.Match(func() string {
+			out, _ := kubectl.RunWithoutErr("get", "volumes.longhorn.io", volume, "--namespace", "longhorn-system",
+				"-o", "jsonpath={.status.state}")
+			return out
+		}, Equal(state)), wait.Options{Class: timeouts.Rollout, Description: "Longhorn volume " + volume + " to be " + state})
+	}
+
+	scale := func(replicas int) {
+		_, err := kubectl.RunWithoutErr("scale", "deployment", deployment, "--namespace", ns, "--replicas", strconv.Itoa(replicas))
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	scale(0)
+	waitForState("detached")
+
+	if dryrun.Enabled() {
+		dryrun.Record("revert Longhorn volume %s to snapshot %s", volume, snapshot)
+	} else {
+		node, err := kubectl.RunWithoutErr("get", "volumes.longhorn.io", volume, "--namespace", "longhorn-system",
+			"-o", "jsonpath={.status.ownerID}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		f, err := portforward.Start(ctx, "longhorn-system", "svc/longhorn-backend", 9500)
+		Expect(err).To(Not(HaveOccurred()))
+		defer f.Stop()
+
+		api := rancherapi.New(f.URL("http"), "")
+		endpoint := "/v1/volumes/" + volume + "?action="
+
+		// Without frontend, the volume is not usable by a workload while it is reverted
+		err = api.Post(endpoint+"attach", map[string]any{"hostId": node, "disableFrontend": true}, nil)
+		Expect(err).To(Not(HaveOccurred()))
+		waitForState("attached")
+
+		err = api.Post(endpoint+"snapshotRevert", map[string]string{"name": snapshot}, nil)
+		Expect(err).To(Not(HaveOccurred()))
+
+		err = api.Post(endpoint+"detach", map[string]string{"hostId": node}, nil)
+		Expect(err).To(Not(HaveOccurred()))
+		waitForState("detached")
+	}
+
+	scale(1)
+	WaitForDeploymentReady(ctx, localCluster, ns, deployment)
+}
+
+/*
+Install external-secrets, with a Vault in dev mode as secret store
+  - @remarks Vault keeps its data in memory, the root token is "root"
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallExternalSecrets(k *kubectl.Kubectl) {
+	AddHelmRepo("hashicorp", "https://helm.releases.hashicorp.com")
+	AddHelmRepo("external-secrets", "https://charts.external-secrets.io")
+
+	RunHelmCmdWithRetry("upgrade", "--install", "vault", "hashicorp/vault",
+		"--namespace", "vault",
+		"--create-namespace",
+		"--set", "server.dev.enabled=true",
+		"--set", "server.dev.devRootToken=root",
+		"--set", "injector.enabled=false",
+		"--wait",
+	)
+	RunHelmCmdWithRetry("upgrade", "--install", "external-secrets", "external-secrets/external-secrets",
+		"--namespace", "external-secrets",
+		"--create-namespace",
+		"--set", "installCRDs=true",
+		"--wait", "--wait-for-jobs",
+	)
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{"vault", "app.kubernetes.io/name=vault"},
+			{"external-secrets", "app.kubernetes.io/name=external-secrets"},
+			{"external-secrets", "app.kubernetes.io/name=external-secrets-webhook"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "external-secrets and Vault pods"})
+}
+
+/*
+Remove external-secrets and Vault
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RemoveExternalSecrets() {
+	for _, name := range []string{"external-secrets", "vault"} {
+		if deployed, _ := GetReleases(localCluster, name); len(deployed) > 0 {
+			err := kubectl.RunHelmBinaryWithCustomErr("uninstall", name, "--namespace", name, "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		}
+
+		_, err := kubectl.RunWithoutErr("delete", "namespace", name, "--ignore-not-found")
+		Expect(err).To(Not(HaveOccurred()))
+	}
+}
+
+/*
+Install policy-reporter, with its REST API
+  - @remarks The API is reached through a port-forward of the policy-reporter service, the UI is not installed
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallPolicyReporter(k *kubectl.Kubectl) {
+	AddHelmRepo("policy-reporter", "https://kyverno.github.io/policy-reporter")
+
+	flags := []string{
+		"upgrade", "--install", "policy-reporter", "policy-reporter/policy-reporter",
+		"--namespace", "policy-reporter",
+		"--create-namespace",
+		"--set", "rest.enabled=true",
+		"--wait",
+	}
+
+	// Set specific policy-reporter version if defined
+	if policyReporterVersion != "" {
+		flags = append(flags, "--version", policyReporterVersion)
+	}
+
+	RunHelmCmdWithRetry(flags...)
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{"policy-reporter", "app.kubernetes.io/name=policy-reporter"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "policy-reporter pods"})
+}
+
+/*
+Remove policy-reporter
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RemovePolicyReporter() {
+	if deployed, _ := GetReleases(localCluster, "policy-reporter"); len(deployed) > 0 {
+		err := kubectl.RunHelmBinaryWithCustomErr("uninstall", "policy-reporter", "--namespace", "policy-reporter", "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	_, err := kubectl.RunWithoutErr("delete", "namespace", "policy-reporter", "--ignore-not-found")
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Install Prometheus with the Prometheus operator
+  - @remarks kube-prometheus-stack without Grafana, Alertmanager and exporters, all the monitors and rules of the cluster are loaded
+  - @param k kubectl structure
+  - @param ns Namespace of Prometheus
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallPrometheus(k *kubectl.Kubectl, ns string) {
+	AddHelmRepo("prometheus-community", "https://prometheus-community.github.io/helm-charts")
+
+	RunHelmCmdWithRetry("upgrade", "--install", "prometheus", "prometheus-community/kube-prometheus-stack",
+		"--namespace", ns,
+		"--create-namespace",
+		"--set", "grafana.enabled=false",
+		"--set", "alertmanager.enabled=false",
+		"--set", "nodeExporter.enabled=false",
+		"--set", "kubeStateMetrics.enabled=false",
+		"--set", "prometheus.prometheusSpec.evaluationInterval=15s",
+		"--set", "prometheus.prometheusSpec.ruleSelectorNilUsesHelmValues=false",
+		"--set", "prometheus.prometheusSpec.serviceMonitorSelectorNilUsesHelmValues=false",
+		"--wait",
+	)
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{ns, "app.kubernetes.io/name=prometheus"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "Prometheus pods"})
+}
+
+/*
+Remove Prometheus installed by InstallPrometheus
+  - @remarks CRDs of the Prometheus operator are kept, as by Helm
+  - @param ns Namespace of Prometheus
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RemovePrometheus(ns string) {
+	if deployed, _ := GetReleases(localCluster, ns); len(deployed) > 0 {
+		err := kubectl.RunHelmBinaryWithCustomErr("uninstall", "prometheus", "--namespace", ns, "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	_, err := kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found")
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Install Chaos Mesh
+  - @remarks The chaos daemon uses the containerd socket of K3s, the dashboard is not installed
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallChaosMesh(k *kubectl.Kubectl) {
+	AddHelmRepo("chaos-mesh", "https://charts.chaos-mesh.org")
+
+	RunHelmCmdWithRetry("upgrade", "--install", "chaos-mesh", "chaos-mesh/chaos-mesh",
+		"--namespace", "chaos-mesh",
+		"--create-namespace",
+		"--set", "chaosDaemon.runtime=containerd",
+		"--set", "chaosDaemon.socketPath=/run/k3s/containerd/containerd.sock",
+		"--set", "dashboard.create=false",
+		"--wait",
+	)
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{"chaos-mesh", "app.kubernetes.io/component=controller-manager"},
+			{"chaos-mesh", "app.kubernetes.io/component=chaos-daemon"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "Chaos Mesh pods"})
+}
+
+/*
+Remove Chaos Mesh
+  - @remarks Experiments of the run are deleted first, so their faults are recovered
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RemoveChaosMesh() {
+	if deployed, _ := GetReleases(localCluster, "chaos-mesh"); len(deployed) > 0 {
+		for _, kind := range []string{"podchaos", "networkchaos", "iochaos"} {
+			_, err := kubectl.RunWithoutErr("delete", kind+".chaos-mesh.org", "-l", runLabel+"="+GetRunID(),
+				"--all-namespaces", "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		}
+
+		err := kubectl.RunHelmBinaryWithCustomErr("uninstall", "chaos-mesh", "--namespace", "chaos-mesh", "--wait")
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	_, err := kubectl.RunWithoutErr("delete", "namespace", "chaos-mesh", "--ignore-not-found")
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Inject the faults of a Chaos Mesh experiment
+  - @remarks The experiment gets the run label, it is deleted at the end of the spec
+  - @param ctx Context of the spec
+  - @param e Experiment, declared with the chaos helper
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InjectChaos(ctx context.Context, e *chaos.Experiment) {
+	e.Labels[runLabel] = GetRunID()
+	err := e.Apply(localCluster)
+	Expect(err).To(Not(HaveOccurred()))
+
+	DeferCleanup(func() {
+		err := e.Delete(localCluster)
+		Expect(err).To(Not(HaveOccurred()))
+	})
+
+	WaitFor(ctx, wait.Check(func() error {
+		return e.Injected(localCluster)
+	}), wait.Options{Class: timeouts.Rollout, Description: e.Kind + " " + e.Name + " to be injected"})
+}
+
+/*
+Install the Elemental operator
+  - @remarks Rancher Manager has to be installed, charts are the SUSE builds of the operator
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallElementalOperator(k *kubectl.Kubectl) {
+	for _, chart := range []string{"elemental-operator-crds", "elemental-operator"} {
+		RunHelmCmdWithRetry("upgrade", "--install", chart, "oci://registry.suse.com/rancher/"+chart+"-chart",
+			"--namespace", "cattle-elemental-system",
+			"--create-namespace",
+			"--wait", "--wait-for-jobs",
+		)
+	}
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{"cattle-elemental-system", "app=elemental-operator"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "Elemental operator pods"})
+	WaitForCRDEstablished(context.Background(), localCluster, "machineregistrations.elemental.cattle.io")
+}
+
+/*
+Remove the Elemental operator
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RemoveElementalOperator() {
+	for _, chart := range []string{"elemental-operator", "elemental-operator-crds"} {
+		if deployed, _ := GetReleases(localCluster, "cattle-elemental-system"); slices.ContainsFunc(deployed, func(r helmRelease) bool {
+			return r.Name == chart
+		}) {
+			err := kubectl.RunHelmBinaryWithCustomErr("uninstall", chart, "--namespace", "cattle-elemental-system", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+		}
+	}
+}
+
+/*
+Install Rancher Manager, with cert-manager for its self-signed certificate
+  - @remarks Hostname is PUBLIC_FQDN, or a sslip.io name of the node IP
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallRancher(k *kubectl.Kubectl) {
+	InstallCertManager()
+
+	hostname := GetRancherHostname()
+
+	channel := rancherChannel
+	if channel == "" {
+		channel = "stable"
+	}
+
+	err := rancher.DeployRancherManager(hostname, channel, rancherVersion, "", "selfsigned", "none")
+	Expect(err).To(Not(HaveOccurred()))
+
+	WaitFor(context.Background(), wait.Check(func() error {
+		return rancher.CheckPod(k, [][]string{
+			{"cattle-system", "app=rancher"},
+			{"cattle-system", "app=rancher-webhook"},
+		})
+	}), wait.Options{Class: timeouts.Install, Description: "Rancher pods"})
+}
+
+/*
+Install cert-manager, with its CRDs
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallCertManager() {
+	AddHelmRepo("jetstack", "https://charts.jetstack.io")
+	RunHelmCmdWithRetry("upgrade", "--install", "cert-manager", "jetstack/cert-manager",
+		"--namespace", "cert-manager",
+		"--create-namespace",
+		"--set", "crds.enabled=true",
+		"--wait", "--wait-for-jobs",
+	)
+}
+
+/*
+Get the hostname of Rancher Manager
+  - @remarks PUBLIC_FQDN, or a sslip.io name of the node IP
+  - @returns Hostname of Rancher
+*/
+func GetRancherHostname() string {
+	if rancherHostname != "" {
+		return rancherHostname
+	}
+
+	return GetNodeIP() + ".sslip.io"
+}
+
+/*
+Create an API token for the Rancher admin
+  - @remarks The token is a Rancher resource, so no password is needed
+  - @returns Bearer token in name:secret format, the function will fail through Ginkgo in case of issue
+*/
+func CreateRancherToken() string {
+	userID, err := kubectl.RunWithoutErr("get", "users.management.cattle.io",
+		"-o", `jsonpath={.items[?(@.username=="admin")].metadata.name}`)
+	Expect(err).To(Not(HaveOccurred()))
+	Expect(userID).To(Not(BeEmpty()), "no admin user in Rancher")
+
+	name := UniqueName("e2e-token")
+	secret := randomHex(32)
+	file := CopyYaml(rancherTokenYaml, map[string]string{
+		"%NAME%":    name,
+		"%TOKEN%":   secret,
+		"%USER_ID%": userID,
+	})
+	_, err = kubectl.RunWithoutErr("apply", "-f", file)
+	Expect(err).To(Not(HaveOccurred()))
+
+	return name + ":" + secret
+}
+
+/*
+Import a cluster into Rancher
+  - @remarks The registration command of Rancher is executed on the node, K3s has to be installed on it
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param node Runner of the node of the cluster
+  - @param name Name of the provisioning cluster, created in fleet-default
+  - @returns ID of the management cluster, the function will fail through Ginkgo in case of issue
+*/
+func ImportCluster(ctx context.Context, node *runner.Runner, name string) string {
+	file := CopyYaml(downstreamClusterYaml, map[string]string{"%NAME%": name})
+	_, err := kubectl.RunWithoutErr("apply", "-f", file)
+	Expect(err).To(Not(HaveOccurred()))
+
+	// Name of the management cluster, used by the Rancher API
+	var clusterID string
+	WaitFor(ctx, wait.Match(func() string {
+		clusterID, _ = kubectl.RunWithoutErr("get", "clusters.provisioning.cattle.io", name,
+			"--namespace", "fleet-default", "-o", "jsonpath={.status.clusterName}")
+		return clusterID
+	}, Not(BeEmpty())), wait.Options{Class: timeouts.Rollout, Description: "management cluster of " + name})
+
+	var command string
+	WaitFor(ctx, wait.Match(func() string {
+		command, _ = kubectl.RunWithoutErr("get", "clusterregistrationtokens.management.cattle.io",
+			"--namespace", clusterID, "-o", "jsonpath={.items[0].status.insecureCommand}")
+		return command
+	}, Not(BeEmpty())), wait.Options{Class: timeouts.Rollout, Description: "registration command of " + name})
+
+	// Rancher uses a self-signed certificate, hence the insecure command
+	_, err = node.WithSudo().Shell(command)
+	Expect(err).To(Not(HaveOccurred()))
+
+	WaitFor(ctx, wait.Match(func() string {
+		out, _ := kubectl.RunWithoutErr("get", "clusters.management.cattle.io", clusterID,
+			"-o", `jsonpath={.status.conditions[?(@.type=="Ready")].status}`)
+		return out
+	}, Equal("True")), wait.Options{Class: timeouts.Install, Description: "cluster " + name + " to be ready"})
+
+	return clusterID
+}
+
+/*
+Get the kubeconfig of a cluster through the Rancher API
+  - @remarks The kubeconfig goes through the Rancher proxy, not to the nodes of the cluster
+  - @param api Client of the Rancher API
+  - @param clusterID ID of the management cluster
+  - @returns Path of the kubeconfig, the function will fail through Ginkgo in case of issue
+*/
+func GetClusterKubeconfig(api *rancherapi.Client, clusterID string) string {
+	file := filepath.Join(GetTempDir(), "kubeconfig-"+clusterID)
+
+	if dryrun.Enabled() {
+		dryrun.Record("get the kubeconfig of %s through the Rancher API", clusterID)
+		return file
+	}
+
+	config, err := api.Kubeconfig(clusterID)
+	Expect(err).To(Not(HaveOccurred()))
+	err = os.WriteFile(file, []byte(config), 0600)
+	Expect(err).To(Not(HaveOccurred()))
+
+	return file
+}
+
+/*
+Install Kubewarden on a downstream cluster through the Rancher API
+  - @remarks A ClusterRepo is created on the downstream cluster, the charts are installed from it by Rancher
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param api Client of the Rancher API, not used in dry-run mode
+  - @param c Downstream cluster
+  - @param clusterID ID of the downstream cluster in Rancher
+  - @param repoName Name of the ClusterRepo
+  - @param vals Values of the charts
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallKubewardenThroughRancher(ctx context.Context, api *rancherapi.Client, c *cluster.Cluster, clusterID, repoName string, vals *values.Builder) {
+	file := CopyYaml(downstreamRepoYaml, map[string]string{
+		"%NAME%": repoName,
+		"%URL%":  kubewardenFlavors[kubewardenFlavor].RepoURL,
+	})
+	_, err := c.KubectlWithoutErr("apply", "-f", file)
+	Expect(err).To(Not(HaveOccurred()))
+
+	WaitFor(ctx, wait.Match(func() string {
+		out, _ := c.KubectlWithoutErr("get", "clusterrepos.catalog.cattle.io", repoName,
+			"-o", `jsonpath={.status.conditions[?(@.type=="Downloaded")].status}`)
+		return out
+	}, Equal("True")), wait.Options{Class: timeouts.Rollout, Description: "index of " + repoName + " on " + c.Name})
+
+	for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+		if dryrun.Enabled() {
+			dryrun.Record("install %s on %s through the Rancher API", chart, c.Name)
+			continue
+		}
+
+		operation, err := api.InstallChart(clusterID, repoName, rancherapi.Chart{
+			Name:      chart,
+			Namespace: kubewardenNS,
+			Values:    vals.Values(chart),
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		WaitFor(ctx, wait.Check(func() error {
+			releases, err := GetReleases(c, kubewardenNS)
+			if err != nil {
+				return err
+			}
+			if !slices.ContainsFunc(releases, func(r helmRelease) bool { return r.Name == chart }) {
+				return fmt.Errorf("release %s not deployed by %s", chart, operation)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Install, Description: "release " + chart + " on " + c.Name})
+
+		// The controller chart creates resources of the CRDs
+		if chart == "kubewarden-crds" {
+			for _, crd := range kubewardenCRDs {
+				WaitForCRDEstablished(ctx, c, crd)
+			}
+		}
+	}
+
+	// Webhooks are checked by the policies enforced on the cluster
+	WaitForDeploymentReady(ctx, c, kubewardenNS, "kubewarden-controller")
+	WaitForDeploymentReady(ctx, c, kubewardenNS, "policy-server-default")
+}
+
+/*
+Get the services referenced by Kubewarden webhooks
+  - @returns List of referenced services in namespace/name format
+*/
+func GetWebhookServices() []string {
+	var services []string
+
+	for _, obj := range []string{"validatingwebhookconfigurations", "mutatingwebhookconfigurations"} {
+		out, err := kubectl.RunWithoutErr("get", obj,
+			"-o", "jsonpath={range .items[*].webhooks[*]}{.clientConfig.service.namespace}/{.clientConfig.service.name}{\"\\n\"}{end}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		for _, svc := range strings.Fields(out) {
+			// Only keep Kubewarden services
+			if strings.Contains(svc, "kubewarden") || strings.Contains(svc, "policy-server") {
+				services = append(services, svc)
+			}
+		}
+	}
+
+	return services
+}
+
+/*
+Check that all services referenced by Kubewarden webhooks exist
+  - @returns Nothing or an error listing the missing services
+*/
+func CheckWebhookServices() error {
+	var missing []string
+
+	for _, svc := range GetWebhookServices() {
+		ns, name, _ := strings.Cut(svc, "/")
+		if _, err := kubectl.RunWithoutErr("get", "service", name, "--namespace", ns); err != nil {
+			missing = append(missing, svc)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("webhooks reference missing services: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+/*
+Get the images of all the containers running in a namespace
+  - @param ns Namespace of the pods
+  - @returns List of pod=image entries, init containers included
+*/
+func GetPodImages(ns string) []string {
+	out, err := kubectl.RunWithoutErr("get", "pods", "--namespace", ns, "--field-selector", "status.phase=Running",
+		"-o", "jsonpath={range .items[*]}{.metadata.name}={.spec.initContainers[*].image} {.spec.containers[*].image}{\"\\n\"}{end}")
+	Expect(err).To(Not(HaveOccurred()))
+
+	var images []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		pod, refs, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		for _, ref := range strings.Fields(refs) {
+			images = append(images, pod+"="+ref)
+		}
+	}
+
+	return images
+}
+
+/*
+Check that all the running containers of a namespace use images of a registry
+  - @param ns Namespace of the pods
+  - @param registry Registry prefix, e.g. registry.suse.com or harbor.local/mirror
+  - @returns Nothing or an error listing the images from other registries
+*/
+func CheckImagesRegistry(ns, registry string) error {
+	images := GetPodImages(ns)
+	if len(images) == 0 {
+		return fmt.Errorf("no running container in namespace %s", ns)
+	}
+
+	var others []string
+	for _, image := range images {
+		_, ref, _ := strings.Cut(image, "=")
+		if !strings.HasPrefix(ref, strings.TrimSuffix(registry, "/")+"/") {
+			others = append(others, image)
+		}
+	}
+
+	if len(others) > 0 {
+		return fmt.Errorf("images not from %s: %s", registry, strings.Join(others, ", "))
+	}
+
+	return nil
+}
+
+/*
+Check if K3s can be reused by the initial installation
+  - @param node Runner of the node where K3s is installed
+  - @returns True if reuse is asked and K3s is installed
+*/
+func ReuseK3s(node *runner.Runner) bool {
+	if !ReuseInstalled() {
+		return false
+	}
+	_, err := node.Run("test", "-x", "/usr/local/bin/k3s")
+	return err == nil
+}
+
+/*
+Install K3s
+  - @param node Runner of the node where K3s is installed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallK3s(node *runner.Runner) {
+	// Before any change, the configuration of K3s is written as root
+	RequireDisposable(node, "install K3s")
+
+	_, err := node.Run("test", "-x", "/usr/local/bin/k3s")
+	installed := err == nil
+
+	if installed {
+		// The installer upgrades (or restarts) K3s in place
+		GinkgoWriter.Printf("Upgrading K3s already installed\n")
+	}
+
+	// Don't uninstall a K3s that was not installed by the tests
+	installedByTests := !installed
+
+	if k3sAuditLogEnabled {
+		EnableK3sAuditLog(node)
+	}
+	if k3sSELinux {
+		EnableK3sSELinux(node)
+	}
+	if k3sIPFamily != "" {
+		EnableK3sIPFamily(node, k3sIPFamily)
+	}
+	// Only for the node of K3S_NODE_HOST, not the other nodes created by the tests
+	if k3sExternalIP != "" && node.Host() == k3sNode.Host() {
+		EnableK3sExternalIP(node, k3sExternalIP)
+	}
+
+	installer := node.WithEnv("INSTALL_K3S_EXEC=--disable metrics-server")
+	if k3sVersion != "" {
+		installer = installer.WithEnv("INSTALL_K3S_VERSION=" + k3sVersion)
+	}
+
+	// Script of the cache, the node does not need to reach get.k3s.io
+	script := GetArtifact(download.K3sScript(os.Getenv("K3S_INSTALL_SHA256")))
+	err = node.PutFile(script, "/tmp/k3s-install.sh")
+	Expect(err).To(Not(HaveOccurred()))
+
+	// Retry in case of (sporadic) failure...
+	WaitFor(context.Background(), func(context.Context) (string, error) {
+		return installer.Run("sh", "/tmp/k3s-install.sh")
+	}, wait.Options{Class: timeouts.Install, Description: "K3s installation"})
+
+	if installedByTests {
+		_, err := node.WithSudo().Run("touch", k3sMarkerFile)
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	// Installed by the K3s script on RPM based distributions, containers would not start without it
+	if k3sSELinux {
+		_, err := node.Run("rpm", "-q", "k3s-selinux")
+		Expect(err).To(Not(HaveOccurred()), "k3s-selinux is not installed")
+	}
+}
+
+/*
+Get an external artifact, downloaded in the cache if needed
+  - @remarks See download.Get, E2E_CACHE_DIR and E2E_CACHE_OFFLINE set where the cache is and if it can be filled
+  - @param a Artifact to get
+  - @returns Path of the artifact in the cache, the function will fail through Ginkgo in case of issue
+*/
+func GetArtifact(a download.Artifact) string {
+	file, err := download.Get(context.Background(), a)
+	Expect(err).To(Not(HaveOccurred()))
+
+	return file
+}
+
+/*
+Advertise a public address of the node, e.g. for cloud instances behind NAT
+  - @remarks The address is added to the certificate of the API server, kubectl and helm use it from the test host
+  - @param node Runner of the node where K3s is installed
+  - @param ip Public IP address of the node
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func EnableK3sExternalIP(node *runner.Runner, ip string) {
+	config := fmt.Sprintf("node-external-ip: %s\ntls-san:\n  - %s\n", ip, ip)
+
+	_, err := node.WithSudo().Shell(fmt.Sprintf("mkdir -p /etc/rancher/k3s/config.yaml.d && echo %s | base64 -d > /etc/rancher/k3s/config.yaml.d/50-e2e-external-ip.yaml",
+		base64.StdEncoding.EncodeToString([]byte(config))))
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Run K3s in dual-stack or IPv6-only mode
+  - @remarks Configuration is added before K3s is installed or restarted, the node needs an IPv6 address
+  - @remarks K3S_NODE_IPS sets the addresses of the node, e.g. 192.168.122.10,fd00::10 for dual-stack
+  - @param node Runner of the node where K3s is installed
+  - @param family IP family of the cluster, dual or ipv6
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func EnableK3sIPFamily(node *runner.Runner, family string) {
+	cidrs := map[string][2]string{
+		"dual": {"10.42.0.0/16,2001:cafe:42::/56", "10.43.0.0/16,2001:cafe:43::/112"},
+		"ipv6": {"2001:cafe:42::/56", "2001:cafe:43::/112"},
+	}
+	cidr, ok := cidrs[family]
+	Expect(ok).To(BeTrue(), "unknown IP family %q, use dual or ipv6", family)
+
+	// Pods use private IPv6 addresses, they need masquerading to reach the outside
+	config := fmt.Sprintf("cluster-cidr: %s\nservice-cidr: %s\nflannel-ipv6-masq: true\n", cidr[0], cidr[1])
+	if ips := os.Getenv("K3S_NODE_IPS"); ips != "" {
+		config += "node-ip: " + ips + "\n"
+	}
+
+	_, err := node.WithSudo().Shell(fmt.Sprintf("mkdir -p /etc/rancher/k3s/config.yaml.d && echo %s | base64 -d > /etc/rancher/k3s/config.yaml.d/50-e2e-ip-family.yaml",
+		base64.StdEncoding.EncodeToString([]byte(config))))
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Run K3s with SELinux enforcing
+  - @remarks Configuration is added before K3s is installed or restarted, so it is used at the next start
+  - @param node Runner of the node where K3s is installed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func EnableK3sSELinux(node *runner.Runner) {
+	if !dryrun.Enabled() {
+		out, err := node.Run("getenforce")
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(strings.TrimSpace(out)).To(Equal("Enforcing"), "SELinux is not enforcing on the node")
+	}
+
+	_, err := node.WithSudo().Shell("mkdir -p /etc/rancher/k3s/config.yaml.d && echo 'selinux: true' > /etc/rancher/k3s/config.yaml.d/50-e2e-selinux.yaml")
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Enable the audit log of the K3s API server
+  - @remarks Configuration is added before K3s is installed or restarted, so it is used at the next start
+  - @param node Runner of the node where K3s is installed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func EnableK3sAuditLog(node *runner.Runner) {
+	policy, err := os.ReadFile(k3sAuditPolicyYaml)
+	Expect(err).To(Not(HaveOccurred()))
+
+	// Same place as the other K3s files, config.yaml is kept for the user
+	config := fmt.Sprintf(`kube-apiserver-arg:
+  - audit-log-path=%s
+  - audit-policy-file=/etc/rancher/k3s/audit-policy.yaml
+  - audit-log-maxage=1
+  - audit-log-maxsize=100
+`, k3sAuditLog)
+
+	for file, content := range map[string]string{
+		"/etc/rancher/k3s/audit-policy.yaml":               string(policy),
+		"/etc/rancher/k3s/config.yaml.d/50-e2e-audit.yaml": config,
+	} {
+		_, err := node.WithSudo().Shell(fmt.Sprintf("mkdir -p $(dirname %[1]s) && echo %[2]s | base64 -d > %[1]s",
+			file, base64.StdEncoding.EncodeToString([]byte(content))))
+		Expect(err).To(Not(HaveOccurred()))
+	}
+}
+
+/*
+Uninstall K3s
+  - @param node Runner of the node where K3s is installed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func UninstallK3s(node *runner.Runner) {
+	RequireDisposable(node, "uninstall K3s")
+
+	// Installed and run as root, like K3s itself
+	_, err := node.WithSudo().Run("k3s-uninstall.sh")
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Make sure a destructive step can be done on a node, e.g. a developer workstation
+  - @remarks Approved with E2E_YES=true or CI=true, on a disposable node or on the terminal, see pkg/guard
+  - @param node Runner of the node
+  - @param action Description of the step, e.g. uninstall K3s
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func ConfirmDestructive(node *runner.Runner, action string) {
+	err := guard.Confirm(node, action)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Refuse a step that could destroy a node which is not a disposable test node
+  - @remarks Neither E2E_YES nor CI are enough, the node has to be flagged, see pkg/guard
+  - @param node Runner of the node
+  - @param action Description of the step, e.g. install K3s
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RequireDisposable(node *runner.Runner, action string) {
+	err := guard.Require(node, action)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Mark a node created by the tests as disposable
+  - @param node Runner of the node
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func MarkDisposable(node *runner.Runner) {
+	err := guard.Mark(node)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Save the kubeconfig in use, before ConfigureKubeconfig replaces it
+  - @remarks Used by the tests creating their own nodes, the user kubeconfig must not point to a deleted node
+  - @returns Function restoring KUBECONFIG and ~/.kube/config, e.g. for DeferCleanup
+*/
+func SaveKubeconfig() func() {
+	env, envSet := os.LookupEnv("KUBECONFIG")
+	clusterKubeconfig := localCluster.Kubeconfig
+
+	userKubeconfig := os.Getenv("HOME") + "/.kube/config"
+	data, err := os.ReadFile(userKubeconfig)
+	userSet := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	return func() {
+		if envSet {
+			Expect(os.Setenv("KUBECONFIG", env)).To(Succeed())
+		} else {
+			Expect(os.Unsetenv("KUBECONFIG")).To(Succeed())
+		}
+		localCluster.Kubeconfig = clusterKubeconfig
+
+		if !userSet {
+			err := os.Remove(userKubeconfig)
+			if !os.IsNotExist(err) {
+				Expect(err).To(Not(HaveOccurred()))
+			}
+			return
+		}
+
+		// Same as ConfigureKubeconfig, renamed in one go so nobody reads a partial file
+		tmpKubeconfig := userKubeconfig + "." + strconv.Itoa(GinkgoParallelProcess())
+		err := os.WriteFile(tmpKubeconfig, data, 0600)
+		Expect(err).To(Not(HaveOccurred()))
+
+		err = os.Rename(tmpKubeconfig, userKubeconfig)
+		Expect(err).To(Not(HaveOccurred()))
+	}
+}
+
+/*
+Use the K3s kubeconfig of a node
+  - @param node Runner of the node where K3s is installed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func ConfigureKubeconfig(node *runner.Runner) {
+	// The user kubeconfig must not be replaced by an empty one
+	if dryrun.Enabled() {
+		dryrun.Record("use kubeconfig of %s", cmp.Or(node.Host(), "localhost"))
+		return
+	}
+
+	// Each parallel process uses its own copy
+	localKubeconfig := filepath.Join(GetTempDir(), "kubeconfig")
+
+	err := node.GetFile(localKubeconfig, "/etc/rancher/k3s/k3s.yaml")
+	Expect(err).To(Not(HaveOccurred()))
+
+	// Replace localhost with the IP of the remote node
+	if host := node.Host(); host != "" {
+		err = tools.Sed("127.0.0.1", host, localKubeconfig)
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	// Default of the helpers of ele-testhelpers, the other clusters are always given explicitly
+	err = os.Setenv("KUBECONFIG", localKubeconfig)
+	Expect(err).To(Not(HaveOccurred()))
+	localCluster.Kubeconfig = localKubeconfig
+
+	// Also keep it in ~/.kube/config for the other processes and manual debugging,
+	// renamed in one go so nobody reads a partial file
+	userKubeconfig := os.Getenv("HOME") + "/.kube/config"
+	err = os.MkdirAll(filepath.Dir(userKubeconfig), 0755)
+	Expect(err).To(Not(HaveOccurred()))
+
+	tmpKubeconfig := userKubeconfig + "." + strconv.Itoa(GinkgoParallelProcess())
+	err = tools.CopyFile(localKubeconfig, tmpKubeconfig)
+	Expect(err).To(Not(HaveOccurred()))
+
+	err = os.Rename(tmpKubeconfig, userKubeconfig)
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Get the versions of a chart available in the Helm repositories
+  - @param chart Chart to search, in repo/chart format
+  - @returns List of chart/app versions, newest first
+*/
+func GetChartVersions(chart string) []helmChart {
+	out, err := kubectl.RunHelmBinaryWithOutput("search", "repo", chart, "--versions", "--devel", "-o", "json")
+	Expect(err).To(Not(HaveOccurred()))
+
+	var charts []helmChart
+	err = json.Unmarshal([]byte(out), &charts)
+	Expect(err).To(Not(HaveOccurred()))
+
+	// Search is a regex, so other charts could match
+	var versions []helmChart
+	for _, c := range charts {
+		if c.Name == chart {
+			versions = append(versions, c)
+		}
+	}
+	Expect(versions).To(Not(BeEmpty()), "no version found for chart %s", chart)
+
+	return versions
+}
+
+/*
+Get the chart version providing an app version
+  - @param chart Chart to search, in repo/chart format
+  - @param appVersion App version to look for
+  - @returns Chart version
+*/
+func GetChartVersion(chart, appVersion string) string {
+	// No repository has been added
+	if dryrun.Enabled() {
+		return "<" + chart + " providing " + appVersion + ">"
+	}
+
+	for _, c := range GetChartVersions(chart) {
+		if c.AppVersion == appVersion {
+			return c.Version
+		}
+	}
+
+	Fail("no version of chart " + chart + " provides app version " + appVersion)
+	return ""
+}
+
+/*
+Get the previous stable Kubewarden version
+  - @returns App version released before the latest stable one
+*/
+func GetPreviousKubewardenVersion() string {
+	if dryrun.Enabled() {
+		return "<previous stable version>"
+	}
+
+	var stable []string
+	for _, c := range GetChartVersions(kubewardenFlavors[kubewardenFlavor].RepoName + "/kubewarden-controller") {
+		if semver.IsValid(c.AppVersion) && semver.Prerelease(c.AppVersion) == "" && !slices.Contains(stable, c.AppVersion) {
+			stable = append(stable, c.AppVersion)
+		}
+	}
+	Expect(len(stable)).To(BeNumerically(">=", 2), "not enough stable Kubewarden versions")
+
+	semver.Sort(stable)
+	return stable[len(stable)-2]
+}
+
+/*
+Get the deployed Helm releases
+  - @param c Cluster of the releases
+  - @param ns Namespace of the releases
+  - @returns List of deployed releases or an error
+*/
+func GetReleases(c *cluster.Cluster, ns string) ([]helmRelease, error) {
+	out, err := c.Helm("list", "--namespace", ns, "--deployed", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []helmRelease
+	if err := json.Unmarshal([]byte(out), &releases); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+/*
+Make sure a Helm release can be installed or upgraded
+  - @remarks Releases left in a pending or failed state by a broken run are rolled back or uninstalled
+  - @param ns Namespace of the release
+  - @param name Name of the release
+  - @returns True if the release is already deployed in the namespace
+*/
+func PrepareRelease(ns, name string) bool {
+	out, err := kubectl.RunHelmBinaryWithOutput("list", "--all", "--all-namespaces", "--filter", "^"+name+"$", "-o", "json")
+	Expect(err).To(Not(HaveOccurred()))
+
+	var releases []helmRelease
+	err = json.Unmarshal([]byte(out), &releases)
+	Expect(err).To(Not(HaveOccurred()))
+
+	i := slices.IndexFunc(releases, func(r helmRelease) bool { return r.Namespace == ns })
+	if i < 0 {
+		// Resources owned by a release in another namespace cannot be installed again
+		for _, r := range releases {
+			Expect(r.Status).To(Not(Equal("deployed")), "release %s is already deployed in namespace %s", name, r.Namespace)
+		}
+		return false
+	}
+
+	r := releases[i]
+	switch r.Status {
+	case "deployed":
+		return true
+	case "pending-upgrade", "pending-rollback":
+		GinkgoWriter.Printf("Rolling back release %s/%s left in %s state\n", ns, name, r.Status)
+		err = kubectl.RunHelmBinaryWithCustomErr("rollback", name, "--namespace", ns, "--wait")
+	case "failed":
+		// Upgrade works if a previous revision has been deployed
+		if r.Revision != "1" {
+			return false
+		}
+		fallthrough
+	default:
+		GinkgoWriter.Printf("Uninstalling release %s/%s left in %s state\n", ns, name, r.Status)
+		err = kubectl.RunHelmBinaryWithCustomErr("uninstall", name, "--namespace", ns, "--wait")
+	}
+	Expect(err).To(Not(HaveOccurred()))
+
+	return false
+}
+
+/*
+Get the installed Kubewarden version
+  - @param ns Namespace where Kubewarden is installed
+  - @returns App version of the kubewarden-controller release
+*/
+func GetInstalledKubewardenVersion(ns string) string {
+	releases, err := GetReleases(localCluster, ns)
+	Expect(err).To(Not(HaveOccurred()))
+
+	i := slices.IndexFunc(releases, func(r helmRelease) bool { return r.Name == "kubewarden-controller" })
+	Expect(i).To(BeNumerically(">=", 0), "kubewarden-controller is not deployed in %s", ns)
+
+	return releases[i].AppVersion
+}
+
+/*
+Skip the spec if the installed Kubewarden does not provide a feature
+  - @remarks Allows the same tests to be used on all maintained release lines
+  - @param feature Name of the feature, as in kubewardenFeatures
+  - @returns Nothing, the spec is skipped with the reason in the report
+*/
+func RequireKubewardenFeature(feature string) {
+	minVersion, ok := kubewardenFeatures[feature]
+	Expect(ok).To(BeTrue(), "unknown Kubewarden feature %s", feature)
+
+	if dryrun.Enabled() {
+		dryrun.Record("require Kubewarden %s for %s", minVersion, feature)
+		return
+	}
+
+	installed := GetInstalledKubewardenVersion(kubewardenNS)
+	if !version.AtLeast(installed, minVersion) {
+		Skip(fmt.Sprintf("%s needs Kubewarden >= %s, installed version is %s", feature, minVersion, installed))
+	}
+}
+
+/*
+Check if Kubewarden is already installed
+  - @param ns Namespace where Kubewarden is installed
+  - @param version Expected Kubewarden app version, any if empty
+  - @returns True if all the Kubewarden charts are deployed with the expected version
+*/
+func IsKubewardenInstalled(ns, version string) bool {
+	releases, err := GetReleases(localCluster, ns)
+	if err != nil {
+		return false
+	}
+
+	for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+		i := slices.IndexFunc(releases, func(r helmRelease) bool { return r.Name == chart })
+		if i < 0 {
+			return false
+		}
+
+		if chart == "kubewarden-controller" && version != "" && releases[i].AppVersion != version {
+			GinkgoWriter.Printf("Kubewarden %s is installed, %s is expected\n", releases[i].AppVersion, version)
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+Install Kubewarden with the default values of the tests
+  - @param k kubectl structure
+  - @param ns Namespace where Kubewarden is installed
+  - @param version Kubewarden app version to install, latest if empty
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func InstallKubewarden(k *kubectl.Kubectl, ns, version string) {
+	InstallKubewardenWithValues(k, ns, version, KubewardenValues())
+}
+
+/*
+Add the Helm repository of the tested Kubewarden flavor
+  - @remarks Set with KUBEWARDEN_FLAVOR, upstream or prime
+  - @returns Name of the repository
+*/
+func AddKubewardenRepo() string {
+	f := kubewardenFlavors[kubewardenFlavor]
+	return AddHelmRepo(f.RepoName, f.RepoURL)
+}
+
+/*
+Add (or update) a Helm repository
+  - @remarks With an offline repository (HELM_OFFLINE_REPO), it is added under the same name, so nothing is fetched upstream
+  - @param name Name of the repository
+  - @param url URL of the upstream repository
+  - @returns Name of the repository
+*/
+func AddHelmRepo(name, url string) string {
+	if offlineChartRepo != "" {
+		url = offlineChartRepo
+	}
+
+	// Only this repository is updated, the others could be upstream ones
+	RunHelmCmdWithRetry("repo", "add", name, url, "--force-update")
+	RunHelmCmdWithRetry("repo", "update", name)
+
+	return name
+}
+
+/*
+Get the default Helm values of the tests
+  - @remarks Images and the modules of the recommended policies are pulled from KUBEWARDEN_REGISTRY if defined
+  - @returns Values builder, to be completed if needed
+*/
+func KubewardenValues() *values.Builder {
+	vals := values.Default()
+	if kubewardenRegistry != "" {
+		vals.Registry(kubewardenRegistry).PoliciesRegistry(kubewardenRegistry, false)
+	}
+
+	return vals
+}
+
+/*
+Check if the initial installation can reuse what is already there
+  - @remarks Set with INSTALL_MODE=skip (or REUSE_STACK=true), only for the install-* steps:
+    the reinstalls and values changes of the tests are always done
+  - @returns True if already installed components are kept
+*/
+func ReuseInstalled() bool {
+	return installMode == installSkip
 }
 
 /*
-Get configured backup directory
-  - @returns Configured backup directory
+Check that an install step is the only one of its ginkgo run
+  - @remarks Install steps are Serial, with other specs in the same run they would be executed after them (e.g. with ginkgo -p)
+  - @param step Label of the install step
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
-func GetBackupDir() string {
-	claimName, err := kubectl.RunWithoutErr("get", "pod", "-l", "app.kubernetes.io/name=rancher-backup",
-		"--namespace", "cattle-resources-system",
-		"-o", "jsonpath={.items[*].spec.volumes[?(@.name==\"pv-storage\")].persistentVolumeClaim.claimName}")
-	Expect(err).To(Not(HaveOccurred()))
+func RequireInstallStep(step string) {
+	if filter := GinkgoLabelFilter(); filter != step {
+		Fail(fmt.Sprintf("%s has to be run alone with --label-filter %s, not with %q", step, step, filter))
+	}
+}
 
-	out, err := kubectl.RunWithoutErr("get", "pv",
-		"--namespace", "cattle-resources-system",
-		"-o", "jsonpath={.items[?(@.spec.claimRef.name==\""+claimName+"\")].spec.local.path}")
-	Expect(err).To(Not(HaveOccurred()))
+/*
+Check if Kubewarden can be reused by the initial installation
+  - @remarks Releases left in a pending or failed state are fixed first, see PrepareRelease
+  - @param ns Namespace where Kubewarden is installed
+  - @param version Expected Kubewarden app version, any if empty
+  - @returns True if reuse is asked and all the charts are deployed with the expected version
+*/
+func ReuseKubewarden(ns, version string) bool {
+	deployed := true
+	for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+		deployed = PrepareRelease(ns, chart) && deployed
+	}
 
-	return out
+	return ReuseInstalled() && deployed && IsKubewardenInstalled(ns, version)
 }
 
 /*
-Install rancher-backup operator
+Install Kubewarden
+  - @remarks Always installed or upgraded, the initial installation checks ReuseKubewarden first
   - @param k kubectl structure
+  - @param ns Namespace where Kubewarden is installed
+  - @param version Kubewarden app version to install, latest if empty
+  - @param vals Helm values of the charts
   - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
-func InstallBackupOperator(k *kubectl.Kubectl) {
-	// Default chart
-	chartRepo := "rancher-chart"
-
-	// Set specific operator version if defined
-	if backupRestoreVersion != "" {
-		chartRepo = "https://github.com/rancher/backup-restore-operator/releases/download/" + backupRestoreVersion
-	} else {
-		RunHelmCmdWithRetry("repo", "add", chartRepo, "https://charts.rancher.io")
-		RunHelmCmdWithRetry("repo", "update")
+func InstallKubewardenWithValues(k *kubectl.Kubectl, ns, version string, vals *values.Builder) {
+	for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
+		PrepareRelease(ns, chart)
 	}
 
-	for _, chart := range []string{"rancher-backup-crd", "rancher-backup"} {
-		// Set the filename in chart if a custom version is defined
+	// Charts of the tested flavor
+	chartRepo := AddKubewardenRepo()
+
+	for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
 		chartName := chart
-		if backupRestoreVersion != "" {
-			chartName = chart + "-" + strings.Trim(backupRestoreVersion, "v") + ".tgz"
-		}
 
 		// Global installation flags
 		flags := []string{
 			"upgrade", "--install", chart, chartRepo + "/" + chartName,
-			"--namespace", "cattle-resources-system",
+			"--namespace", ns,
 			"--create-namespace",
 			"--wait", "--wait-for-jobs",
 		}
 
-		// Add specific options for the rancher-backup chart
-		if chart == "rancher-backup" {
-			flags = append(flags,
-				"--set", "persistence.enabled=true",
-				"--set", "persistence.storageClass=local-path",
-			)
+		// Set the chart version if a custom version is defined
+		if version != "" {
+			flags = append(flags, "--version", GetChartVersion(chartRepo+"/"+chartName, version))
 		}
 
-		RunHelmCmdWithRetry(flags...)
+		valuesFlags, err := vals.Flags(chart, GetTempDir())
+		Expect(err).To(Not(HaveOccurred()))
 
-		Eventually(func() error {
-			return rancher.CheckPod(k, [][]string{{"cattle-resources-system", "app.kubernetes.io/name=rancher-backup"}})
-		}, tools.SetTimeout(4*time.Minute), 30*time.Second).Should(Not(HaveOccurred()))
+		RunHelmCmdWithRetry(append(flags, valuesFlags...)...)
+
+		// The controller chart creates resources of the CRDs
+		if chart == "kubewarden-crds" {
+			for _, crd := range kubewardenCRDs {
+				WaitForCRDEstablished(context.Background(), localCluster, crd)
+			}
+		}
+	}
+
+	// Wait for all pods to be started
+	checkList := [][]string{
+		{ns, "app.kubernetes.io/name=kubewarden-controller"},
+		{ns, "app.kubernetes.io/name=policy-server"},
 	}
+	err := rancher.CheckPod(k, checkList)
+	Expect(err).To(Not(HaveOccurred()))
+
+	WaitForKubewardenReady(context.Background(), ns)
 }
 
 /*
-Install K3s
+Wait for the Kubewarden components to be ready
+  - @remarks Pods can be running before the webhooks are served, policies would be rejected
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param ns Namespace where Kubewarden is installed
   - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
-func InstallK3s() {
-	// Get K3s installation script
-	fileName := "k3s-install.sh"
-	Eventually(func() error {
-		return tools.GetFileFromURL("https://get.k3s.io", fileName, true)
-	}, tools.SetTimeout(2*time.Minute), 10*time.Second).ShouldNot(HaveOccurred())
+func WaitForKubewardenReady(ctx context.Context, ns string) {
+	WaitForDeploymentReady(ctx, localCluster, ns, "kubewarden-controller")
+	WaitForWebhookReady(ctx, ns, "kubewarden-controller-webhook-service", 443)
+	WaitForDeploymentReady(ctx, localCluster, ns, "policy-server-default")
+	WaitForWebhookReady(ctx, ns, "policy-server-default", 8443)
+	WaitForCABundles(ctx, ns)
+}
 
-	// Set command and arguments
-	installCmd := exec.Command("sh", fileName)
-	installCmd.Env = append(os.Environ(), "INSTALL_K3S_EXEC=--disable metrics-server")
+/*
+Wait for the caBundle of the Kubewarden webhooks to match the CA of the controller
+  - @remarks Used after install, upgrade, certificate rotation and restore, certificates served by the policy servers are checked too
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param ns Namespace where Kubewarden is installed
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func WaitForCABundles(ctx context.Context, ns string) {
+	WaitFor(ctx, wait.Check(func() error {
+		ca, err := cabundle.CA(ns)
+		if err != nil {
+			return err
+		}
+		webhooks, err := cabundle.Inspect(ctx)
+		if err != nil {
+			return err
+		}
 
-	// Retry in case of (sporadic) failure...
-	count := 1
-	Eventually(func() error {
-		// Execute K3s installation
-		out, err := installCmd.CombinedOutput()
-		GinkgoWriter.Printf("K3s installation loop %d:\n%s\n", count, out)
-		count++
-		return err
-	}, tools.SetTimeout(2*time.Minute), 5*time.Second).Should(Not(HaveOccurred()))
+		matcher := cabundle.MatchCA(ca)
+		if ok, err := matcher.Match(webhooks); err != nil || !ok {
+			return cmp.Or(err, fmt.Errorf("%s", matcher.FailureMessage(webhooks)))
+		}
+
+		return nil
+	}), wait.Options{Class: timeouts.Rollout, Description: "caBundle of the Kubewarden webhooks"})
 }
 
 /*
-Install Kubewarden
-  - @param k kubectl structure
+Create a VM
+  - @param name Name of the VM
+  - @param disk Path of the VM disk, created from image if it does not exist
+  - @param image Base disk image of the VM
+  - @param mac MAC address of the VM, used to get a fixed IP from DHCP
   - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
-func InstallKubewarden(k *kubectl.Kubectl) {
-	// Install Kubewarden CRDs
-	RunHelmCmdWithRetry("repo", "add", "kubewarden", "https://charts.kubewarden.io")
-	RunHelmCmdWithRetry("repo", "update")
+func CreateVM(name, disk, image, mac string) {
+	// Use a dedicated disk, so the base image is never modified
+	if _, err := os.Stat(disk); os.IsNotExist(err) {
+		_, err := runner.Run("qemu-img", "create", "-f", "qcow2", "-F", "qcow2", "-b", image, disk)
+		Expect(err).To(Not(HaveOccurred()))
+	}
 
-	// Default chart
-	chartRepo := "kubewarden"
+	_, err := runner.Sudo("virt-install",
+		"--name", name,
+		"--memory", "16384",
+		"--vcpus", "4",
+		"--disk", "path="+disk+",bus=sata",
+		"--import",
+		"--os-variant", "opensuse-unknown",
+		"--network=default,mac="+mac,
+		"--noautoconsole")
+	Expect(err).To(Not(HaveOccurred()))
+}
 
-	for _, chart := range []string{"kubewarden-crds", "kubewarden-controller", "kubewarden-defaults"} {
-		// Set the filename in chart if a custom version is defined
-		chartName := chart
+/*
+Destroy a VM and remove its storage
+  - @param name Name of the VM
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func DeleteVM(name string) {
+	// Don't check return code, as the VM could be already stopped
+	_, _ = runner.Sudo("virsh", "destroy", name)
 
-		// Global installation flags
-		flags := []string{
-			"upgrade", "--install", chart, chartRepo + "/" + chartName,
-			"--namespace", "kubewarden",
-			"--create-namespace",
-			"--wait", "--wait-for-jobs",
-		}
+	_, err := runner.Sudo("virsh", "undefine", name, "--remove-all-storage")
+	Expect(err).To(Not(HaveOccurred()))
+}
 
-		// Add specific options for the rancher-backup chart
-		if chart == "kubewarden-controller" {
-			flags = append(flags,
-				"--set", "auditScanner.policyReporter=true",
-			)
-		}
+/*
+Get the IP of the K3s node
+  - @remarks Node ports are reachable on this IP from the pods and the test host
+  - @returns External IP of the first node if set (K3S_NODE_EXTERNAL_IP), its internal IP otherwise,
+    the function will fail through Ginkgo in case of issue
+*/
+func GetNodeIP() string {
+	for _, addr := range []string{"ExternalIP", "InternalIP"} {
+		ip, err := kubectl.RunWithoutErr("get", "nodes",
+			"-o", "jsonpath={.items[0].status.addresses[?(@.type==\""+addr+"\")].address}")
+		Expect(err).To(Not(HaveOccurred()))
 
-		if chart == "kubewarden-defaults" {
-			flags = append(flags,
-				"--set", "recommendedPolicies.enabled=true",
-				"--set", "recommendedPolicies.defaultPolicyMode=protect",
-			)
+		if ip = strings.TrimSpace(ip); ip != "" {
+			return ip
 		}
-		RunHelmCmdWithRetry(flags...)
 	}
 
-	// Wait for all pods to be started
-	checkList := [][]string{
-		{"kubewarden", "app.kubernetes.io/name=kubewarden-controller"},
-		{"kubewarden", "app.kubernetes.io/name=policy-server"},
+	return ""
+}
+
+/*
+Restart K3s, e.g. to apply a configuration change
+  - @param node Runner of the node where K3s is installed
+  - @param k kubectl structure
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RestartK3s(node *runner.Runner, k *kubectl.Kubectl) {
+	_, err := node.WithSudo().Run("systemctl", "restart", "k3s")
+	Expect(err).To(Not(HaveOccurred()))
+
+	WaitForK3s(k)
+}
+
+/*
+Check if K3s uses an embedded etcd datastore
+  - @remarks This function is only used internally, not exported
+  - @param node Runner of the node where K3s is installed
+  - @returns True for etcd, false for the default SQLite datastore
+*/
+func isK3sEtcd(node *runner.Runner) bool {
+	_, err := node.WithSudo().Run("test", "-d", k3sDBDir+"/etcd")
+	return err == nil
+}
+
+/*
+Save a snapshot of the K3s datastore
+  - @remarks etcd is saved with k3s etcd-snapshot, SQLite has no snapshot command so its files are copied while K3s is stopped
+  - @param node Runner of the node where K3s is installed
+  - @param k kubectl structure
+  - @param name Name of the snapshot
+  - @returns Path of the snapshot on the node
+*/
+func SaveK3sSnapshot(node *runner.Runner, k *kubectl.Kubectl, name string) string {
+	sudo := node.WithSudo()
+
+	if isK3sEtcd(node) {
+		_, err := sudo.Run("k3s", "etcd-snapshot", "save", "--name", name)
+		Expect(err).To(Not(HaveOccurred()))
+
+		// The node name and a timestamp are added to the name
+		out, err := sudo.Shell(fmt.Sprintf("ls -1t %s/snapshots/%s-* | head -1", k3sDBDir, name))
+		Expect(err).To(Not(HaveOccurred()))
+		file := strings.TrimSpace(out)
+		Expect(file).To(Not(BeEmpty()), "snapshot %s not found", name)
+		return file
+	}
+
+	dir := k3sDBDir + "/e2e-snapshots/" + name
+	_, err := sudo.Run("systemctl", "stop", "k3s")
+	Expect(err).To(Not(HaveOccurred()))
+	_, err = sudo.Shell(fmt.Sprintf("mkdir -p %[1]s && cp -a %[2]s/state.db* %[1]s/", dir, k3sDBDir))
+	Expect(err).To(Not(HaveOccurred()))
+	StartK3s(node)
+	WaitForK3s(k)
+
+	return dir
+}
+
+/*
+Restore a snapshot of the K3s datastore
+  - @remarks The whole cluster goes back to the time of the snapshot, K3s is stopped during the restore
+  - @param node Runner of the node where K3s is installed
+  - @param k kubectl structure
+  - @param snapshot Path of the snapshot, as returned by SaveK3sSnapshot
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RestoreK3sSnapshot(node *runner.Runner, k *kubectl.Kubectl, snapshot string) {
+	ConfirmDestructive(node, "replace the K3s datastore")
+
+	sudo := node.WithSudo()
+
+	_, err := sudo.Run("systemctl", "stop", "k3s")
+	Expect(err).To(Not(HaveOccurred()))
+
+	if isK3sEtcd(node) {
+		_, err = sudo.Run("k3s", "server", "--cluster-reset", "--cluster-reset-restore-path="+snapshot)
+	} else {
+		_, err = sudo.Shell(fmt.Sprintf("rm -f %[1]s/state.db* && cp -a %[2]s/state.db* %[1]s/", k3sDBDir, snapshot))
 	}
-	err := rancher.CheckPod(k, checkList)
 	Expect(err).To(Not(HaveOccurred()))
+
+	StartK3s(node)
+	WaitForK3s(k)
 }
 
 /*
 Start K3s
+  - @param node Runner of the node where K3s is installed
   - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
-func StartK3s() {
-	err := exec.Command("sudo", "systemctl", "start", "k3s").Run()
+func StartK3s(node *runner.Runner) {
+	_, err := node.WithSudo().Run("systemctl", "start", "k3s")
 	Expect(err).To(Not(HaveOccurred()))
 }
 
@@ -218,9 +2667,15 @@ Execute RunHelmBinaryWithCustomErr within a loop with timeout
   - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
 func RunHelmCmdWithRetry(s ...string) {
-	Eventually(func() error {
+	// Recorded once by the helm shim, there is nothing to retry
+	if dryrun.Enabled() {
+		_ = kubectl.RunHelmBinaryWithCustomErr(s...)
+		return
+	}
+
+	WaitFor(context.Background(), wait.Check(func() error {
 		return kubectl.RunHelmBinaryWithCustomErr(s...)
-	}, tools.SetTimeout(2*time.Minute), 20*time.Second).Should(Not(HaveOccurred()))
+	}), wait.Options{Description: "helm " + strings.Join(s, " ")})
 }
 
 /*
@@ -229,10 +2684,79 @@ Check SSH connection
   - @returns Nothing, the function will fail through Ginkgo in case of issue
 */
 func CheckSSH(cl *tools.Client) {
-	Eventually(func() string {
+	WaitFor(context.Background(), wait.Match(func() string {
 		out, _ := cl.RunSSH("echo SSH_OK")
 		return strings.Trim(out, "\n")
-	}, tools.SetTimeout(10*time.Minute), 5*time.Second).Should(Equal("SSH_OK"))
+	}, Equal("SSH_OK")), wait.Options{Class: timeouts.Install, Description: "SSH connection to " + cl.Host})
+}
+
+/*
+Pause the execution after a failure, so the live cluster can be inspected
+  - @remarks Resumed with a key press on the terminal, by creating the resume file or after PAUSE_TIMEOUT
+  - @returns Nothing
+*/
+func PauseOnFailure() {
+	timeout := 30 * time.Minute
+	if d, err := time.ParseDuration(os.Getenv("PAUSE_TIMEOUT")); err == nil {
+		timeout = d
+	}
+	resumeFile := filepath.Join(GetTempDir(), "resume")
+	_ = os.Remove(resumeFile)
+
+	kubeconfig := localCluster.Kubeconfig
+	if kubeconfig == "" {
+		kubeconfig = os.Getenv("HOME") + "/.kube/config"
+	}
+
+	// Write directly on the terminal, GinkgoWriter is only flushed at the end of the spec
+	msg := fmt.Sprintf("\n*** Spec %q failed, execution paused for %s ***\n"+
+		"  export KUBECONFIG=%s\n"+
+		"  Namespaces: %s, %s, cattle-resources-system\n"+
+		"  Press Enter (or run 'touch %s') to resume\n",
+		CurrentSpecReport().FullText(), timeout, kubeconfig, kubewardenNS, clusterNS, resumeFile)
+	fmt.Fprint(os.Stderr, msg)
+
+	resume := make(chan struct{}, 1)
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		go func() {
+			buf := make([]byte, 1)
+			if _, err := tty.Read(buf); err == nil {
+				resume <- struct{}{}
+			}
+		}()
+	}
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-resume:
+			return
+		case <-deadline:
+			fmt.Fprintf(os.Stderr, "Pause timeout reached, resuming\n")
+			return
+		case <-time.After(5 * time.Second):
+			if _, err := os.Stat(resumeFile); err == nil {
+				return
+			}
+		}
+	}
+}
+
+/*
+Record a failure and skip the rest of the spec
+  - @remarks Used in dry-run mode, where commands return nothing and checks cannot pass,
+    the code after the check would run on empty values
+  - @param message Failure message
+  - @param callerSkip Number of caller levels to skip
+  - @returns Nothing, the spec is skipped through Ginkgo
+*/
+func RecordFailure(message string, callerSkip ...int) {
+	check, _, _ := strings.Cut(message, "\n")
+	dryrun.Record("check: %s", check)
+
+	// Cleanups are still planned
+	Skip("dry-run stopped at a failed check: " + check)
 }
 
 func FailWithReport(message string, callerSkip ...int) {
@@ -240,6 +2764,178 @@ func FailWithReport(message string, callerSkip ...int) {
 	Fail(message, callerSkip[0]+1)
 }
 
+/*
+Record a performance number of the run
+  - @remarks Added to the report, then published with the results of the suite for the trends
+  - @param name Name of the metric, the same across runs
+  - @param value Value of the metric
+  - @param unit Unit of the value, e.g. s
+  - @returns Nothing
+*/
+func RecordMetric(name string, value float64, unit string) {
+	AddReportEntry(name, results.Metric{Metric: name, Value: value, Unit: unit})
+}
+
+/*
+Publish the results of the suite
+  - @remarks Only done if RESULTS_TARGET is set, S3 URL or SQLite file, see cmd/report for the trends
+  - @param report Report of the suite
+  - @returns Nothing, an error is only displayed as the suite is already done
+*/
+func PublishResults(report Report) {
+	target := os.Getenv("RESULTS_TARGET")
+	if target == "" || dryrun.Enabled() {
+		return
+	}
+
+	s := results.FromReport(report, GetRunID())
+	if run := reportManifest(report); run != nil {
+		s.Versions = run.Versions()
+	}
+	if err := results.Publish(s, target); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot publish the results to %s: %s\n", target, err)
+	}
+}
+
+/*
+Post a summary of the suite to a chat webhook
+  - @remarks Only done if NOTIFY_WEBHOOK_URL is set (Slack or Matrix), NOTIFY_ON=failure skips the passed suites
+  - @remarks Versions come from the last run-manifest entry, the link from NOTIFY_ARTIFACTS_URL or the GitHub Actions run
+  - @param report Report of the suite
+  - @returns Nothing, an error is only displayed as the suite is already done
+*/
+func NotifyResults(report Report) {
+	webhook := os.Getenv("NOTIFY_WEBHOOK_URL")
+	if webhook == "" || dryrun.Enabled() {
+		return
+	}
+	if os.Getenv("NOTIFY_ON") == "failure" && report.SuiteSucceeded {
+		return
+	}
+
+	m := &notify.Message{
+		Tier:    os.Getenv("E2E_TIER"),
+		Summary: results.FromReport(report, GetRunID()),
+		Link:    os.Getenv("NOTIFY_ARTIFACTS_URL"),
+	}
+	if m.Link == "" && os.Getenv("GITHUB_RUN_ID") != "" {
+		m.Link = os.Getenv("GITHUB_SERVER_URL") + "/" + os.Getenv("GITHUB_REPOSITORY") + "/actions/runs/" + os.Getenv("GITHUB_RUN_ID")
+	}
+
+	for _, spec := range report.SpecReports {
+		if spec.Failed() {
+			message, _, _ := strings.Cut(spec.Failure.Message, "\n")
+			m.Failures = append(m.Failures, fmt.Sprintf("%s: %s", cmp.Or(spec.FullText(), spec.LeafNodeType.String()), message))
+		}
+	}
+
+	if run := reportManifest(report); run != nil {
+		m.Versions = []string{"K3s " + cmp.Or(run.K3sVersion, "not installed")}
+		for _, chart := range run.Charts {
+			m.Versions = append(m.Versions, chart.Name+" "+chart.AppVersion)
+		}
+	}
+
+	if err := notify.Post(webhook, m); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot notify the results: %s\n", err)
+	}
+}
+
+/*
+Get the run manifest of a suite report
+  - @remarks This function is only used internally, not exported
+  - @remarks The manifest of the end of the suite is the last one
+  - @param report Report of the suite
+  - @returns The last manifest, nil if there is none
+*/
+func reportManifest(report Report) *manifest.Manifest {
+	var last *manifest.Manifest
+	for _, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			if entry.Name != "run-manifest" {
+				continue
+			}
+
+			var data string
+			if raw, ok := entry.GetRawValue().(string); ok {
+				data = raw
+			} else if json.Unmarshal([]byte(entry.Value.AsJSON), &data) != nil {
+				continue
+			}
+
+			var run manifest.Manifest
+			if json.Unmarshal([]byte(data), &run) == nil {
+				last = &run
+			}
+		}
+	}
+	return last
+}
+
+/*
+Record the versions under test in the suite report and in a JSON file
+  - @remarks The file is set with RUN_MANIFEST, default is run-manifest.json
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func RecordRunManifest() {
+	m := manifest.Collect(k3sNode, GetRunID())
+	AddReportEntry("run-manifest", m.JSON())
+
+	err := m.Write(cmp.Or(os.Getenv("RUN_MANIFEST"), "run-manifest.json"))
+	Expect(err).To(Not(HaveOccurred()))
+}
+
+/*
+Check that Kubewarden still enforces its policies
+  - @remarks Done at the end of each suite, to catch the regressions where they happen
+  - @remarks The pods are only created with a server-side dry-run, nothing has to be cleaned
+  - @returns Nothing, the function will fail through Ginkgo in case of issue
+*/
+func CheckEnforcementCanary() {
+	// Recommended policy of kubewarden-defaults, in protect mode
+	const canaryPolicy = "do-not-run-as-root"
+
+	if os.Getenv("E2E_CANARY") == "false" {
+		return
+	}
+	if dryrun.Enabled() {
+		dryrun.Record("check enforcement of policy %s with a known-bad and a known-good pod", canaryPolicy)
+		return
+	}
+
+	// Nothing to check if the suite did not install Kubewarden, or removed it
+	if !IsKubewardenInstalled(kubewardenNS, "") {
+		GinkgoWriter.Printf("Kubewarden is not installed, skipping the enforcement canary\n")
+		return
+	}
+	mode, err := kubectl.RunWithoutErr("get", "clusteradmissionpolicy", canaryPolicy, "--ignore-not-found", "-o", "jsonpath={.spec.mode}")
+	Expect(err).To(Not(HaveOccurred()))
+	if mode != "protect" {
+		GinkgoWriter.Printf("Policy %s is not enforced (mode %q), skipping the enforcement canary\n", canaryPolicy, mode)
+		return
+	}
+	WaitForPolicyActive(context.Background(), localCluster, canaryPolicy)
+
+	runPod := func(securityContext string) (string, error) {
+		return kubectl.Run("run", UniqueName("canary-pod"), "--image=rancher/pause:3.2", "--dry-run=server",
+			"--overrides", `{"spec": {"securityContext": `+securityContext+`}}`)
+	}
+	verdict := func(err error) string {
+		if err != nil {
+			return "denied"
+		}
+		return "accepted"
+	}
+
+	badOut, badErr := runPod(`{"runAsUser": 0}`)
+	goodOut, goodErr := runPod(`{"runAsNonRoot": true, "runAsUser": 1000}`)
+	AddReportEntry("enforcement canary", fmt.Sprintf("known-bad pod %s, known-good pod %s", verdict(badErr), verdict(goodErr)))
+
+	Expect(badErr).To(HaveOccurred(), "known-bad pod accepted, %s is not enforced anymore", canaryPolicy)
+	Expect(badOut).To(ContainSubstring("denied the request"))
+	Expect(goodErr).To(Not(HaveOccurred()), "known-good pod denied: %s", goodOut)
+}
+
 /*
 Wait for K3s to start
   - @param k kubectl structure
@@ -253,30 +2949,189 @@ func WaitForK3s(k *kubectl.Kubectl) {
 		{"kube-system", "app.kubernetes.io/name=traefik"},
 		{"kube-system", "svccontroller.k3s.cattle.io/svcname=traefik"},
 	}
-	Eventually(func() error {
+	WaitFor(context.Background(), wait.Check(func() error {
 		return rancher.CheckPod(k, checkList)
-	}, tools.SetTimeout(4*time.Minute), 30*time.Second).Should(Not(HaveOccurred()))
+	}), wait.Options{Class: timeouts.Rollout, Description: "K3s pods"})
 
 	// Check DaemonSet(s)
 	checkList = [][]string{
 		{"kube-system", "svccontroller.k3s.cattle.io/svcname=traefik"},
 	}
-	Eventually(func() error {
+	WaitFor(context.Background(), wait.Check(func() error {
 		return rancher.CheckDaemonSet(k, checkList)
-	}, tools.SetTimeout(4*time.Minute), 30*time.Second).Should(Not(HaveOccurred()))
+	}), wait.Options{Class: timeouts.Rollout, Description: "K3s daemonsets"})
 }
 
 func TestE2E(t *testing.T) {
-	RegisterFailHandler(FailWithReport)
-	RunSpecs(t, "Elemental End-To-End Test Suite")
+	if dryrun.Enabled() {
+		RegisterFailHandler(RecordFailure)
+	} else {
+		RegisterFailHandler(FailWithReport)
+	}
+
+	// Failure messages include the full output of the failed commands
+	format.RegisterCustomFormatter(cmderr.Format)
+
+	// A silent spec is reported after E2E_PROGRESS_AFTER, then every E2E_PROGRESS_INTERVAL, unless set with the ginkgo flags
+	suiteConfig, reporterConfig := GinkgoConfiguration()
+	progressAfter := timeouts.Scaled(5 * time.Minute)
+	if d, err := time.ParseDuration(os.Getenv("E2E_PROGRESS_AFTER")); err == nil {
+		progressAfter = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("E2E_PROGRESS_INTERVAL")); err == nil {
+		progressInterval = d
+	}
+	if suiteConfig.PollProgressAfter == 0 {
+		suiteConfig.PollProgressAfter = progressAfter
+	}
+	if suiteConfig.PollProgressInterval == 0 {
+		suiteConfig.PollProgressInterval = progressInterval
+	}
+
+	RunSpecs(t, "Elemental End-To-End Test Suite", suiteConfig, reporterConfig)
 }
 
+// Planned operations are displayed per spec
+var _ = AfterEach(func() {
+	if !dryrun.Enabled() {
+		return
+	}
+
+	if plan := dryrun.Flush(); plan != "" {
+		AddReportEntry("dry-run plan", plan, ReportEntryVisibilityAlways)
+	}
+})
+
+// Runs before AfterEach and DeferCleanup, so nothing has been torn down yet
+var _ = JustAfterEach(func() {
+	if !dryrun.Enabled() && CurrentSpecReport().Failed() {
+		ReportTerminating(time.Minute)
+	}
+
+	if os.Getenv("PAUSE_ON_FAILURE") == "true" && CurrentSpecReport().Failed() {
+		PauseOnFailure()
+	}
+})
+
 var _ = BeforeSuite(func() {
+	// Before anything is executed
+	if dryrun.Enabled() {
+		err := dryrun.Setup(filepath.Join(GetTempDir(), "dry-run"))
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	// Fixtures for the unit tests of the helpers, see replay.Setup
+	if fixtures := os.Getenv("E2E_RECORD"); fixtures != "" && !dryrun.Enabled() {
+		_, err := replay.Setup(replay.Record, fixtures, filepath.Join(GetTempDir(), "replay"))
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	// Cluster where Kubewarden (and Rancher) is installed,
+	// the kubeconfig is replaced when K3s is installed by the suite
+	localCluster = cluster.New("local", os.Getenv("KUBECONFIG"))
+
 	auditScannerVersion = os.Getenv("AUDIT_SCANNER_VERSION")
 	backupRestoreVersion = os.Getenv("BACKUP_RESTORE_VERSION")
+	backupStorageClass = os.Getenv("BACKUP_STORAGE_CLASS")
+	backupS3Bucket = os.Getenv("BACKUP_S3_BUCKET")
+	backupS3Endpoint = os.Getenv("BACKUP_S3_ENDPOINT")
+	backupS3Folder = os.Getenv("BACKUP_S3_FOLDER")
+	backupMinIO = os.Getenv("BACKUP_MINIO") == "true"
 	kubewardenControllerVersion = os.Getenv("KUBEWARDEN_CONTROLLER_VERSION")
+	kubewardenNS = os.Getenv("KUBEWARDEN_NAMESPACE")
+	kubewardenPreviousVersion = os.Getenv("KUBEWARDEN_PREVIOUS_VERSION")
+	kubewardenFlavor = os.Getenv("KUBEWARDEN_FLAVOR")
+	kubewardenRegistry = os.Getenv("KUBEWARDEN_REGISTRY")
+	clusterNS = os.Getenv("CLUSTER_NAMESPACE")
 	policyServerVersion = os.Getenv("POLICY_SERVER_VERSION")
 	k3sVersion = os.Getenv("K3S_VERSION")
+	k3sAuditLogEnabled = os.Getenv("K3S_AUDIT_LOG") != "false"
+	k3sSELinux = os.Getenv("K3S_SELINUX") == "true"
+	k3sIPFamily = os.Getenv("K3S_IP_FAMILY")
+	k3sExternalIP = os.Getenv("K3S_NODE_EXTERNAL_IP")
+	offlineChartRepo = os.Getenv("HELM_OFFLINE_REPO")
+	longhornVersion = os.Getenv("LONGHORN_VERSION")
+	policyReporterVersion = os.Getenv("POLICY_REPORTER_VERSION")
 	netDefaultFileName = "../assets/net-default-airgap.xml"
 	rancherHostname = os.Getenv("PUBLIC_FQDN")
+	airgapRegistry = os.Getenv("AIRGAP_REGISTRY")
+	airgapUpgrade = os.Getenv("AIRGAP_UPGRADE") == "true"
+	rancherChannel = os.Getenv("RANCHER_CHANNEL")
+	rancherVersion = os.Getenv("RANCHER_VERSION")
+	installMode = os.Getenv("INSTALL_MODE")
+
+	// Needed to clean resources of this run with the janitor
+	AddReportEntry("run-id", GetRunID())
+
+	// K3s is installed on the test host, unless a remote node is defined
+	k3sNode = &runner.Runner{}
+	if host := os.Getenv("K3S_NODE_HOST"); host != "" {
+		user := os.Getenv("K3S_NODE_USER")
+		if user == "" {
+			user = "root"
+		}
+
+		k3sNode = runner.Remote(&tools.Client{
+			Host:     host + ":22",
+			Username: user,
+			Password: os.Getenv("K3S_NODE_PASSWORD"),
+		})
+	}
+
+	// REUSE_STACK is kept as a shortcut, already installed components are upgraded by default
+	if installMode == "" {
+		installMode = installUpgrade
+		if os.Getenv("REUSE_STACK") == "true" {
+			installMode = installSkip
+		}
+	}
+	Expect(installMode).To(BeElementOf(installUpgrade, installSkip), "invalid INSTALL_MODE")
+
+	// Upstream charts by default, the registry of the flavor can be overridden
+	if kubewardenFlavor == "" {
+		kubewardenFlavor = "upstream"
+	}
+	Expect(kubewardenFlavors).To(HaveKey(kubewardenFlavor), "invalid KUBEWARDEN_FLAVOR")
+	if kubewardenRegistry == "" {
+		kubewardenRegistry = kubewardenFlavors[kubewardenFlavor].Registry
+	}
+
+	// Use default Kubewarden namespace if not defined
+	if kubewardenNS == "" {
+		kubewardenNS = "kubewarden"
+	}
+
+	// Backup/Restore resources are created in Kubewarden namespace if not defined
+	if clusterNS == "" {
+		clusterNS = kubewardenNS
+	}
+
+	// Use K3s default storage class if not defined
+	if backupStorageClass == "" {
+		backupStorageClass = "local-path"
+	}
+
+	// Only once for all parallel processes
+	if GinkgoParallelProcess() == 1 {
+		RecordRunManifest()
+	}
+})
+
+// Only after all parallel processes, the report has all the specs
+var _ = ReportAfterSuite("Flaky specs", func(report Report) {
+	ReportFlakySpecs(report)
+})
+
+var _ = ReportAfterSuite("Publish results", func(report Report) {
+	PublishResults(report)
+})
+
+var _ = ReportAfterSuite("Notify results", func(report Report) {
+	NotifyResults(report)
+})
+
+// Done again at the end, with what has been installed by the tests
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	RecordRunManifest()
+	CheckEnforcementCanary()
 })
@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/supplychain"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// NOTE: trivy and cosign have to be installed on the test host, with access to the registry
+var _ = Describe("E2E - Supply chain verification of the running images", Label("supply-chain", "full"), Ordered, func() {
+	// Upstream images are signed by the GitHub workflows of the Kubewarden organization
+	id := supplychain.Identity{
+		Subject: cmp.Or(os.Getenv("COSIGN_IDENTITY_REGEXP"), "^https://github.com/kubewarden/"),
+		Issuer:  cmp.Or(os.Getenv("COSIGN_OIDC_ISSUER"), "https://token.actions.githubusercontent.com"),
+	}
+
+	var images []string
+
+	BeforeAll(func() {
+		for _, tool := range []string{"trivy", "cosign"} {
+			if _, err := exec.LookPath(tool); err != nil {
+				Skip(tool + " is not installed")
+			}
+		}
+
+		var refs []string
+		for _, image := range GetPodImages(kubewardenNS) {
+			_, ref, _ := strings.Cut(image, "=")
+			refs = append(refs, ref)
+		}
+		images = supplychain.Unique(refs)
+		Expect(images).To(Not(BeEmpty()), "no image running in %s", kubewardenNS)
+
+		AddReportEntry("images", strings.Join(images, "\n"))
+	})
+
+	It("Check that images are signed", func() {
+		var unsigned []string
+		for _, image := range images {
+			err := supplychain.VerifySignature(image, id)
+			if errors.Is(err, supplychain.ErrNotVerified) {
+				unsigned = append(unsigned, image)
+				continue
+			}
+			Expect(err).To(Not(HaveOccurred()), "cannot verify the signature of %s", image)
+		}
+		Expect(unsigned).To(BeEmpty(), "images not signed by %s", id.Subject)
+	})
+
+	It("Check that images have an attested SBOM", func() {
+		sbomType := cmp.Or(os.Getenv("SBOM_TYPE"), "spdxjson")
+
+		var missing []string
+		for _, image := range images {
+			err := supplychain.VerifySBOM(image, id, sbomType)
+			if errors.Is(err, supplychain.ErrNotVerified) {
+				missing = append(missing, image)
+				continue
+			}
+			Expect(err).To(Not(HaveOccurred()), "cannot verify the SBOM attestation of %s", image)
+		}
+		Expect(missing).To(BeEmpty(), "images without %s SBOM attestation", sbomType)
+	})
+
+	It("Check that images have no fixable vulnerability", func() {
+		severity := cmp.Or(os.Getenv("TRIVY_SEVERITY"), "CRITICAL")
+
+		var found []string
+		for _, image := range images {
+			vulns, err := supplychain.Scan(image, severity)
+			Expect(err).To(Not(HaveOccurred()))
+
+			for _, v := range vulns {
+				found = append(found, fmt.Sprintf("%s: %s %s (%s, fixed in %s)", image, v.ID, v.Package, v.Severity, v.FixedVersion))
+			}
+		}
+		Expect(found).To(BeEmpty(), "%s vulnerabilities with a fix available", severity)
+	})
+})
@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cabundle"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: the clock of the K3s node is changed, the test is skipped if K3s was not installed by the tests
+var _ = Describe("E2E - Behave sanely with a skewed node clock", Label("time-skew", "full"), Ordered, Serial, func() {
+	// Serving certificate of the K3s API server, it has to stay valid for the test host
+	const apiServerCert = "/var/lib/rancher/k3s/server/tls/serving-kube-apiserver.crt"
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	policyName := UniqueName("skew-signed-images")
+	ns := UniqueName("time-skew")
+
+	var (
+		// Real time, from the monotonic clock of the test host even if it is the skewed node
+		start = time.Now()
+		// NTP setting of the node before the test
+		ntp string
+		// Signed image of the policy server
+		image string
+	)
+
+	now := func() time.Time {
+		return start.Add(time.Since(start))
+	}
+
+	setClock := func(t time.Time) {
+		_, err := k3sNode.WithSudo().Run("timedatectl", "set-ntp", "false")
+		Expect(err).To(Not(HaveOccurred()))
+		_, err = k3sNode.WithSudo().Run("date", "-s", "@"+strconv.FormatInt(t.Unix(), 10))
+		Expect(err).To(Not(HaveOccurred()))
+		AddReportEntry("node clock", t.UTC().Format(time.RFC3339))
+	}
+
+	// Real time first, NTP may not be reachable in airgap
+	restoreClock := func() {
+		setClock(now())
+		_, err := k3sNode.WithSudo().Run("timedatectl", "set-ntp", ntp)
+		Expect(err).To(Not(HaveOccurred()))
+	}
+
+	runPod := func(podImage, securityContext string) (string, error) {
+		return kubectl.Run("run", UniqueName("skew-pod"), "--namespace", ns, "--image="+podImage, "--dry-run=server",
+			"--overrides", `{"spec": {"securityContext": `+securityContext+`}}`)
+	}
+
+	// Pods of the signed image are accepted, or denied with a message giving the time issue
+	checkSigned := func(ctx SpecContext) {
+		WaitFor(ctx, wait.Check(func() error {
+			out, err := runPod(image, `{"runAsNonRoot": true, "runAsUser": 1000}`)
+			if err == nil {
+				return nil
+			}
+			lower := strings.ToLower(out)
+			if !strings.Contains(lower, "expired") && !strings.Contains(lower, "not yet valid") && !strings.Contains(lower, "time") {
+				return fmt.Errorf("signed image denied without a time related message: %s", out)
+			}
+			AddReportEntry("signed image verdict", out)
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "verification of the signed image " + image})
+	}
+
+	BeforeAll(func() {
+		if _, err := k3sNode.Run("test", "-f", k3sMarkerFile); err != nil {
+			Skip("K3s was not installed by the tests")
+		}
+
+		out, err := k3sNode.Run("timedatectl", "show", "--property", "NTP", "--value")
+		Expect(err).To(Not(HaveOccurred()))
+		ntp = strings.TrimSpace(out)
+		if ntp != "no" {
+			ntp = "true"
+		}
+
+		_, err = kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			restoreClock()
+			WaitForK3s(k)
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found", "--wait=false")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+
+	It("Verify the signature of the policy server image", func(ctx SpecContext) {
+		var err error
+		image, err = kubectl.RunWithoutErr("get", "policyserver", "default", "-o", "jsonpath={.spec.image}")
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(timeSkewPolicyYaml, map[string]string{
+			"%NAME%":      policyName,
+			"%NAMESPACE%": ns,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, policyName)
+
+		out, err := runPod(image, `{"runAsNonRoot": true, "runAsUser": 1000}`)
+		Expect(err).To(Not(HaveOccurred()), out)
+	})
+
+	It("Verify the signatures with the clock two days ahead", func(ctx SpecContext) {
+		// Certificates stay valid, only the verification of the signatures depends on the time
+		setClock(now().Add(48 * time.Hour))
+		checkSigned(ctx)
+	})
+
+	It("Give an actionable error when the webhook certificates are not yet valid", func(ctx SpecContext) {
+		setClock(now())
+
+		// The clock is set back just before the most recent certificate of the webhooks
+		notBefore := now().Add(-time.Hour)
+		if dryrun.Enabled() {
+			dryrun.Record("get the certificates served by the webhooks")
+		} else {
+			webhooks, err := cabundle.Inspect(ctx)
+			Expect(err).To(Not(HaveOccurred()))
+			notBefore = time.Time{}
+			for _, w := range webhooks {
+				if len(w.Served) > 0 && w.Served[0].NotBefore.After(notBefore) {
+					notBefore = w.Served[0].NotBefore
+				}
+			}
+			Expect(notBefore.IsZero()).To(BeFalse(), "no certificate served by the webhooks")
+		}
+		target := notBefore.Add(-time.Hour)
+
+		// kubectl on the test host has to trust the API server, even with the clock of the node
+		out, err := k3sNode.WithSudo().Run("openssl", "x509", "-noout", "-startdate", "-in", apiServerCert)
+		Expect(err).To(Not(HaveOccurred()))
+		apiNotBefore, err := time.Parse("Jan _2 15:04:05 2006 MST", strings.TrimPrefix(strings.TrimSpace(out), "notBefore="))
+		Expect(err).To(Not(HaveOccurred()), "bad start date of %s", apiServerCert)
+		if !apiNotBefore.Before(target) {
+			Skip("certificates of K3s are not older than the ones of the webhooks")
+		}
+
+		setClock(target)
+		WaitFor(ctx, wait.Check(func() error {
+			out, err := runPod("rancher/pause:3.2", `{"runAsUser": 0}`)
+			if err == nil {
+				return fmt.Errorf("pod accepted while the webhook certificates are not valid")
+			}
+			if !strings.Contains(out, "not yet valid") {
+				return fmt.Errorf("no certificate validity error: %s", out)
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "webhook calls to fail on not yet valid certificates"})
+	})
+
+	It("Enforce the policies again once the time is synchronized", func(ctx SpecContext) {
+		restoreClock()
+
+		WaitForPolicyActive(ctx, localCluster, policyName)
+		WaitFor(ctx, wait.Check(func() error {
+			if out, err := runPod(image, `{"runAsNonRoot": true, "runAsUser": 1000}`); err != nil {
+				return fmt.Errorf("signed image denied: %s", out)
+			}
+			if _, err := runPod("rancher/pause:3.2", `{"runAsNonRoot": true, "runAsUser": 1000}`); err == nil {
+				return fmt.Errorf("unsigned image accepted")
+			}
+			return nil
+		}), wait.Options{Class: timeouts.Rollout, Description: "signatures to be verified after the time sync"})
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: should be executed on a cluster with rancher-backup-operator but without Kubewarden
+var _ = Describe("E2E - Test Backup before Kubewarden upgrade", Label("test-upgrade-backup-restore", "upgrade"), Serial, func() {
+	upgradeBackupName := UniqueName("kubewarden-upgrade-backup")
+	upgradePolicyName := UniqueName("upgrade-privileged-pods")
+	upgradeRestoreName := UniqueName("kubewarden-upgrade-restore")
+
+	// Create kubectl context
+	// Default timeout is too small, so New() cannot be used
+	k := &kubectl.Kubectl{
+		Namespace:    "",
+		PollTimeout:  timeouts.For(timeouts.Rollout),
+		PollInterval: 500 * time.Millisecond,
+	}
+
+	// Check that the policies deployed before the backup are active
+	checkPolicies := func(ctx context.Context) {
+		for _, policy := range []string{upgradePolicyName, "do-not-run-as-root"} {
+			WaitForPolicyActive(ctx, localCluster, policy)
+		}
+	}
+
+	It("Backup, upgrade, break and restore Kubewarden", func(ctx SpecContext) {
+		previousVersion := kubewardenPreviousVersion
+
+		By("Installing the previous Kubewarden version", func() {
+			if previousVersion == "" {
+				AddKubewardenRepo()
+				previousVersion = GetPreviousKubewardenVersion()
+			}
+			GinkgoWriter.Printf("Installing Kubewarden %s\n", previousVersion)
+
+			InstallKubewarden(k, kubewardenNS, previousVersion)
+			Expect(GetInstalledKubewardenVersion(kubewardenNS)).To(Equal(previousVersion))
+		})
+
+		By("Deploying policies", func() {
+			policies := CopyYaml(upgradePoliciesYaml, map[string]string{"upgrade-privileged-pods": upgradePolicyName})
+			err := kubectl.Apply(clusterNS, policies)
+			Expect(err).To(Not(HaveOccurred()))
+
+			checkPolicies(ctx)
+		})
+
+		By("Adding a backup resource", func() {
+			ApplyBackup(upgradeBackupName)
+
+			// Wait for backup to be done
+			WaitForReady(ctx, "backup", upgradeBackupName)
+		})
+
+		By("Upgrading Kubewarden to the latest version", func() {
+			InstallKubewarden(k, kubewardenNS, "")
+			Expect(GetInstalledKubewardenVersion(kubewardenNS)).To(Not(Equal(previousVersion)))
+			checkPolicies(ctx)
+		})
+
+		By("Breaking Kubewarden by deleting policies", func() {
+			for _, policy := range []string{upgradePolicyName, "do-not-run-as-root"} {
+				_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policy)
+				Expect(err).To(Not(HaveOccurred()))
+			}
+		})
+
+		By("Adding a restore resource", func() {
+			ApplyRestore(upgradeRestoreName, GetBackupFile(upgradeBackupName), false)
+
+			// Wait for restore to be done
+			WaitForReady(ctx, "restore", upgradeRestoreName)
+		})
+
+		By("Checking that Kubewarden state has been rolled back", func() {
+			checkPolicies(ctx)
+
+			// Helm releases are not part of the restore, upgraded charts are kept
+			GinkgoWriter.Printf("Kubewarden version after restore: %s\n", GetInstalledKubewardenVersion(kubewardenNS))
+		})
+	})
+})
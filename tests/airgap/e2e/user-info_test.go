@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rancher-sandbox/ele-testhelpers/kubectl"
+)
+
+// NOTE: users are impersonated by the admin of the cluster, so no identity provider is needed
+var _ = Describe("E2E - Test policies based on the user of the request", Label("user-info", "full"), Ordered, func() {
+	// Groups of the simulated users, all of them can edit the namespace of the test
+	groups := []string{"e2e-developers", "e2e-restricted", "e2e-admins"}
+
+	policyName := UniqueName("user-info")
+	ns := UniqueName("user-info")
+
+	BeforeAll(func(ctx SpecContext) {
+		_, err := kubectl.RunWithoutErr("create", "namespace", ns)
+		Expect(err).To(Not(HaveOccurred()))
+		LabelRun("namespace", ns)
+
+		DeferCleanup(func() {
+			_, err := kubectl.RunWithoutErr("delete", "clusteradmissionpolicy", policyName, "--ignore-not-found", "--wait")
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = kubectl.RunWithoutErr("delete", "namespace", ns, "--ignore-not-found")
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		// Without RBAC, requests would be denied before the admission
+		args := []string{"create", "rolebinding", "e2e-users", "--namespace", ns, "--clusterrole=edit"}
+		for _, group := range groups {
+			args = append(args, "--group="+group)
+		}
+		_, err = kubectl.RunWithoutErr(args...)
+		Expect(err).To(Not(HaveOccurred()))
+
+		file := CopyYaml(userInfoPolicyYaml, map[string]string{
+			"%NAME%":      policyName,
+			"%NAMESPACE%": ns,
+		})
+		err = kubectl.Apply("", file)
+		Expect(err).To(Not(HaveOccurred()))
+		WaitForPolicyActive(ctx, localCluster, policyName)
+	})
+
+	DescribeTable("Create a ConfigMap as another user",
+		func(user, group, owner, denial string) {
+			name := UniqueName("user-configmap")
+
+			labels := map[string]string{}
+			if owner != "" {
+				labels["owner"] = owner
+			}
+			manifest, err := json.Marshal(map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": name, "namespace": ns, "labels": labels},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			file := filepath.Join(GetTempDir(), name+".json")
+			err = os.WriteFile(file, manifest, 0644)
+			Expect(err).To(Not(HaveOccurred()))
+
+			out, err := kubectl.Run("create", "--filename", file, "--as", user, "--as-group", group)
+
+			webhook := fmt.Sprintf(`admission webhook "clusterwide-%s.kubewarden.admission" denied the request`, policyName)
+			if denial == "" {
+				Expect(err).To(Not(HaveOccurred()), out)
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring(webhook))
+				Expect(out).To(ContainSubstring(denial))
+			}
+		},
+		Entry("developer without the owner label", "e2e-developer", "e2e-developers", "", "The owner label must be the name of the user"),
+		Entry("developer owning the ConfigMap", "e2e-developer", "e2e-developers", "e2e-developer", ""),
+		Entry("developer for another user", "e2e-developer", "e2e-developers", "e2e-admin", "The owner label must be the name of the user"),
+		Entry("restricted user owning the ConfigMap", "e2e-restricted-user", "e2e-restricted", "e2e-restricted-user", "Members of the e2e-restricted group cannot create ConfigMaps"),
+		Entry("admin for another user", "e2e-admin", "e2e-admins", "e2e-developer", ""),
+	)
+})
@@ -4,7 +4,11 @@ go 1.24.0
 
 replace go.qase.io/client => github.com/rancher/qase-go/client v0.0.0-20231114201952-65195ec001fa
 
+// Helpers are a module of their own, to be imported by the other Kubewarden repositories
+replace github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg => ./pkg
+
 require (
+	github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg v0.0.0-00010101000000-000000000000
 	github.com/onsi/ginkgo/v2 v2.23.4
 	github.com/onsi/gomega v1.37.0
 	github.com/rancher-sandbox/ele-testhelpers v0.0.0-20250415062725-efdf8e57c793
@@ -0,0 +1,111 @@
+# Credentials of the instance, generated for each lab
+resource "tls_private_key" "ssh" {
+  algorithm = "ED25519"
+}
+
+resource "random_password" "ssh" {
+  length  = 24
+  special = false
+}
+
+data "aws_ami" "image" {
+  most_recent = true
+  owners      = [var.image_owner]
+
+  filter {
+    name   = "name"
+    values = [var.image_name]
+  }
+}
+
+data "aws_subnet" "selected" {
+  count = var.subnet_id == "" ? 0 : 1
+  id    = var.subnet_id
+}
+
+data "aws_vpc" "default" {
+  count   = var.subnet_id == "" ? 1 : 0
+  default = true
+}
+
+resource "aws_security_group" "node" {
+  name   = var.name
+  vpc_id = var.subnet_id == "" ? data.aws_vpc.default[0].id : data.aws_subnet.selected[0].vpc_id
+
+  # SSH executor, kubectl/helm and the NodePorts used by the tests
+  dynamic "ingress" {
+    for_each = { ssh = [22, 22], api = [6443, 6443], nodeports = [30000, 32767] }
+    content {
+      description = ingress.key
+      from_port   = ingress.value[0]
+      to_port     = ingress.value[1]
+      protocol    = "tcp"
+      cidr_blocks = [var.allowed_cidr]
+    }
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+# The SSH executor of the tests logs in with the password, the key is kept for manual access
+resource "aws_instance" "node" {
+  ami                         = data.aws_ami.image.id
+  instance_type               = var.instance_type
+  subnet_id                   = var.subnet_id == "" ? null : var.subnet_id
+  vpc_security_group_ids      = [aws_security_group.node.id]
+  associate_public_ip_address = true
+
+  root_block_device {
+    volume_size = var.disk_size
+    volume_type = "gp3"
+  }
+
+  user_data = <<-EOT
+    #cloud-config
+    ssh_pwauth: true
+    users:
+      - default
+      - name: ${var.ssh_user}
+        sudo: ALL=(ALL) NOPASSWD:ALL
+        shell: /bin/bash
+        lock_passwd: false
+        passwd: ${bcrypt(random_password.ssh.result)}
+        ssh_authorized_keys:
+          - ${trimspace(tls_private_key.ssh.public_key_openssh)}
+    # Destructive steps of the tests are not confirmed on this node
+    write_files:
+      - path: /etc/kubewarden-e2e-node
+        content: ""
+  EOT
+
+  lifecycle {
+    # bcrypt() gives a new hash at each run
+    ignore_changes = [user_data]
+  }
+
+  tags = {
+    Name = var.name
+  }
+}
+
+# The tests can only connect once cloud-init has created the user
+resource "terraform_data" "ready" {
+  triggers_replace = [aws_instance.node.id]
+
+  connection {
+    type        = "ssh"
+    host        = aws_instance.node.public_ip
+    user        = var.ssh_user
+    private_key = tls_private_key.ssh.private_key_openssh
+    timeout     = "10m"
+  }
+
+  provisioner "remote-exec" {
+    inline = ["cloud-init status --wait >/dev/null"]
+  }
+}
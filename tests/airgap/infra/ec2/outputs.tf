@@ -0,0 +1,31 @@
+# Outputs read by the infra helper, see infra/libvirt/outputs.tf
+output "node_ip" {
+  description = "Public IP address of the instance"
+  value       = aws_instance.node.public_ip
+
+  # Only given once the node can be used
+  depends_on = [terraform_data.ready]
+}
+
+# Differs from node_ip, K3s is configured to advertise the public address
+output "node_private_ip" {
+  description = "Private IP address of the instance"
+  value       = aws_instance.node.private_ip
+}
+
+output "ssh_user" {
+  description = "User of the SSH connection"
+  value       = var.ssh_user
+}
+
+output "ssh_password" {
+  description = "Password of the SSH user"
+  value       = random_password.ssh.result
+  sensitive   = true
+}
+
+output "ssh_private_key" {
+  description = "Private key of the SSH user, in OpenSSH format"
+  value       = tls_private_key.ssh.private_key_openssh
+  sensitive   = true
+}
@@ -0,0 +1,53 @@
+variable "region" {
+  description = "AWS region of the instance"
+  type        = string
+  default     = "eu-central-1"
+}
+
+variable "name" {
+  description = "Name of the instance, also used as prefix of its resources"
+  type        = string
+  default     = "kubewarden-e2e"
+}
+
+# SUSE account, Leap images are published by 679593333241 as openSUSE-Leap-15-6-*
+variable "image_owner" {
+  description = "Owner of the image"
+  type        = string
+  default     = "013907871322"
+}
+
+variable "image_name" {
+  description = "Name filter of the image, the most recent one is used"
+  type        = string
+  default     = "suse-sles-15-sp6-v*-hvm-ssd-x86_64"
+}
+
+variable "instance_type" {
+  description = "Type of the instance, use an arm64 one (e.g. m7g.xlarge) with an arm64 image"
+  type        = string
+  default     = "m6i.xlarge"
+}
+
+variable "disk_size" {
+  description = "Size of the root disk, in GiB"
+  type        = number
+  default     = 40
+}
+
+variable "subnet_id" {
+  description = "Subnet of the instance, with a route to the internet, the default VPC is used if not set"
+  type        = string
+  default     = ""
+}
+
+variable "allowed_cidr" {
+  description = "Network allowed to reach SSH, the Kubernetes API and the NodePorts, e.g. the public IP of the runner"
+  type        = string
+}
+
+variable "ssh_user" {
+  description = "User created by cloud-init, with passwordless sudo"
+  type        = string
+  default     = "e2e"
+}
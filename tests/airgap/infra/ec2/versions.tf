@@ -0,0 +1,30 @@
+# Cloud node of the E2E tests, see the README of tests/airgap
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "~> 3.6"
+    }
+    tls = {
+      source  = "hashicorp/tls"
+      version = "~> 4.0"
+    }
+  }
+}
+
+# Credentials are taken from the usual AWS_* variables or profiles
+provider "aws" {
+  region = var.region
+
+  default_tags {
+    tags = {
+      "e2e-lab" = var.name
+    }
+  }
+}
@@ -0,0 +1,91 @@
+# Credentials of the VM, generated for each lab
+resource "tls_private_key" "ssh" {
+  algorithm = "ED25519"
+}
+
+resource "random_password" "ssh" {
+  length  = 24
+  special = false
+}
+
+resource "libvirt_volume" "base" {
+  name   = "${var.name}-base.qcow2"
+  source = var.image_url
+  format = "qcow2"
+}
+
+resource "libvirt_volume" "disk" {
+  name           = "${var.name}.qcow2"
+  base_volume_id = libvirt_volume.base.id
+  size           = var.disk_size
+}
+
+# The SSH executor of the tests logs in with the password, the key is kept for manual access
+resource "libvirt_cloudinit_disk" "init" {
+  name = "${var.name}-init.iso"
+  user_data = <<-EOT
+    #cloud-config
+    ssh_pwauth: true
+    users:
+      - name: ${var.ssh_user}
+        sudo: ALL=(ALL) NOPASSWD:ALL
+        shell: /bin/bash
+        lock_passwd: false
+        passwd: ${bcrypt(random_password.ssh.result)}
+        ssh_authorized_keys:
+          - ${trimspace(tls_private_key.ssh.public_key_openssh)}
+    # Destructive steps of the tests are not confirmed on this node
+    write_files:
+      - path: /etc/kubewarden-e2e-node
+        content: ""
+  EOT
+
+  lifecycle {
+    # bcrypt() gives a new hash at each run
+    ignore_changes = [user_data]
+  }
+}
+
+resource "libvirt_domain" "node" {
+  name      = var.name
+  vcpu      = var.vcpu
+  memory    = var.memory
+  cloudinit = libvirt_cloudinit_disk.init.id
+
+  cpu {
+    mode = "host-passthrough"
+  }
+
+  disk {
+    volume_id = libvirt_volume.disk.id
+  }
+
+  network_interface {
+    network_name   = var.network
+    mac            = var.mac
+    wait_for_lease = true
+  }
+
+  console {
+    type        = "pty"
+    target_type = "serial"
+    target_port = "0"
+  }
+}
+
+# The tests can only connect once cloud-init has created the user
+resource "terraform_data" "ready" {
+  triggers_replace = [libvirt_domain.node.id]
+
+  connection {
+    type        = "ssh"
+    host        = libvirt_domain.node.network_interface[0].addresses[0]
+    user        = var.ssh_user
+    private_key = tls_private_key.ssh.private_key_openssh
+    timeout     = "10m"
+  }
+
+  provisioner "remote-exec" {
+    inline = ["cloud-init status --wait >/dev/null"]
+  }
+}
@@ -0,0 +1,25 @@
+# Outputs read by the infra helper, other modules have to provide the same ones
+output "node_ip" {
+  description = "IP address of the VM"
+  value       = libvirt_domain.node.network_interface[0].addresses[0]
+
+  # Only given once the node can be used
+  depends_on = [terraform_data.ready]
+}
+
+output "ssh_user" {
+  description = "User of the SSH connection"
+  value       = var.ssh_user
+}
+
+output "ssh_password" {
+  description = "Password of the SSH user"
+  value       = random_password.ssh.result
+  sensitive   = true
+}
+
+output "ssh_private_key" {
+  description = "Private key of the SSH user, in OpenSSH format"
+  value       = tls_private_key.ssh.private_key_openssh
+  sensitive   = true
+}
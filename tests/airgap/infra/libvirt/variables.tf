@@ -0,0 +1,54 @@
+variable "libvirt_uri" {
+  description = "Connection URI of the libvirt daemon"
+  type        = string
+  default     = "qemu:///system"
+}
+
+variable "name" {
+  description = "Name of the VM, also used as prefix of its volumes"
+  type        = string
+  default     = "kubewarden-e2e"
+}
+
+variable "image_url" {
+  description = "Cloud image of the VM, any distribution with cloud-init and sshd"
+  type        = string
+  default     = "https://download.opensuse.org/repositories/Cloud:/Images:/Leap_15.6/images/openSUSE-Leap-15.6.x86_64-NoCloud.qcow2"
+}
+
+variable "network" {
+  description = "Libvirt network of the VM, the default one is defined by assets/net-default-airgap.xml"
+  type        = string
+  default     = "default"
+}
+
+# Reserved in assets/net-default-airgap.xml, the VM gets 192.168.122.102 (rancher-manager.test)
+variable "mac" {
+  description = "MAC address of the VM"
+  type        = string
+  default     = "52:54:00:00:00:10"
+}
+
+variable "vcpu" {
+  description = "Number of virtual CPUs"
+  type        = number
+  default     = 4
+}
+
+variable "memory" {
+  description = "Memory of the VM, in MiB"
+  type        = number
+  default     = 8192
+}
+
+variable "disk_size" {
+  description = "Size of the disk, in bytes"
+  type        = number
+  default     = 42949672960
+}
+
+variable "ssh_user" {
+  description = "User created by cloud-init, with passwordless sudo"
+  type        = string
+  default     = "e2e"
+}
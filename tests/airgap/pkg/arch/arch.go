@@ -0,0 +1,58 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package arch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Names of uname -m, in the GOARCH format used by Kubernetes, images and release assets
+var machines = map[string]string{
+	"x86_64":  "amd64",
+	"amd64":   "amd64",
+	"aarch64": "arm64",
+	"arm64":   "arm64",
+}
+
+/*
+Get the architecture of a machine in the GOARCH format
+  - @param machine Output of uname -m, e.g. aarch64
+  - @returns Architecture (e.g. arm64), or an error if it is not supported
+*/
+func Normalize(machine string) (string, error) {
+	machine = strings.TrimSpace(machine)
+	if a, ok := machines[machine]; ok {
+		return a, nil
+	}
+
+	return "", fmt.Errorf("unsupported architecture %q", machine)
+}
+
+/*
+Get the architecture of a host
+  - @param r Runner of the host, local if its Remote is not set
+  - @returns Architecture in the GOARCH format, or an error
+*/
+func Of(r *runner.Runner) (string, error) {
+	out, err := r.Run("uname", "-m")
+	if err != nil {
+		return "", err
+	}
+
+	return Normalize(out)
+}
@@ -0,0 +1,315 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Supported storage types
+const (
+	LocalPath = "local-path"
+	HostPath  = "hostPath"
+	S3        = "s3"
+)
+
+// Target is a location where backup files are stored
+type Target struct {
+	// Storage type: local-path, hostPath or s3
+	Type string
+	// Directory on the host, or s3://bucket/folder for S3
+	Location string
+	// S3 endpoint URL, only used with S3 storage
+	Endpoint string
+}
+
+// Artifact is a backup file created by the backup operator
+type Artifact struct {
+	// File name, as reported in the Backup status
+	FileName string
+	// Path of the local copy of the backup file
+	LocalFile string
+	// Checksum (sha256) of the backup file
+	Sum string
+	// Size in bytes of the backup file
+	Size int64
+	// Where the backup file is currently stored
+	Target Target
+}
+
+/*
+Create a new backup artifact
+  - @param fileName Backup file name
+  - @param target Storage where the backup file is located
+  - @returns The artifact
+*/
+func New(fileName string, target Target) *Artifact {
+	return &Artifact{
+		FileName: fileName,
+		Target:   target,
+	}
+}
+
+/*
+Compute sha256 checksum of a file
+  - @param file File to check
+  - @returns Checksum of the file or an error
+*/
+func Checksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+/*
+Get the full path of the backup file in its storage
+  - @returns Full path (or S3 URL) of the backup file
+*/
+func (a *Artifact) Path() string {
+	if a.Target.Type == S3 {
+		return strings.TrimSuffix(a.Target.Location, "/") + "/" + a.FileName
+	}
+
+	return filepath.Join(a.Target.Location, a.FileName)
+}
+
+/*
+Locate the backup file in its storage
+  - @returns Full path (or S3 URL) of the backup file or an error
+*/
+func (a *Artifact) Locate() (string, error) {
+	var err error
+
+	switch a.Target.Type {
+	case LocalPath, HostPath:
+		// Backup files are owned by root
+		_, err = runner.Sudo("test", "-f", a.Path())
+	case S3:
+		_, err = a.s3("ls", a.Path())
+	default:
+		return "", fmt.Errorf("unsupported storage type %q", a.Target.Type)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("backup file %s not found: %w", a.Path(), err)
+	}
+
+	return a.Path(), nil
+}
+
+/*
+Copy the backup file from its storage into a local directory and record its checksum
+  - @param dir Local directory where the backup file is stored
+  - @returns Nothing or an error
+*/
+func (a *Artifact) Store(dir string) error {
+	src, err := a.Locate()
+	if err != nil {
+		return err
+	}
+
+	dst := filepath.Join(dir, a.FileName)
+	if err := a.copy(src, dst); err != nil {
+		return err
+	}
+
+	sum, err := Checksum(dst)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		return err
+	}
+
+	a.LocalFile = dst
+	a.Sum = sum
+	a.Size = info.Size()
+
+	// Make sure the copy is identical to the original file
+	return a.VerifyRemote()
+}
+
+/*
+Verify that the local copy of the backup file has not been altered
+  - @returns Nothing or an error
+*/
+func (a *Artifact) Verify() error {
+	if a.LocalFile == "" || a.Sum == "" {
+		return fmt.Errorf("backup file %s has not been stored locally", a.FileName)
+	}
+
+	info, err := os.Stat(a.LocalFile)
+	if err != nil {
+		return fmt.Errorf("backup file %s is not available anymore: %w", a.LocalFile, err)
+	}
+
+	if info.Size() != a.Size {
+		return fmt.Errorf("backup file %s has been truncated: expected %d bytes, got %d", a.LocalFile, a.Size, info.Size())
+	}
+
+	sum, err := Checksum(a.LocalFile)
+	if err != nil {
+		return err
+	}
+
+	if sum != a.Sum {
+		return fmt.Errorf("backup file %s has been altered: expected sha256 %s, got %s", a.LocalFile, a.Sum, sum)
+	}
+
+	return nil
+}
+
+/*
+Verify that the backup file in its storage matches the recorded checksum
+  - @returns Nothing or an error
+*/
+func (a *Artifact) VerifyRemote() error {
+	if a.Sum == "" {
+		return fmt.Errorf("no checksum recorded for backup file %s", a.FileName)
+	}
+
+	sum, err := a.remoteSum()
+	if err != nil {
+		return err
+	}
+
+	if sum != a.Sum {
+		return fmt.Errorf("backup file %s does not match: expected sha256 %s, got %s", a.Path(), a.Sum, sum)
+	}
+
+	return nil
+}
+
+/*
+Copy the local backup file into a (new) storage, so it can be restored
+  - @param target Storage where the backup file is copied
+  - @returns Nothing or an error
+*/
+func (a *Artifact) Inject(target Target) error {
+	// Never inject an altered file
+	if err := a.Verify(); err != nil {
+		return err
+	}
+
+	a.Target = target
+	if err := a.copy(a.LocalFile, a.Path()); err != nil {
+		return err
+	}
+
+	// Make sure the file is where the backup operator expects it
+	return a.VerifyRemote()
+}
+
+/*
+Copy a file from/to a backup storage
+  - @remarks This function is only used internally, not exported
+  - @param src Source file (or S3 URL)
+  - @param dst Destination file (or S3 URL)
+  - @returns Nothing or an error
+*/
+func (a *Artifact) copy(src, dst string) error {
+	var err error
+
+	switch a.Target.Type {
+	case LocalPath, HostPath:
+		// Keep the file readable, so checksum can be computed without sudo
+		_, err = runner.Sudo("install", "-m", "0644", src, dst)
+	case S3:
+		_, err = a.s3("cp", src, dst)
+	default:
+		return fmt.Errorf("unsupported storage type %q", a.Target.Type)
+	}
+
+	if err != nil {
+		return fmt.Errorf("copying %s to %s failed: %w", src, dst, err)
+	}
+
+	return nil
+}
+
+/*
+Compute sha256 checksum of the backup file in its storage
+  - @remarks This function is only used internally, not exported
+  - @returns Checksum of the file or an error
+*/
+func (a *Artifact) remoteSum() (string, error) {
+	switch a.Target.Type {
+	case LocalPath, HostPath:
+		// Backup files are owned by root
+		out, err := runner.Sudo("sha256sum", a.Path())
+		if err != nil {
+			return "", fmt.Errorf("computing checksum of %s failed: %w", a.Path(), err)
+		}
+
+		fields := strings.Fields(out)
+		if len(fields) == 0 {
+			return "", fmt.Errorf("computing checksum of %s failed: no output", a.Path())
+		}
+
+		return fields[0], nil
+	case S3:
+		// Checksum can only be computed on a downloaded copy
+		tmpDir, err := os.MkdirTemp("", "backup-")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		tmpFile := filepath.Join(tmpDir, a.FileName)
+		if err := a.copy(a.Path(), tmpFile); err != nil {
+			return "", err
+		}
+
+		return Checksum(tmpFile)
+	default:
+		return "", fmt.Errorf("unsupported storage type %q", a.Target.Type)
+	}
+}
+
+/*
+Execute an S3 command
+  - @remarks This function is only used internally, not exported
+  - @param args Arguments of the aws s3 command
+  - @returns Output of the command or an error
+*/
+func (a *Artifact) s3(args ...string) (string, error) {
+	flags := []string{"s3"}
+
+	// Custom endpoint is needed for S3 compatible storages like MinIO
+	if a.Target.Endpoint != "" {
+		flags = append(flags, "--endpoint-url", a.Target.Endpoint)
+	}
+
+	return runner.Run("aws", append(flags, args...)...)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/backup"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+)
+
+// Checksum of "hello\n"
+const helloSum = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+// Local copy of a backup file
+func stored(t *testing.T, content string) *backup.Artifact {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "kubewarden-backup.tar.gz")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := backup.New(filepath.Base(file), backup.Target{Type: backup.LocalPath, Location: "/var/lib/backups"})
+	a.LocalFile = file
+	a.Sum = helloSum
+	a.Size = int64(len("hello\n"))
+	return a
+}
+
+func TestChecksum(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name, content, sum string
+	}{
+		{"empty", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"hello", "hello\n", helloSum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sum, err := backup.Checksum(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sum != tt.sum {
+				t.Errorf("checksum is %s, %s expected", sum, tt.sum)
+			}
+		})
+	}
+
+	if _, err := backup.Checksum(filepath.Join(dir, "missing")); err == nil {
+		t.Error("checksum of a missing file should fail")
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		target backup.Target
+		path   string
+	}{
+		{"local-path", backup.Target{Type: backup.LocalPath, Location: "/var/lib/backups"}, "/var/lib/backups/file.tar.gz"},
+		{"hostPath", backup.Target{Type: backup.HostPath, Location: "/mnt/backups/"}, "/mnt/backups/file.tar.gz"},
+		{"s3", backup.Target{Type: backup.S3, Location: "s3://bucket/folder"}, "s3://bucket/folder/file.tar.gz"},
+		{"s3 with a trailing slash", backup.Target{Type: backup.S3, Location: "s3://bucket/"}, "s3://bucket/file.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path := backup.New("file.tar.gz", tt.target).Path(); path != tt.path {
+				t.Errorf("path is %s, %s expected", path, tt.path)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		change  func(a *backup.Artifact)
+		err     string
+	}{
+		{"unchanged", "hello\n", nil, ""},
+		{"not stored", "hello\n", func(a *backup.Artifact) { a.LocalFile = "" }, "has not been stored locally"},
+		{"removed", "hello\n", func(a *backup.Artifact) { os.Remove(a.LocalFile) }, "is not available anymore"},
+		{"truncated", "hell", nil, "has been truncated"},
+		{"altered", "HELLO\n", nil, "has been altered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := stored(t, tt.content)
+			if tt.change != nil {
+				tt.change(a)
+			}
+
+			err := a.Verify()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("error is %v, %q expected", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVerifyRemote(t *testing.T) {
+	a := stored(t, "hello\n")
+	path := a.Path()
+
+	tests := []struct {
+		name, stdout, err string
+	}{
+		{"matching", helloSum + "  " + path + "\n", ""},
+		{"mismatch", strings.Repeat("0", 64) + "  " + path + "\n", "does not match"},
+		{"no output", "", "no output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backup files on the host are read with sudo
+			replay.ForTest(t, replay.Output{Args: []string{"sudo", "sha256sum", path}, Stdout: tt.stdout})
+
+			err := a.VerifyRemote()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("error is %v, %q expected", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnsupportedStorage(t *testing.T) {
+	a := stored(t, "hello\n")
+	a.Target.Type = "nfs"
+
+	checks := map[string]func() error{
+		"Locate": func() error {
+			_, err := a.Locate()
+			return err
+		},
+		"VerifyRemote": a.VerifyRemote,
+		"Inject":       func() error { return a.Inject(a.Target) },
+	}
+
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			if err := check(); err == nil || !strings.Contains(err.Error(), `unsupported storage type "nfs"`) {
+				t.Errorf("error is %v, unsupported storage type expected", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cabundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/portforward"
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+)
+
+// Secrets of the root CA of the controller and their key, the name depends on the controller version
+var caSecrets = [][2]string{
+	{"kubewarden-ca", "ca.crt"},
+	{"kubewarden-root-ca", "policy-server-root-ca-pem"},
+}
+
+// Webhook is a Kubewarden webhook, with the certificates served by its service
+type Webhook struct {
+	Configuration string
+	Name          string
+	Namespace     string
+	Service       string
+	Port          int
+	CABundle      []byte
+	// Certificates sent by the service, leaf first
+	Served []*x509.Certificate
+}
+
+/*
+Get the root CA of the Kubewarden controller
+  - @param ns Namespace where Kubewarden is installed
+  - @returns PEM encoded CA, or an error if no CA secret is found
+*/
+func CA(ns string) ([]byte, error) {
+	for _, s := range caSecrets {
+		out, err := cluster.Default.KubectlWithoutErr("get", "secret", s[0], "--namespace", ns,
+			"-o", "jsonpath={.data."+strings.ReplaceAll(s[1], ".", `\.`)+"}")
+		if err != nil || out == "" {
+			continue
+		}
+
+		return base64.StdEncoding.DecodeString(out)
+	}
+
+	return nil, fmt.Errorf("no CA secret found in %s", ns)
+}
+
+/*
+Get the Kubewarden webhooks and the certificates served by their services
+  - @remarks Each service is reached through a port-forward, stopped before returning
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @returns The webhooks of the policies, or an error
+*/
+func Inspect(ctx context.Context) ([]Webhook, error) {
+	out, err := cluster.Default.KubectlWithoutErr("get", "validatingwebhookconfigurations,mutatingwebhookconfigurations", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Webhooks []struct {
+				Name         string `json:"name"`
+				ClientConfig struct {
+					CABundle []byte `json:"caBundle"`
+					Service  *struct {
+						Namespace string `json:"namespace"`
+						Name      string `json:"name"`
+						Port      *int   `json:"port"`
+					} `json:"service"`
+				} `json:"clientConfig"`
+			} `json:"webhooks"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse webhook configurations: %w", err)
+	}
+
+	// Policies of the same policy server share its service
+	served := map[string][]*x509.Certificate{}
+
+	var webhooks []Webhook
+	for _, config := range list.Items {
+		for _, hook := range config.Webhooks {
+			svc := hook.ClientConfig.Service
+			if !strings.HasSuffix(hook.Name, ".kubewarden.admission") || svc == nil {
+				continue
+			}
+
+			w := Webhook{
+				Configuration: config.Metadata.Name,
+				Name:          hook.Name,
+				Namespace:     svc.Namespace,
+				Service:       svc.Name,
+				Port:          443,
+				CABundle:      hook.ClientConfig.CABundle,
+			}
+			if svc.Port != nil {
+				w.Port = *svc.Port
+			}
+
+			key := fmt.Sprintf("%s/%s:%d", w.Namespace, w.Service, w.Port)
+			if _, ok := served[key]; !ok {
+				certs, err := serve(ctx, w.Namespace, w.Service, w.Port)
+				if err != nil {
+					return nil, err
+				}
+				served[key] = certs
+			}
+			w.Served = served[key]
+
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks, nil
+}
+
+/*
+Get the certificates served by a service
+  - @remarks This function is only used internally, not exported
+  - @param ctx Context of the port-forward
+  - @param ns Namespace of the service
+  - @param service Name of the service
+  - @param port Port of the service
+  - @returns Certificates sent during the TLS handshake, or an error
+*/
+func serve(ctx context.Context, ns, service string, port int) ([]*x509.Certificate, error) {
+	f, err := portforward.Start(ctx, ns, "svc/"+service, port)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Stop()
+
+	// Only the certificates are needed, they are verified by the matcher
+	conn, err := tls.Dial("tcp", f.Addr(), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return nil, fmt.Errorf("no TLS handshake with %s/%s: %w", ns, service, err)
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates, nil
+}
+
+/*
+Match webhooks consistent with a CA
+  - @remarks The caBundle of each webhook has to contain the CA, and the certificate served on its port has to be signed by it
+  - @param ca PEM encoded CA, e.g. from CA()
+  - @returns Gomega matcher of []Webhook
+*/
+func MatchCA(ca []byte) types.GomegaMatcher {
+	var issues strings.Builder
+
+	return gcustom.MakeMatcher(func(webhooks []Webhook) (bool, error) {
+		issues.Reset()
+
+		block, _ := pem.Decode(ca)
+		if block == nil {
+			return false, fmt.Errorf("CA is not PEM encoded")
+		}
+
+		for _, w := range webhooks {
+			if err := check(w, block.Bytes); err != nil {
+				fmt.Fprintf(&issues, "%s (%s): %v\n", w.Name, w.Configuration, err)
+			}
+		}
+
+		return issues.Len() == 0, nil
+	}).WithTemplate("Expected webhooks to match the Kubewarden CA, mismatches:\n{{.Data}}", &issues)
+}
+
+/*
+Check a webhook against a CA
+  - @remarks This function is only used internally, not exported
+  - @param w Webhook to check
+  - @param ca DER encoded CA
+  - @returns An error if the caBundle or the served certificate do not match
+*/
+func check(w Webhook, ca []byte) error {
+	roots := x509.NewCertPool()
+
+	found := false
+	for rest := w.CABundle; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			found = found || bytes.Equal(cert.Raw, ca)
+			roots.AddCert(cert)
+		}
+	}
+	if !found {
+		return fmt.Errorf("caBundle does not contain the CA")
+	}
+
+	if len(w.Served) == 0 {
+		return fmt.Errorf("no certificate served by %s/%s:%d", w.Namespace, w.Service, w.Port)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range w.Served[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	// The API server reaches the service through its DNS name
+	_, err := w.Served[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       w.Service + "." + w.Namespace + ".svc",
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate served by %s/%s:%d: %w", w.Namespace, w.Service, w.Port, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaos
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+)
+
+// API group and version of the Chaos Mesh experiments
+const apiVersion = "chaos-mesh.org/v1alpha1"
+
+// Selector of the pods targeted by an experiment
+type Selector struct {
+	// Namespace of the pods
+	Namespace string
+	// Labels of the pods, all of them have to match
+	Labels map[string]string
+}
+
+// Experiment is a Chaos Mesh resource, declared with PodKill, NetworkDelay or IOLatency
+type Experiment struct {
+	// Kind of the resource, e.g. PodChaos
+	Kind string
+	// Name of the experiment
+	Name string
+	// Namespace of the experiment, the one of the selector by default
+	Namespace string
+	// Labels of the experiment, e.g. to clean it with the run
+	Labels map[string]string
+	// Spec of the experiment
+	Spec map[string]any
+}
+
+/*
+Declare an experiment killing one of the selected pods
+  - @param name Name of the experiment
+  - @param selector Pods to kill
+  - @returns The experiment, to be applied
+*/
+func PodKill(name string, selector Selector) *Experiment {
+	return newExperiment("PodChaos", name, selector, map[string]any{
+		"action": "pod-kill",
+		"mode":   "one",
+	})
+}
+
+/*
+Declare an experiment delaying the traffic of the selected pods to external hosts
+  - @remarks Used to slow down a registry, the delay lasts until the experiment is deleted or the duration is over
+  - @param name Name of the experiment
+  - @param selector Pods sending the traffic
+  - @param hosts Hostnames or IP addresses of the destinations, e.g. ghcr.io
+  - @param latency Latency added to each packet
+  - @param duration Duration of the experiment
+  - @returns The experiment, to be applied
+*/
+func NetworkDelay(name string, selector Selector, hosts []string, latency, duration time.Duration) *Experiment {
+	return newExperiment("NetworkChaos", name, selector, map[string]any{
+		"action":          "delay",
+		"mode":            "all",
+		"direction":       "to",
+		"externalTargets": hosts,
+		"delay":           map[string]any{"latency": latency.String()},
+		"duration":        duration.String(),
+	})
+}
+
+/*
+Declare an experiment adding latency to the file operations of a volume
+  - @remarks Used on the backup volume, Chaos Mesh injects the latency with a FUSE layer on the mount point
+  - @param name Name of the experiment
+  - @param selector Pods mounting the volume
+  - @param volumePath Mount point of the volume in the containers
+  - @param delay Latency added to each operation
+  - @param duration Duration of the experiment
+  - @returns The experiment, to be applied
+*/
+func IOLatency(name string, selector Selector, volumePath string, delay, duration time.Duration) *Experiment {
+	return newExperiment("IOChaos", name, selector, map[string]any{
+		"action":     "latency",
+		"mode":       "all",
+		"volumePath": volumePath,
+		"delay":      delay.String(),
+		"percent":    100,
+		"duration":   duration.String(),
+	})
+}
+
+/*
+Create an experiment
+  - @remarks This function is only used internally, not exported
+  - @param kind Kind of the resource
+  - @param name Name of the experiment
+  - @param selector Targeted pods
+  - @param spec Spec of the experiment, without the selector
+  - @returns The experiment
+*/
+func newExperiment(kind, name string, selector Selector, spec map[string]any) *Experiment {
+	spec["selector"] = map[string]any{
+		"namespaces":     []string{selector.Namespace},
+		"labelSelectors": selector.Labels,
+	}
+
+	return &Experiment{Kind: kind, Name: name, Namespace: selector.Namespace, Labels: map[string]string{}, Spec: spec}
+}
+
+/*
+Get the manifest of the experiment
+  - @returns Manifest in JSON, accepted by kubectl apply, or an error
+*/
+func (e *Experiment) Manifest() ([]byte, error) {
+	return json.MarshalIndent(map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       e.Kind,
+		"metadata": map[string]any{
+			"name":      e.Name,
+			"namespace": e.Namespace,
+			"labels":    e.Labels,
+		},
+		"spec": e.Spec,
+	}, "", "  ")
+}
+
+/*
+Apply the experiment
+  - @remarks The manifest is written in a temporary file, so it is displayed in the dry-run plan
+  - @param c Cluster where Chaos Mesh is installed
+  - @returns An error if the experiment cannot be applied
+*/
+func (e *Experiment) Apply(c *cluster.Cluster) error {
+	manifest, err := e.Manifest()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp("", "chaos-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(manifest); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	_, err = c.KubectlWithoutErr("apply", "-f", f.Name())
+	return err
+}
+
+/*
+Delete the experiment, the faults are recovered by Chaos Mesh
+  - @param c Cluster where Chaos Mesh is installed
+  - @returns An error if the experiment cannot be deleted
+*/
+func (e *Experiment) Delete(c *cluster.Cluster) error {
+	_, err := c.KubectlWithoutErr("delete", e.Resource(), e.Name, "--namespace", e.Namespace, "--ignore-not-found", "--wait")
+	return err
+}
+
+/*
+Check if the faults of the experiment are injected
+  - @param c Cluster where Chaos Mesh is installed
+  - @returns An error until Chaos Mesh reports all the faults as injected
+*/
+func (e *Experiment) Injected(c *cluster.Cluster) error {
+	out, err := c.KubectlWithoutErr("get", e.Resource(), e.Name, "--namespace", e.Namespace,
+		"-o", `jsonpath={.status.conditions[?(@.type=="AllInjected")].status}`)
+	if err != nil {
+		return err
+	}
+	if out != "True" {
+		return fmt.Errorf("%s %s is not injected yet", e.Kind, e.Name)
+	}
+
+	return nil
+}
+
+/*
+Get the resource name of the experiment for kubectl
+  - @returns Resource with its group, e.g. podchaos.chaos-mesh.org
+*/
+func (e *Experiment) Resource() string {
+	return strings.ToLower(e.Kind) + ".chaos-mesh.org"
+}
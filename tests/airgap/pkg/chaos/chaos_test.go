@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaos_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/chaos"
+)
+
+var selector = chaos.Selector{Namespace: "kubewarden", Labels: map[string]string{"app": "kubewarden-policy-server-default"}}
+
+// Decode the manifest of an experiment
+func manifest(t *testing.T, e *chaos.Experiment) map[string]any {
+	data, err := e.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPodKill(t *testing.T) {
+	e := chaos.PodKill("kill", selector)
+	e.Labels["e2e-run"] = "abc"
+	m := manifest(t, e)
+
+	if m["kind"] != "PodChaos" || m["apiVersion"] != "chaos-mesh.org/v1alpha1" {
+		t.Errorf("unexpected type %v %v", m["apiVersion"], m["kind"])
+	}
+	metadata := m["metadata"].(map[string]any)
+	if metadata["namespace"] != "kubewarden" || metadata["labels"].(map[string]any)["e2e-run"] != "abc" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+	spec := m["spec"].(map[string]any)
+	if spec["action"] != "pod-kill" || spec["mode"] != "one" {
+		t.Errorf("unexpected spec %v", spec)
+	}
+	labels := spec["selector"].(map[string]any)["labelSelectors"].(map[string]any)
+	if labels["app"] != "kubewarden-policy-server-default" {
+		t.Errorf("unexpected selector %v", spec["selector"])
+	}
+	if e.Resource() != "podchaos.chaos-mesh.org" {
+		t.Errorf("unexpected resource %s", e.Resource())
+	}
+}
+
+func TestNetworkDelay(t *testing.T) {
+	spec := manifest(t, chaos.NetworkDelay("delay", selector, []string{"ghcr.io"}, 2*time.Second, 5*time.Minute))["spec"].(map[string]any)
+
+	if spec["action"] != "delay" || spec["direction"] != "to" || spec["duration"] != "5m0s" {
+		t.Errorf("unexpected spec %v", spec)
+	}
+	if spec["delay"].(map[string]any)["latency"] != "2s" {
+		t.Errorf("unexpected latency %v", spec["delay"])
+	}
+	if targets := spec["externalTargets"].([]any); len(targets) != 1 || targets[0] != "ghcr.io" {
+		t.Errorf("unexpected targets %v", targets)
+	}
+}
+
+func TestIOLatency(t *testing.T) {
+	spec := manifest(t, chaos.IOLatency("io", selector, "/var/lib/backups", 100*time.Millisecond, time.Minute))["spec"].(map[string]any)
+
+	if spec["action"] != "latency" || spec["volumePath"] != "/var/lib/backups" || spec["delay"] != "100ms" || spec["percent"] != 100.0 {
+		t.Errorf("unexpected spec %v", spec)
+	}
+}
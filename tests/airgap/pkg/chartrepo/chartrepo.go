@@ -0,0 +1,162 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartrepo
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// Chart packaged in the offline repository
+type Chart struct {
+	// URL of the upstream repository, or of the chart archive itself
+	Repo string
+	// Name of the chart, empty if Repo is the archive
+	Name string
+	// Version of the chart, latest stable if empty
+	Version string
+}
+
+// Server is a static Helm repository, serving the archives and the index of a directory
+type Server struct {
+	// Directory of the repository
+	Dir string
+
+	url      string
+	server   *http.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+/*
+Download the archives of the charts
+  - @param dir Directory of the repository, created if needed
+  - @param charts Charts to download
+  - @returns Nothing or an error
+*/
+func Package(dir string, charts []Chart) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, c := range charts {
+		args := []string{"pull", c.Repo, "--destination", dir}
+		if c.Name != "" {
+			args = []string{"pull", c.Name, "--repo", c.Repo, "--destination", dir}
+		}
+		if c.Version != "" {
+			args = append(args, "--version", c.Version)
+		}
+
+		if _, err := runner.Run("helm", args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Start serving a repository
+  - @remarks The index is generated with the URL of the server, so the archives are also fetched from it
+  - @param dir Directory of the repository, with the archives of the charts
+  - @param addr Listening address, e.g. 0.0.0.0:8879 to be reachable from the airgap network
+  - @param host Host of the repository URL, e.g. 127.0.0.1 or the IP of the test host
+  - @returns The running server or an error
+*/
+func Serve(dir, addr, host string) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	s := &Server{Dir: dir, url: "http://" + net.JoinHostPort(host, port)}
+
+	if _, err := runner.Run("helm", "repo", "index", dir, "--url", s.url); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	files := http.FileServer(http.Dir(dir))
+	s.server = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.mu.Unlock()
+
+		files.ServeHTTP(w, r)
+	})}
+	go func() {
+		if err := s.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			GinkgoWriter.Printf("Chart repository of %s stopped: %v\n", dir, err)
+		}
+	}()
+
+	return s, nil
+}
+
+/*
+Get the URL of the repository
+  - @returns URL to use with helm repo add
+*/
+func (s *Server) URL() string {
+	return s.url
+}
+
+/*
+Get the paths requested to the server
+  - @returns Requested paths, in order, e.g. /index.yaml
+*/
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.requests...)
+}
+
+/*
+Check if an archive of a chart has been downloaded
+  - @param chart Name of the chart
+  - @returns True if an archive of the chart has been requested
+*/
+func (s *Server) Served(chart string) bool {
+	for _, path := range s.Requests() {
+		name := strings.TrimPrefix(path, "/")
+		if strings.HasSuffix(name, ".tgz") && strings.HasPrefix(name, chart+"-") {
+			// Not another chart with the same prefix, e.g. rancher-backup and rancher-backup-crd
+			version := strings.TrimSuffix(strings.TrimPrefix(name, chart+"-"), ".tgz")
+			if version != "" && version[0] >= '0' && version[0] <= '9' {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+/*
+Stop the server
+  - @returns Nothing or an error
+*/
+func (s *Server) Close() error {
+	return s.server.Close()
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os/exec"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+)
+
+// Cluster is a Kubernetes cluster reached through its own kubeconfig,
+// kubectl and helm get it explicitly so nothing depends on the KUBECONFIG of the process
+type Cluster struct {
+	// Name of the cluster, only used in messages
+	Name string
+	// Path of the kubeconfig, the default one of kubectl and helm if empty
+	Kubeconfig string
+}
+
+// Default is the cluster of the KUBECONFIG of the process, used by the helpers of this module
+var Default = New("default", "")
+
+/*
+Create a cluster
+  - @param name Name of the cluster
+  - @param kubeconfig Path of the kubeconfig, empty for the default one
+  - @returns The cluster
+*/
+func New(name, kubeconfig string) *Cluster {
+	return &Cluster{Name: name, Kubeconfig: kubeconfig}
+}
+
+/*
+Get a copy of the cluster using another kubeconfig
+  - @remarks Used to override the cluster of a single spec, e.g. with the credentials of another user
+  - @param kubeconfig Path of the kubeconfig
+  - @returns The new cluster
+*/
+func (c *Cluster) WithKubeconfig(kubeconfig string) *Cluster {
+	n := *c
+	n.Kubeconfig = kubeconfig
+	return &n
+}
+
+/*
+Get the environment of the commands not supporting --kubeconfig
+  - @returns Environment variables, in KEY=value format, empty for the default kubeconfig
+*/
+func (c *Cluster) Env() []string {
+	if c.Kubeconfig == "" {
+		return nil
+	}
+	return []string{"KUBECONFIG=" + c.Kubeconfig}
+}
+
+func (c *Cluster) String() string {
+	return c.Name
+}
+
+/*
+Execute a kubectl command, stderr included in the output
+  - @remarks Same as kubectl.Run of ele-testhelpers, used to check the messages of the admission webhooks
+  - @param args Arguments of kubectl
+  - @returns Output of the command or an error
+*/
+func (c *Cluster) Kubectl(args ...string) (string, error) {
+	stdout, stderr, err := c.run("kubectl", args...)
+	return stdout + stderr, err
+}
+
+/*
+Execute a kubectl command
+  - @remarks Same as kubectl.RunWithoutErr of ele-testhelpers, stderr is only kept in the error
+  - @param args Arguments of kubectl
+  - @returns Standard output of the command or an error
+*/
+func (c *Cluster) KubectlWithoutErr(args ...string) (string, error) {
+	stdout, _, err := c.run("kubectl", args...)
+	return stdout, err
+}
+
+/*
+Execute a helm command
+  - @param args Arguments of helm
+  - @returns Standard output of the command or an error
+*/
+func (c *Cluster) Helm(args ...string) (string, error) {
+	stdout, _, err := c.run("helm", args...)
+	return stdout, err
+}
+
+/*
+Execute kubectl or helm with the kubeconfig of the cluster
+  - @remarks This function is only used internally, not exported
+  - @remarks Binaries are found through PATH, so the dry-run and replay shims are used too
+  - @param name Binary to execute
+  - @param args Arguments of the binary
+  - @returns Standard output and error of the command, or an error of type *cmderr.Error
+*/
+func (c *Cluster) run(name string, args ...string) (string, string, error) {
+	if c.Kubeconfig != "" {
+		args = append([]string{"--kubeconfig", c.Kubeconfig}, args...)
+	}
+
+	return cmderr.Run(exec.Command(name, args...))
+}
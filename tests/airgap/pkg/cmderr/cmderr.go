@@ -0,0 +1,169 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmderr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Error is returned when a command fails, with everything needed to understand why
+type Error struct {
+	// Executed command line
+	Cmd string
+	// Host where the command was executed, empty for local
+	Host string
+	// Exit code of the command, -1 if it did not run
+	ExitCode int
+	// Standard output of the command
+	Stdout string
+	// Standard error of the command, included in Stdout for remote commands
+	Stderr string
+	// Underlying error
+	Err error
+}
+
+func (e *Error) Error() string {
+	where := "locally"
+	if e.Host != "" {
+		where = "on " + e.Host
+	}
+
+	msg := fmt.Sprintf("command %q failed %s with exit code %d: %v", e.Cmd, where, e.ExitCode, e.Err)
+	if out := strings.TrimSpace(e.Stderr); out != "" {
+		msg += ": " + out
+	} else if out := strings.TrimSpace(e.Stdout); out != "" {
+		msg += ": " + out
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+/*
+Get the full details of the error
+  - @remarks Used by Gomega in failure messages, the outputs are never truncated
+  - @returns Command line, exit code and outputs of the command
+*/
+func (e *Error) GomegaString() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "command: %s\n", e.Cmd)
+	if e.Host != "" {
+		fmt.Fprintf(&b, "host: %s\n", e.Host)
+	}
+	fmt.Fprintf(&b, "exit code: %d\n", e.ExitCode)
+	fmt.Fprintf(&b, "error: %v\n", e.Err)
+	if e.Stdout != "" {
+		fmt.Fprintf(&b, "stdout:\n%s\n", strings.TrimRight(e.Stdout, "\n"))
+	}
+	if e.Stderr != "" {
+		fmt.Fprintf(&b, "stderr:\n%s\n", strings.TrimRight(e.Stderr, "\n"))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+/*
+Format the errors wrapping a command error
+  - @remarks To register with format.RegisterCustomFormatter, GomegaString is only used for unwrapped errors
+  - @param value Value displayed by Gomega
+  - @returns Full details of the command error and true, or false if the value is not an error of a command
+*/
+func Format(value any) (string, bool) {
+	err, ok := value.(error)
+	if !ok {
+		return "", false
+	}
+
+	var cmdErr *Error
+	if !errors.As(err, &cmdErr) {
+		return "", false
+	}
+
+	return cmdErr.GomegaString(), true
+}
+
+/*
+Execute a local command
+  - @remarks Stdout and stderr of the command are captured, unless already set
+  - @param cmd Command to execute
+  - @returns Standard output and error of the command, or an error of type *Error
+*/
+func Run(cmd *exec.Cmd) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	if cmd.Stdout == nil {
+		cmd.Stdout = &stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), stderr.String(), &Error{
+			Cmd:      Quote(cmd.Args),
+			ExitCode: ExitCode(err),
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			Err:      err,
+		}
+	}
+
+	return stdout.String(), stderr.String(), nil
+}
+
+/*
+Get the exit code of a command error
+  - @param err Error of a local or remote (SSH) command
+  - @returns The exit code, -1 if unknown
+*/
+func ExitCode(err error) int {
+	var localErr *exec.ExitError
+	if errors.As(err, &localErr) {
+		return localErr.ExitCode()
+	}
+
+	// Error returned by SSH sessions
+	var remoteErr interface{ ExitStatus() int }
+	if errors.As(err, &remoteErr) {
+		return remoteErr.ExitStatus()
+	}
+
+	return -1
+}
+
+/*
+Quote arguments for a shell
+  - @param args Command and its arguments
+  - @returns Quoted command line, can be copied in a terminal
+*/
+func Quote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`*?!;&|<>(){}[]#~") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+
+	return strings.Join(quoted, " ")
+}
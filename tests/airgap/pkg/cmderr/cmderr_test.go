@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmderr_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+)
+
+func TestRun(t *testing.T) {
+	stdout, _, err := cmderr.Run(exec.Command("bash", "-c", "echo out; echo err >&2; exit 3"))
+
+	var cmdErr *cmderr.Error
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("%v is not a command error", err)
+	}
+	if stdout != "out\n" || cmdErr.Stdout != "out\n" || cmdErr.Stderr != "err\n" {
+		t.Errorf("unexpected outputs %q and %q", cmdErr.Stdout, cmdErr.Stderr)
+	}
+	if cmdErr.ExitCode != 3 {
+		t.Errorf("exit code %d, 3 expected", cmdErr.ExitCode)
+	}
+	if cmdErr.Cmd != "bash -c 'echo out; echo err >&2; exit 3'" {
+		t.Errorf("unexpected command line %s", cmdErr.Cmd)
+	}
+}
+
+func TestRunNotFound(t *testing.T) {
+	_, _, err := cmderr.Run(exec.Command("e2e-not-a-command"))
+
+	var cmdErr *cmderr.Error
+	if !errors.As(err, &cmdErr) || cmdErr.ExitCode != -1 {
+		t.Errorf("%v is not a command error without exit code", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// Outputs longer than format.MaxLength of Gomega are kept
+	long := strings.Repeat("x", 5000)
+	err := fmt.Errorf("applying the policy: %w", &cmderr.Error{
+		Cmd:      "kubectl apply -f policy.yaml",
+		ExitCode: 1,
+		Stderr:   long,
+		Err:      errors.New("exit status 1"),
+	})
+
+	out, ok := cmderr.Format(err)
+	if !ok {
+		t.Fatal("wrapped command error not formatted")
+	}
+	for _, s := range []string{"command: kubectl apply -f policy.yaml", "exit code: 1", "stderr:\n" + long} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q not in the formatted error", s[:min(len(s), 40)])
+		}
+	}
+
+	if _, ok := cmderr.Format(errors.New("other")); ok {
+		t.Error("other errors must be left to Gomega")
+	}
+}
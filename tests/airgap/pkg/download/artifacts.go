@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import "fmt"
+
+/*
+Get the install script of K3s
+  - @remarks No sum is published, it is pinned by the checksums of the cache or with K3S_INSTALL_SHA256
+  - @param sum Expected sha256 sum, empty to use the one of the cache
+  - @returns The artifact
+*/
+func K3sScript(sum string) Artifact {
+	return Artifact{Name: "k3s-install.sh", URL: "https://get.k3s.io", SHA256: sum}
+}
+
+/*
+Get the kubectl binary
+  - @param version Version of kubectl, e.g. v1.28.2
+  - @param arch Architecture, in GOARCH format
+  - @returns The artifact
+*/
+func Kubectl(version, arch string) Artifact {
+	url := fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/%s/kubectl", version, arch)
+	return Artifact{Name: fmt.Sprintf("kubectl-%s-%s", version, arch), URL: url, SumURL: url + ".sha256"}
+}
+
+/*
+Get the archive of helm
+  - @param version Version of helm, e.g. v3.17.3
+  - @param arch Architecture, in GOARCH format
+  - @returns The artifact
+*/
+func Helm(version, arch string) Artifact {
+	name := fmt.Sprintf("helm-%s-linux-%s.tar.gz", version, arch)
+	url := "https://get.helm.sh/" + name
+	return Artifact{Name: name, URL: url, SumURL: url + ".sha256sum"}
+}
+
+/*
+Get the archive of kwctl
+  - @param version Version of kwctl, e.g. v1.23.0
+  - @param arch Architecture, in GOARCH format
+  - @returns The artifact
+*/
+func Kwctl(version, arch string) Artifact {
+	// Release assets use the names of uname -m
+	machine := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}[arch]
+	return Artifact{
+		Name: fmt.Sprintf("kwctl-%s-linux-%s.zip", version, machine),
+		URL:  fmt.Sprintf("https://github.com/kubewarden/kwctl/releases/download/%s/kwctl-linux-%s.zip", version, machine),
+	}
+}
+
+/*
+Get the wasm module of a policy
+  - @remarks Modules are attached to the GitHub releases of the policies
+  - @param policy Repository of the policy in the kubewarden organization, e.g. pod-privileged-policy
+  - @param version Version of the policy, e.g. v0.2.1
+  - @returns The artifact
+*/
+func Policy(policy, version string) Artifact {
+	return Artifact{
+		Name: fmt.Sprintf("%s-%s.wasm", policy, version),
+		URL:  fmt.Sprintf("https://github.com/kubewarden/%s/releases/download/%s/policy.wasm", policy, version),
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+)
+
+// Checksums of the cached files, in the format of sha256sum, shipped with a pre-seeded cache
+const sumsFile = "SHA256SUMS"
+
+// Serializes the updates of the checksums in a process
+var sumsMutex sync.Mutex
+
+// Artifact is an external file fetched by the tests
+type Artifact struct {
+	// Name of the file in the cache, must be unique for each version
+	Name string
+	// Download URL
+	URL string
+	// Expected sha256 sum, taken from SumURL or the checksums of the cache if empty
+	SHA256 string
+	// URL of the published sum, the first field of the file is used
+	SumURL string
+}
+
+/*
+Get the directory of the cache
+  - @remarks Set with E2E_CACHE_DIR
+  - @returns Path of the cache, ~/.cache/kubewarden-e2e by default
+*/
+func Dir() string {
+	if dir := os.Getenv("E2E_CACHE_DIR"); dir != "" {
+		return dir
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "kubewarden-e2e")
+}
+
+/*
+Check if the artifacts can only be taken from the cache
+  - @remarks Set with E2E_CACHE_OFFLINE=true, e.g. on airgap test hosts with a pre-seeded cache
+  - @returns True if nothing is downloaded
+*/
+func Offline() bool {
+	return os.Getenv("E2E_CACHE_OFFLINE") == "true"
+}
+
+/*
+Get an artifact, from the cache if already downloaded
+  - @remarks The sum is always verified, a file without known sum is trusted on first download and its sum recorded
+  - @param ctx Context of the download
+  - @param a Artifact to get
+  - @returns Path of the file in the cache or an error
+*/
+func Get(ctx context.Context, a Artifact) (string, error) {
+	dir := Dir()
+	file := filepath.Join(dir, a.Name)
+
+	if dryrun.Enabled() {
+		dryrun.Record("download %s to %s", a.URL, file)
+		return file, nil
+	}
+
+	expected := cmp.Or(a.SHA256, recordedSum(dir, a.Name))
+	if _, err := os.Stat(file); err == nil {
+		sum, err := Sum(file)
+		if err != nil {
+			return "", err
+		}
+		if expected == "" || sum == expected {
+			return file, nil
+		}
+		if Offline() {
+			return "", fmt.Errorf("sha256 of cached %s is %s, %s expected", file, sum, expected)
+		}
+	}
+
+	if Offline() {
+		return "", fmt.Errorf("%s is not in the cache %s, download it from %s", a.Name, dir, a.URL)
+	}
+
+	if expected == "" && a.SumURL != "" {
+		published, err := fetch(ctx, a.SumURL)
+		if err != nil {
+			return "", err
+		}
+		fields := strings.Fields(string(published))
+		if len(fields) == 0 {
+			return "", fmt.Errorf("no sum in %s", a.SumURL)
+		}
+		expected = fields[0]
+	}
+
+	sum, err := save(ctx, a.URL, file)
+	if err != nil {
+		return "", err
+	}
+	if expected != "" && sum != expected {
+		_ = os.Remove(file)
+		return "", fmt.Errorf("sha256 of %s is %s, %s expected", a.URL, sum, expected)
+	}
+
+	return file, recordSum(dir, a.Name, sum)
+}
+
+/*
+Compute the sha256 sum of a file
+  - @param file Path of the file
+  - @returns Hex encoded sum or an error
+*/
+func Sum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+/*
+Download a URL in a file
+  - @remarks This function is only used internally, not exported
+  - @remarks Written in a temporary file first, so a partial download is never cached
+  - @param ctx Context of the download
+  - @param url URL to download
+  - @param file Destination file
+  - @returns Sum of the file or an error
+*/
+func save(ctx context.Context, url, file string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+
+	body, err := open(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), body)
+	if err := cmp.Or(err, tmp.Close()); err != nil {
+		return "", fmt.Errorf("downloading %s: %w", url, err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+/*
+Download a small file
+  - @remarks This function is only used internally, not exported
+  - @param ctx Context of the download
+  - @param url URL to download
+  - @returns Content of the file or an error
+*/
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	body, err := open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(io.LimitReader(body, 1<<20))
+}
+
+/*
+Send a GET request
+  - @remarks This function is only used internally, not exported
+  - @param ctx Context of the request
+  - @param url URL to get
+  - @returns Body of the response or an error
+*/
+func open(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+/*
+Get the sum of a file recorded in the cache
+  - @remarks This function is only used internally, not exported
+  - @param dir Directory of the cache
+  - @param name Name of the file
+  - @returns The last recorded sum, empty if none
+*/
+func recordedSum(dir, name string) string {
+	f, err := os.Open(filepath.Join(dir, sumsFile))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var sum string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Binary files are prefixed with * by sha256sum
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			sum = fields[0]
+		}
+	}
+
+	return sum
+}
+
+/*
+Record the sum of a file in the cache
+  - @remarks This function is only used internally, not exported
+  - @param dir Directory of the cache
+  - @param name Name of the file
+  - @param sum Sum of the file
+  - @returns Nothing or an error
+*/
+func recordSum(dir, name, sum string) error {
+	sumsMutex.Lock()
+	defer sumsMutex.Unlock()
+
+	if recordedSum(dir, name) == sum {
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, sumsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s  %s\n", sum, name)
+	return err
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/download"
+)
+
+const content = "#!/bin/sh\necho k3s\n"
+
+// Serve the content, and a published sum, counting the downloads
+func serve(t *testing.T, downloads *int) *httptest.Server {
+	sum := sha256.Sum256([]byte(content))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".sha256") {
+			_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  install.sh\n"))
+			return
+		}
+		*downloads++
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestGetCached(t *testing.T) {
+	t.Setenv("E2E_CACHE_DIR", t.TempDir())
+	downloads := 0
+	srv := serve(t, &downloads)
+
+	a := download.Artifact{Name: "install.sh", URL: srv.URL + "/install.sh", SumURL: srv.URL + "/install.sh.sha256"}
+	for range 2 {
+		file, err := download.Get(context.Background(), a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(file); string(data) != content {
+			t.Errorf("unexpected content %q", data)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("%d downloads, the second one must come from the cache", downloads)
+	}
+
+	// Sum is recorded for the offline runs
+	sums, _ := os.ReadFile(filepath.Join(download.Dir(), "SHA256SUMS"))
+	if !strings.HasSuffix(string(sums), "  install.sh\n") {
+		t.Errorf("sum not recorded: %q", sums)
+	}
+}
+
+func TestGetBadSum(t *testing.T) {
+	t.Setenv("E2E_CACHE_DIR", t.TempDir())
+	downloads := 0
+	srv := serve(t, &downloads)
+
+	a := download.Artifact{Name: "install.sh", URL: srv.URL + "/install.sh", SHA256: strings.Repeat("0", 64)}
+	if _, err := download.Get(context.Background(), a); err == nil || !strings.Contains(err.Error(), "expected") {
+		t.Errorf("bad sum not detected: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(download.Dir(), a.Name)); err == nil {
+		t.Error("file with a bad sum kept in the cache")
+	}
+}
+
+func TestGetOffline(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("E2E_CACHE_DIR", dir)
+	t.Setenv("E2E_CACHE_OFFLINE", "true")
+
+	// Nothing listens on this URL
+	a := download.Artifact{Name: "install.sh", URL: "http://127.0.0.1:1/install.sh"}
+	if _, err := download.Get(context.Background(), a); err == nil || !strings.Contains(err.Error(), "not in the cache") {
+		t.Errorf("missing file not detected: %v", err)
+	}
+
+	// Pre-seeded cache, with a file modified after its sum was recorded
+	if err := os.WriteFile(filepath.Join(dir, a.Name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := download.Get(context.Background(), a); err != nil {
+		t.Errorf("pre-seeded file not used: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(strings.Repeat("0", 64)+"  install.sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := download.Get(context.Background(), a); err == nil {
+		t.Error("modified file of the cache used")
+	}
+}
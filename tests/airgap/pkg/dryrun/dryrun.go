@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// Environment variable used by the shims to find the plan file
+const planEnv = "E2E_DRY_RUN_PLAN"
+
+// Binaries replaced by a shim, helpers of ele-testhelpers call them through PATH
+var shims = []string{"kubectl", "helm"}
+
+// Records the command line and the manifests/values files given to it,
+// JSON output is expected to be a list by all callers
+const shimScript = `#!/bin/bash
+{
+  printf '%%s' %s
+  printf ' %%q' "$@"
+  echo
+  prev=""
+  for arg in "$@"; do
+    if [[ $prev == -f || $prev == --filename || $prev == --values ]] && [[ -f $arg ]]; then
+      echo "--- $arg"
+      cat "$arg"
+      echo
+    fi
+    prev=$arg
+  done
+} >> "$` + planEnv + `"
+
+for arg in "$@"; do
+  case $arg in
+    json|--output=json|-ojson) echo '[]'; break ;;
+  esac
+done
+exit 0
+`
+
+/*
+Check if the dry-run mode is enabled
+  - @remarks Set with E2E_DRY_RUN=true
+  - @returns True if enabled
+*/
+func Enabled() bool {
+	return os.Getenv("E2E_DRY_RUN") == "true"
+}
+
+/*
+Replace kubectl and helm by shims recording the planned operations
+  - @param dir Directory where the shims and the plan file are created
+  - @returns Nothing or an error
+*/
+func Setup(dir string) error {
+	bin := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		return err
+	}
+
+	for _, name := range shims {
+		script := fmt.Sprintf(shimScript, name)
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Setenv(planEnv, filepath.Join(dir, "plan.log")); err != nil {
+		return err
+	}
+
+	return os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+/*
+Record a planned operation
+  - @param format Format of the message, as in fmt.Printf
+  - @param args Arguments of the format
+  - @returns Nothing
+*/
+func Record(format string, args ...any) {
+	msg := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	GinkgoWriter.Printf("[dry-run] %s\n", msg)
+
+	plan := os.Getenv(planEnv)
+	if plan == "" {
+		return
+	}
+
+	f, err := os.OpenFile(plan, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		GinkgoWriter.Printf("Cannot write dry-run plan: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, msg)
+}
+
+/*
+Get the operations planned since the last call
+  - @returns The planned operations, empty if none
+*/
+func Flush() string {
+	plan := os.Getenv(planEnv)
+	if plan == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(plan)
+	if err != nil {
+		return ""
+	}
+	_ = os.Truncate(plan, 0)
+
+	return string(data)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+)
+
+// Event is a Kubernetes event, only what is checked
+type Event struct {
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message"`
+	Count          int    `json:"count"`
+	InvolvedObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+		UID  string `json:"uid"`
+	} `json:"involvedObject"`
+}
+
+/*
+Get the events of a resource
+  - @param kind Kind of the resource (e.g. ReplicaSet), empty for all kinds
+  - @param name Name of the resource, empty for all resources of the kind
+  - @param ns Namespace of the events
+  - @returns Events, oldest first, or an error
+*/
+func For(kind, name, ns string) ([]Event, error) {
+	args := []string{"get", "events", "--namespace", ns, "--sort-by", ".lastTimestamp", "-o", "json"}
+
+	var selectors []string
+	if kind != "" {
+		selectors = append(selectors, "involvedObject.kind="+kind)
+	}
+	if name != "" {
+		selectors = append(selectors, "involvedObject.name="+name)
+	}
+	if len(selectors) > 0 {
+		args = append(args, "--field-selector", strings.Join(selectors, ","))
+	}
+
+	out, err := cluster.Default.KubectlWithoutErr(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []Event `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse events: %w", err)
+	}
+
+	return list.Items, nil
+}
+
+/*
+Keep the events of one instance of a resource
+  - @remarks Events of a deleted resource are kept by the API server, a new resource with the same name gets them too
+  - @param events Events to filter
+  - @param uid UID of the resource
+  - @returns Events involving the resource with this UID
+*/
+func Involving(events []Event, uid string) []Event {
+	var kept []Event
+	for _, e := range events {
+		if e.InvolvedObject.UID == uid {
+			kept = append(kept, e)
+		}
+	}
+
+	return kept
+}
+
+/*
+Get an event in a readable format
+  - @returns Kind/name, reason and message of the event
+*/
+func (e Event) String() string {
+	return fmt.Sprintf("%s/%s %s (%s, x%d): %s",
+		e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, e.Type, max(e.Count, 1), e.Message)
+}
+
+/*
+Get a list of events in a readable format
+  - @param events Events to format
+  - @returns One event per line
+*/
+func Format(events []Event) string {
+	var b strings.Builder
+	for _, e := range events {
+		b.WriteString(e.String() + "\n")
+	}
+
+	return b.String()
+}
+
+/*
+Match a list of events containing a reason
+  - @param reason Reason of the event, e.g. FailedCreate
+  - @returns Gomega matcher of []Event
+*/
+func HaveEvent(reason string) types.GomegaMatcher {
+	return HaveEventWithMessage(reason, "")
+}
+
+/*
+Match a list of events containing a reason with a message
+  - @param reason Reason of the event, e.g. FailedCreate
+  - @param message Substring of the message, empty for any message
+  - @returns Gomega matcher of []Event
+*/
+func HaveEventWithMessage(reason, message string) types.GomegaMatcher {
+	expected := "to have a " + reason + " event"
+	if message != "" {
+		expected += " with message containing " + message
+	}
+
+	return gcustom.MakeMatcher(func(events []Event) (bool, error) {
+		for _, e := range events {
+			if e.Reason == reason && strings.Contains(e.Message, message) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}).WithTemplate("Expected events:\n{{format .Actual 1}}\n{{.To}} " + expected)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/events"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+// Events of a deployment deleted then created again with the same name
+const list = `{"items": [
+  {"type": "Normal", "reason": "ScalingReplicaSet", "message": "Scaled up replica set policy-server-default-5d8f to 1", "count": 1,
+   "involvedObject": {"kind": "Deployment", "name": "policy-server-default", "uid": "old"}},
+  {"type": "Warning", "reason": "FailedCreate", "message": "admission webhook denied the request", "count": 3,
+   "involvedObject": {"kind": "Deployment", "name": "policy-server-default", "uid": "new"}}
+]}`
+
+var selected = []string{"kubectl", "get", "events", "--namespace", "kubewarden", "--sort-by", ".lastTimestamp", "-o", "json",
+	"--field-selector", "involvedObject.kind=Deployment,involvedObject.name=policy-server-default"}
+
+func setup(t *testing.T, args []string, stdout, stderr string, code int) {
+	t.Helper()
+
+	replay.ForTest(t, replay.Output{Args: args, Stdout: stdout, Stderr: stderr, Code: code})
+}
+
+func TestFor(t *testing.T) {
+	setup(t, selected, list, "", 0)
+
+	got, err := events.For("Deployment", "policy-server-default", "kubewarden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Reason != "ScalingReplicaSet" || got[1].InvolvedObject.UID != "new" {
+		t.Errorf("unexpected events %v", got)
+	}
+}
+
+func TestForWithoutSelector(t *testing.T) {
+	setup(t, []string{"kubectl", "get", "events", "--namespace", "kubewarden", "--sort-by", ".lastTimestamp", "-o", "json"}, `{"items": []}`, "", 0)
+
+	got, err := events.For("", "", "kubewarden")
+	if err != nil || len(got) != 0 {
+		t.Errorf("events are %v (%v), none expected", got, err)
+	}
+}
+
+func TestForErrors(t *testing.T) {
+	tests := []struct {
+		name, stdout, stderr string
+		code                 int
+	}{
+		{"kubectl failure", "", "the server could not find the requested resource", 1},
+		{"invalid output", "No resources found", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t, selected, tt.stdout, tt.stderr, tt.code)
+
+			if got, err := events.For("Deployment", "policy-server-default", "kubewarden"); err == nil {
+				t.Errorf("no error, events are %v", got)
+			}
+		})
+	}
+}
+
+func TestInvolving(t *testing.T) {
+	setup(t, selected, list, "", 0)
+	all, err := events.For("Deployment", "policy-server-default", "kubewarden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		uid     string
+		reasons []string
+	}{
+		{"old", []string{"ScalingReplicaSet"}},
+		{"new", []string{"FailedCreate"}},
+		{"deleted", nil},
+	}
+
+	for _, tt := range tests {
+		var reasons []string
+		for _, e := range events.Involving(all, tt.uid) {
+			reasons = append(reasons, e.Reason)
+		}
+		if strings.Join(reasons, ",") != strings.Join(tt.reasons, ",") {
+			t.Errorf("events of %s are %v, %v expected", tt.uid, reasons, tt.reasons)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	setup(t, selected, list, "", 0)
+	all, err := events.For("Deployment", "policy-server-default", "kubewarden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Deployment/policy-server-default ScalingReplicaSet (Normal, x1): Scaled up replica set policy-server-default-5d8f to 1\n" +
+		"Deployment/policy-server-default FailedCreate (Warning, x3): admission webhook denied the request\n"
+	if got := events.Format(all); got != want {
+		t.Errorf("formatted events are:\n%s", got)
+	}
+}
+
+func TestHaveEvent(t *testing.T) {
+	setup(t, selected, list, "", 0)
+	all, err := events.For("Deployment", "policy-server-default", "kubewarden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		matcher types.GomegaMatcher
+		match   bool
+	}{
+		{"reason", events.HaveEvent("FailedCreate"), true},
+		{"missing reason", events.HaveEvent("BackOff"), false},
+		{"reason and message", events.HaveEventWithMessage("FailedCreate", "webhook denied"), true},
+		{"message of another event", events.HaveEventWithMessage("FailedCreate", "Scaled up"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok, err := tt.matcher.Match(all); err != nil || ok != tt.match {
+				t.Errorf("match is %v (%v), %v expected", ok, err, tt.match)
+			}
+		})
+	}
+
+	g := gomega.NewWithT(t)
+	g.Expect(events.Involving(all, "new")).NotTo(events.HaveEvent("ScalingReplicaSet"))
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/tls"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Cipher suites approved by FIPS 140-3 (NIST SP 800-52r2), TLS 1.3 ones included
+var ApprovedCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_AES_128_GCM_SHA256,
+	tls.TLS_AES_256_GCM_SHA384,
+}
+
+// Cipher suites that a FIPS server has to refuse
+var RejectedCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+/*
+Check if FIPS mode is enabled on a host
+  - @param r Runner of the host
+  - @returns True if the kernel runs in FIPS mode
+*/
+func Enabled(r *runner.Runner) bool {
+	out, err := r.Run("cat", "/proc/sys/crypto/fips_enabled")
+	return err == nil && strings.TrimSpace(out) == "1"
+}
+
+/*
+Check if a cipher suite is approved
+  - @param suite ID of the cipher suite
+  - @returns True if the cipher suite is in ApprovedCipherSuites
+*/
+func Approved(suite uint16) bool {
+	return slices.Contains(ApprovedCipherSuites, suite)
+}
+
+/*
+Do a TLS handshake with a restricted set of cipher suites
+  - @remarks The certificate is not verified, only the negotiated parameters are checked
+  - @param addr Address of the server in host:port format
+  - @param suites TLS 1.2 cipher suites offered, all of Go defaults if empty
+  - @param maxVersion Maximal TLS version, e.g. tls.VersionTLS12 to force one of the suites
+  - @returns State of the connection or an error, e.g. if no cipher suite is accepted
+*/
+func Handshake(addr string, suites []uint16, maxVersion uint16) (*tls.ConnectionState, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+		// Certificates are self-signed by the controller
+		InsecureSkipVerify: true,
+		CipherSuites:       suites,
+		MaxVersion:         maxVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	return &state, nil
+}
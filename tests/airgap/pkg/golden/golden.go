@@ -0,0 +1,225 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"gopkg.in/yaml.v3"
+)
+
+// Object is a Kubernetes resource, as returned by kubectl
+type Object map[string]any
+
+// Fields set by the API server or changed by every rollout, as paths of map keys
+var volatileFields = [][]string{
+	{"status"},
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "managedFields"},
+	{"metadata", "selfLink"},
+	{"metadata", "annotations", "deployment.kubernetes.io/revision"},
+	{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+	{"spec", "clusterIP"},
+	{"spec", "clusterIPs"},
+	{"spec", "template", "metadata", "annotations", "kubectl.kubernetes.io/restartedAt"},
+	{"spec", "template", "metadata", "annotations", "kubewarden/config-version"},
+}
+
+/*
+Get the objects of a kind
+  - @param kind Kind of the objects
+  - @param ns Namespace of the objects, empty for cluster-wide ones
+  - @param selector Label selector, empty for all objects
+  - @returns Objects or an error
+*/
+func Get(kind, ns, selector string) ([]Object, error) {
+	args := []string{"get", kind, "-o", "json"}
+	if ns != "" {
+		args = append(args, "--namespace", ns)
+	}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+
+	out, err := cluster.Default.KubectlWithoutErr(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []Object `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", kind, err)
+	}
+
+	return list.Items, nil
+}
+
+/*
+Get the name of an object
+  - @returns metadata.name, empty if not set
+*/
+func (o Object) Name() string {
+	name, _ := lookup(o, "metadata", "name").(string)
+	return name
+}
+
+/*
+Remove what changes from one run to another
+  - @remarks Owner UIDs and webhook CA bundles are removed too, versions and options are kept on purpose
+  - @returns The normalized object, as YAML with sorted keys
+*/
+func (o Object) Normalize() ([]byte, error) {
+	for _, field := range volatileFields {
+		remove(o, field)
+	}
+
+	if owners, ok := lookup(o, "metadata", "ownerReferences").([]any); ok {
+		for _, owner := range owners {
+			if m, ok := owner.(map[string]any); ok {
+				delete(m, "uid")
+			}
+		}
+	}
+
+	if webhooks, ok := o["webhooks"].([]any); ok {
+		for _, webhook := range webhooks {
+			if m, ok := webhook.(map[string]any); ok {
+				remove(m, []string{"clientConfig", "caBundle"})
+			}
+		}
+	}
+
+	return yaml.Marshal(map[string]any(o))
+}
+
+/*
+Compare a normalized object with its golden file
+  - @remarks A missing golden file is an error, a new resource has to be reviewed like a changed one
+  - @param file Golden file
+  - @param data Normalized object
+  - @param update Write the golden file instead of comparing
+  - @returns Differences, empty if none, or an error
+*/
+func Compare(file string, data []byte, update bool) (string, error) {
+	if update {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return "", err
+		}
+
+		return "", os.WriteFile(file, data, 0644)
+	}
+
+	want, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no golden file %s", file)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return Diff(string(want), string(data)), nil
+}
+
+/*
+Get the line differences between two texts
+  - @remarks Based on the longest common subsequence, good enough for files of a few hundred lines
+  - @param want Expected text
+  - @param got Actual text
+  - @returns Removed lines prefixed with '-' and added lines with '+', empty if identical
+*/
+func Diff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&diff, "+%s\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&diff, "-%s\n", a[i])
+			i++
+		}
+	}
+
+	return diff.String()
+}
+
+/*
+Get a nested value of an object
+  - @remarks This function is only used internally, not exported
+  - @returns The value, nil if not found
+*/
+func lookup(m map[string]any, keys ...string) any {
+	var v any = m
+	for _, key := range keys {
+		next, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = next[key]
+	}
+
+	return v
+}
+
+/*
+Remove a nested value of an object
+  - @remarks This function is only used internally, not exported
+  - @returns Nothing
+*/
+func remove(m map[string]any, field []string) {
+	parent, ok := lookup(m, field[:len(field)-1]...).(map[string]any)
+	if !ok {
+		return
+	}
+
+	delete(parent, field[len(field)-1])
+	// Empty annotations are not kept, they could be set or not
+	if len(field) > 1 && len(parent) == 0 && field[len(field)-2] == "annotations" {
+		grandparent, _ := lookup(m, field[:len(field)-2]...).(map[string]any)
+		delete(grandparent, "annotations")
+	}
+}
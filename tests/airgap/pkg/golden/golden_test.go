@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golden_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/golden"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+)
+
+// Deployment as returned by kubectl, with the fields changed by every rollout
+const deployment = `{"items": [{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {
+    "name": "kubewarden-controller",
+    "namespace": "kubewarden",
+    "uid": "0b6e3c1a",
+    "resourceVersion": "1234",
+    "generation": 3,
+    "creationTimestamp": "2025-01-01T00:00:00Z",
+    "annotations": {"deployment.kubernetes.io/revision": "3"},
+    "ownerReferences": [{"kind": "ReplicaSet", "name": "owner", "uid": "9f8e7d6c"}]
+  },
+  "spec": {
+    "replicas": 1,
+    "template": {
+      "metadata": {"annotations": {"kubectl.kubernetes.io/restartedAt": "2025-01-01T00:00:00Z", "kept": "true"}},
+      "spec": {"containers": [{"name": "manager", "image": "ghcr.io/kubewarden/kubewarden-controller:v1.20.0"}]}
+    }
+  },
+  "status": {"readyReplicas": 1}
+}]}`
+
+const normalized = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: kubewarden-controller
+    namespace: kubewarden
+    ownerReferences:
+        - kind: ReplicaSet
+          name: owner
+spec:
+    replicas: 1
+    template:
+        metadata:
+            annotations:
+                kept: "true"
+        spec:
+            containers:
+                - image: ghcr.io/kubewarden/kubewarden-controller:v1.20.0
+                  name: manager
+`
+
+func TestNormalize(t *testing.T) {
+	replay.ForTest(t, replay.Output{Args: []string{"kubectl", "get", "deployments", "-o", "json", "--namespace", "kubewarden"}, Stdout: deployment})
+
+	objects, err := golden.Get("deployments", "kubewarden", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Name() != "kubewarden-controller" {
+		t.Fatalf("unexpected objects %v", objects)
+	}
+
+	data, err := objects[0].Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := golden.Diff(normalized, string(data)); diff != "" {
+		t.Errorf("unexpected normalized object:\n%s", diff)
+	}
+}
+
+func TestNormalizeWebhooks(t *testing.T) {
+	o := golden.Object{
+		"metadata": map[string]any{
+			"name":        "clusterwide-do-not-run-as-root",
+			"annotations": map[string]any{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"webhooks": []any{
+			map[string]any{"name": "clusterwide-do-not-run-as-root.kubewarden.admission",
+				"clientConfig": map[string]any{"caBundle": "LS0tLS1", "service": map[string]any{"name": "policy-server-default"}}},
+		},
+	}
+
+	data, err := o.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Emptied annotations are removed, the service is kept
+	got := string(data)
+	for _, removed := range []string{"caBundle", "annotations"} {
+		if strings.Contains(got, removed) {
+			t.Errorf("%s is not removed:\n%s", removed, got)
+		}
+	}
+	if !strings.Contains(got, "name: policy-server-default") {
+		t.Errorf("service is removed:\n%s", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name, want, got, diff string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", ""},
+		{"final newline", "a\nb", "a\nb\n", ""},
+		{"added", "a\nc\n", "a\nb\nc\n", "+b\n"},
+		{"removed", "a\nb\nc\n", "a\nc\n", "-b\n"},
+		{"changed", "image: v1\nreplicas: 1\n", "image: v2\nreplicas: 1\n", "+image: v2\n-image: v1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := golden.Diff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("got %q, %q expected", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deployments", "kubewarden-controller.yaml")
+
+	// A missing golden file is not silently written
+	if _, err := golden.Compare(file, []byte(normalized), false); err == nil || !strings.Contains(err.Error(), "no golden file") {
+		t.Fatalf("unexpected error %v for a missing golden file", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("golden file is written without update: %v", err)
+	}
+
+	if _, err := golden.Compare(file, []byte(normalized), true); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := golden.Compare(file, []byte(normalized), false)
+	if err != nil || diff != "" {
+		t.Errorf("unexpected diff %q or error %v with the written golden file", diff, err)
+	}
+
+	diff, err = golden.Compare(file, []byte(strings.Replace(normalized, "replicas: 1", "replicas: 2", 1)), false)
+	if err != nil || diff != "+    replicas: 2\n-    replicas: 1\n" {
+		t.Errorf("unexpected diff %q or error %v with a changed object", diff, err)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guard
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// File marking a node as disposable, written by the infra modules and on the VMs created by the tests
+const MarkerFile = "/etc/kubewarden-e2e-node"
+
+// Variables approving the destructive steps without asking, CI is set by most CI systems
+var approvalEnv = []string{"E2E_YES", "CI"}
+
+// Hostnames of disposable nodes if E2E_DISPOSABLE_HOSTNAME is not set, the default name of the infra modules
+const defaultHostnamePattern = `^kubewarden-e2e`
+
+/*
+Check if the destructive steps are approved by the environment
+  - @remarks Set with E2E_YES=true (-yes of cmd/e2e) or CI=true
+  - @returns Name of the approving variable, empty if not approved
+*/
+func Approved() string {
+	for _, env := range approvalEnv {
+		if os.Getenv(env) == "true" {
+			return env
+		}
+	}
+	return ""
+}
+
+/*
+Check if a hostname is the one of a disposable node
+  - @remarks The pattern is a regular expression set with E2E_DISPOSABLE_HOSTNAME, an invalid one matches nothing
+  - @param hostname Hostname of the node
+  - @returns True if the hostname matches the pattern
+*/
+func DisposableHostname(hostname string) bool {
+	re, err := regexp.Compile(cmp.Or(os.Getenv("E2E_DISPOSABLE_HOSTNAME"), defaultHostnamePattern))
+	return err == nil && hostname != "" && re.MatchString(hostname)
+}
+
+/*
+Check if a node is a disposable test node
+  - @remarks Flagged with E2E_DISPOSABLE_NODE=true, the marker file or its hostname
+  - @param node Runner of the node
+  - @returns Why the node is disposable, empty if it is not
+*/
+func Disposable(node *runner.Runner) string {
+	if os.Getenv("E2E_DISPOSABLE_NODE") == "true" {
+		return "E2E_DISPOSABLE_NODE"
+	}
+	if _, err := node.Run("test", "-f", MarkerFile); err == nil {
+		return MarkerFile
+	}
+	if out, err := node.Run("hostname"); err == nil && DisposableHostname(strings.TrimSpace(out)) {
+		return "hostname " + strings.TrimSpace(out)
+	}
+	return ""
+}
+
+/*
+Mark a node as disposable
+  - @remarks Only for the nodes created by the tests, e.g. VMs of the downstream clusters
+  - @param node Runner of the node
+  - @returns Nothing or an error
+*/
+func Mark(node *runner.Runner) error {
+	_, err := node.WithSudo().Run("touch", MarkerFile)
+	return err
+}
+
+/*
+Make sure a destructive step can be done on a node
+  - @remarks Allowed if approved by the environment or if the node is disposable, asked on the terminal otherwise
+  - @param node Runner of the node
+  - @param action Description of the step, e.g. uninstall K3s
+  - @returns Nothing, or an error if the step is not confirmed
+*/
+func Confirm(node *runner.Runner, action string) error {
+	host := cmp.Or(node.Host(), "localhost")
+
+	// Nothing is executed anyway
+	if dryrun.Enabled() {
+		dryrun.Record("confirm %s on %s", action, host)
+		return nil
+	}
+
+	if env := Approved(); env != "" {
+		GinkgoWriter.Printf("%s on %s approved by %s\n", action, host, env)
+		return nil
+	}
+	if reason := Disposable(node); reason != "" {
+		GinkgoWriter.Printf("%s on %s, disposable node by %s\n", action, host, reason)
+		return nil
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("%s on %s is not confirmed: set E2E_YES=true, run from a terminal or create %s on a disposable node", action, host, MarkerFile)
+	}
+	defer tty.Close()
+
+	return Ask(tty, tty, action, host)
+}
+
+/*
+Make sure a node is disposable before a step that could destroy it
+  - @remarks Unlike Confirm, neither an approval nor an answer is enough, e.g. for the installation of K3s
+  - @param node Runner of the node
+  - @param action Description of the step, e.g. uninstall K3s
+  - @returns Nothing, or an error if the node is not flagged as disposable
+*/
+func Require(node *runner.Runner, action string) error {
+	host := cmp.Or(node.Host(), "localhost")
+
+	if dryrun.Enabled() {
+		dryrun.Record("require a disposable node to %s on %s", action, host)
+		return nil
+	}
+
+	reason := Disposable(node)
+	if reason == "" {
+		return fmt.Errorf("refusing to %s on %s, which is not flagged as a disposable test node: "+
+			"create %s, set E2E_DISPOSABLE_NODE=true or use a hostname matching %s",
+			action, host, MarkerFile, cmp.Or(os.Getenv("E2E_DISPOSABLE_HOSTNAME"), defaultHostnamePattern))
+	}
+	GinkgoWriter.Printf("%s on %s, disposable node by %s\n", action, host, reason)
+
+	return nil
+}
+
+/*
+Ask for the confirmation of a step
+  - @param in Answer of the user
+  - @param out Where the question is written
+  - @param action Description of the step
+  - @param host Host of the step
+  - @returns Nothing, or an error if the answer is not yes
+*/
+func Ask(in io.Reader, out io.Writer, action, host string) error {
+	fmt.Fprintf(out, "\n*** About to %s on %s, which is not a disposable test node (%s) ***\n"+
+		"  Continue? [y/N] ", action, host, MarkerFile)
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("%s on %s refused", action, host)
+	}
+}
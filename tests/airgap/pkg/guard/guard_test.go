@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guard_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/guard"
+)
+
+func TestApproved(t *testing.T) {
+	t.Setenv("E2E_YES", "")
+	t.Setenv("CI", "")
+	if env := guard.Approved(); env != "" {
+		t.Errorf("approved by %s without any variable", env)
+	}
+
+	t.Setenv("CI", "true")
+	if env := guard.Approved(); env != "CI" {
+		t.Errorf("approved by %q, CI expected", env)
+	}
+
+	t.Setenv("E2E_YES", "true")
+	if env := guard.Approved(); env != "E2E_YES" {
+		t.Errorf("approved by %q, E2E_YES expected", env)
+	}
+}
+
+func TestDisposableHostname(t *testing.T) {
+	t.Setenv("E2E_DISPOSABLE_HOSTNAME", "")
+	for hostname, ok := range map[string]bool{
+		"kubewarden-e2e":       true,
+		"kubewarden-e2e-nodes": true,
+		"my-laptop":            false,
+		"":                     false,
+	} {
+		if guard.DisposableHostname(hostname) != ok {
+			t.Errorf("hostname %q: %t expected", hostname, ok)
+		}
+	}
+
+	t.Setenv("E2E_DISPOSABLE_HOSTNAME", `^ci-runner-\d+$`)
+	if !guard.DisposableHostname("ci-runner-12") || guard.DisposableHostname("kubewarden-e2e") {
+		t.Errorf("pattern of E2E_DISPOSABLE_HOSTNAME not used")
+	}
+
+	// Nothing is disposable with a broken pattern
+	t.Setenv("E2E_DISPOSABLE_HOSTNAME", `(`)
+	if guard.DisposableHostname("kubewarden-e2e") {
+		t.Errorf("invalid pattern matched")
+	}
+}
+
+func TestAsk(t *testing.T) {
+	for answer, ok := range map[string]bool{
+		"y\n":   true,
+		"YES\n": true,
+		"n\n":   false,
+		"\n":    false,
+		"":      false,
+	} {
+		var out bytes.Buffer
+		err := guard.Ask(strings.NewReader(answer), &out, "uninstall K3s", "10.0.0.1")
+		if (err == nil) != ok {
+			t.Errorf("answer %q: unexpected result %v", answer, err)
+		}
+		if !strings.Contains(out.String(), "uninstall K3s on 10.0.0.1") {
+			t.Errorf("unexpected question %q", out.String())
+		}
+	}
+}
@@ -0,0 +1,320 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harbor
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client of the Harbor v2.0 API, authenticated as a Harbor user
+type Client struct {
+	// URL of Harbor, e.g. https://rancher-manager.test:30003
+	URL      string
+	User     string
+	Password string
+
+	http *http.Client
+}
+
+// Robot is a robot account, its secret is only known at creation
+type Robot struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+/*
+Create a Harbor client
+  - @param harborURL URL of Harbor
+  - @param user Harbor user, usually admin
+  - @param password Password of the user
+  - @param ca PEM certificate of the CA signing the Harbor certificate
+  - @returns The client or an error
+*/
+func New(harborURL, user, password string, ca []byte) (*Client, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no valid certificate in the Harbor CA")
+	}
+
+	return &Client{
+		URL:      harborURL,
+		User:     user,
+		Password: password,
+		http: &http.Client{
+			Timeout:   time.Minute,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+/*
+Create a private project
+  - @remarks Nothing is done if the project already exists
+  - @param name Name of the project
+  - @returns Nothing or an error
+*/
+func (c *Client) CreateProject(name string) error {
+	err := c.do(http.MethodPost, "projects", map[string]any{"project_name": name, "public": false}, nil)
+	if isConflict(err) {
+		return nil
+	}
+
+	return err
+}
+
+/*
+Create a robot account allowed to pull from a project
+  - @param project Name of the project
+  - @param name Name of the robot account, prefixed by Harbor
+  - @returns The robot account with its secret, or an error
+*/
+func (c *Client) CreateRobot(project, name string) (*Robot, error) {
+	// Secret cannot be read again, so the robot is recreated
+	var robots []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	query := url.Values{"q": {"name=~" + name}}
+	if err := c.do(http.MethodGet, "robots?"+query.Encode(), nil, &robots); err != nil {
+		return nil, err
+	}
+	for _, r := range robots {
+		// Project robots are named robot$<project>+<name>
+		if !strings.HasSuffix(r.Name, project+"+"+name) {
+			continue
+		}
+		if err := c.do(http.MethodDelete, "robots/"+strconv.Itoa(r.ID), nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	robot := &Robot{}
+	err := c.do(http.MethodPost, "robots", map[string]any{
+		"name":     name,
+		"level":    "project",
+		"duration": -1,
+		"permissions": []map[string]any{{
+			"kind":      "project",
+			"namespace": project,
+			"access": []map[string]string{
+				{"resource": "repository", "action": "pull"},
+				{"resource": "artifact", "action": "read"},
+			},
+		}},
+	}, robot)
+
+	return robot, err
+}
+
+/*
+Replicate the repositories of another registry in a project
+  - @remarks Paths are kept, the first level is replaced by the project
+  - @param project Destination project
+  - @param registryURL URL of the source registry, TLS is not verified
+  - @param filter Repositories to replicate, e.g. kubewarden/**
+  - @param timeout Maximum duration of the replication
+  - @returns Nothing or an error
+*/
+func (c *Client) Replicate(project, registryURL, filter string, timeout time.Duration) error {
+	name := project + "-source"
+
+	err := c.do(http.MethodPost, "registries", map[string]any{
+		"name":     name,
+		"type":     "docker-registry",
+		"url":      registryURL,
+		"insecure": true,
+	}, nil)
+	if err != nil && !isConflict(err) {
+		return err
+	}
+
+	registryID, err := c.id("registries", name)
+	if err != nil {
+		return err
+	}
+
+	err = c.do(http.MethodPost, "replication/policies", map[string]any{
+		"name":                         name,
+		"src_registry":                 map[string]int{"id": registryID},
+		"dest_namespace":               project,
+		"dest_namespace_replace_count": 1,
+		"filters":                      []map[string]string{{"type": "name", "value": filter}},
+		"trigger":                      map[string]string{"type": "manual"},
+		"override":                     true,
+		"enabled":                      true,
+	}, nil)
+	if err != nil && !isConflict(err) {
+		return err
+	}
+
+	policyID, err := c.id("replication/policies", name)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(http.MethodPost, "replication/executions", map[string]int{"policy_id": policyID}, nil); err != nil {
+		return err
+	}
+
+	// Only the last execution is relevant
+	deadline := time.Now().Add(timeout)
+	for {
+		var executions []struct {
+			Status     string `json:"status"`
+			StatusText string `json:"status_text"`
+		}
+		query := url.Values{"policy_id": {strconv.Itoa(policyID)}, "sort": {"-start_time"}, "page_size": {"1"}}
+		if err := c.do(http.MethodGet, "replication/executions?"+query.Encode(), nil, &executions); err != nil {
+			return err
+		}
+
+		if len(executions) > 0 {
+			switch executions[0].Status {
+			case "Succeed":
+				return nil
+			case "Failed", "Stopped":
+				return fmt.Errorf("replication from %s %s: %s", registryURL, executions[0].Status, executions[0].StatusText)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("replication from %s not done after %s", registryURL, timeout)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+/*
+List the repositories of a project
+  - @param project Name of the project
+  - @returns Repository paths, including the project, or an error
+*/
+func (c *Client) Repositories(project string) ([]string, error) {
+	var names []string
+	for page := 1; ; page++ {
+		var repos []struct {
+			Name string `json:"name"`
+		}
+		query := url.Values{"page": {strconv.Itoa(page)}, "page_size": {"100"}}
+		if err := c.do(http.MethodGet, path.Join("projects", project, "repositories")+"?"+query.Encode(), nil, &repos); err != nil {
+			return nil, err
+		}
+
+		for _, r := range repos {
+			names = append(names, r.Name)
+		}
+		if len(repos) < 100 {
+			return names, nil
+		}
+	}
+}
+
+// Error returned by the Harbor API
+type apiError struct {
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("harbor API returned %d: %s", e.Status, e.Body)
+}
+
+/*
+Check if a resource already exists
+  - @remarks This function is only used internally, not exported
+  - @returns True if the error is a conflict
+*/
+func isConflict(err error) bool {
+	e, ok := err.(*apiError)
+	return ok && e.Status == http.StatusConflict
+}
+
+/*
+Get the ID of a named resource
+  - @remarks This function is only used internally, not exported
+  - @returns ID of the resource or an error
+*/
+func (c *Client) id(resource, name string) (int, error) {
+	var items []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	query := url.Values{"q": {"name=" + name}}
+	if err := c.do(http.MethodGet, resource+"?"+query.Encode(), nil, &items); err != nil {
+		return 0, err
+	}
+
+	for _, item := range items {
+		if item.Name == name {
+			return item.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s %s not found", resource, name)
+}
+
+/*
+Call the Harbor API
+  - @remarks This function is only used internally, not exported
+  - @returns Nothing or an error, the response is decoded in out if not nil
+*/
+func (c *Client) do(method, endpoint string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.URL+"/api/v2.0/"+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.User, c.Password)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return &apiError{Status: resp.StatusCode, Body: string(data)}
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagelist
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Get the images referenced by rendered manifests
+  - @remarks Values of image keys (containers, PolicyServer) and module keys (policies) are kept, in any document
+  - @param manifests Output of helm template, several YAML documents
+  - @returns Sorted list of references, without duplicates, or an error
+*/
+func FromManifests(manifests string) ([]string, error) {
+	var refs []string
+
+	dec := yaml.NewDecoder(strings.NewReader(manifests))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the manifests: %w", err)
+		}
+
+		refs = append(refs, find(&doc)...)
+	}
+
+	slices.Sort(refs)
+	return slices.Compact(refs), nil
+}
+
+/*
+Find the image and module values of a YAML node
+  - @remarks This function is only used internally, not exported
+  - @param n YAML node
+  - @returns References of the node and its children
+*/
+func find(n *yaml.Node) []string {
+	var refs []string
+
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if (key.Value == "image" || key.Value == "module") && value.Kind == yaml.ScalarNode && value.Value != "" {
+				refs = append(refs, value.Value)
+			}
+		}
+	}
+
+	for _, child := range n.Content {
+		refs = append(refs, find(child)...)
+	}
+
+	return refs
+}
+
+/*
+Read a list of images, one per line
+  - @remarks Format of the imagelist.txt and policylist.txt files of the charts, empty lines and comments are ignored
+  - @param file Path of the list
+  - @returns References of the list or an error
+*/
+func Read(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var refs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+
+	return refs, scanner.Err()
+}
+
+/*
+Normalize an image reference, so equivalent references can be compared
+  - @remarks registry:// of policies, docker.io and library/ are removed, latest is the default tag
+  - @param ref Image or policy reference
+  - @returns Normalized reference
+*/
+func Normalize(ref string) string {
+	ref = strings.TrimPrefix(ref, "registry://")
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+
+	// Digests are kept as is, a tag is only added if there is none
+	name, _, hasDigest := strings.Cut(ref, "@")
+	if !hasDigest && strings.LastIndex(name, ":") <= strings.LastIndex(name, "/") {
+		ref += ":latest"
+	}
+
+	return ref
+}
+
+/*
+Get the references not covered by a mirror list
+  - @param refs References used by the charts
+  - @param mirrored References of the mirror manifest
+  - @returns References of refs missing in mirrored, empty if all are covered
+*/
+func Missing(refs, mirrored []string) []string {
+	covered := map[string]bool{}
+	for _, ref := range mirrored {
+		covered[Normalize(ref)] = true
+	}
+
+	var missing []string
+	for _, ref := range refs {
+		if !covered[Normalize(ref)] {
+			missing = append(missing, ref)
+		}
+	}
+
+	return missing
+}
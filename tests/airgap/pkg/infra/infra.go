@@ -0,0 +1,212 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package infra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+)
+
+// Name of the private key written next to the state of the module
+const keyFile = "id_ed25519"
+
+// Outputs of a module, all modules have to provide them
+type Outputs struct {
+	// IP address of the test node
+	NodeIP string `json:"node_ip"`
+	// Private IP address of the test node, only set by cloud modules
+	NodePrivateIP string `json:"node_private_ip"`
+	// User of the SSH connection
+	SSHUser string `json:"ssh_user"`
+	// Password of the SSH user, used by the SSH executor of the tests
+	SSHPassword string `json:"ssh_password"`
+	// Private key of the SSH user, in OpenSSH format
+	SSHPrivateKey string `json:"ssh_private_key"`
+}
+
+/*
+Get the binary used to apply the modules
+  - @remarks Set with TF_BINARY, tofu is preferred over terraform
+  - @returns Name or path of the binary
+*/
+func Binary() string {
+	if bin := os.Getenv("TF_BINARY"); bin != "" {
+		return bin
+	}
+	if _, err := exec.LookPath("tofu"); err == nil {
+		return "tofu"
+	}
+
+	return "terraform"
+}
+
+/*
+Execute a command of the binary in the directory of a module
+  - @remarks This function is only used internally, not exported
+  - @param dir Directory of the module
+  - @param stdout Output of the command, displayed if nil
+  - @param args Arguments of the command
+  - @returns Nothing or an error of type *cmderr.Error
+*/
+func run(dir string, stdout *bytes.Buffer, args ...string) error {
+	// Still displayed while running, the plan and apply can be long
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(Binary(), args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+
+	fmt.Printf("[%s] $ %s %s\n", dir, Binary(), strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		cmdErr := &cmderr.Error{
+			Cmd:      "cd " + cmderr.Quote([]string{dir}) + " && " + cmderr.Quote(cmd.Args),
+			ExitCode: cmderr.ExitCode(err),
+			Stderr:   stderr.String(),
+			Err:      err,
+		}
+		if stdout != nil {
+			cmdErr.Stdout = stdout.String()
+		}
+		return cmdErr
+	}
+
+	return nil
+}
+
+/*
+Create the infrastructure of a module and get its outputs
+  - @remarks Variables of the module are set with TF_VAR_<name>, the private key is written in the module directory
+  - @param dir Directory of the module, e.g. infra/libvirt
+  - @returns The outputs of the module or an error
+*/
+func Apply(dir string) (*Outputs, error) {
+	if err := run(dir, nil, "init", "-input=false"); err != nil {
+		return nil, err
+	}
+	if err := run(dir, nil, "apply", "-input=false", "-auto-approve"); err != nil {
+		return nil, err
+	}
+
+	out, err := Read(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if out.SSHPrivateKey != "" {
+		if err := os.WriteFile(KeyFile(dir), []byte(out.SSHPrivateKey), 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+/*
+Get the outputs of an already applied module
+  - @param dir Directory of the module
+  - @returns The outputs of the module or an error
+*/
+func Read(dir string) (*Outputs, error) {
+	var stdout bytes.Buffer
+	if err := run(dir, &stdout, "output", "-json"); err != nil {
+		return nil, err
+	}
+
+	// Each output is an object with its value, type and sensitivity
+	var raw map[string]struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse the outputs of %s: %w", dir, err)
+	}
+
+	values := map[string]json.RawMessage{}
+	for name, o := range raw {
+		values[name] = o.Value
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Outputs{}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("unexpected outputs of %s: %w", dir, err)
+	}
+	if out.NodeIP == "" {
+		return nil, fmt.Errorf("module %s has no node_ip output", dir)
+	}
+
+	return out, nil
+}
+
+/*
+Destroy the infrastructure of a module
+  - @param dir Directory of the module
+  - @returns Nothing or an error
+*/
+func Destroy(dir string) error {
+	if err := run(dir, nil, "destroy", "-input=false", "-auto-approve"); err != nil {
+		return err
+	}
+
+	if err := os.Remove(KeyFile(dir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+/*
+Get the file of the private key of a module
+  - @param dir Directory of the module
+  - @returns Path of the private key, e.g. for ssh -i
+*/
+func KeyFile(dir string) string {
+	return filepath.Join(dir, keyFile)
+}
+
+/*
+Get the environment of the tests for the outputs
+  - @remarks Only what is defined by the module is set
+  - @returns Environment variables, in KEY=value format
+*/
+func (o *Outputs) Env() []string {
+	env := []string{"K3S_NODE_HOST=" + o.NodeIP}
+	// Behind NAT, K3s has to advertise the address used by the test host
+	if o.NodePrivateIP != "" && o.NodePrivateIP != o.NodeIP {
+		env = append(env, "K3S_NODE_EXTERNAL_IP="+o.NodeIP)
+	}
+	if o.SSHUser != "" {
+		env = append(env, "K3S_NODE_USER="+o.SSHUser)
+	}
+	if o.SSHPassword != "" {
+		env = append(env, "K3S_NODE_PASSWORD="+o.SSHPassword)
+	}
+
+	return env
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubebench
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Result of a single check
+type Result struct {
+	Number      string `json:"test_number"`
+	Description string `json:"test_desc"`
+	Status      string `json:"status"`
+	Scored      bool   `json:"scored"`
+}
+
+// Report is the JSON output of kube-bench
+type Report struct {
+	Controls []struct {
+		ID    string `json:"id"`
+		Text  string `json:"text"`
+		Tests []struct {
+			Section string   `json:"section"`
+			Results []Result `json:"results"`
+		} `json:"tests"`
+	} `json:"Controls"`
+}
+
+/*
+Run kube-bench on a node
+  - @remarks kube-bench has to be installed on the node
+  - @param r Runner of the node
+  - @param benchmark Benchmark to run, e.g. k3s-cis-1.8
+  - @returns The report or an error
+*/
+func Run(r *runner.Runner, benchmark string) (*Report, error) {
+	out, err := r.WithSudo().Run("kube-bench", "run", "--benchmark", benchmark, "--json")
+	if err != nil {
+		return nil, err
+	}
+
+	return parse([]byte(out))
+}
+
+/*
+Load a report saved in a file
+  - @param file JSON output of kube-bench
+  - @returns The report or an error
+*/
+func Load(file string) (*Report, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(data)
+}
+
+/*
+Get the critical findings, failed scored checks
+  - @returns Numbers of the failed checks, sorted
+*/
+func (rep *Report) Failures() []string {
+	var failed []string
+	for _, control := range rep.Controls {
+		for _, test := range control.Tests {
+			for _, res := range test.Results {
+				if res.Scored && res.Status == "FAIL" {
+					failed = append(failed, res.Number)
+				}
+			}
+		}
+	}
+	slices.Sort(failed)
+
+	return slices.Compact(failed)
+}
+
+/*
+Get the critical findings not in another report
+  - @param baseline Report used as reference
+  - @returns Numbers of the failed checks only in this report
+*/
+func (rep *Report) NewFailures(baseline *Report) []string {
+	known := baseline.Failures()
+
+	var added []string
+	for _, number := range rep.Failures() {
+		if !slices.Contains(known, number) {
+			added = append(added, number)
+		}
+	}
+
+	return added
+}
+
+/*
+Parse the JSON output of kube-bench
+  - @remarks This function is only used internally, not exported
+  - @returns The report or an error
+*/
+func parse(data []byte) (*Report, error) {
+	rep := &Report{}
+	if err := json.Unmarshal(data, rep); err != nil {
+		return nil, err
+	}
+
+	return rep, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubebench_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/kubebench"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Extract of a kube-bench JSON output
+const baseline = `{"Controls": [{"id": "1", "text": "Control Plane Security Configuration", "tests": [
+  {"section": "1.1", "results": [
+    {"test_number": "1.1.1", "test_desc": "API server pod specification file permissions", "status": "PASS", "scored": true},
+    {"test_number": "1.1.2", "test_desc": "API server pod specification file ownership", "status": "FAIL", "scored": true},
+    {"test_number": "1.1.3", "test_desc": "Controller manager pod specification file permissions", "status": "FAIL", "scored": false}
+  ]},
+  {"section": "1.2", "results": [
+    {"test_number": "1.2.1", "test_desc": "--anonymous-auth argument is set to false", "status": "WARN", "scored": true}
+  ]}
+]}]}`
+
+// Same checks, with a new scored failure
+const current = `{"Controls": [{"id": "1", "text": "Control Plane Security Configuration", "tests": [
+  {"section": "1.1", "results": [
+    {"test_number": "1.1.1", "test_desc": "API server pod specification file permissions", "status": "FAIL", "scored": true},
+    {"test_number": "1.1.2", "test_desc": "API server pod specification file ownership", "status": "FAIL", "scored": true},
+    {"test_number": "1.1.3", "test_desc": "Controller manager pod specification file permissions", "status": "FAIL", "scored": false}
+  ]},
+  {"section": "1.2", "results": [
+    {"test_number": "1.2.1", "test_desc": "--anonymous-auth argument is set to false", "status": "WARN", "scored": true}
+  ]}
+]}]}`
+
+func load(t *testing.T, content string) *kubebench.Report {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "kube-bench.json")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := kubebench.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rep
+}
+
+func TestFailures(t *testing.T) {
+	tests := []struct {
+		name, report string
+		failures     []string
+	}{
+		{"baseline", baseline, []string{"1.1.2"}},
+		{"current", current, []string{"1.1.1", "1.1.2"}},
+		{"empty", `{"Controls": []}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := load(t, tt.report).Failures(); !slices.Equal(got, tt.failures) {
+				t.Errorf("failures are %v, %v expected", got, tt.failures)
+			}
+		})
+	}
+}
+
+func TestNewFailures(t *testing.T) {
+	before, after := load(t, baseline), load(t, current)
+
+	if got := after.NewFailures(before); !slices.Equal(got, []string{"1.1.1"}) {
+		t.Errorf("new failures are %v", got)
+	}
+	if got := before.NewFailures(after); len(got) != 0 {
+		t.Errorf("fixed failures are reported as new: %v", got)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "kube-bench.json")
+	if err := os.WriteFile(file, []byte("[FAIL] 1.1.1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := kubebench.Load(file); err == nil {
+		t.Error("text output is loaded as a report")
+	}
+	if _, err := kubebench.Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing file is loaded as a report")
+	}
+}
+
+func TestRun(t *testing.T) {
+	// kube-bench is run with sudo, its command line is replayed
+	replay.ForTest(t, replay.Output{Args: []string{"sudo", "kube-bench", "run", "--benchmark", "k3s-cis-1.8", "--json"}, Stdout: current})
+
+	rep, err := kubebench.Run(&runner.Runner{}, "k3s-cis-1.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rep.Failures(); !slices.Equal(got, []string{"1.1.1", "1.1.2"}) {
+		t.Errorf("failures are %v", got)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+)
+
+// Entry is a JSON log line
+type Entry map[string]any
+
+/*
+Get the JSON log entries of a resource
+  - @remarks Lines which are not JSON (e.g. startup messages) are ignored
+  - @param ns Namespace of the resource
+  - @param resource Resource of the pods, e.g. deployment/policy-server-default
+  - @param since Only the entries written after this time
+  - @returns The entries, oldest first, or an error
+*/
+func Get(ns, resource string, since time.Time) ([]Entry, error) {
+	out, err := cluster.Default.KubectlWithoutErr("logs", resource, "--namespace", ns, "--all-containers",
+		"--since-time", since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, line := range strings.Split(out, "\n") {
+		var e Entry
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+/*
+Get a field of an entry
+  - @remarks Fields are searched at the top level, then in the fields and spans of the tracing format
+  - @param name Name of the field, e.g. policy_id
+  - @returns Value of the field as a string, empty if not found
+*/
+func (e Entry) Field(name string) string {
+	scopes := []any{map[string]any(e), e["fields"], e["span"]}
+	// Innermost spans last in the tracing format
+	if spans, ok := e["spans"].([]any); ok {
+		for i := len(spans) - 1; i >= 0; i-- {
+			scopes = append(scopes, spans[i])
+		}
+	}
+
+	for _, scope := range scopes {
+		m, ok := scope.(map[string]any)
+		if !ok {
+			continue
+		}
+		if v, ok := m[name]; ok {
+			return fmt.Sprint(v)
+		}
+	}
+
+	return ""
+}
+
+/*
+Find the entries with some fields
+  - @param entries Entries to search
+  - @param fields Expected values of the fields, compared as strings
+  - @returns The matching entries, empty if none
+*/
+func Find(entries []Entry, fields map[string]string) []Entry {
+	var found []Entry
+
+next:
+	for _, e := range entries {
+		for name, value := range fields {
+			if e.Field(name) != value {
+				continue next
+			}
+		}
+		found = append(found, e)
+	}
+
+	return found
+}
+
+/*
+Match a list of entries containing one with some fields
+  - @param fields Expected values of the fields, e.g. {"policy_id": "clusterwide-do-not-run-as-root", "allowed": "false"}
+  - @returns Gomega matcher of []Entry
+*/
+func HaveEntry(fields map[string]string) types.GomegaMatcher {
+	var expected []string
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		expected = append(expected, name+"="+fields[name])
+	}
+
+	return gcustom.MakeMatcher(func(entries []Entry) (bool, error) {
+		return len(Find(entries, fields)) > 0, nil
+	}).WithTemplate("Expected log entries:\n{{format .Actual 1}}\n{{.To}} contain an entry with " + strings.Join(expected, ", "))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/logs"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+)
+
+// Logs of a policy server, in the JSON and tracing formats, with a startup message
+const output = `Starting policy server
+{"level":"info","message":"policy evaluation","policy_id":"clusterwide-do-not-run-as-root","allowed":false}
+{"level":"info","fields":{"message":"policy download"},"span":{"name":"download","module":"registry://ghcr.io/kubewarden/tests/pod-privileged:v0.2.5"}}
+{"level":"debug","fields":{"message":"validation"},"spans":[{"policy_id":"outer","kind":"Pod"},{"policy_id":"inner"}],"allowed":true}
+`
+
+var since = time.Date(2025, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+func get(t *testing.T) []logs.Entry {
+	t.Helper()
+
+	args := []string{"kubectl", "logs", "deployment/policy-server-default", "--namespace", "kubewarden", "--all-containers",
+		"--since-time", "2025-01-01T11:00:00Z"}
+	replay.ForTest(t, replay.Output{Args: args, Stdout: output})
+
+	entries, err := logs.Get("kubewarden", "deployment/policy-server-default", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestGet(t *testing.T) {
+	if entries := get(t); len(entries) != 3 {
+		t.Errorf("entries are %v, the 3 JSON lines expected", entries)
+	}
+}
+
+func TestField(t *testing.T) {
+	entries := get(t)
+
+	tests := []struct {
+		name  string
+		entry int
+		field string
+		value string
+	}{
+		{"top level", 0, "policy_id", "clusterwide-do-not-run-as-root"},
+		{"not a string", 0, "allowed", "false"},
+		{"tracing fields", 1, "message", "policy download"},
+		{"tracing span", 1, "module", "registry://ghcr.io/kubewarden/tests/pod-privileged:v0.2.5"},
+		{"innermost span first", 2, "policy_id", "inner"},
+		{"outer span", 2, "kind", "Pod"},
+		{"missing", 2, "namespace", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entries[tt.entry].Field(tt.field); got != tt.value {
+				t.Errorf("field %s is %q, %q expected", tt.field, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	entries := get(t)
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		found  int
+	}{
+		{"all", nil, 3},
+		{"one field", map[string]string{"level": "info"}, 2},
+		{"several fields", map[string]string{"level": "info", "allowed": "false"}, 1},
+		{"no match", map[string]string{"allowed": "false", "policy_id": "inner"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logs.Find(entries, tt.fields); len(got) != tt.found {
+				t.Errorf("found %v, %d entries expected", got, tt.found)
+			}
+			if ok, err := logs.HaveEntry(tt.fields).Match(entries); err != nil || ok != (tt.found > 0) {
+				t.Errorf("match is %v (%v)", ok, err)
+			}
+		})
+	}
+}
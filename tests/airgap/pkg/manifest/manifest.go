@@ -0,0 +1,214 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/arch"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Chart is a deployed Helm release
+type Chart struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Chart      string `json:"chart"`
+	AppVersion string `json:"app_version"`
+}
+
+// Policy is a Kubewarden policy and the module it runs
+type Policy struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Module string `json:"module"`
+	// Digest of the module image, empty if it cannot be resolved (e.g. airgap)
+	Digest string `json:"digest,omitempty"`
+}
+
+// Manifest describes what is actually under test
+type Manifest struct {
+	RunID        string    `json:"run_id"`
+	Date         time.Time `json:"date"`
+	TestHostOS   string    `json:"test_host_os"`
+	TestHostArch string    `json:"test_host_arch"`
+	NodeOS       string    `json:"node_os,omitempty"`
+	NodeArch     string    `json:"node_arch,omitempty"`
+	K3sVersion   string    `json:"k3s_version,omitempty"`
+	Charts       []Chart   `json:"charts"`
+	Policies     []Policy  `json:"policies"`
+	// Information that could not be collected, nothing is installed at the beginning of a fresh run
+	Errors []string `json:"errors,omitempty"`
+}
+
+/*
+Collect the versions under test, everything is best effort
+  - @param node Node where K3s is installed
+  - @param runID ID of the run
+  - @returns The manifest
+*/
+func Collect(node *runner.Runner, runID string) *Manifest {
+	m := &Manifest{
+		RunID:    runID,
+		Date:     time.Now().UTC(),
+		Charts:   []Chart{},
+		Policies: []Policy{},
+	}
+
+	m.TestHostOS = osName(&runner.Runner{})
+	m.TestHostArch = runtime.GOARCH
+
+	out, err := cluster.Default.KubectlWithoutErr("get", "nodes",
+		"-o", "jsonpath={.items[0].status.nodeInfo.kubeletVersion}|{.items[0].status.nodeInfo.osImage}|{.items[0].status.nodeInfo.architecture}")
+	if err != nil {
+		m.fail("K3s version", err)
+		// The node OS and architecture are still useful when K3s is not installed yet
+		m.NodeOS = osName(node)
+		if m.NodeArch, err = arch.Of(node); err != nil {
+			m.fail("node architecture", err)
+		}
+	} else {
+		fields := strings.SplitN(out, "|", 3)
+		fields = append(fields, "", "")
+		m.K3sVersion, m.NodeOS, m.NodeArch = fields[0], fields[1], fields[2]
+	}
+
+	out, err = cluster.Default.Helm("list", "--all-namespaces", "--deployed", "-o", "json")
+	if err == nil {
+		err = json.Unmarshal([]byte(out), &m.Charts)
+	}
+	if err != nil {
+		m.fail("Helm releases", err)
+	}
+
+	out, err = cluster.Default.KubectlWithoutErr("get", "clusteradmissionpolicies,admissionpolicies", "--all-namespaces",
+		"-o", "jsonpath={range .items[*]}{.kind} {.metadata.name} {.spec.module}{\"\\n\"}{end}")
+	if err != nil {
+		m.fail("policies", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		p := Policy{Kind: fields[0], Name: fields[1], Module: fields[2]}
+		if p.Digest, err = digest(p.Module, m.NodeArch); err != nil {
+			m.fail("digest of "+p.Module, err)
+		}
+		m.Policies = append(m.Policies, p)
+	}
+
+	return m
+}
+
+/*
+Get the manifest in JSON format
+  - @returns Indented JSON
+*/
+func (m *Manifest) JSON() string {
+	data, _ := json.MarshalIndent(m, "", "  ")
+	return string(data)
+}
+
+/*
+Get the versions under test, as compared between runs
+  - @returns Version of K3s and of each chart, by name
+*/
+func (m *Manifest) Versions() map[string]string {
+	versions := map[string]string{"K3s": cmp.Or(m.K3sVersion, "not installed")}
+	for _, chart := range m.Charts {
+		versions[chart.Name] = fmt.Sprintf("%s (app %s)", chart.Chart, chart.AppVersion)
+	}
+	return versions
+}
+
+/*
+Write the manifest in a JSON file
+  - @param file Destination file, parent directories are created
+  - @returns Nothing or an error
+*/
+func (m *Manifest) Write(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, []byte(m.JSON()+"\n"), 0644)
+}
+
+/*
+Keep an information that could not be collected
+  - @remarks This function is only used internally, not exported
+  - @returns Nothing
+*/
+func (m *Manifest) fail(what string, err error) {
+	m.Errors = append(m.Errors, fmt.Sprintf("%s: %v", what, err))
+}
+
+/*
+Get the OS name of a host
+  - @remarks This function is only used internally, not exported
+  - @returns PRETTY_NAME of os-release, empty if unknown
+*/
+func osName(r *runner.Runner) string {
+	out, err := r.Run("cat", "/etc/os-release")
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+			return strings.Trim(name, `"`)
+		}
+	}
+
+	return ""
+}
+
+/*
+Resolve the digest of a policy module
+  - @remarks This function is only used internally, not exported
+  - @param module Module of the policy, e.g. registry://ghcr.io/kubewarden/policies/pod-privileged:v0.2.1
+  - @param platform Architecture of the node, images with several platforms are resolved for it
+  - @returns Digest of the module image, or an error
+*/
+func digest(module, platform string) (string, error) {
+	ref, ok := strings.CutPrefix(module, "registry://")
+	if !ok {
+		return "", fmt.Errorf("not an OCI module")
+	}
+
+	// Already pinned
+	if _, d, found := strings.Cut(ref, "@"); found {
+		return d, nil
+	}
+
+	args := []string{"inspect", "--format", "{{.Digest}}"}
+	if platform != "" {
+		// Otherwise the platform of the test host is used
+		args = append(args, "--override-os", "linux", "--override-arch", platform)
+	}
+
+	out, err := runner.Run("skopeo", append(args, "docker://"+ref)...)
+	return strings.TrimSpace(out), err
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest_test
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/manifest"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+var (
+	nodes    = []string{"kubectl", "get", "nodes", "-o", "jsonpath={.items[0].status.nodeInfo.kubeletVersion}|{.items[0].status.nodeInfo.osImage}|{.items[0].status.nodeInfo.architecture}"}
+	releases = []string{"helm", "list", "--all-namespaces", "--deployed", "-o", "json"}
+	policies = []string{"kubectl", "get", "clusteradmissionpolicies,admissionpolicies", "--all-namespaces",
+		"-o", "jsonpath={range .items[*]}{.kind} {.metadata.name} {.spec.module}{\"\\n\"}{end}"}
+)
+
+func TestCollect(t *testing.T) {
+	// The digests are resolved with skopeo
+	replay.ForTest(t, []replay.Output{
+		{Args: nodes, Stdout: "v1.31.4+k3s1|SUSE Linux Enterprise Server 15 SP6|arm64"},
+		{Args: releases, Stdout: `[{"name": "kubewarden-controller", "namespace": "kubewarden", "chart": "kubewarden-controller-4.1.0", "app_version": "v1.20.0", "status": "deployed"}]`},
+		{Args: policies, Stdout: "ClusterAdmissionPolicy no-privileged-pod registry://ghcr.io/kubewarden/policies/pod-privileged:v0.2.1\n" +
+			"AdmissionPolicy pinned registry://ghcr.io/kubewarden/policies/safe-labels@sha256:1234\n" +
+			"ClusterAdmissionPolicy local file:///tmp/policy.wasm\n"},
+		{Args: []string{"skopeo", "inspect", "--format", "{{.Digest}}", "--override-os", "linux", "--override-arch", "arm64",
+			"docker://ghcr.io/kubewarden/policies/pod-privileged:v0.2.1"}, Stdout: "sha256:abcd\n"},
+	}...)
+
+	m := manifest.Collect(&runner.Runner{}, "run-1")
+
+	if m.RunID != "run-1" || m.TestHostArch != runtime.GOARCH {
+		t.Errorf("unexpected run %s on %s", m.RunID, m.TestHostArch)
+	}
+	if m.K3sVersion != "v1.31.4+k3s1" || m.NodeOS != "SUSE Linux Enterprise Server 15 SP6" || m.NodeArch != "arm64" {
+		t.Errorf("unexpected node %q %q %q", m.K3sVersion, m.NodeOS, m.NodeArch)
+	}
+	if len(m.Charts) != 1 || m.Charts[0].AppVersion != "v1.20.0" {
+		t.Errorf("unexpected charts %v", m.Charts)
+	}
+
+	digests := map[string]string{}
+	for _, p := range m.Policies {
+		digests[p.Name] = p.Digest
+	}
+	want := map[string]string{"no-privileged-pod": "sha256:abcd", "pinned": "sha256:1234", "local": ""}
+	if !maps.Equal(digests, want) {
+		t.Errorf("digests are %v, %v expected", digests, want)
+	}
+	if len(m.Errors) != 1 || !strings.HasPrefix(m.Errors[0], "digest of file:///tmp/policy.wasm") {
+		t.Errorf("unexpected errors %v", m.Errors)
+	}
+}
+
+func TestCollectFreshRun(t *testing.T) {
+	// Without K3s, the node architecture is taken with uname
+	replay.ForTest(t, []replay.Output{
+		{Args: nodes, Stderr: "The connection to the server localhost:8080 was refused", Code: 1},
+		{Args: releases, Stderr: "Kubernetes cluster unreachable", Code: 1},
+		{Args: policies, Stderr: "The connection to the server localhost:8080 was refused", Code: 1},
+		{Args: []string{"uname", "-m"}, Stdout: "x86_64\n"},
+	}...)
+
+	m := manifest.Collect(&runner.Runner{}, "run-2")
+
+	if m.K3sVersion != "" || m.NodeArch != "amd64" || len(m.Charts) != 0 || len(m.Policies) != 0 {
+		t.Errorf("unexpected manifest %s", m.JSON())
+	}
+
+	var failed []string
+	for _, e := range m.Errors {
+		what, _, _ := strings.Cut(e, ":")
+		failed = append(failed, what)
+	}
+	if want := []string{"K3s version", "Helm releases", "policies"}; !slices.Equal(failed, want) {
+		t.Errorf("errors are %v, %v expected", m.Errors, want)
+	}
+
+	if got := m.Versions(); !maps.Equal(got, map[string]string{"K3s": "not installed"}) {
+		t.Errorf("versions are %v", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	m := &manifest.Manifest{
+		RunID:      "run-3",
+		K3sVersion: "v1.31.4+k3s1",
+		Charts:     []manifest.Chart{{Name: "kubewarden-defaults", Chart: "kubewarden-defaults-3.1.0", AppVersion: "v1.20.0"}},
+		Policies:   []manifest.Policy{},
+	}
+	file := filepath.Join(t.TempDir(), "results", "run-manifest.json")
+
+	if err := m.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded manifest.Manifest
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"K3s": "v1.31.4+k3s1", "kubewarden-defaults": "kubewarden-defaults-3.1.0 (app v1.20.0)"}
+	if got := loaded.Versions(); !maps.Equal(got, want) {
+		t.Errorf("versions are %v, %v expected", got, want)
+	}
+	if strings.Contains(string(data), "errors") {
+		t.Errorf("empty errors are written:\n%s", data)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Samples are the values of a scrape, by series (name and labels as exposed)
+type Samples map[string]float64
+
+/*
+Scrape a Prometheus endpoint
+  - @param url URL of the endpoint, e.g. http://127.0.0.1:8080/metrics
+  - @returns Samples of the text exposition format, or an error
+*/
+func Scrape(url string) (Samples, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s returned %s: %s", url, resp.Status, body)
+	}
+
+	return Parse(resp.Body)
+}
+
+/*
+Parse the Prometheus text exposition format
+  - @param r Reader of the exposition
+  - @returns Samples, timestamps are ignored, or an error
+*/
+func Parse(r io.Reader) (Samples, error) {
+	samples := Samples{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Label values can contain spaces, the value is after the closing brace
+		series, rest := line, ""
+		if i := strings.LastIndex(line, "}"); i >= 0 {
+			series, rest = line[:i+1], line[i+1:]
+		} else if name, value, found := strings.Cut(line, " "); found {
+			series, rest = name, value
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("no value in %q", line)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad value in %q: %w", line, err)
+		}
+
+		samples[series] = value
+	}
+
+	return samples, scanner.Err()
+}
+
+/*
+Sum the series of a metric
+  - @param name Name of the metric, e.g. kubewarden_policy_evaluations_total
+  - @param labels Label pairs the series must have, e.g. controller="policyserver"
+  - @returns Sum of the matching series, and whether at least one matched
+*/
+func (s Samples) Sum(name string, labels ...string) (float64, bool) {
+	var (
+		sum   float64
+		found bool
+	)
+
+	for series, value := range s {
+		metric, rest, _ := strings.Cut(series, "{")
+		if metric != name {
+			continue
+		}
+
+		matches := true
+		for _, l := range labels {
+			if !strings.Contains(rest, l) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			sum += value
+			found = true
+		}
+	}
+
+	return sum, found
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Alert of the rules evaluated by Prometheus
+type Alert struct {
+	Labels map[string]string `json:"labels"`
+	// pending or firing
+	State string `json:"state"`
+	Value string `json:"value"`
+}
+
+// Name of the alert, from its alertname label
+func (a Alert) Name() string {
+	return a.Labels["alertname"]
+}
+
+/*
+Get the active alerts of Prometheus
+  - @param baseURL URL of Prometheus, e.g. http://127.0.0.1:9090
+  - @returns Pending and firing alerts, or an error
+*/
+func Alerts(baseURL string) ([]Alert, error) {
+	var data struct {
+		Alerts []Alert `json:"alerts"`
+	}
+	if err := getAPI(baseURL+"/api/v1/alerts", &data); err != nil {
+		return nil, err
+	}
+
+	return data.Alerts, nil
+}
+
+/*
+Get the rule groups loaded by Prometheus
+  - @param baseURL URL of Prometheus, e.g. http://127.0.0.1:9090
+  - @returns Names of the groups, or an error
+*/
+func RuleGroups(baseURL string) ([]string, error) {
+	var data struct {
+		Groups []struct {
+			Name string `json:"name"`
+		} `json:"groups"`
+	}
+	if err := getAPI(baseURL+"/api/v1/rules", &data); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(data.Groups))
+	for _, g := range data.Groups {
+		names = append(names, g.Name)
+	}
+
+	return names, nil
+}
+
+/*
+Call the HTTP API of Prometheus
+  - @remarks This function is only used internally, not exported
+  - @param url URL of the endpoint
+  - @param data Decoded data of the response
+  - @returns An error if the call failed or was not successful
+*/
+func getAPI(url string, data any) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, body)
+	}
+
+	var envelope struct {
+		Status string          `json:"status"`
+		Error  string          `json:"error"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("cannot parse the response of %s: %w", url, err)
+	}
+	if envelope.Status != "success" {
+		return fmt.Errorf("%s failed: %s", url, envelope.Error)
+	}
+
+	return json.Unmarshal(envelope.Data, data)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/results"
+)
+
+// Maximum number of failures in a message, the others are in the artifacts
+const maxFailures = 10
+
+// Message is the summary of a suite sent to the chat
+type Message struct {
+	// Tier of cmd/e2e, empty if the suite is run directly
+	Tier     string
+	Summary  *results.Summary
+	Versions []string
+	// Failed specs with the first line of their failure
+	Failures []string
+	// Link to the logs and artifacts of the run, empty if unknown
+	Link string
+}
+
+/*
+Format the message in Markdown, supported by both Slack and Matrix
+  - @returns Text of the message
+*/
+func (m *Message) Text() string {
+	s := m.Summary
+
+	status := ":white_check_mark: passed"
+	if s.Failed > 0 {
+		status = ":x: failed"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Kubewarden E2E %s*", status)
+	if m.Tier != "" {
+		fmt.Fprintf(&b, " - tier `%s`", m.Tier)
+	}
+	fmt.Fprintf(&b, ", step `%s`, run `%s`\n", s.Suite, s.RunID)
+	fmt.Fprintf(&b, "%d passed, %d failed, %d skipped, %d flaky in %s\n",
+		s.Passed, s.Failed, s.Skipped, s.Flaky, time.Duration(s.Duration*float64(time.Second)).Round(time.Second))
+
+	if len(m.Versions) > 0 {
+		fmt.Fprintf(&b, "Versions: %s\n", strings.Join(m.Versions, ", "))
+	}
+
+	for i, failure := range m.Failures {
+		if i == maxFailures {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(m.Failures)-maxFailures)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", failure)
+	}
+
+	if m.Link != "" {
+		fmt.Fprintf(&b, "Logs and artifacts: %s\n", m.Link)
+	}
+
+	return b.String()
+}
+
+/*
+Post a message to a webhook
+  - @remarks Slack incoming webhooks and the Matrix hookshot generic webhooks both accept a text field
+  - @param webhook URL of the webhook
+  - @param m Message to post
+  - @returns Nothing or an error
+*/
+func Post(webhook string, m *Message) error {
+	body, err := json.Marshal(map[string]string{"text": m.Text()})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The URL contains the secret of the webhook, it is not displayed
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("cannot post to the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	return nil
+}
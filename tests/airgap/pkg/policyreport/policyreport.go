@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+)
+
+// Result is the result of a policy for a resource, from the PolicyReports of the audit scanner
+type Result struct {
+	// Policy, as named by the audit scanner (with a prefix for cluster-wide policies)
+	Policy string
+	// pass, fail, warn, error or skip
+	Result string
+	// Message of the policy, only set for failures
+	Message string
+	// Severity of the policy, from its io.kubewarden.policy.severity annotation
+	Severity string
+	// Kind of the audited resource, e.g. Deployment
+	Kind string
+	// Name of the audited resource
+	Name string
+}
+
+// Reports of both formats, per resource (with a scope) or per namespace (with resources in each result)
+type report struct {
+	Scope   *resource `json:"scope"`
+	Results []struct {
+		Policy    string     `json:"policy"`
+		Result    string     `json:"result"`
+		Message   string     `json:"message"`
+		Severity  string     `json:"severity"`
+		Resources []resource `json:"resources"`
+	} `json:"results"`
+}
+
+type resource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+/*
+Get the results of the PolicyReports of a namespace
+  - @param ns Namespace of the reports
+  - @returns One result per policy and resource, or an error
+*/
+func Results(ns string) ([]Result, error) {
+	out, err := cluster.Default.KubectlWithoutErr("get", "policyreports", "--namespace", ns, "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []report `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse policy reports: %w", err)
+	}
+
+	var results []Result
+	for _, r := range list.Items {
+		for _, res := range r.Results {
+			resources := res.Resources
+			if r.Scope != nil {
+				resources = []resource{*r.Scope}
+			}
+			for _, obj := range resources {
+				results = append(results, Result{
+					Policy:   res.Policy,
+					Result:   res.Result,
+					Message:  res.Message,
+					Severity: res.Severity,
+					Kind:     obj.Kind,
+					Name:     obj.Name,
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+/*
+Get the result of a policy for a resource
+  - @param results Results of the reports
+  - @param kind Kind of the resource
+  - @param name Name of the resource
+  - @param policy Name of the policy, the prefix of the audit scanner is ignored
+  - @returns The result, e.g. pass or fail, empty if the resource has not been audited by the policy
+*/
+func Find(results []Result, kind, name, policy string) string {
+	for _, r := range results {
+		if r.Kind == kind && r.Name == name && strings.HasSuffix(r.Policy, policy) {
+			return r.Result
+		}
+	}
+
+	return ""
+}
+
+// Key of the results counted by Count, the way the reports are aggregated by their consumers
+type Key struct {
+	Policy   string
+	Severity string
+	Result   string
+}
+
+/*
+Count the results by policy, severity and result
+  - @param results Results of the reports
+  - @returns Number of results of each key
+*/
+func Count(results []Result) map[Key]int {
+	counts := map[Key]int{}
+	for _, r := range results {
+		counts[Key{Policy: r.Policy, Severity: r.Severity, Result: r.Result}]++
+	}
+
+	return counts
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyreporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/policyreport"
+)
+
+// Source of the results written by the audit scanner of Kubewarden
+const Source = "kubewarden"
+
+// Result as listed by the REST API of policy-reporter
+type result struct {
+	Policy   string `json:"policy"`
+	Status   string `json:"status"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+}
+
+/*
+Get the Kubewarden results of a namespace from the REST API of policy-reporter
+  - @param baseURL URL of the API, e.g. http://127.0.0.1:8080
+  - @param ns Namespace of the results
+  - @returns Results in the format of the policyreport helper, to be compared with the reports, or an error
+*/
+func Results(baseURL, ns string) ([]policyreport.Result, error) {
+	query := url.Values{"namespaces": {ns}, "sources": {Source}}
+	u := baseURL + "/v1/namespaced-resources/results?" + query.Encode()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s: %s", u, resp.Status, body)
+	}
+
+	return Parse(body)
+}
+
+/*
+Parse a list of results of the REST API
+  - @remarks Paginated responses have the list in items, older versions return the list itself
+  - @param body Body of the response
+  - @returns Results in the format of the policyreport helper, or an error
+*/
+func Parse(body []byte) ([]policyreport.Result, error) {
+	var list struct {
+		Items []result `json:"items"`
+	}
+	var err error
+	if body = bytes.TrimSpace(body); bytes.HasPrefix(body, []byte("[")) {
+		err = json.Unmarshal(body, &list.Items)
+	} else {
+		err = json.Unmarshal(body, &list)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse policy-reporter results: %w", err)
+	}
+
+	results := make([]policyreport.Result, 0, len(list.Items))
+	for _, r := range list.Items {
+		results = append(results, policyreport.Result{
+			Policy:   r.Policy,
+			Result:   r.Status,
+			Message:  r.Message,
+			Severity: r.Severity,
+			Kind:     r.Kind,
+			Name:     r.Name,
+		})
+	}
+
+	return results, nil
+}
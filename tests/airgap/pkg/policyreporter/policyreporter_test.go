@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyreporter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/policyreport"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/policyreporter"
+)
+
+const items = `[
+  {"policy": "clusterwide-pinned", "status": "fail", "severity": "medium", "kind": "Deployment", "name": "a"},
+  {"policy": "clusterwide-pinned", "status": "fail", "severity": "medium", "kind": "Deployment", "name": "b"},
+  {"policy": "clusterwide-privileged", "status": "pass", "severity": "high", "kind": "Deployment", "name": "a"}
+]`
+
+func TestResults(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"items": ` + items + `, "count": 3}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	results, err := policyreporter.Results(srv.URL, "e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "namespaces=e2e&sources=kubewarden" {
+		t.Errorf("unexpected query %q", query)
+	}
+
+	counts := policyreport.Count(results)
+	for key, want := range map[policyreport.Key]int{
+		{Policy: "clusterwide-pinned", Severity: "medium", Result: "fail"}:   2,
+		{Policy: "clusterwide-privileged", Severity: "high", Result: "pass"}: 1,
+	} {
+		if counts[key] != want {
+			t.Errorf("%v counted %d times, %d expected", key, counts[key], want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	results, err := policyreporter.Parse([]byte(items))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[2].Result != "pass" || results[2].Name != "a" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestResultsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	if _, err := policyreporter.Results(srv.URL, "e2e"); err == nil {
+		t.Error("no error for a 404")
+	}
+}
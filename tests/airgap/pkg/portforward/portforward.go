@@ -0,0 +1,196 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// Forward is a running kubectl port-forward
+type Forward struct {
+	// Namespace of the forwarded resource
+	Namespace string
+	// Forwarded resource, e.g. svc/policy-server-default
+	Resource string
+	// Port of the resource
+	RemotePort int
+	// Port on 127.0.0.1
+	LocalPort int
+
+	cmd    *exec.Cmd
+	err    error
+	done   chan struct{}
+	mu     sync.Mutex
+	output bytes.Buffer
+}
+
+/*
+Start a port-forward, stopped automatically at the end of the spec
+  - @param ctx Context, usually the SpecContext of the running spec
+  - @param ns Namespace of the resource
+  - @param resource Resource to forward, e.g. svc/minio
+  - @param remotePort Port of the resource
+  - @returns The port-forward, ready to be used, or an error
+*/
+func Start(ctx context.Context, ns, resource string, remotePort int) (*Forward, error) {
+	localPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Forward{
+		Namespace:  ns,
+		Resource:   resource,
+		RemotePort: remotePort,
+		LocalPort:  localPort,
+		done:       make(chan struct{}),
+	}
+
+	// Nothing is listening, the port-forward is stopped from the start
+	if dryrun.Enabled() {
+		dryrun.Record("port-forward %s/%s:%d to %s", ns, resource, remotePort, f.Addr())
+		close(f.done)
+		return f, nil
+	}
+
+	f.cmd = exec.Command("kubectl", "port-forward", "--namespace", ns, resource,
+		"--address", "127.0.0.1", fmt.Sprintf("%d:%d", localPort, remotePort))
+	f.cmd.Stdout = f
+	f.cmd.Stderr = f
+
+	GinkgoWriter.Printf("Forwarding %s/%s:%d to %s\n", ns, resource, remotePort, f.Addr())
+	if err := f.cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		f.err = f.cmd.Wait()
+		close(f.done)
+	}()
+
+	// Teardown is always done, even if the spec fails
+	DeferCleanup(f.Stop)
+
+	err = wait.For(ctx, func(context.Context) (string, error) {
+		select {
+		case <-f.done:
+			// Both outputs of kubectl are kept together
+			return f.Output(), &cmderr.Error{
+				Cmd:      cmderr.Quote(f.cmd.Args),
+				ExitCode: cmderr.ExitCode(f.err),
+				Stdout:   f.Output(),
+				Err:      cmp.Or(f.err, fmt.Errorf("port-forward exited")),
+			}
+		default:
+		}
+
+		conn, err := net.DialTimeout("tcp", f.Addr(), time.Second)
+		if err != nil {
+			return f.Output(), err
+		}
+		return "", conn.Close()
+	}, wait.Options{
+		Class:       timeouts.Default,
+		Description: "port-forward to " + ns + "/" + resource,
+		Interval:    time.Second,
+		MaxInterval: 5 * time.Second,
+	})
+	if err != nil {
+		f.Stop()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+/*
+Get the local address of the port-forward
+  - @returns Address in host:port format
+*/
+func (f *Forward) Addr() string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(f.LocalPort))
+}
+
+/*
+Get the local URL of the port-forward
+  - @param scheme URL scheme, e.g. http or https
+  - @returns URL of the forwarded resource
+*/
+func (f *Forward) URL(scheme string) string {
+	return scheme + "://" + f.Addr()
+}
+
+/*
+Get the output of kubectl port-forward
+  - @returns Output of the command
+*/
+func (f *Forward) Output() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.output.String()
+}
+
+/*
+Stop the port-forward, can be called multiple times
+  - @returns Nothing
+*/
+func (f *Forward) Stop() {
+	select {
+	case <-f.done:
+		return
+	default:
+	}
+
+	GinkgoWriter.Printf("Stopping port-forward to %s/%s\n", f.Namespace, f.Resource)
+	_ = f.cmd.Process.Kill()
+	<-f.done
+}
+
+// Write keeps the output of kubectl, as it can be written from two goroutines
+func (f *Forward) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.output.Write(p)
+}
+
+/*
+Get a free local port
+  - @remarks This function is only used internally, not exported
+  - @returns A free TCP port or an error
+*/
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
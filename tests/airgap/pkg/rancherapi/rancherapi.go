@@ -0,0 +1,273 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rancherapi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client of the Rancher API, as used by the UI extensions
+type Client struct {
+	// URL of Rancher, e.g. https://192.168.122.10.sslip.io
+	URL string
+	// Bearer token, in name:secret format
+	Token string
+
+	http *http.Client
+}
+
+/*
+Create a Rancher client
+  - @remarks Rancher of the tests uses a self-signed certificate, so it is not verified
+  - @param rancherURL URL of Rancher
+  - @param token API token of a Rancher user
+  - @returns The client
+*/
+func New(rancherURL, token string) *Client {
+	return &Client{
+		URL:   strings.TrimSuffix(rancherURL, "/"),
+		Token: token,
+		http: &http.Client{
+			Timeout:   time.Minute,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+/*
+Get a path of the Rancher API
+  - @param endpoint Path of the API, e.g. /v1/policies.kubewarden.io.policyservers
+  - @param out Decoded JSON response, nil to only check the status
+  - @returns Nothing or an error
+*/
+func (c *Client) Get(endpoint string, out any) error {
+	return c.do(http.MethodGet, endpoint, nil, out)
+}
+
+/*
+Post to a path of the Rancher API, e.g. an action of a resource
+  - @param endpoint Path of the API, e.g. /v3/clusters/c-abcde?action=generateKubeconfig
+  - @param in Body of the request, encoded in JSON, nil for none
+  - @param out Decoded JSON response, nil to only check the status
+  - @returns Nothing or an error
+*/
+func (c *Client) Post(endpoint string, in, out any) error {
+	return c.do(http.MethodPost, endpoint, in, out)
+}
+
+/*
+Send a request to the Rancher API
+  - @remarks This function is only used internally, not exported
+  - @param method HTTP method
+  - @param endpoint Path of the API
+  - @param in Body of the request, encoded in JSON, nil for none
+  - @param out Decoded JSON response, nil to only check the status
+  - @returns Nothing or an error
+*/
+func (c *Client) do(method, endpoint string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.URL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// Actions answer with 200 or 201
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s %s returned %d: %.200s", method, endpoint, resp.StatusCode, data)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("cannot parse the response of %s: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+/*
+List the IDs of a Steve collection
+  - @remarks Steve is the /v1 API used by the Rancher UI
+  - @param collection Type of the collection, e.g. policies.kubewarden.io.clusteradmissionpolicies
+  - @returns IDs of the resources, namespace/name for namespaced ones, or an error
+*/
+func (c *Client) IDs(collection string) ([]string, error) {
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := c.Get("/v1/"+collection, &list); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(list.Data))
+	for _, item := range list.Data {
+		ids = append(ids, item.ID)
+	}
+
+	return ids, nil
+}
+
+// Chart installed by Rancher on a cluster, as done by the Apps page of the UI
+type Chart struct {
+	// Name of the chart in the repository
+	Name string
+	// Version of the chart, latest if empty
+	Version string
+	// Namespace of the release
+	Namespace string
+	// Helm values of the chart
+	Values map[string]any
+}
+
+/*
+Get a kubeconfig of a cluster managed by Rancher
+  - @remarks The kubeconfig uses the Rancher proxy (/k8s/clusters/<id>) and the token of the client
+  - @param clusterID ID of the management cluster, e.g. c-abcde
+  - @returns Content of the kubeconfig or an error
+*/
+func (c *Client) Kubeconfig(clusterID string) (string, error) {
+	var resp struct {
+		Config string `json:"config"`
+	}
+	if err := c.Post("/v3/clusters/"+clusterID+"?action=generateKubeconfig", nil, &resp); err != nil {
+		return "", err
+	}
+	if resp.Config == "" {
+		return "", fmt.Errorf("empty kubeconfig for cluster %s", clusterID)
+	}
+
+	return resp.Config, nil
+}
+
+/*
+Get the latest version of a chart of a repository
+  - @param clusterID ID of the management cluster where the repository is defined
+  - @param repo Name of the ClusterRepo
+  - @param chart Name of the chart
+  - @returns Version of the chart or an error
+*/
+func (c *Client) ChartVersion(clusterID, repo, chart string) (string, error) {
+	var index struct {
+		Entries map[string][]struct {
+			Version string `json:"version"`
+		} `json:"entries"`
+	}
+	if err := c.Get(clusterPath(clusterID)+"/v1/catalog.cattle.io.clusterrepos/"+repo+"?link=index", &index); err != nil {
+		return "", err
+	}
+
+	// Entries are sorted from the newest version
+	versions := index.Entries[chart]
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no chart %s in repository %s", chart, repo)
+	}
+
+	return versions[0].Version, nil
+}
+
+/*
+Install (or upgrade) a chart on a cluster through Rancher
+  - @remarks Rancher runs a helm-operation pod in cattle-system of the cluster, the release is only deployed once it has finished
+  - @param clusterID ID of the management cluster, e.g. c-abcde
+  - @param repo Name of the ClusterRepo of the chart
+  - @param chart Chart to install
+  - @returns Name of the helm operation, or an error
+*/
+func (c *Client) InstallChart(clusterID, repo string, chart Chart) (string, error) {
+	version := chart.Version
+	if version == "" {
+		var err error
+		if version, err = c.ChartVersion(clusterID, repo, chart.Name); err != nil {
+			return "", err
+		}
+	}
+
+	values := chart.Values
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	req := map[string]any{
+		"namespace": chart.Namespace,
+		"wait":      true,
+		"timeout":   "600s",
+		"charts": []map[string]any{{
+			"chartName":   chart.Name,
+			"version":     version,
+			"releaseName": chart.Name,
+			"values":      values,
+			"annotations": map[string]string{
+				"catalog.cattle.io/ui-source-repo-type": "cluster",
+				"catalog.cattle.io/ui-source-repo":      repo,
+			},
+		}},
+	}
+
+	var resp struct {
+		OperationName string `json:"operationName"`
+	}
+	if err := c.Post(clusterPath(clusterID)+"/v1/catalog.cattle.io.clusterrepos/"+repo+"?action=install", req, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.OperationName, nil
+}
+
+/*
+Get the path of the API of a cluster
+  - @remarks This function is only used internally, not exported
+  - @param clusterID ID of the management cluster, local for the Rancher cluster itself
+  - @returns Prefix of the paths, empty for the local cluster
+*/
+func clusterPath(clusterID string) string {
+	if clusterID == "" || clusterID == "local" {
+		return ""
+	}
+
+	return "/k8s/clusters/" + clusterID
+}
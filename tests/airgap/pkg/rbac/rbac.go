@@ -0,0 +1,187 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+	"gopkg.in/yaml.v3"
+)
+
+// Golden permissions of each component, per scope, as patterns of path.Match
+type Golden map[string]map[string][]string
+
+// Result of a SelfSubjectRulesReview, only what is compared
+type review struct {
+	Status struct {
+		ResourceRules []struct {
+			Verbs         []string `json:"verbs"`
+			APIGroups     []string `json:"apiGroups"`
+			Resources     []string `json:"resources"`
+			ResourceNames []string `json:"resourceNames"`
+		} `json:"resourceRules"`
+		NonResourceRules []struct {
+			Verbs           []string `json:"verbs"`
+			NonResourceURLs []string `json:"nonResourceURLs"`
+		} `json:"nonResourceRules"`
+		Incomplete bool `json:"incomplete"`
+	} `json:"status"`
+}
+
+/*
+Get the effective permissions of a service account
+  - @remarks A SelfSubjectRulesReview is created while impersonating the service account
+  - @param ns Namespace of the service account
+  - @param sa Name of the service account
+  - @param reviewNS Namespace where permissions are evaluated, cluster-wide ones are always included
+  - @returns Sorted permissions as group/resource[/name]:verb or url:verb, or an error
+*/
+func Permissions(ns, sa, reviewNS string) ([]string, error) {
+	f, err := os.CreateTemp("", "rules-review-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = fmt.Fprintf(f, `{"apiVersion": "authorization.k8s.io/v1", "kind": "SelfSubjectRulesReview", "spec": {"namespace": %q}}`, reviewNS)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := cluster.Default.KubectlWithoutErr("create", "-f", f.Name(), "-o", "json",
+		"--as", "system:serviceaccount:"+ns+":"+sa)
+	if err != nil {
+		return nil, err
+	}
+
+	var r review
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		return nil, err
+	}
+	if r.Status.Incomplete {
+		return nil, fmt.Errorf("incomplete rules review of %s/%s", ns, sa)
+	}
+
+	var perms []string
+	for _, rule := range r.Status.ResourceRules {
+		names := rule.ResourceNames
+		if len(names) == 0 {
+			names = []string{""}
+		}
+
+		for _, group := range rule.APIGroups {
+			if group == "" {
+				group = "core"
+			}
+			for _, res := range rule.Resources {
+				for _, name := range names {
+					for _, verb := range rule.Verbs {
+						perms = append(perms, path.Join(group, res, name)+":"+verb)
+					}
+				}
+			}
+		}
+	}
+	for _, rule := range r.Status.NonResourceRules {
+		for _, url := range rule.NonResourceURLs {
+			for _, verb := range rule.Verbs {
+				perms = append(perms, url+":"+verb)
+			}
+		}
+	}
+
+	slices.Sort(perms)
+	return slices.Compact(perms), nil
+}
+
+/*
+Load a golden permissions file
+  - @param file YAML file, component -> scope -> permission patterns
+  - @returns The golden permissions or an error
+*/
+func Load(file string) (Golden, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	g := Golden{}
+	return g, yaml.Unmarshal(data, &g)
+}
+
+/*
+Write a golden permissions file
+  - @param file Destination YAML file
+  - @returns Nothing or an error
+*/
+func (g Golden) Write(file string) error {
+	data, err := yaml.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+/*
+Get the permissions not allowed by the golden file
+  - @param component Component of the golden file
+  - @param scope Scope of the golden file, namespace or cluster
+  - @param perms Actual permissions
+  - @returns Permissions matching none of the golden patterns, empty if none
+*/
+func (g Golden) Unexpected(component, scope string, perms []string) []string {
+	patterns := g[component][scope]
+
+	var unexpected []string
+	for _, p := range perms {
+		if !slices.ContainsFunc(patterns, func(pattern string) bool {
+			ok, _ := path.Match(pattern, p)
+			return ok
+		}) {
+			unexpected = append(unexpected, p)
+		}
+	}
+
+	return unexpected
+}
+
+/*
+Get the golden patterns not used anymore
+  - @remarks Useful to tighten the golden file, not a failure
+  - @param component Component of the golden file
+  - @param scope Scope of the golden file, namespace or cluster
+  - @param perms Actual permissions
+  - @returns Patterns matching none of the permissions, empty if none
+*/
+func (g Golden) Unused(component, scope string, perms []string) []string {
+	var unused []string
+	for _, pattern := range g[component][scope] {
+		if !slices.ContainsFunc(perms, func(p string) bool {
+			ok, _ := path.Match(pattern, p)
+			return ok
+		}) {
+			unused = append(unused, pattern)
+		}
+	}
+
+	return unused
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/rbac"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+)
+
+// Rules review of the controller service account
+const review = `{"apiVersion": "authorization.k8s.io/v1", "kind": "SelfSubjectRulesReview", "status": {
+  "resourceRules": [
+    {"verbs": ["get", "list"], "apiGroups": [""], "resources": ["secrets", "services"]},
+    {"verbs": ["update"], "apiGroups": ["policies.kubewarden.io"], "resources": ["policyservers/status"]},
+    {"verbs": ["get"], "apiGroups": ["coordination.k8s.io"], "resources": ["leases"], "resourceNames": ["a4ddbf36.kubewarden.io"]},
+    {"verbs": ["list"], "apiGroups": [""], "resources": ["secrets"]}
+  ],
+  "nonResourceRules": [{"verbs": ["get"], "nonResourceURLs": ["/healthz", "/version"]}],
+  "incomplete": false
+}}`
+
+func setup(t *testing.T, stdout string) {
+	t.Helper()
+
+	// The review is created from a temporary file, its key depends on the content only
+	request := filepath.Join(t.TempDir(), "rules-review.json")
+	content := `{"apiVersion": "authorization.k8s.io/v1", "kind": "SelfSubjectRulesReview", "spec": {"namespace": "kubewarden"}}`
+	if err := os.WriteFile(request, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"kubectl", "create", "-f", request, "-o", "json", "--as", "system:serviceaccount:kubewarden:kubewarden-controller"}
+	replay.ForTest(t, replay.Output{Args: args, Stdout: stdout})
+}
+
+func TestPermissions(t *testing.T) {
+	setup(t, review)
+
+	got, err := rbac.Permissions("kubewarden", "kubewarden-controller", "kubewarden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"/healthz:get",
+		"/version:get",
+		"coordination.k8s.io/leases/a4ddbf36.kubewarden.io:get",
+		"core/secrets:get",
+		"core/secrets:list",
+		"core/services:get",
+		"core/services:list",
+		"policies.kubewarden.io/policyservers/status:update",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("permissions are %v, %v expected", got, want)
+	}
+}
+
+func TestPermissionsIncomplete(t *testing.T) {
+	setup(t, `{"status": {"resourceRules": [], "incomplete": true}}`)
+
+	if got, err := rbac.Permissions("kubewarden", "kubewarden-controller", "kubewarden"); err == nil {
+		t.Errorf("no error, permissions are %v", got)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	g := rbac.Golden{"controller": {
+		"namespace": {"core/secrets:*", "core/services:get", "coordination.k8s.io/leases/*:get"},
+		"cluster":   {"/healthz:get"},
+	}}
+	perms := []string{"core/secrets:get", "core/secrets:list", "core/services:list", "coordination.k8s.io/leases/a4ddbf36.kubewarden.io:get"}
+
+	tests := []struct {
+		name, component, scope string
+		unexpected, unused     []string
+	}{
+		{"namespace", "controller", "namespace", []string{"core/services:list"}, []string{"core/services:get"}},
+		{"other scope", "controller", "cluster", perms, []string{"/healthz:get"}},
+		{"unknown component", "audit-scanner", "namespace", perms, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Unexpected(tt.component, tt.scope, perms); !slices.Equal(got, tt.unexpected) {
+				t.Errorf("unexpected permissions are %v, %v expected", got, tt.unexpected)
+			}
+			if got := g.Unused(tt.component, tt.scope, perms); !slices.Equal(got, tt.unused) {
+				t.Errorf("unused patterns are %v, %v expected", got, tt.unused)
+			}
+		})
+	}
+}
+
+func TestLoadWrite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rbac.yaml")
+	g := rbac.Golden{"policy-server": {"namespace": {"core/configmaps:get"}}}
+
+	if err := g.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := rbac.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded["policy-server"]["namespace"], []string{"core/configmaps:get"}) {
+		t.Errorf("loaded golden permissions are %v", loaded)
+	}
+
+	if _, err := rbac.Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("missing golden file is loaded")
+	}
+}
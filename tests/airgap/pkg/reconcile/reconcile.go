@@ -0,0 +1,274 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+)
+
+// API group of the Kubewarden resources
+const apiGroup = "policies.kubewarden.io"
+
+// Issue is a resource not reconciled yet
+type Issue struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Reason    string
+}
+
+// Common fields of the Kubewarden resources
+type resource struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Namespace         string  `json:"namespace"`
+		Name              string  `json:"name"`
+		DeletionTimestamp *string `json:"deletionTimestamp"`
+	} `json:"metadata"`
+	Status struct {
+		PolicyStatus string `json:"policyStatus"`
+		Conditions   []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// Webhook configurations, only what is needed to find the services and their CA
+type webhookConfigurations struct {
+	Items []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Webhooks []struct {
+			Name         string `json:"name"`
+			ClientConfig struct {
+				CABundle []byte `json:"caBundle"`
+				Service  *struct {
+					Namespace string `json:"namespace"`
+					Name      string `json:"name"`
+				} `json:"service"`
+			} `json:"clientConfig"`
+		} `json:"webhooks"`
+	} `json:"items"`
+}
+
+/*
+Get an issue in a readable format
+  - @returns Kind, namespace/name and reason of the issue
+*/
+func (i Issue) String() string {
+	name := i.Name
+	if i.Namespace != "" {
+		name = i.Namespace + "/" + name
+	}
+
+	return fmt.Sprintf("%s %s: %s", i.Kind, name, i.Reason)
+}
+
+/*
+Get a list of issues in a readable format
+  - @param issues Issues to format
+  - @returns One issue per line
+*/
+func Format(issues []Issue) string {
+	var b strings.Builder
+	for _, i := range issues {
+		b.WriteString(i.String() + "\n")
+	}
+
+	return b.String()
+}
+
+/*
+Check that all the Kubewarden resources have been reconciled
+  - @remarks Resources being deleted are ignored, their status is not updated anymore
+  - @returns Resources not reconciled yet, or an error if they cannot be listed
+*/
+func Check() ([]Issue, error) {
+	out, err := cluster.Default.KubectlWithoutErr("api-resources", "--api-group", apiGroup, "-o", "name")
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, kind := range strings.Fields(out) {
+		found, err := checkResources(kind)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+
+	found, err := checkWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(issues, found...), nil
+}
+
+/*
+Check the status of all the resources of a kind
+  - @remarks This function is only used internally, not exported
+  - @param kind Kind of the resources, e.g. policyservers.policies.kubewarden.io
+  - @returns Resources not reconciled yet, or an error
+*/
+func checkResources(kind string) ([]Issue, error) {
+	out, err := cluster.Default.KubectlWithoutErr("get", kind, "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []resource `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", kind, err)
+	}
+
+	var issues []Issue
+	for _, r := range list.Items {
+		if r.Metadata.DeletionTimestamp != nil {
+			continue
+		}
+
+		issue := Issue{Kind: r.Kind, Namespace: r.Metadata.Namespace, Name: r.Metadata.Name}
+
+		// Policy servers have conditions, policies and policy groups have a status
+		if r.Kind == "PolicyServer" {
+			if len(r.Status.Conditions) == 0 {
+				issue.Reason = "no condition"
+				issues = append(issues, issue)
+			}
+			for _, c := range r.Status.Conditions {
+				if c.Status != "True" {
+					issue.Reason = fmt.Sprintf("condition %s is %s: %s", c.Type, c.Status, c.Message)
+					issues = append(issues, issue)
+				}
+			}
+		} else if r.Status.PolicyStatus != "active" {
+			issue.Reason = fmt.Sprintf("policy status is %q", r.Status.PolicyStatus)
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+/*
+Check that the Kubewarden webhooks reference live services, served with a certificate of their caBundle
+  - @remarks This function is only used internally, not exported
+  - @returns Webhooks not reconciled yet, or an error
+*/
+func checkWebhooks() ([]Issue, error) {
+	out, err := cluster.Default.KubectlWithoutErr("get", "validatingwebhookconfigurations,mutatingwebhookconfigurations", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list webhookConfigurations
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse webhook configurations: %w", err)
+	}
+
+	var issues []Issue
+	for _, config := range list.Items {
+		for _, webhook := range config.Webhooks {
+			// Webhooks of the controller itself are not reconciled by it
+			if !strings.HasSuffix(webhook.Name, ".kubewarden.admission") {
+				continue
+			}
+
+			issue := Issue{Kind: config.Kind, Name: config.Metadata.Name}
+			svc := webhook.ClientConfig.Service
+			if svc == nil {
+				issue.Reason = "webhook " + webhook.Name + " has no service"
+				issues = append(issues, issue)
+				continue
+			}
+
+			if err := checkService(svc.Namespace, svc.Name, webhook.ClientConfig.CABundle); err != nil {
+				issue.Reason = fmt.Sprintf("webhook %s: %v", webhook.Name, err)
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+/*
+Check that a service has endpoints and that its serving certificate is signed by a CA
+  - @remarks This function is only used internally, not exported
+  - @param ns Namespace of the service
+  - @param name Name of the service, also the name of its certificate secret
+  - @param caBundle PEM encoded CA of the webhook
+  - @returns An error if the service cannot be used by the webhook
+*/
+func checkService(ns, name string, caBundle []byte) error {
+	out, err := cluster.Default.KubectlWithoutErr("get", "endpoints", name, "--namespace", ns,
+		"-o", "jsonpath={.subsets[*].addresses[*].ip}")
+	if err != nil {
+		return fmt.Errorf("service %s/%s not found: %w", ns, name, err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return fmt.Errorf("service %s/%s has no ready endpoint", ns, name)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return errors.New("caBundle has no certificate")
+	}
+
+	out, err = cluster.Default.KubectlWithoutErr("get", "secret", name, "--namespace", ns, "-o", `jsonpath={.data.tls\.crt}`)
+	if err != nil {
+		return fmt.Errorf("serving certificate secret %s/%s not found: %w", ns, name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(out)
+	if err != nil {
+		return fmt.Errorf("cannot decode the serving certificate of %s/%s: %w", ns, name, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("serving certificate of %s/%s is not PEM encoded", ns, name)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("cannot parse the serving certificate of %s/%s: %w", ns, name, err)
+	}
+
+	// The API server reaches the service through its DNS name
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		DNSName:   name + "." + ns + ".svc",
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("serving certificate of %s/%s does not match the caBundle: %w", ns, name, err)
+	}
+
+	return nil
+}
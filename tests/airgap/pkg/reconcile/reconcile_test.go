@@ -0,0 +1,221 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/reconcile"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+)
+
+const (
+	policyServers = `{"items": [
+  {"kind": "PolicyServer", "metadata": {"name": "default"},
+   "status": {"conditions": [{"type": "ServiceReconciled", "status": "True"}, {"type": "DeploymentReconciled", "status": "True"}]}},
+  {"kind": "PolicyServer", "metadata": {"name": "deleted", "deletionTimestamp": "2025-01-01T00:00:00Z"}}
+]}`
+	policies = `{"items": [
+  {"kind": "ClusterAdmissionPolicy", "metadata": {"name": "no-privileged-pod"}, "status": {"policyStatus": "active"}}
+]}`
+	webhooksTemplate = `{"items": [
+  {"kind": "ValidatingWebhookConfiguration", "metadata": {"name": "clusterwide-no-privileged-pod"}, "webhooks": [
+    {"name": "clusterwide-no-privileged-pod.kubewarden.admission",
+     "clientConfig": {"caBundle": %q, "service": {"namespace": "kubewarden", "name": "policy-server-default"}}}
+  ]},
+  {"kind": "ValidatingWebhookConfiguration", "metadata": {"name": "kubewarden-controller"}, "webhooks": [
+    {"name": "vpolicyserver.kb.io", "clientConfig": {"url": "https://controller"}}
+  ]}
+]}`
+)
+
+// PEM encoded certificate and key pair
+type pair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// Create a certificate, self-signed if parent is nil
+func certificate(t *testing.T, name string, parent *pair) *pair {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		tmpl.DNSNames = []string{name}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &pair{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// State of the cluster returned by the replayed commands
+type state struct {
+	policyServers, policies string
+	caBundle, servingCert   []byte
+	endpoints               string
+}
+
+func setup(t *testing.T, s state) {
+	t.Helper()
+
+	kubectl := func(stdout string, args ...string) replay.Output {
+		return replay.Output{Args: append([]string{"kubectl"}, args...), Stdout: stdout}
+	}
+	replay.ForTest(t,
+		kubectl("policyservers.policies.kubewarden.io\nclusteradmissionpolicies.policies.kubewarden.io\n",
+			"api-resources", "--api-group", "policies.kubewarden.io", "-o", "name"),
+		kubectl(s.policyServers, "get", "policyservers.policies.kubewarden.io", "--all-namespaces", "-o", "json"),
+		kubectl(s.policies, "get", "clusteradmissionpolicies.policies.kubewarden.io", "--all-namespaces", "-o", "json"),
+		kubectl(fmt.Sprintf(webhooksTemplate, base64.StdEncoding.EncodeToString(s.caBundle)),
+			"get", "validatingwebhookconfigurations,mutatingwebhookconfigurations", "-o", "json"),
+		kubectl(s.endpoints,
+			"get", "endpoints", "policy-server-default", "--namespace", "kubewarden", "-o", "jsonpath={.subsets[*].addresses[*].ip}"),
+		kubectl(base64.StdEncoding.EncodeToString(s.servingCert),
+			"get", "secret", "policy-server-default", "--namespace", "kubewarden", "-o", `jsonpath={.data.tls\.crt}`),
+	)
+}
+
+func TestCheck(t *testing.T) {
+	ca := certificate(t, "kubewarden-ca", nil)
+	serving := certificate(t, "policy-server-default.kubewarden.svc", ca).pem
+	other := certificate(t, "other-ca", nil)
+
+	reconciled := state{
+		policyServers: policyServers,
+		policies:      policies,
+		caBundle:      ca.pem,
+		servingCert:   serving,
+		endpoints:     "10.42.0.12",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*state)
+		issues string
+		// The verification errors come from crypto/x509, only their prefix is checked
+		prefix bool
+	}{
+		{"reconciled", func(*state) {}, "", false},
+		{
+			"pending policy",
+			func(s *state) {
+				s.policies = `{"items": [{"kind": "ClusterAdmissionPolicy", "metadata": {"name": "no-privileged-pod"}, "status": {"policyStatus": "pending"}}]}`
+			},
+			"ClusterAdmissionPolicy no-privileged-pod: policy status is \"pending\"\n",
+			false,
+		},
+		{
+			"policy server without condition",
+			func(s *state) {
+				s.policyServers = `{"items": [{"kind": "PolicyServer", "metadata": {"name": "default"}}]}`
+			},
+			"PolicyServer default: no condition\n",
+			false,
+		},
+		{
+			"failed condition",
+			func(s *state) {
+				s.policyServers = `{"items": [{"kind": "PolicyServer", "metadata": {"name": "default"},
+  "status": {"conditions": [{"type": "DeploymentReconciled", "status": "False", "message": "quota exceeded"}]}}]}`
+			},
+			"PolicyServer default: condition DeploymentReconciled is False: quota exceeded\n",
+			false,
+		},
+		{
+			"no endpoint",
+			func(s *state) { s.endpoints = "" },
+			"ValidatingWebhookConfiguration clusterwide-no-privileged-pod: webhook clusterwide-no-privileged-pod.kubewarden.admission: " +
+				"service kubewarden/policy-server-default has no ready endpoint\n",
+			false,
+		},
+		{
+			"empty caBundle",
+			func(s *state) { s.caBundle = nil },
+			"ValidatingWebhookConfiguration clusterwide-no-privileged-pod: webhook clusterwide-no-privileged-pod.kubewarden.admission: " +
+				"caBundle has no certificate\n",
+			false,
+		},
+		{
+			"certificate of another CA",
+			func(s *state) { s.caBundle = other.pem },
+			"ValidatingWebhookConfiguration clusterwide-no-privileged-pod: webhook clusterwide-no-privileged-pod.kubewarden.admission: " +
+				"serving certificate of kubewarden/policy-server-default does not match the caBundle: ",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := reconciled
+			tt.modify(&s)
+			setup(t, s)
+
+			issues, err := reconcile.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := reconcile.Format(issues)
+
+			if tt.prefix && len(issues) == 1 && strings.HasPrefix(got, tt.issues) {
+				return
+			}
+			if got != tt.issues {
+				t.Errorf("issues are:\n%s\nexpected:\n%s", got, tt.issues)
+			}
+		})
+	}
+}
+
+func TestCheckInvalidOutput(t *testing.T) {
+	setup(t, state{policyServers: "No resources found", policies: "{}", endpoints: "10.42.0.12"})
+
+	if issues, err := reconcile.Check(); err == nil {
+		t.Errorf("no error, issues are %v", issues)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Mode of the harness
+type Mode string
+
+const (
+	// Commands are executed and their outputs saved as fixtures
+	Record Mode = "record"
+	// Fixtures are returned instead of executing the commands
+	Replay Mode = "replay"
+)
+
+// Environment variables used by the shims
+const (
+	modeEnv     = "E2E_REPLAY_MODE"
+	fixturesEnv = "E2E_REPLAY_FIXTURES"
+	stateEnv    = "E2E_REPLAY_STATE"
+	pathEnv     = "E2E_REPLAY_PATH"
+)
+
+// Binaries replaced by a shim, helpers of ele-testhelpers call them through PATH
+var Binaries = []string{"kubectl", "helm"}
+
+// Exit code of a command without fixture
+const MissingFixture = 97
+
+// Output is a replayed execution of a command, for the unit tests
+type Output struct {
+	// Command line, the binary first
+	Args   []string
+	Stdout string
+	Stderr string
+	Code   int
+}
+
+// Fixtures are named <binary>-<key>.<n>.{out,err,code}, n being the occurrence of the command,
+// manifests and values files are identified by their content as their paths are temporary
+const shimScript = `#!/bin/bash
+name=$(basename "$0")
+key_args=()
+prev=""
+for arg in "$name" "$@"; do
+  if [[ $prev == -f || $prev == --filename || $prev == --values ]] && [[ -f $arg ]]; then
+    key_args+=("file:$(sha256sum < "$arg" | cut -c1-16)")
+  else
+    key_args+=("$arg")
+  fi
+  prev=$arg
+done
+key=$(printf '%s\0' "${key_args[@]}" | sha256sum | cut -c1-16)
+prefix="$E2E_REPLAY_FIXTURES/$name-$key"
+
+case $E2E_REPLAY_MODE in
+  record)
+    n=$(ls "$prefix".*.code 2>/dev/null | wc -l)
+    printf '%q ' "$name" "$@" > "$prefix.cmd"
+    PATH=$E2E_REPLAY_PATH "$name" "$@" > "$prefix.$n.out" 2> "$prefix.$n.err"
+    code=$?
+    echo "$code" > "$prefix.$n.code"
+    ;;
+  replay)
+    # Polling loops get the next output, then the last one again
+    state="$E2E_REPLAY_STATE/$name-$key"
+    n=$(cat "$state" 2>/dev/null || echo 0)
+    if [[ -f $prefix.$n.code ]]; then
+      echo $((n + 1)) > "$state"
+    else
+      n=$((n - 1))
+    fi
+    if [[ ! -f $prefix.$n.code ]]; then
+      echo "no fixture for: $name $*" >&2
+      exit 97
+    fi
+    ;;
+esac
+
+cat "$prefix.$n.out"
+cat "$prefix.$n.err" >&2
+exit "$(cat "$prefix.$n.code")"
+`
+
+/*
+Replace kubectl and helm by shims recording or replaying their interactions
+  - @remarks Recording is not safe with parallel processes, occurrences of a same command could be mixed
+  - @param mode Record or Replay
+  - @param fixtures Directory of the fixtures, created if needed
+  - @param dir Directory where the shims and the replay state are created
+  - @returns Function restoring the environment, or an error
+*/
+func Setup(mode Mode, fixtures, dir string) (func(), error) {
+	return setup(mode, fixtures, dir, Binaries)
+}
+
+/*
+Replace kubectl, helm and some other binaries by shims
+  - @remarks This function is only used internally, not exported
+  - @param mode Record or Replay
+  - @param fixtures Directory of the fixtures, created if needed
+  - @param dir Directory where the shims and the replay state are created
+  - @param binaries Binaries to replace
+  - @returns Function restoring the environment, or an error
+*/
+func setup(mode Mode, fixtures, dir string, binaries []string) (func(), error) {
+	if mode != Record && mode != Replay {
+		return nil, fmt.Errorf("unknown replay mode %q", mode)
+	}
+
+	bin := filepath.Join(dir, "bin")
+	state := filepath.Join(dir, "state")
+	for _, d := range []string{bin, state, fixtures} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range binaries {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(shimScript), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	fixtures, err := filepath.Abs(fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{
+		modeEnv:     string(mode),
+		fixturesEnv: fixtures,
+		stateEnv:    state,
+		pathEnv:     os.Getenv("PATH"),
+		"PATH":      bin + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+
+	previous := map[string]*string{}
+	for name, value := range env {
+		if old, ok := os.LookupEnv(name); ok {
+			previous[name] = &old
+		} else {
+			previous[name] = nil
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return func() {
+		for name, old := range previous {
+			if old == nil {
+				_ = os.Unsetenv(name)
+			} else {
+				_ = os.Setenv(name, *old)
+			}
+		}
+	}, nil
+}
+
+/*
+Write a fixture without recording it
+  - @remarks Used by unit tests for the outputs that are hard to get from a cluster, like errors
+  - @param fixtures Directory of the fixtures
+  - @param args Command line, the binary first
+  - @param stdout Standard output of the command
+  - @param stderr Error output of the command
+  - @param code Exit code of the command
+  - @returns Nothing or an error
+*/
+func Fixture(fixtures string, args []string, stdout, stderr string, code int) error {
+	if err := os.MkdirAll(fixtures, 0755); err != nil {
+		return err
+	}
+
+	prefix := filepath.Join(fixtures, args[0]+"-"+Key(args))
+	matches, err := filepath.Glob(prefix + ".*.code")
+	if err != nil {
+		return err
+	}
+	base := fmt.Sprintf("%s.%d", prefix, len(matches))
+
+	files := map[string]string{
+		".out":  stdout,
+		".err":  stderr,
+		".code": strconv.Itoa(code) + "\n",
+	}
+	for ext, content := range files {
+		if err := os.WriteFile(base+ext, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(prefix+".cmd", []byte(strings.Join(args, " ")+" "), 0644)
+}
+
+/*
+Replay some outputs during a unit test
+  - @remarks The binaries of the outputs (e.g. sudo, skopeo) are replaced too, the environment is restored at the end of the test
+  - @param t Test using the outputs
+  - @param outputs Outputs of the commands, in the order they are returned for a same command line
+  - @returns Directory of the fixtures, the test fails if they cannot be set up
+*/
+func ForTest(t testing.TB, outputs ...Output) string {
+	t.Helper()
+
+	fixtures := t.TempDir()
+	binaries := slices.Clone(Binaries)
+	for _, o := range outputs {
+		if err := Fixture(fixtures, o.Args, o.Stdout, o.Stderr, o.Code); err != nil {
+			t.Fatalf("cannot write fixture of %s: %v", strings.Join(o.Args, " "), err)
+		}
+		if !slices.Contains(binaries, o.Args[0]) {
+			binaries = append(binaries, o.Args[0])
+		}
+	}
+
+	restore, err := setup(Replay, fixtures, t.TempDir(), binaries)
+	if err != nil {
+		t.Fatalf("cannot set up the replay: %v", err)
+	}
+	t.Cleanup(restore)
+
+	return fixtures
+}
+
+/*
+Get the key of a command line, as computed by the shims
+  - @param args Command line, the binary first
+  - @returns Key of the fixtures of the command
+*/
+func Key(args []string) string {
+	h := sha256.New()
+
+	prev := ""
+	for _, arg := range args {
+		part := arg
+		if prev == "-f" || prev == "--filename" || prev == "--values" {
+			if data, err := os.ReadFile(arg); err == nil {
+				sum := sha256.Sum256(data)
+				part = "file:" + hex.EncodeToString(sum[:])[:16]
+			}
+		}
+		h.Write([]byte(part + "\x00"))
+		prev = arg
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+)
+
+// Real kubectl of the recording, each call returns a new output
+const fakeKubectl = `#!/bin/bash
+n=$(cat "$0.count" 2>/dev/null || echo 0)
+echo $((n + 1)) > "$0.count"
+if [[ $1 == fail ]]; then
+  echo "error: the server doesn't have a resource type" >&2
+  exit 1
+fi
+echo "call $n: $*"
+`
+
+// Result of a command
+type result struct {
+	out  string
+	code int
+}
+
+func run(t *testing.T, args ...string) result {
+	t.Helper()
+
+	out, err := exec.Command("kubectl", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return result{string(out), exitErr.ExitCode()}
+	}
+	if err != nil {
+		t.Fatalf("cannot run kubectl: %v", err)
+	}
+
+	return result{string(out), 0}
+}
+
+func setup(t *testing.T, mode replay.Mode, fixtures string) {
+	t.Helper()
+
+	restore, err := replay.Setup(mode, fixtures, t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot set up %s: %v", mode, err)
+	}
+	t.Cleanup(restore)
+}
+
+func TestRecordReplay(t *testing.T) {
+	fixtures := t.TempDir()
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(manifest, []byte("kind: Namespace\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := [][]string{
+		{"get", "pods"},
+		{"get", "pods"},
+		{"fail"},
+		{"apply", "-f", manifest},
+	}
+
+	// Recording with the fake kubectl in PATH
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "kubectl"), []byte(fakeKubectl), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	setup(t, replay.Record, fixtures)
+	var recorded []result
+	for _, args := range calls {
+		recorded = append(recorded, run(t, args...))
+	}
+
+	if recorded[0].out == recorded[1].out {
+		t.Fatalf("fake kubectl should return a new output for each call, got %q twice", recorded[0].out)
+	}
+	if recorded[2].code != 1 {
+		t.Fatalf("recorded exit code is %d, 1 expected", recorded[2].code)
+	}
+
+	// Replaying without any kubectl, the manifest moves to another temporary path
+	t.Setenv("PATH", "/usr/bin:/bin")
+	moved := filepath.Join(t.TempDir(), "other.yaml")
+	if err := os.Rename(manifest, moved); err != nil {
+		t.Fatal(err)
+	}
+	calls[3][2] = moved
+
+	setup(t, replay.Replay, fixtures)
+	for i, args := range calls {
+		if got := run(t, args...); got != recorded[i] {
+			t.Errorf("replay of %v: got %+v, recorded %+v", args, got, recorded[i])
+		}
+	}
+
+	// Polling loops keep the last output
+	if got := run(t, "get", "pods"); got != recorded[1] {
+		t.Errorf("replay after the last occurrence: got %+v, %+v expected", got, recorded[1])
+	}
+}
+
+func TestMissingFixture(t *testing.T) {
+	fixtures := t.TempDir()
+	setup(t, replay.Replay, fixtures)
+
+	if got := run(t, "get", "nodes"); got.code != replay.MissingFixture {
+		t.Errorf("command without fixture exited with %d, %d expected", got.code, replay.MissingFixture)
+	}
+}
+
+func TestFixture(t *testing.T) {
+	fixtures := t.TempDir()
+	if err := replay.Fixture(fixtures, []string{"kubectl", "get", "ns", "-o", "name"}, "namespace/kubewarden\n", "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := replay.Fixture(fixtures, []string{"kubectl", "get", "ns", "-o", "name"}, "", "timeout\n", 1); err != nil {
+		t.Fatal(err)
+	}
+	setup(t, replay.Replay, fixtures)
+
+	want := []result{{"namespace/kubewarden\n", 0}, {"", 1}}
+	for _, w := range want {
+		if got := run(t, "get", "ns", "-o", "name"); got != w {
+			t.Errorf("got %+v, %+v expected", got, w)
+		}
+	}
+}
+
+func TestForTest(t *testing.T) {
+	ns := []string{"kubectl", "get", "ns", "-o", "name"}
+	fixtures := replay.ForTest(t,
+		replay.Output{Args: ns, Stdout: "namespace/kubewarden\n"},
+		replay.Output{Args: ns, Stderr: "timeout\n", Code: 1},
+		replay.Output{Args: []string{"uname", "-m"}, Stdout: "aarch64\n"},
+	)
+
+	want := []result{{"namespace/kubewarden\n", 0}, {"", 1}, {"", 1}}
+	for _, w := range want {
+		if got := run(t, "get", "ns", "-o", "name"); got != w {
+			t.Errorf("got %+v, %+v expected", got, w)
+		}
+	}
+
+	// Binaries of the outputs are replaced too
+	if out, err := exec.Command("uname", "-m").Output(); err != nil || string(out) != "aarch64\n" {
+		t.Errorf("uname returned %q (%v)", out, err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(fixtures, "uname-*.0.out")); len(matches) != 1 {
+		t.Errorf("fixtures of uname are %v", matches)
+	}
+}
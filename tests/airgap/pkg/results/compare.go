@@ -0,0 +1,267 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package results
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// States of the specs counted as failures, as given by ginkgo
+var failureStates = []string{"failed", "panicked", "interrupted", "aborted", "timedout"}
+
+// Change is a value that differs between two runs, empty if missing in one of them
+type Change struct {
+	Name      string `json:"name"`
+	Base      string `json:"base"`
+	Candidate string `json:"candidate"`
+}
+
+// MetricChange is a performance number that differs between two runs
+type MetricChange struct {
+	Metric    string  `json:"metric"`
+	Unit      string  `json:"unit"`
+	Base      float64 `json:"base"`
+	Candidate float64 `json:"candidate"`
+	// Relative change, in percent of the base value
+	Percent   float64 `json:"percent"`
+	Regressed bool    `json:"regressed"`
+}
+
+// Delta is what changed between two runs, e.g. the last release candidate and a new one
+type Delta struct {
+	Base      string `json:"base"`
+	Candidate string `json:"candidate"`
+	// Spec names that passed in the base run and fail in the candidate, or new failing ones
+	Regressions []string       `json:"regressions"`
+	Fixed       []string       `json:"fixed"`
+	Versions    []Change       `json:"versions"`
+	Specs       []Change       `json:"specs"`
+	Metrics     []MetricChange `json:"metrics"`
+}
+
+/*
+Compare two runs
+  - @remarks Each run can have several summaries, one per suite; the last state of a spec is kept and metrics are averaged
+  - @remarks Metrics are durations or latencies, only an increase above the threshold is a regression
+  - @param base Summaries of the reference run
+  - @param candidate Summaries of the compared run
+  - @param threshold Accepted increase of the metrics, in percent
+  - @returns The delta
+*/
+func Compare(base, candidate []Summary, threshold float64) *Delta {
+	d := &Delta{
+		Base:        runIDs(base),
+		Candidate:   runIDs(candidate),
+		Regressions: []string{},
+		Fixed:       []string{},
+		Versions:    changes(versions(base), versions(candidate)),
+		Specs:       changes(states(base), states(candidate)),
+		Metrics:     []MetricChange{},
+	}
+
+	for _, c := range d.Specs {
+		switch {
+		case slices.Contains(failureStates, c.Candidate) && !slices.Contains(failureStates, c.Base):
+			d.Regressions = append(d.Regressions, c.Name)
+		case slices.Contains(failureStates, c.Base) && c.Candidate == "passed":
+			d.Fixed = append(d.Fixed, c.Name)
+		}
+	}
+
+	baseMetrics, candidateMetrics := metrics(base), metrics(candidate)
+	for _, name := range slices.Sorted(maps.Keys(candidateMetrics)) {
+		b, ok := baseMetrics[name]
+		c := candidateMetrics[name]
+		if !ok || b.Value == c.Value {
+			continue
+		}
+
+		mc := MetricChange{Metric: name, Unit: c.Unit, Base: b.Value, Candidate: c.Value}
+		if b.Value != 0 {
+			mc.Percent = 100 * (c.Value - b.Value) / b.Value
+		}
+		mc.Regressed = mc.Percent > threshold
+		if mc.Regressed {
+			d.Regressions = append(d.Regressions, "metric "+name)
+		}
+		d.Metrics = append(d.Metrics, mc)
+	}
+
+	return d
+}
+
+/*
+Check if the candidate run regressed
+  - @returns True if a spec or a metric regressed
+*/
+func (d *Delta) Regressed() bool {
+	return len(d.Regressions) > 0
+}
+
+/*
+Get the delta in a human-readable form
+  - @returns Text of the delta, only the sections with changes
+*/
+func (d *Delta) Text() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Base: %s\nCandidate: %s\n", d.Base, d.Candidate)
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	format := func(changes []Change) []string {
+		var lines []string
+		for _, c := range changes {
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", c.Name, missing(c.Base), missing(c.Candidate)))
+		}
+		return lines
+	}
+
+	section("Regressions", d.Regressions)
+	section("Fixed", d.Fixed)
+	section("Versions", format(d.Versions))
+	section("Specs", format(d.Specs))
+
+	var lines []string
+	for _, m := range d.Metrics {
+		flag := ""
+		if m.Regressed {
+			flag = " REGRESSED"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %g -> %g %s (%+.1f%%)%s", m.Metric, m.Base, m.Candidate, m.Unit, m.Percent, flag))
+	}
+	section("Metrics", lines)
+
+	if !d.Regressed() {
+		b.WriteString("\nNo regression\n")
+	}
+
+	return b.String()
+}
+
+/*
+Get the IDs of the runs of summaries
+  - @remarks This function is only used internally, not exported
+  - @param summaries Summaries of a run
+  - @returns Run IDs, comma separated
+*/
+func runIDs(summaries []Summary) string {
+	var ids []string
+	for _, s := range summaries {
+		if !slices.Contains(ids, s.RunID) {
+			ids = append(ids, s.RunID)
+		}
+	}
+	return strings.Join(ids, ", ")
+}
+
+/*
+Get the versions of a run, the last summary wins
+  - @remarks This function is only used internally, not exported
+  - @param summaries Summaries of a run, sorted by date
+  - @returns Versions by component
+*/
+func versions(summaries []Summary) map[string]string {
+	out := map[string]string{}
+	for _, s := range summaries {
+		maps.Copy(out, s.Versions)
+	}
+	return out
+}
+
+/*
+Get the state of the specs of a run, the last summary wins
+  - @remarks This function is only used internally, not exported
+  - @param summaries Summaries of a run, sorted by date
+  - @returns States by spec name, flaky passed specs are passed
+*/
+func states(summaries []Summary) map[string]string {
+	out := map[string]string{}
+	for _, s := range summaries {
+		for _, spec := range s.Specs {
+			out[spec.Name] = spec.State
+		}
+	}
+	return out
+}
+
+/*
+Get the average of each metric of a run
+  - @remarks This function is only used internally, not exported
+  - @param summaries Summaries of a run
+  - @returns Metrics by name
+*/
+func metrics(summaries []Summary) map[string]Metric {
+	sums := map[string]Metric{}
+	counts := map[string]int{}
+	for _, s := range summaries {
+		for _, m := range s.Metrics {
+			sum := sums[m.Metric]
+			sum.Metric, sum.Unit = m.Metric, m.Unit
+			sum.Value += m.Value
+			sums[m.Metric] = sum
+			counts[m.Metric]++
+		}
+	}
+
+	for name, m := range sums {
+		m.Value /= float64(counts[name])
+		sums[name] = m
+	}
+	return sums
+}
+
+/*
+Get the values that differ between two runs
+  - @remarks This function is only used internally, not exported
+  - @param base Values of the reference run
+  - @param candidate Values of the compared run
+  - @returns Changes sorted by name
+*/
+func changes(base, candidate map[string]string) []Change {
+	out := []Change{}
+
+	all := maps.Clone(base)
+	maps.Copy(all, candidate)
+	for _, name := range slices.Sorted(maps.Keys(all)) {
+		if base[name] != candidate[name] {
+			out = append(out, Change{Name: name, Base: base[name], Candidate: candidate[name]})
+		}
+	}
+	return out
+}
+
+/*
+Get a displayed value, missing if empty
+  - @remarks This function is only used internally, not exported
+  - @param value Value of a change
+  - @returns The value
+*/
+func missing(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package results_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/results"
+)
+
+func TestCompare(t *testing.T) {
+	base := []results.Summary{{
+		RunID:    "rc1",
+		Suite:    "smoke",
+		Versions: map[string]string{"K3s": "v1.31.1+k3s1", "kubewarden-controller": "kubewarden-controller-4.0.0 (app v1.18.0)"},
+		Specs: []results.Spec{
+			{Name: "backup", State: "passed"},
+			{Name: "restore", State: "failed"},
+			{Name: "upgrade", State: "passed"},
+		},
+		Metrics: []results.Metric{
+			{Metric: "restore time", Value: 100, Unit: "s"},
+			{Metric: "p95 latency", Value: 2, Unit: "s"},
+		},
+	}}
+	candidate := []results.Summary{
+		{
+			RunID:    "rc2",
+			Suite:    "smoke",
+			Versions: map[string]string{"K3s": "v1.31.1+k3s1", "kubewarden-controller": "kubewarden-controller-4.1.0 (app v1.19.0)"},
+			Specs: []results.Spec{
+				{Name: "backup", State: "passed"},
+				{Name: "restore", State: "passed"},
+				{Name: "upgrade", State: "timedout"},
+			},
+			Metrics: []results.Metric{{Metric: "restore time", Value: 105, Unit: "s"}},
+		},
+		{
+			RunID:   "rc2",
+			Suite:   "perf",
+			Specs:   []results.Spec{{Name: "scale", State: "failed"}},
+			Metrics: []results.Metric{{Metric: "p95 latency", Value: 3, Unit: "s"}},
+		},
+	}
+
+	d := results.Compare(base, candidate, 10)
+
+	if d.Base != "rc1" || d.Candidate != "rc2" {
+		t.Errorf("unexpected runs %q and %q", d.Base, d.Candidate)
+	}
+	if !slices.Equal(d.Regressions, []string{"scale", "upgrade", "metric p95 latency"}) {
+		t.Errorf("unexpected regressions %v", d.Regressions)
+	}
+	if !slices.Equal(d.Fixed, []string{"restore"}) {
+		t.Errorf("unexpected fixed specs %v", d.Fixed)
+	}
+	if len(d.Versions) != 1 || d.Versions[0].Name != "kubewarden-controller" {
+		t.Errorf("unexpected version changes %v", d.Versions)
+	}
+	if len(d.Metrics) != 2 || !d.Metrics[0].Regressed || d.Metrics[0].Percent != 50 || d.Metrics[1].Regressed {
+		t.Errorf("unexpected metric changes %+v", d.Metrics)
+	}
+
+	text := d.Text()
+	for _, line := range []string{
+		"kubewarden-controller: kubewarden-controller-4.0.0 (app v1.18.0) -> kubewarden-controller-4.1.0 (app v1.19.0)",
+		"scale: (none) -> failed",
+		"p95 latency: 2 -> 3 s (+50.0%) REGRESSED",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("%q not in:\n%s", line, text)
+		}
+	}
+}
+
+func TestCompareSame(t *testing.T) {
+	run := []results.Summary{{RunID: "rc1", Specs: []results.Spec{{Name: "backup", State: "passed"}}}}
+
+	d := results.Compare(run, run, 10)
+	if d.Regressed() || len(d.Specs) != 0 || !strings.Contains(d.Text(), "No regression") {
+		t.Errorf("unexpected delta of the same run:\n%s", d.Text())
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// Metric is a performance number of a run, e.g. an admission latency
+type Metric struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit"`
+}
+
+// Spec is the result of one spec
+type Spec struct {
+	Name     string   `json:"name"`
+	Labels   []string `json:"labels,omitempty"`
+	State    string   `json:"state"`
+	Duration float64  `json:"duration"`
+	Attempts int      `json:"attempts"`
+}
+
+// Summary is the result of a suite, one per ginkgo execution
+type Summary struct {
+	RunID string    `json:"run_id"`
+	Suite string    `json:"suite"`
+	Date  time.Time `json:"date"`
+	// Duration of the suite, in seconds
+	Duration float64  `json:"duration"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Skipped  int      `json:"skipped"`
+	Flaky    int      `json:"flaky"`
+	Specs    []Spec   `json:"specs"`
+	Metrics  []Metric `json:"metrics"`
+	// Versions under test from the run manifest, by component, e.g. K3s or a chart
+	Versions map[string]string `json:"versions,omitempty"`
+}
+
+/*
+Displayed value of a metric in the report entries
+  - @returns Value and unit of the metric
+*/
+func (m Metric) String() string {
+	return fmt.Sprintf("%g %s", m.Value, m.Unit)
+}
+
+/*
+Summarize the report of a suite
+  - @remarks Metrics are the report entries with a Metric value, also when they come from another parallel process
+  - @param report Report of the suite
+  - @param runID ID of the run
+  - @returns The summary
+*/
+func FromReport(report types.Report, runID string) *Summary {
+	suite := report.SuiteConfig.LabelFilter
+	if suite == "" {
+		suite = "all"
+	}
+
+	s := &Summary{
+		RunID:    runID,
+		Suite:    suite,
+		Date:     report.StartTime.UTC(),
+		Duration: report.RunTime.Seconds(),
+		Specs:    []Spec{},
+		Metrics:  []Metric{},
+	}
+
+	for _, spec := range report.SpecReports {
+		// Setup nodes like BeforeSuite are only useful for their metrics, specs out of the label filter are not started
+		if spec.LeafNodeType == types.NodeTypeIt && spec.NumAttempts > 0 {
+			s.Specs = append(s.Specs, Spec{
+				Name:     spec.FullText(),
+				Labels:   spec.Labels(),
+				State:    spec.State.String(),
+				Duration: spec.RunTime.Seconds(),
+				Attempts: spec.NumAttempts,
+			})
+
+			switch {
+			case spec.State.Is(types.SpecStatePassed) && spec.NumAttempts > 1:
+				s.Flaky++
+				s.Passed++
+			case spec.State.Is(types.SpecStatePassed):
+				s.Passed++
+			case spec.State.Is(types.SpecStateFailureStates):
+				s.Failed++
+			default:
+				s.Skipped++
+			}
+		}
+
+		for _, entry := range spec.ReportEntries {
+			// Only decoded from JSON if the entry has been sent by another process
+			m, ok := entry.GetRawValue().(Metric)
+			if !ok && json.Unmarshal([]byte(entry.Value.AsJSON), &m) != nil {
+				continue
+			}
+			if m.Metric != "" {
+				s.Metrics = append(s.Metrics, m)
+			}
+		}
+	}
+
+	return s
+}
+
+/*
+Get the pass rate of a run
+  - @returns Percentage of the passed specs, skipped ones excluded
+*/
+func (s *Summary) PassRate() float64 {
+	if s.Passed+s.Failed == 0 {
+		return 0
+	}
+
+	return 100 * float64(s.Passed) / float64(s.Passed+s.Failed)
+}
+
+/*
+Publish a summary in the results storage
+  - @remarks The target is an S3 URL (s3://bucket/prefix) or a SQLite file, RESULTS_S3_ENDPOINT sets a custom endpoint
+  - @param s Summary to publish
+  - @param target Results storage
+  - @returns Nothing or an error
+*/
+func Publish(s *Summary, target string) error {
+	tmpDir, err := os.MkdirTemp("", "results")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Sorted by date, the suite is in the name as several suites are executed in the same run
+	suite := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '-'
+	}, s.Suite)
+	name := fmt.Sprintf("%s-%s-%s.json", s.Date.Format("20060102T150405Z"), s.RunID, suite)
+	file := filepath.Join(tmpDir, name)
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	if bucket, ok := strings.CutPrefix(target, "s3://"); ok {
+		_, err = s3("cp", file, "s3://"+strings.TrimSuffix(bucket, "/")+"/"+name)
+		return err
+	}
+
+	_, err = run("sqlite3", target,
+		"CREATE TABLE IF NOT EXISTS runs (run_id TEXT, suite TEXT, date TEXT, summary TEXT);"+
+			fmt.Sprintf("INSERT INTO runs VALUES (%s, %s, %s, readfile(%s));",
+				quote(s.RunID), quote(s.Suite), quote(s.Date.Format(time.RFC3339)), quote(file)))
+	return err
+}
+
+/*
+Load all the summaries of the results storage
+  - @param target Results storage, as for Publish
+  - @returns Summaries sorted by date or an error
+*/
+func Load(target string) ([]Summary, error) {
+	var raw []string
+
+	if bucket, ok := strings.CutPrefix(target, "s3://"); ok {
+		tmpDir, err := os.MkdirTemp("", "results")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		if _, err := s3("sync", "s3://"+strings.TrimSuffix(bucket, "/"), tmpDir, "--exclude", "*", "--include", "*.json"); err != nil {
+			return nil, err
+		}
+
+		files, err := filepath.Glob(filepath.Join(tmpDir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			raw = append(raw, string(data))
+		}
+	} else {
+		out, err := run("sqlite3", "-json", target, "SELECT summary FROM runs;")
+		if err != nil {
+			return nil, err
+		}
+
+		// Nothing is returned by an empty table
+		var rows []struct {
+			Summary string `json:"summary"`
+		}
+		if strings.TrimSpace(out) != "" {
+			if err := json.Unmarshal([]byte(out), &rows); err != nil {
+				return nil, err
+			}
+		}
+		for _, row := range rows {
+			raw = append(raw, row.Summary)
+		}
+	}
+
+	summaries := make([]Summary, 0, len(raw))
+	for _, data := range raw {
+		var s Summary
+		if err := json.Unmarshal([]byte(data), &s); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	slices.SortFunc(summaries, func(a, b Summary) int { return a.Date.Compare(b.Date) })
+
+	return summaries, nil
+}
+
+/*
+Execute an S3 command
+  - @remarks This function is only used internally, not exported
+  - @param args Arguments of the aws s3 command
+  - @returns Output of the command or an error
+*/
+func s3(args ...string) (string, error) {
+	flags := []string{"s3"}
+
+	// Custom endpoint is needed for S3 compatible storages like MinIO
+	if endpoint := os.Getenv("RESULTS_S3_ENDPOINT"); endpoint != "" {
+		flags = append(flags, "--endpoint-url", endpoint)
+	}
+
+	return run("aws", append(flags, args...)...)
+}
+
+/*
+Execute a command
+  - @remarks This function is only used internally, not exported
+  - @remarks Also used by cmd/report, outside of ginkgo, so the runner helper cannot be used
+  - @param name Command to execute
+  - @param args Arguments of the command
+  - @returns Standard output of the command or an error of type *cmderr.Error
+*/
+func run(name string, args ...string) (string, error) {
+	out, _, err := cmderr.Run(exec.Command(name, args...))
+	return out, err
+}
+
+/*
+Quote a SQL string
+  - @remarks This function is only used internally, not exported
+  - @returns The quoted string
+*/
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,329 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cmderr"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/dryrun"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/rancher-sandbox/ele-testhelpers/tools"
+)
+
+// Maximum size of the output kept in the spec report
+const maxReportOutput = 4096
+
+// Runner executes commands locally or on a remote host
+type Runner struct {
+	// Execute the commands with sudo
+	Sudo bool
+	// Remote host, commands are executed locally if not set
+	Remote *tools.Client
+	// Additional environment variables, in KEY=value format
+	Env []string
+}
+
+// Error is returned when a command fails, kept here for the callers of the runner
+type Error = cmderr.Error
+
+/*
+Create a runner executing commands on a remote host
+  - @param cl Client (node) informations
+  - @returns The runner
+*/
+func Remote(cl *tools.Client) *Runner {
+	return &Runner{Remote: cl}
+}
+
+/*
+Get a copy of the runner executing commands with sudo
+  - @returns The new runner
+*/
+func (r *Runner) WithSudo() *Runner {
+	n := *r
+	n.Sudo = true
+	return &n
+}
+
+/*
+Get a copy of the runner with additional environment variables
+  - @param env Environment variables, in KEY=value format
+  - @returns The new runner
+*/
+func (r *Runner) WithEnv(env ...string) *Runner {
+	n := *r
+	n.Env = append(append([]string{}, r.Env...), env...)
+	return &n
+}
+
+/*
+Get the host where commands are executed
+  - @returns IP address (or name) of the remote host, empty for local
+*/
+func (r *Runner) Host() string {
+	if r.Remote == nil {
+		return ""
+	}
+
+	host, _, _ := strings.Cut(r.Remote.Host, ":")
+	return host
+}
+
+/*
+Get the prefix of remote paths
+  - @remarks This function is only used internally, not exported
+  - @returns host: for a remote host, empty for local
+*/
+func (r *Runner) hostPrefix() string {
+	if r.Remote == nil {
+		return ""
+	}
+
+	return r.Remote.Host + ":"
+}
+
+/*
+Copy a file from the host where commands are executed
+  - @param localFile Destination file on the test host
+  - @param file Source file
+  - @returns Nothing or an error
+*/
+func (r *Runner) GetFile(localFile, file string) error {
+	if dryrun.Enabled() {
+		dryrun.Record("copy %s%s to %s", r.hostPrefix(), file, localFile)
+		return nil
+	}
+
+	if r.Remote == nil {
+		GinkgoWriter.Printf("Copying %s to %s\n", file, localFile)
+		return tools.CopyFile(file, localFile)
+	}
+
+	GinkgoWriter.Printf("Copying %s:%s to %s\n", r.Remote.Host, file, localFile)
+	return r.Remote.GetFile(localFile, file, 0644)
+}
+
+/*
+Copy a file to the host where commands are executed
+  - @param localFile Source file on the test host
+  - @param file Destination file
+  - @returns Nothing or an error
+*/
+func (r *Runner) PutFile(localFile, file string) error {
+	if dryrun.Enabled() {
+		dryrun.Record("copy %s to %s%s", localFile, r.hostPrefix(), file)
+		return nil
+	}
+
+	if r.Remote == nil {
+		GinkgoWriter.Printf("Copying %s to %s\n", localFile, file)
+		return tools.CopyFile(localFile, file)
+	}
+
+	GinkgoWriter.Printf("Copying %s to %s:%s\n", localFile, r.Remote.Host, file)
+	return r.Remote.SendFile(localFile, file, "0644")
+}
+
+/*
+Execute a local command
+  - @param name Command to execute
+  - @param args Arguments of the command
+  - @returns Standard output of the command or an error
+*/
+func Run(name string, args ...string) (string, error) {
+	return (&Runner{}).Run(name, args...)
+}
+
+/*
+Execute a local command with sudo
+  - @param name Command to execute
+  - @param args Arguments of the command
+  - @returns Standard output of the command or an error
+*/
+func Sudo(name string, args ...string) (string, error) {
+	return (&Runner{Sudo: true}).Run(name, args...)
+}
+
+/*
+Execute a local shell script
+  - @param script Script to execute with bash
+  - @returns Standard output of the script or an error
+*/
+func Shell(script string) (string, error) {
+	return (&Runner{}).Shell(script)
+}
+
+/*
+Execute a command
+  - @param name Command to execute
+  - @param args Arguments of the command
+  - @returns Standard output of the command or an error
+*/
+func (r *Runner) Run(name string, args ...string) (string, error) {
+	if r.Remote != nil {
+		return r.runRemote(quote(append([]string{name}, args...)))
+	}
+
+	if r.Sudo {
+		// Keep the additional environment through sudo
+		args = append(append(append([]string{}, r.Env...), name), args...)
+		name = "sudo"
+	}
+
+	return r.runLocal(name, args...)
+}
+
+/*
+Execute a shell script
+  - @param script Script to execute with bash
+  - @returns Standard output of the script or an error
+*/
+func (r *Runner) Shell(script string) (string, error) {
+	if r.Remote != nil {
+		return r.runRemote("bash -c " + quote([]string{script}))
+	}
+
+	return r.Run("bash", "-c", script)
+}
+
+/*
+Execute a command locally
+  - @remarks This function is only used internally, not exported
+  - @param name Command to execute
+  - @param args Arguments of the command
+  - @returns Standard output of the command or an error
+*/
+func (r *Runner) runLocal(name string, args ...string) (string, error) {
+	cmdLine := quote(append([]string{name}, args...))
+
+	if dryrun.Enabled() {
+		// With sudo the environment is already in the arguments
+		if !r.Sudo && len(r.Env) > 0 {
+			cmdLine = "env " + quote(r.Env) + " " + cmdLine
+		}
+		dryrun.Record("$ %s", cmdLine)
+		return "", nil
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), r.Env...)
+
+	GinkgoWriter.Printf("$ %s\n", cmdLine)
+
+	stdout, stderr, err := cmderr.Run(cmd)
+	r.log(cmdLine, "", stdout, stderr, err)
+
+	return stdout, err
+}
+
+/*
+Execute a command on the remote host
+  - @remarks This function is only used internally, not exported
+  - @param cmdLine Command line to execute
+  - @returns Output of the command or an error
+*/
+func (r *Runner) runRemote(cmdLine string) (string, error) {
+	if len(r.Env) > 0 {
+		cmdLine = "env " + quote(r.Env) + " " + cmdLine
+	}
+	// No need for sudo if already connected as root
+	if r.Sudo && r.Remote.Username != "root" {
+		cmdLine = "sudo " + cmdLine
+	}
+
+	if dryrun.Enabled() {
+		dryrun.Record("[%s] $ %s", r.Remote.Host, cmdLine)
+		return "", nil
+	}
+
+	GinkgoWriter.Printf("[%s] $ %s\n", r.Remote.Host, cmdLine)
+
+	out, err := r.Remote.RunSSH(cmdLine)
+	r.log(cmdLine, r.Remote.Host, out, "", err)
+	if err != nil {
+		return out, &Error{
+			Cmd:      cmdLine,
+			Host:     r.Remote.Host,
+			ExitCode: cmderr.ExitCode(err),
+			Stdout:   out,
+			Err:      err,
+		}
+	}
+
+	return out, nil
+}
+
+/*
+Log the result of a command and add it to the spec report
+  - @remarks This function is only used internally, not exported
+  - @returns Nothing
+*/
+func (r *Runner) log(cmdLine, host, stdout, stderr string, err error) {
+	if stdout != "" {
+		GinkgoWriter.Printf("%s\n", strings.TrimRight(stdout, "\n"))
+	}
+	if stderr != "" {
+		GinkgoWriter.Printf("%s\n", strings.TrimRight(stderr, "\n"))
+	}
+
+	entry := "$ " + cmdLine
+	if host != "" {
+		entry = "[" + host + "] " + entry
+	}
+	if err != nil {
+		entry += fmt.Sprintf("\nexit code: %d", cmderr.ExitCode(err))
+	}
+	if stdout != "" {
+		entry += "\nstdout:\n" + tail(stdout)
+	}
+	if stderr != "" {
+		entry += "\nstderr:\n" + tail(stderr)
+	}
+
+	// Outside of a spec (e.g. in the unit tests of the packages) there is no report
+	if CurrentSpecReport().LeafNodeType == types.NodeTypeInvalid {
+		return
+	}
+
+	// Only displayed on failure or in verbose mode, to keep reports readable
+	AddReportEntry("command", entry, ReportEntryVisibilityFailureOrVerbose)
+}
+
+/*
+Quote arguments for a shell
+  - @remarks This function is only used internally, not exported
+  - @returns Quoted command line
+*/
+func quote(args []string) string {
+	return cmderr.Quote(args)
+}
+
+/*
+Keep the end of an output
+  - @remarks This function is only used internally, not exported
+  - @returns The last bytes of the output
+*/
+func tail(out string) string {
+	if len(out) <= maxReportOutput {
+		return out
+	}
+
+	return "[...]" + out[len(out)-maxReportOutput:]
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// Fields of an AVC record, e.g. avc:  denied  { read } for  pid=1 comm="policy-server" ... tclass=file
+var (
+	permissionsRegexp = regexp.MustCompile(`avc:\s+denied\s+\{\s*([^}]*?)\s*\}`)
+	commRegexp        = regexp.MustCompile(`\bcomm="([^"]*)"`)
+	scontextRegexp    = regexp.MustCompile(`\bscontext=(\S+)`)
+	tcontextRegexp    = regexp.MustCompile(`\btcontext=(\S+)`)
+	tclassRegexp      = regexp.MustCompile(`\btclass=(\S+)`)
+)
+
+// Denial is an AVC denial of the audit log
+type Denial struct {
+	Comm        string
+	Permissions string
+	Source      string
+	Target      string
+	Class       string
+	// Full record, for the details not parsed
+	Raw string
+}
+
+/*
+Search the AVC denials of a host
+  - @remarks ausearch has to be installed (audit package) and auditd running
+  - @param node Runner of the host
+  - @param start Start of the search, in an ausearch format, e.g. this-boot or recent
+  - @returns Denials found, or an error
+*/
+func Search(node *runner.Runner, start string) ([]Denial, error) {
+	out, err := node.WithSudo().Run("ausearch", "--message", "AVC,USER_AVC", "--start", start, "--input-logs")
+	if err != nil {
+		// Exit code is 1 when nothing is found
+		var runErr *runner.Error
+		if errors.As(err, &runErr) && strings.Contains(runErr.Stdout+runErr.Stderr, "<no matches>") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return Parse(out), nil
+}
+
+/*
+Parse the output of ausearch
+  - @param out Raw records, one per line
+  - @returns Denials found in the records
+*/
+func Parse(out string) []Denial {
+	var denials []Denial
+	for _, line := range strings.Split(out, "\n") {
+		m := permissionsRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		denials = append(denials, Denial{
+			Comm:        field(commRegexp, line),
+			Permissions: m[1],
+			Source:      field(scontextRegexp, line),
+			Target:      field(tcontextRegexp, line),
+			Class:       field(tclassRegexp, line),
+			Raw:         line,
+		})
+	}
+
+	return denials
+}
+
+/*
+Keep the denials of some processes
+  - @remarks The comm of the records is truncated to 15 characters by the kernel
+  - @param denials Denials to filter
+  - @param comms Names of the processes, prefixes are enough
+  - @returns Denials of the processes
+*/
+func Filter(denials []Denial, comms []string) []Denial {
+	var found []Denial
+	for _, d := range denials {
+		for _, comm := range comms {
+			if strings.HasPrefix(d.Comm, comm[:min(len(comm), 15)]) {
+				found = append(found, d)
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+/*
+Get a denial in a readable format
+  - @returns Process, permissions and contexts of the denial
+*/
+func (d Denial) String() string {
+	return fmt.Sprintf("%s denied { %s } on %s: %s -> %s", d.Comm, d.Permissions, d.Class, d.Source, d.Target)
+}
+
+/*
+Get a list of denials in a readable format
+  - @param denials Denials to format
+  - @returns One denial per line
+*/
+func Format(denials []Denial) string {
+	var b strings.Builder
+	for _, d := range denials {
+		b.WriteString(d.String() + "\n")
+	}
+
+	return b.String()
+}
+
+/*
+Get the value of a field
+  - @remarks This function is only used internally, not exported
+  - @returns Value of the first match, empty if not found
+*/
+func field(re *regexp.Regexp, line string) string {
+	if m := re.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
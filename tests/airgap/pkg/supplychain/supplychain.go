@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supplychain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+)
+
+// ErrNotVerified is returned when cosign ran but found no signature or attestation of the identity
+var ErrNotVerified = errors.New("not verified")
+
+// Messages of cosign when the verification itself fails, other failures are errors of the command
+var verificationFailures = []string{
+	"no matching signatures",
+	"no signatures found",
+	"no matching attestations",
+	"none of the expected identities matched",
+}
+
+// Identity expected in the keyless signatures
+type Identity struct {
+	// Regular expression of the certificate identity, e.g. the signing workflow
+	Subject string
+	// OIDC issuer of the certificate
+	Issuer string
+}
+
+// Vulnerability found by trivy
+type Vulnerability struct {
+	ID               string `json:"VulnerabilityID"`
+	Package          string `json:"PkgName"`
+	InstalledVersion string `json:"InstalledVersion"`
+	FixedVersion     string `json:"FixedVersion"`
+	Severity         string `json:"Severity"`
+}
+
+/*
+Scan an image with trivy
+  - @remarks Vulnerabilities without a fix are ignored, nothing can be done about them
+  - @param image Image to scan
+  - @param severity Comma separated list of severities to report, e.g. CRITICAL,HIGH
+  - @returns The vulnerabilities found or an error
+*/
+func Scan(image, severity string) ([]Vulnerability, error) {
+	out, err := runner.Run("trivy", "image", "--quiet", "--ignore-unfixed",
+		"--severity", severity, "--format", "json", image)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Results []struct {
+			Vulnerabilities []Vulnerability `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		return nil, err
+	}
+
+	var vulns []Vulnerability
+	for _, res := range report.Results {
+		vulns = append(vulns, res.Vulnerabilities...)
+	}
+
+	return vulns, nil
+}
+
+/*
+Verify the signature of an image with cosign
+  - @param image Image to verify
+  - @param id Expected identity of the signer
+  - @returns Nothing, ErrNotVerified if the image is not signed by the identity, or an error of the command
+*/
+func VerifySignature(image string, id Identity) error {
+	_, err := runner.Run("cosign", append([]string{"verify"}, id.flags(image)...)...)
+	return Classify(err)
+}
+
+/*
+Verify the SBOM attestation of an image with cosign
+  - @param image Image to verify
+  - @param id Expected identity of the signer
+  - @param sbomType Type of the attestation, e.g. spdxjson or cyclonedx
+  - @returns Nothing, ErrNotVerified if no SBOM is attested by the identity, or an error of the command
+*/
+func VerifySBOM(image string, id Identity, sbomType string) error {
+	args := append([]string{"verify-attestation", "--type", sbomType}, id.flags(image)...)
+	_, err := runner.Run("cosign", args...)
+	return Classify(err)
+}
+
+/*
+Tell a failed verification from a failure of cosign
+  - @remarks cosign exits with 1 in both cases, only its message differs (e.g. registry not reachable)
+  - @param err Error of the cosign command
+  - @returns Nil, an error wrapping ErrNotVerified, or the error of the command
+*/
+func Classify(err error) error {
+	var runErr *runner.Error
+	if err == nil || !errors.As(err, &runErr) {
+		return err
+	}
+
+	out := runErr.Stdout + runErr.Stderr
+	for _, msg := range verificationFailures {
+		if strings.Contains(out, msg) {
+			return fmt.Errorf("%w: %s", ErrNotVerified, msg)
+		}
+	}
+
+	return err
+}
+
+/*
+Get the list of distinct images
+  - @param images Images, some could be used multiple times
+  - @returns Sorted list without duplicates
+*/
+func Unique(images []string) []string {
+	images = slices.Clone(images)
+	slices.Sort(images)
+
+	return slices.Compact(images)
+}
+
+/*
+Get the cosign flags checking the identity
+  - @remarks This function is only used internally, not exported
+  - @returns The cosign flags, with the image at the end
+*/
+func (id Identity) flags(image string) []string {
+	return []string{
+		"--certificate-identity-regexp", id.Subject,
+		"--certificate-oidc-issuer", id.Issuer,
+		"--output", "json",
+		image,
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supplychain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/runner"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/supplychain"
+)
+
+func TestClassify(t *testing.T) {
+	cosign := func(stderr string) error {
+		return &runner.Error{Cmd: "cosign verify", ExitCode: 1, Stderr: stderr, Err: errors.New("exit status 1")}
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		notVerified bool
+		failed      bool
+	}{
+		{"verified", nil, false, false},
+		{"no signature", cosign("Error: no signatures found"), true, false},
+		{"other identity", cosign("Error: none of the expected identities matched what was in the certificate"), true, false},
+		{"no attestation", cosign("Error: no matching attestations: "), true, false},
+		{"registry not reachable", cosign("Error: dial tcp: lookup ghcr.io: no such host"), false, true},
+		{"not a command error", errors.New("cosign not found"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := supplychain.Classify(tt.err)
+			if got := errors.Is(err, supplychain.ErrNotVerified); got != tt.notVerified {
+				t.Errorf("not verified is %v, %v expected: %v", got, tt.notVerified, err)
+			}
+			if got := err != nil && !tt.notVerified; got != tt.failed {
+				t.Errorf("failure is %v, %v expected: %v", got, tt.failed, err)
+			}
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	got := supplychain.Unique([]string{"ghcr.io/b:v1", "ghcr.io/a:v1", "ghcr.io/b:v1"})
+	if len(got) != 2 || got[0] != "ghcr.io/a:v1" || got[1] != "ghcr.io/b:v1" {
+		t.Errorf("unique images are %v", got)
+	}
+}
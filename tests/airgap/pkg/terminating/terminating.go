@@ -0,0 +1,125 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminating
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/cluster"
+)
+
+// Resource is a resource being deleted, only what is needed to know why it is stuck
+type Resource struct {
+	Kind              string
+	Namespace         string
+	Name              string
+	Finalizers        []string
+	DeletionTimestamp time.Time
+}
+
+/*
+Find the resources being deleted for longer than a duration
+  - @param kinds Kinds of the resources, e.g. policyservers, namespaces
+  - @param ns Namespace of the resources, empty for all namespaces
+  - @param older Minimum time since the deletion request
+  - @returns Resources stuck terminating, oldest first, or an error
+*/
+func Find(kinds []string, ns string, older time.Duration) ([]Resource, error) {
+	args := []string{"get", strings.Join(kinds, ","), "-o", "json"}
+	if ns != "" {
+		args = append(args, "--namespace", ns)
+	} else {
+		args = append(args, "--all-namespaces")
+	}
+
+	out, err := cluster.Default.KubectlWithoutErr(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Namespace         string     `json:"namespace"`
+				Name              string     `json:"name"`
+				Finalizers        []string   `json:"finalizers"`
+				DeletionTimestamp *time.Time `json:"deletionTimestamp"`
+			} `json:"metadata"`
+			Spec struct {
+				// Finalizers of namespaces are in their spec
+				Finalizers []string `json:"finalizers"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("cannot parse resources: %w", err)
+	}
+
+	var stuck []Resource
+	for _, item := range list.Items {
+		deletion := item.Metadata.DeletionTimestamp
+		if deletion == nil || time.Since(*deletion) < older {
+			continue
+		}
+
+		stuck = append(stuck, Resource{
+			Kind:              item.Kind,
+			Namespace:         item.Metadata.Namespace,
+			Name:              item.Metadata.Name,
+			Finalizers:        append(item.Metadata.Finalizers, item.Spec.Finalizers...),
+			DeletionTimestamp: *deletion,
+		})
+	}
+
+	// Oldest ones are usually the cause of the others
+	slices.SortFunc(stuck, func(a, b Resource) int {
+		return a.DeletionTimestamp.Compare(b.DeletionTimestamp)
+	})
+
+	return stuck, nil
+}
+
+/*
+Get a resource in a readable format
+  - @returns Kind, namespace/name, time since the deletion and finalizers of the resource
+*/
+func (r Resource) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+
+	return fmt.Sprintf("%s %s terminating for %s, finalizers: %s",
+		r.Kind, name, time.Since(r.DeletionTimestamp).Round(time.Second), strings.Join(r.Finalizers, ", "))
+}
+
+/*
+Get a list of resources in a readable format
+  - @param resources Resources to format
+  - @returns One resource per line
+*/
+func Format(resources []Resource) string {
+	var b strings.Builder
+	for _, r := range resources {
+		b.WriteString(r.String() + "\n")
+	}
+
+	return b.String()
+}
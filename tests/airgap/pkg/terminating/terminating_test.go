@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminating_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/replay"
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/terminating"
+)
+
+// Command of Find for all namespaces
+var getArgs = []string{"kubectl", "get", "policyservers,namespaces", "-o", "json", "--all-namespaces"}
+
+func setup(t *testing.T, stdout, stderr string, code int) {
+	t.Helper()
+
+	replay.ForTest(t, replay.Output{Args: getArgs, Stdout: stdout, Stderr: stderr, Code: code})
+}
+
+func TestFind(t *testing.T) {
+	now := time.Now().UTC()
+	item := func(kind, ns, name string, deleted time.Duration, finalizers string) string {
+		deletion := "null"
+		if deleted > 0 {
+			deletion = fmt.Sprintf("%q", now.Add(-deleted).Format(time.RFC3339))
+		}
+		return fmt.Sprintf(`{"kind": %q, "metadata": {"namespace": %q, "name": %q, "deletionTimestamp": %s, "finalizers": [%s]}}`,
+			kind, ns, name, deletion, finalizers)
+	}
+
+	setup(t, `{"items": [`+strings.Join([]string{
+		item("PolicyServer", "", "recent", 10*time.Second, `"kubewarden"`),
+		item("PolicyServer", "", "alive", 0, `"kubewarden"`),
+		item("PolicyServer", "", "stuck", 10*time.Minute, `"kubewarden"`),
+		item("Namespace", "", "oldest", time.Hour, ""),
+	}, ", ")+`]}`, "", 0)
+
+	stuck, err := terminating.Find([]string{"policyservers", "namespaces"}, "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, r := range stuck {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "oldest,stuck" {
+		t.Errorf("stuck resources are %q, oldest,stuck expected", got)
+	}
+	if len(stuck) == 2 && !strings.HasPrefix(stuck[1].String(), "PolicyServer stuck terminating for 10m") {
+		t.Errorf("unexpected format of %s", stuck[1])
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdout  string
+		code    int
+		message string
+	}{
+		{"kubectl failure", "", 1, "failed"},
+		{"invalid output", "not json", 0, "cannot parse resources"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t, tt.stdout, "error: connection refused\n", tt.code)
+
+			_, err := terminating.Find([]string{"policyservers", "namespaces"}, "", 0)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error is %v, %q expected", err, tt.message)
+			}
+		})
+	}
+}
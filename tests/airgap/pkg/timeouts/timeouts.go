@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timeouts
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Class of operation, each one has its own default timeout
+type Class string
+
+const (
+	Default Class = "default"
+	Install Class = "install"
+	Rollout Class = "rollout"
+	Backup  Class = "backup"
+	Restore Class = "restore"
+	// Whole spec, for the long ones like the airgap installation
+	Spec Class = "spec"
+)
+
+// Default timeouts per class, before scaling
+var defaults = map[Class]time.Duration{
+	Default: 2 * time.Minute,
+	Install: 10 * time.Minute,
+	Rollout: 5 * time.Minute,
+	Backup:  5 * time.Minute,
+	Restore: 10 * time.Minute,
+	Spec:    time.Hour,
+}
+
+/*
+Get the timeout multiplier
+  - @remarks Set with TIMEOUT_SCALE, unlike tools.SetTimeout decimal values (e.g. 1.5) are allowed
+  - @returns The multiplier, 1 if unset or invalid
+*/
+func Scale() float64 {
+	s, set := os.LookupEnv("TIMEOUT_SCALE")
+	if !set {
+		return 1
+	}
+
+	scale, err := strconv.ParseFloat(s, 64)
+	if err != nil || scale <= 0 {
+		return 1
+	}
+
+	return scale
+}
+
+/*
+Scale a timeout
+  - @param d Timeout to scale
+  - @returns The scaled timeout
+*/
+func Scaled(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * Scale())
+}
+
+/*
+Get the timeout of a class
+  - @param c Class of operation
+  - @returns The scaled timeout, Default one if the class is unknown
+*/
+func For(c Class) time.Duration {
+	d, ok := defaults[c]
+	if !ok {
+		d = defaults[Default]
+	}
+
+	return Scaled(d)
+}
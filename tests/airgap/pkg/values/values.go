@@ -0,0 +1,259 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kubewarden charts configured by the builder
+const (
+	CRDs       = "kubewarden-crds"
+	Controller = "kubewarden-controller"
+	Defaults   = "kubewarden-defaults"
+)
+
+// Builder creates the Helm values of the Kubewarden charts
+type Builder struct {
+	values map[string]map[string]any
+}
+
+/*
+Create an empty builder
+  - @returns The builder, charts are installed with their own defaults
+*/
+func New() *Builder {
+	return &Builder{values: map[string]map[string]any{}}
+}
+
+/*
+Create a builder with the values used by default in the tests
+  - @returns The builder, with policy reports and recommended policies in protect mode
+*/
+func Default() *Builder {
+	return New().
+		Set(Controller, "auditScanner.policyReporter", true).
+		RecommendedPolicies(true, "protect")
+}
+
+/*
+Set a value of a chart
+  - @param chart Name of the chart
+  - @param path Dotted path of the value, e.g. policyServer.replicaCount
+  - @param value Value to set
+  - @returns The builder
+*/
+func (b *Builder) Set(chart, path string, value any) *Builder {
+	if b.values[chart] == nil {
+		b.values[chart] = map[string]any{}
+	}
+
+	m := b.values[chart]
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+
+	return b
+}
+
+/*
+Set a value of all the charts
+  - @param path Dotted path of the value
+  - @param value Value to set
+  - @returns The builder
+*/
+func (b *Builder) SetAll(path string, value any) *Builder {
+	for _, chart := range []string{CRDs, Controller, Defaults} {
+		b.Set(chart, path, value)
+	}
+
+	return b
+}
+
+/*
+Enable or disable the telemetry of the controller and policy servers
+  - @param enabled Metrics and tracing are enabled if true
+  - @returns The builder
+*/
+func (b *Builder) Telemetry(enabled bool) *Builder {
+	return b.
+		Set(Controller, "telemetry.metrics", enabled).
+		Set(Controller, "telemetry.tracing", enabled)
+}
+
+/*
+Configure the recommended policies
+  - @param enabled Recommended policies are deployed if true
+  - @param mode Policy mode, monitor or protect
+  - @returns The builder
+*/
+func (b *Builder) RecommendedPolicies(enabled bool, mode string) *Builder {
+	return b.
+		Set(Defaults, "recommendedPolicies.enabled", enabled).
+		Set(Defaults, "recommendedPolicies.defaultPolicyMode", mode)
+}
+
+/*
+Configure the audit scanner
+  - @param enabled The CronJob of the audit scanner is deployed if true
+  - @param schedule Schedule of the scans, in cron format
+  - @returns The builder
+*/
+func (b *Builder) AuditScanner(enabled bool, schedule string) *Builder {
+	return b.
+		Set(Controller, "auditScanner.enabled", enabled).
+		Set(Controller, "auditScanner.cronJob.schedule", schedule)
+}
+
+/*
+Set the number of replicas of the controller
+  - @param n Number of replicas
+  - @returns The builder
+*/
+func (b *Builder) ControllerReplicas(n int) *Builder {
+	return b.Set(Controller, "replicas", n)
+}
+
+/*
+Set the number of replicas of the default policy server
+  - @param n Number of replicas
+  - @returns The builder
+*/
+func (b *Builder) PolicyServerReplicas(n int) *Builder {
+	return b.Set(Defaults, "policyServer.replicaCount", n)
+}
+
+/*
+Pull all the images from another registry
+  - @param registry Registry host (and port), e.g. registry.suse.com
+  - @returns The builder
+*/
+func (b *Builder) Registry(registry string) *Builder {
+	return b.SetAll("global.cattle.systemDefaultRegistry", registry)
+}
+
+/*
+Pull the policies of the default policy server from another registry
+  - @param registry Registry host (and port) of the policies
+  - @param insecure Plain HTTP or self-signed registry if true
+  - @returns The builder
+*/
+func (b *Builder) PoliciesRegistry(registry string, insecure bool) *Builder {
+	b.Set(Defaults, "recommendedPolicies.defaultPoliciesRegistry", registry)
+	if insecure {
+		b.Set(Defaults, "policyServer.insecureSources", []string{registry})
+	}
+
+	return b
+}
+
+/*
+Always pull the images of the controller and the default policy server
+  - @remarks Images are then resolved by the registry each time a pod starts
+  - @returns The builder
+*/
+func (b *Builder) PullAlways() *Builder {
+	return b.
+		Set(Controller, "image.pullPolicy", "Always").
+		Set(Defaults, "policyServer.image.pullPolicy", "Always")
+}
+
+/*
+Add an environment variable to the default policy server
+  - @remarks The list replaces the one of the chart, variables added before are kept
+  - @param name Name of the variable, e.g. KUBEWARDEN_LOG_FMT
+  - @param value Value of the variable
+  - @returns The builder
+*/
+func (b *Builder) PolicyServerEnv(name, value string) *Builder {
+	var env []map[string]string
+	if ps, ok := b.values[Defaults]["policyServer"].(map[string]any); ok {
+		env, _ = ps["env"].([]map[string]string)
+	}
+
+	return b.Set(Defaults, "policyServer.env", append(env, map[string]string{"name": name, "value": value}))
+}
+
+/*
+Use the FIPS builds of the Kubewarden images
+  - @remarks FIPS builds are published as separate repositories, usually in a product registry
+  - @param prefix Repository prefix of the FIPS images, e.g. rancher/fips
+  - @returns The builder
+*/
+func (b *Builder) FIPS(prefix string) *Builder {
+	return b.
+		Set(Controller, "image.repository", prefix+"/kubewarden-controller").
+		Set(Controller, "auditScanner.image.repository", prefix+"/audit-scanner").
+		Set(Defaults, "policyServer.image.repository", prefix+"/policy-server")
+}
+
+/*
+Get the values of a chart in YAML format
+  - @param chart Name of the chart
+  - @returns The values, empty if none is set
+*/
+func (b *Builder) YAML(chart string) (string, error) {
+	if len(b.values[chart]) == 0 {
+		return "", nil
+	}
+
+	data, err := yaml.Marshal(b.values[chart])
+	return string(data), err
+}
+
+/*
+Get the values of a chart
+  - @remarks For APIs taking the values as an object, e.g. the Rancher Apps
+  - @param chart Name of the chart
+  - @returns The values, nil if none is set
+*/
+func (b *Builder) Values(chart string) map[string]any {
+	return b.values[chart]
+}
+
+/*
+Write the values of a chart in a file
+  - @param chart Name of the chart
+  - @param dir Directory where the file is created
+  - @returns The Helm flags to use the file, none if no value is set, or an error
+*/
+func (b *Builder) Flags(chart, dir string) ([]string, error) {
+	data, err := b.YAML(chart)
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp(dir, chart+"-values-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(data); err != nil {
+		return nil, err
+	}
+
+	return []string{"--values", f.Name()}, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/values"
+)
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *values.Builder
+		chart   string
+		yaml    string
+	}{
+		{"nothing set", values.New(), values.Controller, ""},
+		{"other chart", values.New().Telemetry(true), values.Defaults, ""},
+		{"last value wins", values.New().ControllerReplicas(1).ControllerReplicas(3), values.Controller, "replicas: 3\n"},
+		{
+			"siblings are kept",
+			values.New().Telemetry(true).Set(values.Controller, "telemetry.tracing", false),
+			values.Controller,
+			"telemetry:\n    metrics: true\n    tracing: false\n",
+		},
+		{
+			"scalar replaced by a map",
+			values.New().Set(values.Controller, "image", "kubewarden").Set(values.Controller, "image.tag", "v1"),
+			values.Controller,
+			"image:\n    tag: v1\n",
+		},
+		{
+			"defaults overridden",
+			values.Default().RecommendedPolicies(true, "monitor"),
+			values.Defaults,
+			"recommendedPolicies:\n    defaultPolicyMode: monitor\n    enabled: true\n",
+		},
+		{
+			"environment appended",
+			values.New().PolicyServerEnv("KUBEWARDEN_LOG_FMT", "json").PolicyServerEnv("KUBEWARDEN_LOG_LEVEL", "info"),
+			values.Defaults,
+			"policyServer:\n    env:\n        - name: KUBEWARDEN_LOG_FMT\n          value: json\n        - name: KUBEWARDEN_LOG_LEVEL\n          value: info\n",
+		},
+		{
+			"insecure policies registry",
+			values.New().PoliciesRegistry("registry.local:5000", true),
+			values.Defaults,
+			"policyServer:\n    insecureSources:\n        - registry.local:5000\nrecommendedPolicies:\n    defaultPoliciesRegistry: registry.local:5000\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.YAML(tt.chart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.yaml {
+				t.Errorf("values are:\n%s\nexpected:\n%s", got, tt.yaml)
+			}
+		})
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	b := values.New().Registry("registry.suse.com")
+
+	for _, chart := range []string{values.CRDs, values.Controller, values.Defaults} {
+		got, err := b.YAML(chart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "global:\n    cattle:\n        systemDefaultRegistry: registry.suse.com\n"; got != want {
+			t.Errorf("values of %s are:\n%s", chart, got)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	dir := t.TempDir()
+	b := values.New().Telemetry(false)
+
+	flags, err := b.Flags(values.Defaults, dir)
+	if err != nil || len(flags) != 0 {
+		t.Errorf("flags of a chart without values are %v (%v), none expected", flags, err)
+	}
+
+	flags, err = b.Flags(values.Controller, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flags) != 2 || flags[0] != "--values" || filepath.Dir(flags[1]) != dir ||
+		!strings.HasPrefix(filepath.Base(flags[1]), values.Controller+"-values-") {
+		t.Fatalf("unexpected flags %v", flags)
+	}
+
+	data, err := os.ReadFile(flags[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "telemetry:\n    metrics: false\n    tracing: false\n"; string(data) != want {
+		t.Errorf("values file is:\n%s", data)
+	}
+
+	// Each call writes a new file, a previous installation keeps its own
+	again, err := b.Flags(values.Controller, dir)
+	if err != nil || len(again) != 2 || again[1] == flags[1] {
+		t.Errorf("second values file is %v (%v)", again, err)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+/*
+Get the canonical form of a version
+  - @remarks Helm app versions are not always prefixed with v
+  - @param v Version to convert
+  - @returns Version in vMAJOR.MINOR.PATCH[-PRERELEASE] format, empty if invalid
+*/
+func Canonical(v string) string {
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+
+	return semver.Canonical(v)
+}
+
+/*
+Check that a version is at least the minimal one
+  - @remarks Pre-releases of the minimal version are accepted, as they already provide the feature
+  - @param v Version to check
+  - @param min Minimal version
+  - @returns True if v >= min, false if one of them is invalid
+*/
+func AtLeast(v, min string) bool {
+	v, min = Canonical(v), Canonical(min)
+	if v == "" || min == "" {
+		return false
+	}
+
+	// Compare without the pre-release part, v1.17.0-rc1 provides v1.17.0 features
+	release, _, _ := strings.Cut(v, "-")
+
+	return semver.Compare(release, min) >= 0
+}
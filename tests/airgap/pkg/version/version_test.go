@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version_test
+
+import (
+	"testing"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/version"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct{ version, canonical string }{
+		{"v1.17.0", "v1.17.0"},
+		{"1.17.0", "v1.17.0"},
+		{"1.17", "v1.17.0"},
+		{"1.17.0-rc1", "v1.17.0-rc1"},
+		{"1.17.0+build", "v1.17.0"},
+		{"latest", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := version.Canonical(tt.version); got != tt.canonical {
+			t.Errorf("canonical form of %q is %q, %q expected", tt.version, got, tt.canonical)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		name, version, min string
+		atLeast            bool
+	}{
+		{"same", "1.17.0", "v1.17.0", true},
+		{"newer patch", "1.17.2", "1.17.0", true},
+		{"newer minor", "1.18.0", "1.17.0", true},
+		{"older", "1.16.3", "1.17.0", false},
+		{"pre-release of the minimal", "1.17.0-rc1", "1.17.0", true},
+		{"pre-release of an older", "1.16.0-rc1", "1.17.0", false},
+		{"invalid version", "main", "1.17.0", false},
+		{"invalid minimal", "1.17.0", "next", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := version.AtLeast(tt.version, tt.min); got != tt.atLeast {
+				t.Errorf("AtLeast(%q, %q) is %v", tt.version, tt.min, got)
+			}
+		})
+	}
+}
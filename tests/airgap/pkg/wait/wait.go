@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/timeouts"
+	"github.com/onsi/gomega/types"
+)
+
+// Condition returns the observed state and a nil error when done
+type Condition func(ctx context.Context) (string, error)
+
+// Error of a condition that will never be met, the wait stops immediately
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Options of a wait, unset fields use defaults
+type Options struct {
+	Class       timeouts.Class
+	Description string
+	Timeout     time.Duration
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Factor      float64
+	// Called after each failed attempt with the status of the wait, e.g. to log the progress
+	OnRetry func(status string)
+}
+
+/*
+Wait for a condition with exponential backoff
+  - @param ctx Context, its deadline is kept if sooner than the timeout
+  - @param cond Condition to check
+  - @param opts Options of the wait
+  - @returns Nothing or an error with the last observed state
+*/
+func For(ctx context.Context, cond Condition, opts Options) error {
+	if opts.Timeout == 0 {
+		opts.Timeout = timeouts.For(opts.Class)
+	}
+	if opts.Interval == 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.MaxInterval == 0 {
+		opts.MaxInterval = 30 * time.Second
+	}
+	if opts.Factor < 1 {
+		opts.Factor = 2
+	}
+	if opts.Description == "" {
+		opts.Description = "condition"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	interval := opts.Interval
+	for attempt := 1; ; attempt++ {
+		state, err := cond(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return fmt.Errorf("%s cannot be met after %s and %d attempts, last state: %q: %w",
+				opts.Description, time.Since(start).Round(time.Second), attempt, state, permanent.err)
+		}
+
+		if opts.OnRetry != nil {
+			opts.OnRetry(fmt.Sprintf("waiting for %s since %s, %d attempts, last state: %q",
+				opts.Description, time.Since(start).Round(time.Second), attempt, state))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not met after %s and %d attempts (%v), last state: %q: %w",
+				opts.Description, time.Since(start).Round(time.Second), attempt, ctx.Err(), state, err)
+		case <-time.After(interval):
+		}
+
+		interval = time.Duration(float64(interval) * opts.Factor)
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
+
+/*
+Stop a wait, the condition will never be met
+  - @param err Reason of the failure
+  - @returns Error to return from a condition
+*/
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+/*
+Get a condition from a check function
+  - @param check Function returning an error until done
+  - @returns Condition with the error as state
+*/
+func Check(check func() error) Condition {
+	return func(context.Context) (string, error) {
+		if err := check(); err != nil {
+			return err.Error(), err
+		}
+
+		return "", nil
+	}
+}
+
+/*
+Get a condition from a value and a Gomega matcher
+  - @param value Function returning the observed value
+  - @param matcher Matcher the value should satisfy
+  - @returns Condition with the value as state
+*/
+func Match(value func() string, matcher types.GomegaMatcher) Condition {
+	return func(context.Context) (string, error) {
+		out := value()
+
+		ok, err := matcher.Match(out)
+		if err != nil {
+			return out, err
+		}
+		if !ok {
+			return out, fmt.Errorf("%s", matcher.FailureMessage(out))
+		}
+
+		return out, nil
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubewarden/kubewarden-end-to-end-tests/tests/airgap/pkg/wait"
+	. "github.com/onsi/gomega"
+)
+
+// Short intervals, the tests do not wait for a cluster
+var fast = wait.Options{Description: "test", Timeout: time.Second, Interval: time.Millisecond, MaxInterval: 10 * time.Millisecond}
+
+func TestFor(t *testing.T) {
+	attempts := 0
+	var retries []string
+
+	opts := fast
+	opts.OnRetry = func(status string) { retries = append(retries, status) }
+
+	err := wait.For(context.Background(), wait.Check(func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("%d policies active", attempts)
+		}
+		return nil
+	}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 || len(retries) != 2 {
+		t.Errorf("%d attempts and %d retries, 3 and 2 expected", attempts, len(retries))
+	}
+	if len(retries) > 0 && !strings.Contains(retries[len(retries)-1], `2 attempts, last state: "2 policies active"`) {
+		t.Errorf("unexpected retry status %q", retries[len(retries)-1])
+	}
+}
+
+func TestForTimeout(t *testing.T) {
+	opts := fast
+	opts.Timeout = 50 * time.Millisecond
+
+	err := wait.For(context.Background(), wait.Match(func() string { return "InProgress" }, Equal("Completed")), opts)
+	if err == nil {
+		t.Fatal("wait should time out")
+	}
+	if !strings.Contains(err.Error(), `test not met after`) || !strings.Contains(err.Error(), `last state: "InProgress"`) {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
+func TestForPermanent(t *testing.T) {
+	attempts := 0
+	reason := errors.New("backup failed")
+
+	err := wait.For(context.Background(), wait.Check(func() error {
+		attempts++
+		return wait.Permanent(reason)
+	}), fast)
+
+	if !errors.Is(err, reason) {
+		t.Errorf("error %v should wrap %v", err, reason)
+	}
+	if attempts != 1 {
+		t.Errorf("%d attempts, a permanent error must stop the wait", attempts)
+	}
+}
+
+func TestForContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	opts := fast
+	opts.Timeout = time.Hour
+
+	start := time.Now()
+	err := wait.For(ctx, wait.Check(func() error { return errors.New("not ready") }), opts)
+	if err == nil {
+		t.Fatal("wait should stop with the context")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("wait took %s, the deadline of the context is not kept", time.Since(start))
+	}
+}
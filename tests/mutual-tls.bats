@@ -47,12 +47,12 @@ function check_service_mtls {
     # Check mTLS is enabled in kubernetes
     kubectl get nodes -l node-role.kubernetes.io/control-plane -o yaml | grep -F "admission-control-config-file"
     # Check default PS logs
-    kubectl logs -n kubewarden -l kubewarden/policy-server=default | grep -E "certs: Loaded client CA certificates client_ca_certs_added=[1-9]"
+    kubectl logs -n $NAMESPACE -l kubewarden/policy-server=default | grep -E "certs: Loaded client CA certificates client_ca_certs_added=[1-9]"
 
     # Check what services require certificates
-    check_service_mtls https://kubewarden-controller-webhook-service.kubewarden.svc:443/validate-policies-kubewarden-io-v1-policyserver
-    check_service_mtls https://policy-server-default.kubewarden.svc:443/validate
-    check_service_mtls https://policy-server-mtls-pserver.kubewarden.svc:443/validate
+    check_service_mtls https://kubewarden-controller-webhook-service.$NAMESPACE.svc:443/validate-policies-kubewarden-io-v1-policyserver
+    check_service_mtls https://policy-server-default.$NAMESPACE.svc:443/validate
+    check_service_mtls https://policy-server-mtls-pserver.$NAMESPACE.svc:443/validate
 
     # Check protected policy still blocks requests
     apply_policy safe-labels-pods-policy.yaml
@@ -63,10 +63,10 @@ function check_service_mtls {
     helmer set kubewarden-controller --set mTLS.enable=false
 
     # Talk to services without a certificate
-    curlpod https://kubewarden-controller-webhook-service.kubewarden.svc:443/validate-policies-kubewarden-io-v1-policyserver
-    curlpod https://policy-server-default.kubewarden.svc:443/validate
-    curlpod https://policy-server-mtls-pserver.kubewarden.svc:443/validate
+    curlpod https://kubewarden-controller-webhook-service.$NAMESPACE.svc:443/validate-policies-kubewarden-io-v1-policyserver
+    curlpod https://policy-server-default.$NAMESPACE.svc:443/validate
+    curlpod https://policy-server-mtls-pserver.$NAMESPACE.svc:443/validate
 
     # Check mTLS is disabled in on policy server log (grep -vz negates search)
-    kubectl logs -n kubewarden -l kubewarden/policy-server=default | grep -vzE "certs: Loaded client CA certificates client_ca_certs_added=[1-9]"
+    kubectl logs -n $NAMESPACE -l kubewarden/policy-server=default | grep -vzE "certs: Loaded client CA certificates client_ca_certs_added=[1-9]"
 }
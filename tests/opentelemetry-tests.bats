@@ -78,7 +78,7 @@ export -f get_metrics # required by retry command
     wait_pods -n $NAMESPACE
 
     # Check all pods have sidecar (otc-container) - might take a minute to start
-    retry "kubectl get pods -n kubewarden --field-selector=status.phase==Running -o json | jq -e '[.items[].spec.containers[1].name == \"otc-container\"] | all'"
+    retry "kubectl get pods -n $NAMESPACE --field-selector=status.phase==Running -o json | jq -e '[.items[].spec.containers[1].name == \"otc-container\"] | all'"
     # Policy server service has the metrics ports
     kubectl get services -n $NAMESPACE  policy-server-default -o json | jq -e '[.spec.ports[].name == "metrics"] | any'
     # Controller service has the metrics ports
@@ -122,7 +122,7 @@ export -f get_metrics # required by retry command
     wait_pods -n $NAMESPACE
 
     # Check sidecars (otc-container) - have been removed
-    retry "kubectl get pods -n kubewarden -o json | jq -e '[.items[].spec.containers[1].name != \"otc-container\"] | all'"
+    retry "kubectl get pods -n $NAMESPACE -o json | jq -e '[.items[].spec.containers[1].name != \"otc-container\"] | all'"
     # Policy server service has no metrics ports
     kubectl get services -n $NAMESPACE policy-server-default -o json | jq -e '[.spec.ports[].name != "metrics"] | all'
     # Controller service has no metrics ports
@@ -133,7 +133,10 @@ export -f get_metrics # required by retry command
     kubectl apply --namespace $NAMESPACE -f $RESOURCES_DIR/otel-collector-deployment.yaml
     wait_pods -n $NAMESPACE
 
-    helmer set kubewarden-controller --values $RESOURCES_DIR/opentelemetry-telemetry-remote.yaml
+    # Collector is deployed in the Kubewarden namespace
+    local values="$BATS_RUN_TMPDIR/opentelemetry-telemetry-remote.yaml"
+    envsubst '$NAMESPACE' < "$RESOURCES_DIR/opentelemetry-telemetry-remote.yaml" > "$values"
+    helmer set kubewarden-controller --values "$values"
     helmer set kubewarden-defaults --set recommendedPolicies.enabled=True
     wait_pods -n $NAMESPACE
 }
@@ -0,0 +1,82 @@
+#!/usr/bin/env bats
+# Requires multi-node cluster: make cluster WORKER_COUNT=2
+
+setup() {
+    setup_helper
+
+    # Drain needs somewhere to move policy-server pods
+    local nodes
+    nodes=$(kubectl get nodes --no-headers | wc -l)
+    [ "$nodes" -ge 2 ] || skip "requires at least 2 nodes (found $nodes)"
+}
+
+teardown_file() {
+    # First, a drained node must not be left behind if the cleanup fails
+    kubectl uncordon $(kubectl get nodes -o jsonpath='{.items[*].metadata.name}')
+    teardown_helper
+    kubectl delete ps ha-server --ignore-not-found
+}
+
+@test "$(tfile) Create policy server with PodDisruptionBudget" {
+    kubectl apply -f - <<EOF
+apiVersion: policies.kubewarden.io/v1
+kind: PolicyServer
+metadata:
+  name: ha-server
+spec:
+  image: $(kubectl get ps default -o json | jq -er '.spec.image')
+  replicas: 2
+  minAvailable: 1
+  affinity:
+    podAntiAffinity:
+      preferredDuringSchedulingIgnoredDuringExecution:
+      - weight: 100
+        podAffinityTerm:
+          topologyKey: kubernetes.io/hostname
+          labelSelector:
+            matchLabels:
+              app.kubernetes.io/instance: policy-server-ha-server
+EOF
+    wait_policyserver ha-server
+
+    # Controller creates PDB with the same name as deployment
+    kubectl get pdb -n $NAMESPACE policy-server-ha-server -o json | jq -e '.spec.minAvailable == 1'
+}
+
+@test "$(tfile) Apply policy on HA policy server" {
+    yq '.spec.policyServer = "ha-server"' $RESOURCES_DIR/policies/privileged-pod-policy.yaml | apply_policy
+    wait_policyserver ha-server
+
+    kubefail_privileged run pod-privileged --image=rancher/pause:3.2 --privileged
+}
+
+@test "$(tfile) Admission is available while node is drained" {
+    local node pid
+    node=$(kubectl get pods -n $NAMESPACE -l app.kubernetes.io/instance=policy-server-ha-server -o json | jq -er '.items[0].spec.nodeName')
+
+    # Drain respects PDB, so at least one policy-server replica should serve requests
+    kubectl drain "$node" --ignore-daemonsets --delete-emptydir-data --timeout=5m &
+    pid=$!
+
+    # Webhook call fails with different error if policy-server is not reachable
+    while kill -0 $pid 2>/dev/null; do
+        kubefail_privileged run pod-privileged --image=rancher/pause:3.2 --privileged
+        sleep 2
+    done
+    wait $pid
+
+    # Pods were moved out of drained node
+    wait_policyserver ha-server
+    kubectl get pods -n $NAMESPACE -l app.kubernetes.io/instance=policy-server-ha-server -o json \
+        | jq -e --arg n "$node" '[.items[].spec.nodeName != $n] | all'
+    kubefail_privileged run pod-privileged --image=rancher/pause:3.2 --privileged
+
+    kubectl uncordon "$node"
+}
+
+@test "$(tfile) Delete HA policy server" {
+    kubectl delete --wait cap privileged-pods
+    kubectl delete --wait ps ha-server
+    run kubectl get pdb -n $NAMESPACE policy-server-ha-server
+    assert_failure
+}
@@ -19,7 +19,7 @@ teardown_file() {
         --set policyServer.imagePullSecret=null \
         --set policyServer.sourceAuthorities=null
     # Can't delete secret - https://github.com/kubewarden/policy-server/issues/459
-    # kubectl --namespace kubewarden delete secret secret-registry-docker ||:
+    # kubectl --namespace $NAMESPACE delete secret secret-registry-docker ||:
 
     kubectl delete -f $RESOURCES_DIR/private-registry-deploy.yaml --ignore-not-found
     kubectl delete cm registry-auth --ignore-not-found
@@ -59,7 +59,7 @@ teardown_file() {
 # https://docs.kubewarden.io/operator-manual/policy-servers/private-registry
 @test "$(tfile) Set up policy server access to registry" {
     # Create secret to access registry
-    kubectl --namespace kubewarden create secret docker-registry secret-registry-docker \
+    kubectl --namespace $NAMESPACE create secret docker-registry secret-registry-docker \
       --docker-username=testuser \
       --docker-password=testpassword \
       --docker-server=$REGISTRY